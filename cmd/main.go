@@ -4,8 +4,17 @@ import (
 	"Forum_BE/config"
 	"Forum_BE/infrastructure"
 	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"Forum_BE/routes"
+	"Forum_BE/utils"
 	"log"
 
 	"github.com/gin-contrib/cors"
@@ -15,6 +24,9 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	if err := utils.CheckCursorSecret(); err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
 	redisClient := config.InitRedis()
 
 	// Connect to MySQL
@@ -46,11 +58,44 @@ func main() {
 		&models.TopicFollow{},
 		&models.QuestionFollow{},
 		&models.UserFollow{},
+		&models.Draft{},
+		&models.QuestionCloseVote{},
+		&models.QuestionEvent{},
+		&models.SuggestedEdit{},
+		&models.Poll{},
+		&models.PollOption{},
+		&models.PollBallot{},
+		&models.PollBallotChoice{},
+		&models.Mention{},
+		&models.BookmarkCollection{},
+		&models.Bookmark{},
+		&models.FeedToken{},
+		&models.SlugHistory{},
+		&models.SitemapSegment{},
+		&models.AnswerRequest{},
+		&models.ModerationClaim{},
+		&models.ModerationLog{},
+		&models.AutoModerationRule{},
+		&models.AutoModerationLog{},
+		&models.ContentFingerprint{},
+		&models.SpamTrainingSample{},
+		&models.SpamModelVersion{},
 		//&models.QuestionTopic{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+	// Tính path cho các bình luận tạo trước khi có cây bình luận (không làm gì nếu đã đủ)
+	commentRepo := repositories.NewCommentRepository(db)
+	if count, err := commentRepo.BackfillPaths(); err != nil {
+		log.Println("Failed to backfill comment paths:", err)
+	} else if count > 0 {
+		log.Println("Backfilled paths for", count, "comments")
+	}
+	// Tính plain_content (dùng cho tìm kiếm toàn văn) cho các câu hỏi và bình luận tạo trước khi có cột này
+	questionRepo := repositories.NewQuestionRepository(db)
+	backfillPlainContent("questions", questionRepo.ListMissingPlainContent, questionRepo.UpdatePlainContent)
+	backfillPlainContent("comments", commentRepo.ListMissingPlainContent, commentRepo.UpdatePlainContent)
 
 	// Initialize Gin router
 	r := gin.Default()
@@ -65,10 +110,54 @@ func main() {
 	}))
 
 	// Setup routes với redisClient
-	routes.SetupRoutes(r, db, cfg.JWTSecret, redisClient)
+	shutdown := routes.SetupRoutes(r, db, cfg.JWTSecret, redisClient)
+
+	// Start server; khi nhận SIGINT/SIGTERM thì dừng nhận request rồi đóng chỉ mục tìm kiếm
+	// để journal được gộp vào snapshot và khoá thư mục chỉ mục được nhả
+	srv := &http.Server{Addr: ":" + cfg.ServerPort, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to run server:", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
 
-	// Start server
-	if err := r.Run(":" + cfg.ServerPort); err != nil {
-		log.Fatal("Failed to run server:", err)
+	log.Println("Shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Failed to shut down server gracefully:", err)
+	}
+	shutdown()
+}
+
+// backfillPlainContent chuyển HTML của các bản ghi cũ thành văn bản thuần theo từng lô (không làm gì nếu đã đủ)
+func backfillPlainContent(name string, list func(afterID uint, limit int) ([]repositories.PlainContentRow, error), update func(id uint, plain string) error) {
+	const batchSize = 500
+	var afterID uint
+	count := 0
+	for {
+		rows, err := list(afterID, batchSize)
+		if err != nil {
+			log.Println("Failed to backfill plain content of", name+":", err)
+			return
+		}
+		for _, row := range rows {
+			afterID = row.ID
+			if err := update(row.ID, utils.StripHTML(row.Content)); err != nil {
+				log.Println("Failed to backfill plain content of", name+":", err)
+				return
+			}
+			count++
+		}
+		if len(rows) < batchSize {
+			break
+		}
+	}
+	if count > 0 {
+		log.Println("Backfilled plain content for", count, name)
 	}
 }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"Forum_BE/config"
+	"Forum_BE/infrastructure"
+	"Forum_BE/repositories"
+	"Forum_BE/searchindex"
+	"Forum_BE/services"
+	"errors"
+	"flag"
+	"log"
+	"os"
+)
+
+// Dựng lại toàn bộ chỉ mục tìm kiếm nhúng từ MySQL.
+// Chạy khi server đang dừng: go run ./cmd/reindex -dir ./data/search
+// Khi server đang chạy, chỉ mục bị server khoá nên lệnh này từ chối chạy; dùng POST /api/search/reindex.
+func main() {
+	defaultDir := os.Getenv("SEARCH_INDEX_DIR")
+	if defaultDir == "" {
+		defaultDir = "./data/search"
+	}
+	dir := flag.String("dir", defaultDir, "thư mục chứa chỉ mục tìm kiếm")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	db, err := infrastructure.ConnectMySQL(cfg.DBDSN)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	index, err := searchindex.NewDiskIndex(*dir)
+	if errors.Is(err, searchindex.ErrIndexLocked) {
+		log.Fatalf("Search index at %s is in use (is the server running?); use POST /api/search/reindex instead", *dir)
+	}
+	if err != nil {
+		log.Fatal("Failed to open search index:", err)
+	}
+	defer index.Close()
+
+	searchIndexService := services.NewSearchIndexService(repositories.NewSearchRepository(db), index)
+	count, err := searchIndexService.Reindex()
+	if err != nil {
+		log.Fatal("Failed to rebuild search index:", err)
+	}
+	log.Printf("Indexed %d documents into %s", count, *dir)
+}
@@ -69,6 +69,9 @@ func InitPermissions() {
 			"delete":              {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
 			"change_status":       {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
 			"change_inter_status": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"close":               {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
+			"lock":                {models.RoleRoot, models.RoleAdmin},
+			"vote_close":          {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
 		},
 		"answer": {
 			"create":      {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
@@ -161,7 +164,7 @@ func InitPermissions() {
 		"pass": {
 			"create": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
 			"view":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
-			"delete": {models.RoleRoot},
+			"delete": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
 		},
 		"permission": {
 			"create": {models.RoleRoot},
@@ -175,6 +178,67 @@ func InitPermissions() {
 		"activities": {
 			"view": {models.RoleRoot, models.RoleAdmin},
 		},
+		"schedule": {
+			"view":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"edit":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"delete": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"manage": {models.RoleRoot, models.RoleAdmin},
+		},
+		"suggested_edit": {
+			"create": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"view":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"review": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
+		},
+		"poll": {
+			"create": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"view":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"vote":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"manage": {models.RoleRoot, models.RoleAdmin},
+		},
+		"draft": {
+			"create": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"view":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"delete": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+		},
+		"search": {
+			"view":    {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"reindex": {models.RoleRoot, models.RoleAdmin},
+		},
+		"mention": {
+			"autocomplete": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"view":         {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+		},
+		"bookmark": {
+			"create": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"view":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"edit":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"delete": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+		},
+		"feed": {
+			"view": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+		},
+		"answer_request": {
+			"create": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"view":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+			"edit":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee, models.RoleUser},
+		},
+		"anonymous": {
+			"reveal": {models.RoleRoot, models.RoleAdmin},
+		},
+		"moderation": {
+			"view":   {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
+			"claim":  {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
+			"review": {models.RoleRoot, models.RoleAdmin, models.RoleEmployee},
+			"stats":  {models.RoleRoot, models.RoleAdmin},
+		},
+		"auto_moderation": {
+			"view":   {models.RoleRoot, models.RoleAdmin},
+			"manage": {models.RoleRoot, models.RoleAdmin},
+		},
+		"spam_classifier": {
+			"view":   {models.RoleRoot, models.RoleAdmin},
+			"manage": {models.RoleRoot, models.RoleAdmin},
+		},
 	}
 
 	// Derive resources from allowedPermissions keys
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// canRevealAnonymous cho biết người xem được thấy danh tính thật của nội dung ẩn danh
+// (cờ do middleware RevealAnonymous gắn vào context)
+func canRevealAnonymous(c *gin.Context) bool {
+	return c.GetBool("reveal_anonymous")
+}
+
+// excludeAnonymousFromProfile loại nội dung ẩn danh khi danh sách được lọc theo tác giả (trang hồ sơ)
+// và người xem không phải chính tác giả cũng không có quyền xem danh tính
+func excludeAnonymousFromProfile(c *gin.Context, filters map[string]interface{}) {
+	authorID, ok := filters["user_id"].(uint)
+	if ok && authorID != c.GetUint("user_id") && !canRevealAnonymous(c) {
+		filters["exclude_anonymous"] = true
+	}
+}
+
+// Các hàm dưới đây dựng response như thường (tác giả ẩn danh đã được thay bằng bí danh)
+// và gắn thêm danh tính thật nếu người xem có quyền
+func questionResponse(c *gin.Context, question *models.Question) responses.QuestionResponse {
+	response := responses.ToQuestionResponse(question)
+	if canRevealAnonymous(c) {
+		response.RevealAuthor(question)
+	}
+	return response
+}
+
+func answerResponse(c *gin.Context, answer *models.Answer) responses.AnswerResponse {
+	response := responses.ToAnswerResponse(answer)
+	if canRevealAnonymous(c) {
+		response.RevealAuthor(answer)
+	}
+	return response
+}
+
+func commentResponse(c *gin.Context, comment *models.Comment) responses.CommentResponse {
+	response := responses.ToCommentResponse(comment)
+	if canRevealAnonymous(c) {
+		response.RevealAuthor(comment)
+	}
+	return response
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
@@ -11,20 +13,23 @@ import (
 )
 
 type AnswerController struct {
-	answerService services.AnswerService
+	answerService   services.AnswerService
+	bookmarkService services.BookmarkService
+	reactionCounter services.ReactionCounter
 }
 
-func NewAnswerController(a services.AnswerService) *AnswerController {
-	return &AnswerController{answerService: a}
+func NewAnswerController(a services.AnswerService, b services.BookmarkService, r services.ReactionCounter) *AnswerController {
+	return &AnswerController{answerService: a, bookmarkService: b, reactionCounter: r}
 }
 
 func (ac *AnswerController) CreateAnswer(c *gin.Context) {
 	var req struct {
-		Title      string `json:"title" binding:"required"`
-		Content    string `json:"content" binding:"required"`
-		QuestionID uint   `json:"questionId" binding:"required"`
-		Tags       []uint `json:"tags"`
-		Status     string `json:"status"`
+		Title         string `json:"title" binding:"required"`
+		Content       string `json:"content" binding:"required"`
+		QuestionID    uint   `json:"questionId" binding:"required"`
+		Tags          []uint `json:"tags"`
+		ContentFormat string `json:"contentFormat"` // html (mặc định) hoặc markdown
+		Anonymous     bool   `json:"anonymous"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -34,7 +39,7 @@ func (ac *AnswerController) CreateAnswer(c *gin.Context) {
 
 	userID := c.GetUint("user_id")
 
-	answer, err := ac.answerService.CreateAnswer(req.Content, userID, req.QuestionID, req.Tags, req.Title, req.Status)
+	answer, err := ac.answerService.CreateAnswer(req.Content, userID, req.QuestionID, req.Tags, req.Title, req.ContentFormat, req.Anonymous)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -60,8 +65,13 @@ func (ac *AnswerController) GetAnswer(c *gin.Context) {
 		return
 	}
 
+	response := answerResponse(c, answer)
+	response.Bookmarked = ac.bookmarkService.BookmarkedIDs(c.GetUint("user_id"), models.BookmarkAnswer, []uint{answer.ID})[answer.ID]
+	reactions := ac.reactionCounter.Counts(models.ReactionTargetAnswer, []uint{answer.ID})[answer.ID]
+	response.Reactions, response.ReactionCount = reactions, int(reactions.Total())
+
 	c.JSON(http.StatusOK, gin.H{
-		"answer": responses.ToAnswerResponse(answer),
+		"answer": response,
 	})
 }
 
@@ -74,10 +84,10 @@ func (ac *AnswerController) EditAnswer(c *gin.Context) {
 	}
 
 	var req struct {
-		Title   string `json:"title"`
-		Content string `json:"content" binding:"required"`
-		Status  string `json:"status"`
-		Tags    []uint `json:"tags"`
+		Title         string `json:"title"`
+		Content       string `json:"content" binding:"required"`
+		Tags          []uint `json:"tags"`
+		ContentFormat string `json:"contentFormat"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -85,7 +95,7 @@ func (ac *AnswerController) EditAnswer(c *gin.Context) {
 		return
 	}
 
-	answer, err := ac.answerService.UpdateAnswer(uint(id), req.Title, req.Content, req.Status, req.Tags)
+	answer, err := ac.answerService.UpdateAnswer(uint(id), req.Title, req.Content, req.Tags, req.ContentFormat)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -179,6 +189,9 @@ func (ac *AnswerController) ListAnswers(c *gin.Context) {
 	filters["limit"] = limit
 	filters["page"] = page
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	answers, total, err := ac.answerService.ListAnswers(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "không thể tải danh sách câu trả lời"})
@@ -187,13 +200,20 @@ func (ac *AnswerController) ListAnswers(c *gin.Context) {
 
 	var responseAnswers []responses.AnswerResponse
 	for _, answer := range answers {
-		responseAnswers = append(responseAnswers, responses.ToAnswerResponse(&answer))
+		responseAnswers = append(responseAnswers, answerResponse(c, &answer))
 	}
+	markBookmarked(ac.bookmarkService, c.GetUint("user_id"), models.BookmarkAnswer, responseAnswers, func(r *responses.AnswerResponse) (uint, *bool) {
+		return r.ID, &r.Bookmarked
+	})
+	markReactionCounts(ac.reactionCounter, models.ReactionTargetAnswer, responseAnswers, func(r *responses.AnswerResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"answers": responseAnswers,
-		"total":   total,
-	})
+	}, filters, answers, func(item *models.Answer) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (ac *AnswerController) GetAllAnswers(c *gin.Context) {
@@ -247,6 +267,11 @@ func (ac *AnswerController) GetAllAnswers(c *gin.Context) {
 		}
 	}
 
+	excludeAnonymousFromProfile(c, filters)
+
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	answers, total, err := ac.answerService.GetAllAnswers(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -255,13 +280,20 @@ func (ac *AnswerController) GetAllAnswers(c *gin.Context) {
 
 	var responseAnswers []responses.AnswerResponse
 	for _, answer := range answers {
-		responseAnswers = append(responseAnswers, responses.ToAnswerResponse(&answer))
+		responseAnswers = append(responseAnswers, answerResponse(c, &answer))
 	}
+	markBookmarked(ac.bookmarkService, c.GetUint("user_id"), models.BookmarkAnswer, responseAnswers, func(r *responses.AnswerResponse) (uint, *bool) {
+		return r.ID, &r.Bookmarked
+	})
+	markReactionCounts(ac.reactionCounter, models.ReactionTargetAnswer, responseAnswers, func(r *responses.AnswerResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"answers": responseAnswers,
-		"total":   total,
-	})
+	}, filters, answers, func(item *models.Answer) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (ac *AnswerController) UpdateAnswerStatus(c *gin.Context) {
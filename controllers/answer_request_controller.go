@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"Forum_BE/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type AnswerRequestController struct {
+	answerRequestService services.AnswerRequestService
+}
+
+func NewAnswerRequestController(s services.AnswerRequestService) *AnswerRequestController {
+	return &AnswerRequestController{answerRequestService: s}
+}
+
+// RequestAnswers nhờ những người trong userIds trả lời câu hỏi; người đã được nhờ hoặc đã trả lời được bỏ qua
+func (ac *AnswerRequestController) RequestAnswers(c *gin.Context) {
+	questionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID câu hỏi không hợp lệ"})
+		return
+	}
+	var req struct {
+		UserIDs []uint `json:"userIds" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requests, err := ac.answerRequestService.RequestAnswers(uint(questionID), c.GetUint("user_id"), req.UserIDs)
+	if err != nil {
+		answerRequestError(c, err)
+		return
+	}
+
+	result := make([]responses.AnswerRequestResponse, 0, len(requests))
+	for i := range requests {
+		result = append(result, responses.ToAnswerRequestResponse(&requests[i]))
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Đã gửi lời nhờ trả lời",
+		"requests": result,
+	})
+}
+
+func (ac *AnswerRequestController) ListForQuestion(c *gin.Context) {
+	questionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID câu hỏi không hợp lệ"})
+		return
+	}
+
+	requests, err := ac.answerRequestService.ListForQuestion(uint(questionID))
+	if err != nil {
+		answerRequestError(c, err)
+		return
+	}
+
+	result := make([]responses.AnswerRequestResponse, 0, len(requests))
+	for i := range requests {
+		result = append(result, responses.ToAnswerRequestResponse(&requests[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"requests": result})
+}
+
+// ListInbox liệt kê lời nhờ trả lời gửi tới người dùng hiện tại, mặc định những lời nhờ đang chờ (?status=all để lấy tất cả)
+func (ac *AnswerRequestController) ListInbox(c *gin.Context) {
+	filters := make(map[string]interface{})
+	switch status := c.DefaultQuery("status", string(models.AnswerRequestPending)); status {
+	case "all":
+	default:
+		filters["status"] = status
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filters["page"] = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filters["limit"] = l
+		}
+	}
+
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
+	requests, total, err := ac.answerRequestService.ListInbox(c.GetUint("user_id"), filters)
+	if err != nil {
+		answerRequestError(c, err)
+		return
+	}
+
+	result := make([]responses.AnswerRequestResponse, 0, len(requests))
+	for i := range requests {
+		result = append(result, responses.ToAnswerRequestResponse(&requests[i]))
+	}
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"requests": result,
+	}, filters, requests, func(item *models.AnswerRequest) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
+}
+
+func (ac *AnswerRequestController) DeclineRequest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID lời nhờ không hợp lệ"})
+		return
+	}
+
+	if err := ac.answerRequestService.DeclineRequest(uint(id), c.GetUint("user_id")); err != nil {
+		answerRequestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Đã từ chối lời nhờ trả lời"})
+}
+
+func answerRequestError(c *gin.Context, err error) {
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "daily answer request limit"):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
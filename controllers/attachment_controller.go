@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -150,6 +152,9 @@ func (ac *AttachmentController) ListAttachments(c *gin.Context) {
 	filters["limit"] = limit
 	filters["page"] = page
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	attachments, total, err := ac.attachmentService.ListAttachments(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê tệp đính kèm"})
@@ -161,8 +166,9 @@ func (ac *AttachmentController) ListAttachments(c *gin.Context) {
 		responseAttachments = append(responseAttachments, responses.ToAttachmentResponse(&attachment))
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"attachments": responseAttachments,
-		"total":       total,
-	})
+	}, filters, attachments, func(item *models.Attachment) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
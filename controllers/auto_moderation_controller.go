@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"Forum_BE/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type AutoModerationController struct {
+	autoModService services.AutoModerationService
+}
+
+func NewAutoModerationController(s services.AutoModerationService) *AutoModerationController {
+	return &AutoModerationController{autoModService: s}
+}
+
+type autoModerationRuleRequest struct {
+	Name        string                          `json:"name" binding:"required"`
+	Description string                          `json:"description"`
+	Conditions  models.AutoModerationConditions `json:"conditions"`
+	Action      string                          `json:"action" binding:"required"`
+	Reason      string                          `json:"reason"`
+	Enabled     *bool                           `json:"enabled"`
+	DryRun      bool                            `json:"dryRun"`
+}
+
+func (req *autoModerationRuleRequest) input() services.AutoModerationRuleInput {
+	return services.AutoModerationRuleInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Conditions:  req.Conditions,
+		Action:      models.AutoModerationAction(req.Action),
+		Reason:      req.Reason,
+		Enabled:     req.Enabled,
+		DryRun:      req.DryRun,
+	}
+}
+
+// ListRules trả về mọi luật kèm số lần khớp trong 30 ngày gần nhất, tách riêng các lần chạy thử
+func (ac *AutoModerationController) ListRules(c *gin.Context) {
+	rules, summaries, err := ac.autoModService.ListRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy danh sách luật kiểm duyệt"})
+		return
+	}
+
+	responseRules := []responses.AutoModerationRuleResponse{}
+	for i := range rules {
+		responseRules = append(responseRules, responses.ToAutoModerationRuleResponse(&rules[i], summaries))
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": responseRules})
+}
+
+func (ac *AutoModerationController) GetRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID luật không hợp lệ"})
+		return
+	}
+
+	rule, err := ac.autoModService.GetRule(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy luật kiểm duyệt"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rule": responses.ToAutoModerationRuleResponse(rule, nil)})
+}
+
+func (ac *AutoModerationController) CreateRule(c *gin.Context) {
+	var req autoModerationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := ac.autoModService.CreateRule(c.GetUint("user_id"), req.input())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tạo luật kiểm duyệt thành công",
+		"rule":    responses.ToAutoModerationRuleResponse(rule, nil),
+	})
+}
+
+func (ac *AutoModerationController) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID luật không hợp lệ"})
+		return
+	}
+	var req autoModerationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := ac.autoModService.UpdateRule(uint(id), c.GetUint("user_id"), req.input())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cập nhật luật kiểm duyệt thành công",
+		"rule":    responses.ToAutoModerationRuleResponse(rule, nil),
+	})
+}
+
+func (ac *AutoModerationController) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID luật không hợp lệ"})
+		return
+	}
+
+	if err := ac.autoModService.DeleteRule(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy luật kiểm duyệt"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Đã xoá luật kiểm duyệt"})
+}
+
+// ListLogs trả về nhật ký các lần luật khớp; dryRun=true để xem các luật chạy thử lẽ ra đã làm gì
+func (ac *AutoModerationController) ListLogs(c *gin.Context) {
+	filters := map[string]interface{}{}
+	if ruleID := c.Query("ruleId"); ruleID != "" {
+		if id, err := strconv.ParseUint(ruleID, 10, 64); err == nil {
+			filters["rule_id"] = uint(id)
+		}
+	}
+	if dryRun := c.Query("dryRun"); dryRun != "" {
+		filters["dry_run"] = dryRun == "true"
+	}
+	if action := c.Query("action"); action != "" {
+		filters["action"] = action
+	}
+	if contentType := c.Query("type"); contentType != "" {
+		filters["content_type"] = contentType
+	}
+	if userID := c.Query("userId"); userID != "" {
+		if id, err := strconv.ParseUint(userID, 10, 64); err == nil {
+			filters["user_id"] = uint(id)
+		}
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filters["page"] = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filters["limit"] = l
+		}
+	}
+
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
+	entries, total, err := ac.autoModService.ListLogs(filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy nhật ký kiểm duyệt tự động"})
+		return
+	}
+
+	responseLogs := []responses.AutoModerationLogResponse{}
+	for i := range entries {
+		responseLogs = append(responseLogs, responses.ToAutoModerationLogResponse(&entries[i]))
+	}
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"logs": responseLogs,
+	}, filters, entries, func(item *models.AutoModerationLog) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
+}
+
+// TestRules chạy các luật đang bật (kể cả luật chạy thử) với nội dung mẫu mà không lưu gì,
+// để quản trị viên kiểm tra luật trước khi bật. userId (tuỳ chọn) dùng cho các điều kiện về tài khoản.
+func (ac *AutoModerationController) TestRules(c *gin.Context) {
+	var req struct {
+		ContentType string `json:"contentType" binding:"required"`
+		Event       string `json:"event"`
+		UserID      uint   `json:"userId"`
+		Title       string `json:"title"`
+		Body        string `json:"body" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.IsValidModerationContentType(req.ContentType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Loại nội dung không hợp lệ"})
+		return
+	}
+	if req.Event == "" {
+		req.Event = models.AutoModerationEventCreate
+	}
+
+	verdict := ac.autoModService.Evaluate(services.AutoModerationSubject{
+		ContentType: req.ContentType,
+		UserID:      req.UserID,
+		Event:       req.Event,
+		Title:       req.Title,
+		Body:        req.Body,
+	})
+	c.JSON(http.StatusOK, gin.H{"verdict": verdict})
+}
@@ -0,0 +1,366 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"Forum_BE/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type BookmarkController struct {
+	bookmarkService services.BookmarkService
+}
+
+func NewBookmarkController(b services.BookmarkService) *BookmarkController {
+	return &BookmarkController{bookmarkService: b}
+}
+
+// AddBookmark lưu nội dung; gọi lại với nội dung đã lưu sẽ chuyển nó sang bộ sưu tập mới
+func (bc *BookmarkController) AddBookmark(c *gin.Context) {
+	var req struct {
+		TargetType   string `json:"targetType" binding:"required"`
+		TargetID     uint   `json:"targetId" binding:"required"`
+		CollectionID *uint  `json:"collectionId"`
+		Note         string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookmark, err := bc.bookmarkService.AddBookmark(c.GetUint("user_id"), services.BookmarkInput{
+		TargetType:   models.BookmarkTargetType(req.TargetType),
+		TargetID:     req.TargetID,
+		CollectionID: req.CollectionID,
+		Note:         req.Note,
+	})
+	if err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Đã lưu nội dung",
+		"bookmark": responses.ToBookmarkResponse(bookmark, true, nil, nil, nil),
+	})
+}
+
+// UpdateBookmark sửa ghi chú hoặc chuyển bookmark sang bộ sưu tập khác (collectionId = 0 là mục mặc định)
+func (bc *BookmarkController) UpdateBookmark(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bookmark không hợp lệ"})
+		return
+	}
+	var req struct {
+		Note         *string `json:"note"`
+		CollectionID *uint   `json:"collectionId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookmark, err := bc.bookmarkService.UpdateBookmark(uint(id), c.GetUint("user_id"), services.BookmarkUpdate{
+		Note:         req.Note,
+		CollectionID: req.CollectionID,
+	})
+	if err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Cập nhật bookmark thành công",
+		"bookmark": responses.ToBookmarkResponse(bookmark, true, nil, nil, nil),
+	})
+}
+
+func (bc *BookmarkController) RemoveBookmark(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bookmark không hợp lệ"})
+		return
+	}
+
+	if err := bc.bookmarkService.RemoveBookmark(uint(id), c.GetUint("user_id")); err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã bỏ lưu nội dung"})
+}
+
+// RemoveBookmarkByTarget bỏ lưu theo nội dung, dùng cho nút bookmark trên trang câu hỏi/câu trả lời/bài viết
+func (bc *BookmarkController) RemoveBookmarkByTarget(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Query("targetId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "targetId không hợp lệ"})
+		return
+	}
+
+	err = bc.bookmarkService.RemoveBookmarkByTarget(c.GetUint("user_id"), models.BookmarkTargetType(c.Query("targetType")), uint(targetID))
+	if err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã bỏ lưu nội dung"})
+}
+
+// ListBookmarks liệt kê bookmark của người dùng; collectionId = 0 là mục mặc định, bỏ trống là tất cả
+func (bc *BookmarkController) ListBookmarks(c *gin.Context) {
+	filters := bookmarkFilters(c)
+	if raw := c.Query("collectionId"); raw != "" {
+		collectionID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID bộ sưu tập không hợp lệ"})
+			return
+		}
+		filters["collection_id"] = uint(collectionID)
+	}
+	_, byCollection := filters["collection_id"]
+	defaultSort := "desc"
+	if byCollection {
+		defaultSort = "asc"
+	}
+	if !bindCursor(c, filters, defaultSort) {
+		return
+	}
+
+	items, total, err := bc.bookmarkService.ListBookmarks(c.GetUint("user_id"), filters)
+	if err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"bookmarks": toBookmarkResponses(items),
+	}, filters, items, bookmarkCursor(byCollection), total))
+}
+
+// ReorderBookmarks nhận toàn bộ ID bookmark trong bộ sưu tập theo thứ tự mới
+func (bc *BookmarkController) ReorderBookmarks(c *gin.Context) {
+	var req struct {
+		CollectionID uint   `json:"collectionId"`
+		BookmarkIDs  []uint `json:"bookmarkIds" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := bc.bookmarkService.ReorderBookmarks(c.GetUint("user_id"), req.CollectionID, req.BookmarkIDs); err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã sắp xếp lại bookmark"})
+}
+
+func (bc *BookmarkController) CreateCollection(c *gin.Context) {
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		IsPublic    bool   `json:"isPublic"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := bc.bookmarkService.CreateCollection(c.GetUint("user_id"), services.CollectionInput{
+		Name:        req.Name,
+		Description: req.Description,
+		IsPublic:    req.IsPublic,
+	})
+	if err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Tạo bộ sưu tập thành công",
+		"collection": responses.ToBookmarkCollectionResponse(collection, nil),
+	})
+}
+
+func (bc *BookmarkController) UpdateCollection(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bộ sưu tập không hợp lệ"})
+		return
+	}
+	var req struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		IsPublic    bool   `json:"isPublic"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := bc.bookmarkService.UpdateCollection(uint(id), c.GetUint("user_id"), services.CollectionInput{
+		Name:        req.Name,
+		Description: req.Description,
+		IsPublic:    req.IsPublic,
+	})
+	if err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Cập nhật bộ sưu tập thành công",
+		"collection": responses.ToBookmarkCollectionResponse(collection, nil),
+	})
+}
+
+// DeleteCollection xoá bộ sưu tập, các bookmark bên trong được chuyển về mục mặc định
+func (bc *BookmarkController) DeleteCollection(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bộ sưu tập không hợp lệ"})
+		return
+	}
+
+	if err := bc.bookmarkService.DeleteCollection(uint(id), c.GetUint("user_id")); err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Xoá bộ sưu tập thành công"})
+}
+
+// ListCollections trả về bộ sưu tập của người dùng hiện tại
+func (bc *BookmarkController) ListCollections(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	bc.listCollections(c, userID, userID)
+}
+
+// ListUserCollections trả về các bộ sưu tập công khai của một người dùng khác
+func (bc *BookmarkController) ListUserCollections(c *gin.Context) {
+	ownerID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID người dùng không hợp lệ"})
+		return
+	}
+	bc.listCollections(c, uint(ownerID), c.GetUint("user_id"))
+}
+
+func (bc *BookmarkController) listCollections(c *gin.Context, ownerID, viewerID uint) {
+	summaries, defaultCount, err := bc.bookmarkService.ListCollections(ownerID, viewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê bộ sưu tập"})
+		return
+	}
+
+	collections := []responses.BookmarkCollectionResponse{}
+	for i := range summaries {
+		count := summaries[i].ItemCount
+		collections = append(collections, responses.ToBookmarkCollectionResponse(&summaries[i].Collection, &count))
+	}
+
+	response := gin.H{"collections": collections}
+	if ownerID == viewerID {
+		response["defaultCount"] = defaultCount
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCollection trả về bộ sưu tập cùng bookmark; người khác chỉ xem được bộ sưu tập công khai
+func (bc *BookmarkController) GetCollection(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bộ sưu tập không hợp lệ"})
+		return
+	}
+
+	filters := bookmarkFilters(c)
+	if !bindCursor(c, filters, "asc") {
+		return
+	}
+	collection, items, total, err := bc.bookmarkService.GetCollection(uint(id), c.GetUint("user_id"), filters)
+	if err != nil {
+		bookmarkError(c, err)
+		return
+	}
+
+	var itemCount *int
+	if total >= 0 {
+		itemCount = &total
+	}
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"collection": responses.ToBookmarkCollectionResponse(collection, itemCount),
+		"bookmarks":  toBookmarkResponses(items),
+	}, filters, items, bookmarkCursor(true), total))
+}
+
+func bookmarkFilters(c *gin.Context) map[string]interface{} {
+	filters := make(map[string]interface{})
+	if targetType := c.Query("targetType"); targetType != "" {
+		filters["typefilter"] = targetType
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filters["page"] = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filters["limit"] = l
+		}
+	}
+	return filters
+}
+
+// bookmarkCursor trả về vị trí keyset của bookmark: (position, id) trong bộ sưu tập, (created_at, id) khi liệt kê tất cả
+func bookmarkCursor(byCollection bool) func(*services.BookmarkItem) utils.Cursor {
+	return func(item *services.BookmarkItem) utils.Cursor {
+		if byCollection {
+			position := item.Bookmark.Position
+			return utils.Cursor{Position: &position, ID: item.Bookmark.ID}
+		}
+		return utils.Cursor{Time: item.Bookmark.CreatedAt, ID: item.Bookmark.ID}
+	}
+}
+
+func toBookmarkResponses(items []services.BookmarkItem) []responses.BookmarkResponse {
+	result := []responses.BookmarkResponse{}
+	for i := range items {
+		item := &items[i]
+		result = append(result, responses.ToBookmarkResponse(&item.Bookmark, item.Available, item.Question, item.Answer, item.Post))
+	}
+	return result
+}
+
+func bookmarkError(c *gin.Context, err error) {
+	if strings.HasSuffix(err.Error(), "not found") {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// markBookmarked gắn cờ bookmarked cho danh sách response theo người dùng hiện tại;
+// item trả về ID nội dung và con trỏ tới trường Bookmarked của response
+func markBookmarked[T any](s services.BookmarkService, userID uint, targetType models.BookmarkTargetType, items []T, item func(*T) (uint, *bool)) {
+	if s == nil || len(items) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(items))
+	for i := range items {
+		id, _ := item(&items[i])
+		ids = append(ids, id)
+	}
+	marked := s.BookmarkedIDs(userID, targetType, ids)
+	for i := range items {
+		id, flag := item(&items[i])
+		*flag = marked[id]
+	}
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
@@ -10,28 +12,31 @@ import (
 )
 
 type CommentController struct {
-	commentService services.CommentService
-	voteService    services.VoteService
+	commentService  services.CommentService
+	voteService     services.VoteService
+	reactionCounter services.ReactionCounter
 }
 
-func NewCommentController(c services.CommentService, v services.VoteService) *CommentController {
-	return &CommentController{commentService: c, voteService: v}
+func NewCommentController(c services.CommentService, v services.VoteService, r services.ReactionCounter) *CommentController {
+	return &CommentController{commentService: c, voteService: v, reactionCounter: r}
 }
 
 func (cc *CommentController) CreateComment(c *gin.Context) {
 	var req struct {
-		Content  string `json:"content" binding:"required"`
-		PostID   *uint  `json:"post_id"`
-		AnswerID *uint  `json:"answer_id"`
-		ParentID *uint  `json:"parent_id"`
-		Status   string `json:"status"`
+		Content       string `json:"content" binding:"required"`
+		PostID        *uint  `json:"post_id"`
+		AnswerID      *uint  `json:"answer_id"`
+		QuestionID    *uint  `json:"question_id"`
+		ParentID      *uint  `json:"parent_id"`
+		ContentFormat string `json:"content_format"` // html (mặc định) hoặc markdown
+		Anonymous     bool   `json:"anonymous"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Yêu cầu không hợp lệ: " + err.Error()})
 		return
 	}
 	userID := c.GetUint("user_id")
-	comment, err := cc.commentService.CreateComment(req.Content, userID, req.PostID, req.AnswerID, req.ParentID, req.Status)
+	comment, err := cc.commentService.CreateComment(req.Content, userID, req.PostID, req.AnswerID, req.QuestionID, req.ParentID, req.ContentFormat, req.Anonymous)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -56,8 +61,12 @@ func (cc *CommentController) GetComment(c *gin.Context) {
 		return
 	}
 
+	response := commentResponse(c, comment)
+	reactions := cc.reactionCounter.Counts(models.ReactionTargetComment, []uint{comment.ID})[comment.ID]
+	response.Reactions, response.ReactionCount = reactions, int(reactions.Total())
+
 	c.JSON(http.StatusOK, gin.H{
-		"comment": responses.ToCommentResponse(comment),
+		"comment": response,
 	})
 }
 
@@ -69,7 +78,8 @@ func (cc *CommentController) EditComment(c *gin.Context) {
 	}
 
 	var req struct {
-		Content string `json:"content" binding:"required"`
+		Content       string `json:"content" binding:"required"`
+		ContentFormat string `json:"content_format"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -77,7 +87,7 @@ func (cc *CommentController) EditComment(c *gin.Context) {
 		return
 	}
 
-	comment, err := cc.commentService.UpdateComment(uint(id), req.Content)
+	comment, err := cc.commentService.UpdateComment(uint(id), req.Content, req.ContentFormat)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -123,6 +133,13 @@ func (cc *CommentController) ListComments(c *gin.Context) {
 		}
 	}
 
+	questionID := c.Query("question_id")
+	if questionID != "" {
+		if qID, err := strconv.ParseUint(questionID, 10, 64); err == nil {
+			filters["question_id"] = uint(qID)
+		}
+	}
+
 	userID := c.Query("user_id")
 	if userID != "" {
 		if uID, err := strconv.ParseUint(userID, 10, 64); err == nil {
@@ -146,6 +163,9 @@ func (cc *CommentController) ListComments(c *gin.Context) {
 		}
 	}
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	comments, total, err := cc.commentService.ListComments(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -154,13 +174,17 @@ func (cc *CommentController) ListComments(c *gin.Context) {
 
 	var responseComments []responses.CommentResponse
 	for _, comment := range comments {
-		responseComments = append(responseComments, responses.ToCommentResponse(&comment))
+		responseComments = append(responseComments, commentResponse(c, &comment))
 	}
+	markReactionCounts(cc.reactionCounter, models.ReactionTargetComment, responseComments, func(r *responses.CommentResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"comments": responseComments,
-		"total":    total,
-	})
+	}, filters, comments, func(item *models.Comment) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (cc *CommentController) ListReplies(c *gin.Context) {
@@ -184,6 +208,9 @@ func (cc *CommentController) ListReplies(c *gin.Context) {
 	if status := c.Query("status"); status != "" {
 		filters["status"] = status
 	}
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	replies, total, err := cc.commentService.ListReplies(uint(parentID), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -192,12 +219,108 @@ func (cc *CommentController) ListReplies(c *gin.Context) {
 
 	var responseReplies []responses.CommentResponse
 	for _, reply := range replies {
-		responseReplies = append(responseReplies, responses.ToCommentResponse(&reply))
+		responseReplies = append(responseReplies, commentResponse(c, &reply))
 	}
+	markReactionCounts(cc.reactionCounter, models.ReactionTargetComment, responseReplies, func(r *responses.CommentResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"replies": responseReplies,
-		"total":   total,
+	}, filters, replies, func(item *models.Comment) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
+}
+
+// GetThread trả về cây bình luận của một bài viết, câu trả lời hoặc câu hỏi (?post_id=, ?answer_id= hoặc ?question_id=).
+// depth là số tầng trả lời được tải, children là số trả lời tối đa của mỗi nút; cursor phân trang các bình luận gốc.
+func (cc *CommentController) GetThread(c *gin.Context) {
+	filters := map[string]interface{}{"status": c.DefaultQuery("status", "approved")}
+	if postID, err := strconv.ParseUint(c.Query("post_id"), 10, 64); err == nil {
+		filters["post_id"] = uint(postID)
+	} else if answerID, err := strconv.ParseUint(c.Query("answer_id"), 10, 64); err == nil {
+		filters["answer_id"] = uint(answerID)
+	} else if questionID, err := strconv.ParseUint(c.Query("question_id"), 10, 64); err == nil {
+		filters["question_id"] = uint(questionID)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cần post_id, answer_id hoặc question_id"})
+		return
+	}
+	cc.respondThread(c, filters, "comments", gin.H{})
+}
+
+// GetCommentThread trả về nhánh bên dưới một bình luận; với nextCursor của nút, đây cũng là cách tải thêm trả lời
+func (cc *CommentController) GetCommentThread(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bình luận không hợp lệ"})
+		return
+	}
+	comment, err := cc.commentService.GetCommentByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy bình luận"})
+		return
+	}
+	descendants, err := cc.commentService.CountReplies(comment.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể đếm trả lời"})
+		return
+	}
+
+	filters := map[string]interface{}{
+		"status":    c.DefaultQuery("status", "approved"),
+		"parent_id": comment.ID,
+	}
+	cc.respondThread(c, filters, "replies", gin.H{
+		"comment":         commentResponse(c, comment),
+		"descendantCount": descendants,
+	})
+}
+
+// respondThread tải cây theo filters và ghi các nút đầu vào result[key] kèm thông tin phân trang
+func (cc *CommentController) respondThread(c *gin.Context, filters map[string]interface{}, key string, result gin.H) {
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		filters["limit"] = limit
+	}
+	depth, err := strconv.Atoi(c.Query("depth"))
+	if err != nil {
+		depth = -1
+	}
+	children, _ := strconv.Atoi(c.Query("children"))
+	if !bindCursor(c, filters, "asc") {
+		return
+	}
+
+	nodes, total, err := cc.commentService.GetThread(filters, depth, children)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	threads := make([]responses.CommentThreadResponse, 0, len(nodes))
+	for _, node := range nodes {
+		threads = append(threads, responses.ToCommentThreadResponse(node, canRevealAnonymous(c)))
+	}
+	cc.markThreadReactionCounts(threads)
+	result[key] = threads
+
+	c.JSON(http.StatusOK, withPageInfo(result, filters, nodes, func(item **models.CommentNode) utils.Cursor {
+		return utils.Cursor{Time: (*item).Comment.CreatedAt, ID: (*item).Comment.ID}
+	}, total))
+}
+
+// markThreadReactionCounts điền số tương tác cho mọi nút của cây bằng một lần đọc bộ đếm
+func (cc *CommentController) markThreadReactionCounts(threads []responses.CommentThreadResponse) {
+	var nodes []*responses.CommentThreadResponse
+	var collect func(items []responses.CommentThreadResponse)
+	collect = func(items []responses.CommentThreadResponse) {
+		for i := range items {
+			nodes = append(nodes, &items[i])
+			collect(items[i].Replies)
+		}
+	}
+	collect(threads)
+	markReactionCounts(cc.reactionCounter, models.ReactionTargetComment, nodes, func(r **responses.CommentThreadResponse) (uint, *models.ReactionCounts, *int) {
+		return (*r).ID, &(*r).Reactions, &(*r).ReactionCount
 	})
 }
 
@@ -227,6 +350,9 @@ func (cc *CommentController) GetAllComments(c *gin.Context) {
 		}
 	}
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	comments, total, err := cc.commentService.GetAllComments(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -235,13 +361,17 @@ func (cc *CommentController) GetAllComments(c *gin.Context) {
 
 	var responseComments []responses.CommentResponse
 	for _, comment := range comments {
-		responseComments = append(responseComments, responses.ToCommentResponse(&comment))
+		responseComments = append(responseComments, commentResponse(c, &comment))
 	}
+	markReactionCounts(cc.reactionCounter, models.ReactionTargetComment, responseComments, func(r *responses.CommentResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"comments": responseComments,
-		"total":    total,
-	})
+	}, filters, comments, func(item *models.Comment) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 func (cc *CommentController) UpdateStatus(c *gin.Context) {
 	idParam := c.Param("id")
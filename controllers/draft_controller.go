@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"Forum_BE/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type DraftController struct {
+	draftService services.DraftService
+}
+
+func NewDraftController(d services.DraftService) *DraftController {
+	return &DraftController{draftService: d}
+}
+
+// SaveDraft tự động lưu bản nháp; client gửi kèm version đang giữ để biết có bị ghi đè từ nơi khác hay không
+func (dc *DraftController) SaveDraft(c *gin.Context) {
+	var req struct {
+		TargetType    string `json:"targetType" binding:"required"`
+		TargetID      uint   `json:"targetId"`
+		Title         string `json:"title"`
+		Content       string `json:"content"`
+		ContentFormat string `json:"contentFormat"`
+		TopicID       uint   `json:"topicId"`
+		TagIDs        []uint `json:"tagIds"`
+		Version       uint   `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	draft, conflict, err := dc.draftService.SaveDraft(userID, services.DraftInput{
+		TargetType:  models.DraftTargetType(req.TargetType),
+		TargetID:    req.TargetID,
+		Title:       req.Title,
+		Content:     req.Content,
+		Format:      models.ContentFormat(req.ContentFormat),
+		TopicID:     req.TopicID,
+		TagIDs:      req.TagIDs,
+		BaseVersion: req.Version,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Đã lưu bản nháp",
+		"draft":    responses.ToDraftResponse(draft),
+		"conflict": conflict,
+	})
+}
+
+func (dc *DraftController) ListDrafts(c *gin.Context) {
+	filters := make(map[string]interface{})
+	if targetType := c.Query("targetType"); targetType != "" {
+		filters["typefilter"] = targetType
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filters["page"] = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filters["limit"] = l
+		}
+	}
+
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
+	drafts, total, err := dc.draftService.ListDrafts(c.GetUint("user_id"), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê bản nháp"})
+		return
+	}
+
+	responseDrafts := []responses.DraftResponse{}
+	for i := range drafts {
+		responseDrafts = append(responseDrafts, responses.ToDraftResponse(&drafts[i]))
+	}
+
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"drafts": responseDrafts,
+	}, filters, drafts, func(item *models.Draft) utils.Cursor {
+		return utils.Cursor{Time: item.UpdatedAt, ID: item.ID}
+	}, total))
+}
+
+// GetDraftByTarget trả về bản nháp của người dùng cho một đích cụ thể (ví dụ câu trả lời cho câu hỏi X)
+func (dc *DraftController) GetDraftByTarget(c *gin.Context) {
+	targetType := c.Query("targetType")
+	if targetType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Thiếu targetType"})
+		return
+	}
+	var targetID uint64
+	if raw := c.Query("targetId"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "targetId không hợp lệ"})
+			return
+		}
+		targetID = parsed
+	}
+
+	draft, err := dc.draftService.GetDraftByTarget(c.GetUint("user_id"), models.DraftTargetType(targetType), uint(targetID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy bản nháp"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"draft": responses.ToDraftResponse(draft),
+	})
+}
+
+func (dc *DraftController) GetDraft(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bản nháp không hợp lệ"})
+		return
+	}
+
+	draft, err := dc.draftService.GetDraft(uint(id), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy bản nháp"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"draft": responses.ToDraftResponse(draft),
+	})
+}
+
+func (dc *DraftController) DeleteDraft(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bản nháp không hợp lệ"})
+		return
+	}
+
+	if err := dc.draftService.DeleteDraft(uint(id), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy bản nháp"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Xoá bản nháp thành công"})
+}
+
+// PublishDraft chuyển bản nháp thành câu hỏi, câu trả lời hoặc bài viết thật rồi xoá bản nháp
+func (dc *DraftController) PublishDraft(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bản nháp không hợp lệ"})
+		return
+	}
+
+	result, err := dc.draftService.PublishDraft(uint(id), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "Đăng bản nháp thành công"}
+	if result.Question != nil {
+		response["question"] = responses.ToQuestionResponse(result.Question)
+	}
+	if result.Answer != nil {
+		response["answer"] = responses.ToAnswerResponse(result.Answer)
+	}
+	if result.Post != nil {
+		response["post"] = responses.ToPostResponse(result.Post)
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"Forum_BE/services"
+	"Forum_BE/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FeedController struct {
+	feedService services.FeedService
+}
+
+func NewFeedController(f services.FeedService) *FeedController {
+	return &FeedController{feedService: f}
+}
+
+// GetToken trả về feed token của người dùng (tạo mới nếu chưa có) để ghép vào URL feed
+func (fc *FeedController) GetToken(c *gin.Context) {
+	token, err := fc.feedService.GetToken(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo feed token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token.Token})
+}
+
+// RotateToken đổi feed token; các URL feed cũ sẽ không dùng được nữa
+func (fc *FeedController) RotateToken(c *gin.Context) {
+	token, err := fc.feedService.RotateToken(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể đổi feed token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã đổi feed token",
+		"token":   token.Token,
+	})
+}
+
+func (fc *FeedController) QuestionsFeed(c *gin.Context) {
+	feed, err := fc.feedService.QuestionsFeed(0)
+	fc.writeFeed(c, feed, err)
+}
+
+func (fc *FeedController) TopicFeed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID chủ đề không hợp lệ"})
+		return
+	}
+	feed, err := fc.feedService.QuestionsFeed(uint(id))
+	fc.writeFeed(c, feed, err)
+}
+
+func (fc *FeedController) TagFeed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID thẻ không hợp lệ"})
+		return
+	}
+	feed, err := fc.feedService.TagFeed(uint(id))
+	fc.writeFeed(c, feed, err)
+}
+
+func (fc *FeedController) UserFeed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID người dùng không hợp lệ"})
+		return
+	}
+	feed, err := fc.feedService.UserFeed(uint(id))
+	fc.writeFeed(c, feed, err)
+}
+
+func (fc *FeedController) AnswersFeed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID câu hỏi không hợp lệ"})
+		return
+	}
+	feed, err := fc.feedService.AnswersFeed(uint(id))
+	fc.writeFeed(c, feed, err)
+}
+
+// writeFeed xuất feed theo ?format=rss|atom (mặc định atom) và hỗ trợ conditional GET:
+// trả 304 khi If-None-Match khớp ETag hoặc feed không đổi kể từ If-Modified-Since
+func (fc *FeedController) writeFeed(c *gin.Context, feed *utils.Feed, err error) {
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo feed"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "atom")
+	if format != "atom" && format != "rss" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format phải là 'atom' hoặc 'rss'"})
+		return
+	}
+	feed.SelfLink = feedSelfLink(c)
+
+	etag := feed.ETag()
+	if format == "rss" {
+		// Hai định dạng có nội dung khác nhau nên không dùng chung ETag
+		etag = strings.TrimSuffix(etag, `"`) + `-rss"`
+	}
+	lastModified := feed.Updated.UTC().Truncate(time.Second)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, max-age=300")
+
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		if etagMatches(match, etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !lastModified.After(since) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	var body []byte
+	contentType := "application/atom+xml; charset=utf-8"
+	if format == "rss" {
+		body, err = utils.RenderRSS(feed)
+		contentType = "application/rss+xml; charset=utf-8"
+	} else {
+		body, err = utils.RenderAtom(feed)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo feed"})
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// feedSelfLink dựng lại URL của feed đang được yêu cầu (kể cả token để trình đọc feed dùng lại được)
+func feedSelfLink(c *gin.Context) string {
+	self := url.URL{Scheme: requestScheme(c), Host: c.Request.Host, Path: c.Request.URL.Path, RawQuery: c.Request.URL.RawQuery}
+	return self.String()
+}
+
+func requestScheme(c *gin.Context) string {
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		return "https"
+	}
+	return "http"
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
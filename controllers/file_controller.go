@@ -4,6 +4,7 @@ import (
 	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -101,6 +102,9 @@ func (fc *FileController) ListFiles(c *gin.Context) {
 		}
 	}
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	files, total, err := fc.fileService.ListFiles(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -112,10 +116,11 @@ func (fc *FileController) ListFiles(c *gin.Context) {
 		responseFiles = append(responseFiles, responses.ToFileResponse(&file))
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"files": responseFiles,
-		"total": total,
-	})
+	}, filters, files, func(item *models.Attachment) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (fc *FileController) DownloadFile(c *gin.Context) {
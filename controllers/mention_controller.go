@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"Forum_BE/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type MentionController struct {
+	mentionService services.MentionService
+}
+
+func NewMentionController(m services.MentionService) *MentionController {
+	return &MentionController{mentionService: m}
+}
+
+// Autocomplete gợi ý người dùng khi gõ @ trong trình soạn thảo
+func (mc *MentionController) Autocomplete(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	users, err := mc.mentionService.Autocomplete(c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tìm người dùng"})
+		return
+	}
+
+	suggestions := []responses.UserSuggestionResponse{}
+	for i := range users {
+		suggestions = append(suggestions, responses.ToUserSuggestionResponse(&users[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"users": suggestions})
+}
+
+// ListMentions liệt kê các nội dung đã nhắc đến người dùng hiện tại
+func (mc *MentionController) ListMentions(c *gin.Context) {
+	filters := make(map[string]interface{})
+	if targetType := c.Query("type"); targetType != "" {
+		filters["type"] = targetType
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filters["page"] = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filters["limit"] = l
+		}
+	}
+
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
+	mentions, total, err := mc.mentionService.ListMentions(c.GetUint("user_id"), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê lượt nhắc"})
+		return
+	}
+
+	responseMentions := []responses.MentionResponse{}
+	for i := range mentions {
+		responseMentions = append(responseMentions, responses.ToMentionResponse(&mentions[i]))
+	}
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"mentions": responseMentions,
+	}, filters, mentions, func(item *models.Mention) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
+}
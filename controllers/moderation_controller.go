@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"Forum_BE/utils"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ModerationController struct {
+	moderationService services.ModerationService
+}
+
+func NewModerationController(s services.ModerationService) *ModerationController {
+	return &ModerationController{moderationService: s}
+}
+
+// ListQueue trả về các nội dung đang chờ duyệt hoặc bị đánh dấu spam của mọi loại, mục chờ lâu nhất lên trước.
+// Mặc định ẩn mục người khác đang giữ; claimed=mine|unclaimed|all để đổi cách lọc.
+func (mc *ModerationController) ListQueue(c *gin.Context) {
+	filters := map[string]interface{}{"moderator_id": c.GetUint("user_id")}
+	if contentType := c.Query("type"); contentType != "" {
+		if !models.IsValidModerationContentType(contentType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Loại nội dung không hợp lệ"})
+			return
+		}
+		filters["content_type"] = contentType
+	}
+	if status := c.Query("status"); status != "" {
+		if status != "pending" && status != "spam" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Trạng thái không hợp lệ"})
+			return
+		}
+		filters["status"] = status
+	}
+	if claimed := c.Query("claimed"); claimed != "" {
+		if claimed != "mine" && claimed != "unclaimed" && claimed != "all" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Giá trị claimed không hợp lệ"})
+			return
+		}
+		filters["claimed"] = claimed
+	}
+	if userID := c.Query("userId"); userID != "" {
+		if id, err := strconv.ParseUint(userID, 10, 64); err == nil {
+			filters["user_id"] = uint(id)
+		}
+	}
+	if search := c.Query("search"); search != "" {
+		filters["search"] = search
+	}
+	if sort := c.Query("sort"); sort != "" {
+		filters["sort"] = sort
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filters["page"] = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filters["limit"] = l
+		}
+	}
+
+	if !bindCursor(c, filters, "asc") {
+		return
+	}
+	items, total, err := mc.moderationService.ListQueue(filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy hàng đợi kiểm duyệt"})
+		return
+	}
+
+	responseItems := []responses.ModerationItemResponse{}
+	for i := range items {
+		responseItems = append(responseItems, responses.ToModerationItemResponse(&items[i]))
+	}
+
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"items": responseItems,
+	}, filters, items, func(item *models.ModerationItem) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, Ref: item.QueueRef}
+	}, total))
+}
+
+// Claim giữ một mục để người khác không duyệt trùng; gọi lại khi đang giữ sẽ gia hạn thời gian giữ
+func (mc *ModerationController) Claim(c *gin.Context) {
+	var req struct {
+		ContentType string `json:"contentType" binding:"required"`
+		ContentID   uint   `json:"contentId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claim, err := mc.moderationService.Claim(c.GetUint("user_id"), req.ContentType, req.ContentID)
+	if errors.Is(err, repositories.ErrModerationClaimed) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Mục này đang được người kiểm duyệt khác xử lý"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã nhận mục kiểm duyệt",
+		"claim":   responses.ToModerationClaimResponse(claim),
+	})
+}
+
+func (mc *ModerationController) Release(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID nội dung không hợp lệ"})
+		return
+	}
+
+	if err := mc.moderationService.Release(c.GetUint("user_id"), c.Param("type"), uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bạn không giữ mục này"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã trả lại mục kiểm duyệt"})
+}
+
+// Review duyệt hoặc từ chối hàng loạt; reason là lý do chung, mỗi mục có thể ghi đè bằng reason riêng.
+// Kết quả trả về theo từng mục, mục lỗi (đã được xử lý, người khác đang giữ...) không làm hỏng cả lô.
+func (mc *ModerationController) Review(c *gin.Context) {
+	var req struct {
+		Action string                      `json:"action" binding:"required"`
+		Reason string                      `json:"reason"`
+		Items  []services.ModerationTarget `json:"items" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := mc.moderationService.Review(c.GetUint("user_id"), models.ModerationAction(req.Action), req.Items, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Error == "" {
+			succeeded++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Đã xử lý %d/%d mục", succeeded, len(results)),
+		"results": results,
+	})
+}
+
+// Stats trả về số mục mỗi người kiểm duyệt đã xử lý và thời gian chờ trung bình, mặc định trong 30 ngày gần nhất
+func (mc *ModerationController) Stats(c *gin.Context) {
+	filters := map[string]interface{}{"from": time.Now().AddDate(0, 0, -30)}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ngày bắt đầu không hợp lệ (YYYY-MM-DD)"})
+			return
+		}
+		filters["from"] = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ngày kết thúc không hợp lệ (YYYY-MM-DD)"})
+			return
+		}
+		filters["to"] = t.AddDate(0, 0, 1)
+	}
+	if moderatorID := c.Query("moderatorId"); moderatorID != "" {
+		if id, err := strconv.ParseUint(moderatorID, 10, 64); err == nil {
+			filters["moderator_id"] = uint(id)
+		}
+	}
+	if contentType := c.Query("type"); contentType != "" {
+		filters["content_type"] = contentType
+	}
+
+	stats, err := mc.moderationService.Stats(filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy thống kê kiểm duyệt"})
+		return
+	}
+
+	responseStats := []responses.ModerationStatResponse{}
+	for i := range stats {
+		responseStats = append(responseStats, responses.ToModerationStatResponse(&stats[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": responseStats})
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"Forum_BE/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const defaultPageLimit = 10
+
+// bindCursor đọc tham số phân trang keyset vào filters:
+//   - cursor: chuỗi next_cursor/prev_cursor của lần gọi trước; cursor quyết định luôn chiều sắp xếp
+//   - with_total: có đếm tổng số bản ghi hay không (mặc định có ở trang đầu, không khi đi theo cursor)
+//
+// Trả về false (và đã trả lỗi 400) nếu cursor không hợp lệ.
+func bindCursor(c *gin.Context, filters map[string]interface{}, defaultSort string) bool {
+	if sort, _ := filters["sort"].(string); sort != "asc" && sort != "desc" {
+		filters["sort"] = defaultSort
+	}
+	if limit, ok := filters["limit"].(int); !ok || limit < 1 {
+		filters["limit"] = defaultPageLimit
+	}
+
+	withTotal := true
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := utils.DecodeCursor(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor không hợp lệ"})
+			return false
+		}
+		filters["cursor"] = *cursor
+		filters["sort"] = cursor.Sort
+		delete(filters, "page")
+		withTotal = false
+	}
+	if raw := c.Query("with_total"); raw != "" {
+		withTotal = raw == "true" || raw == "1"
+	}
+	filters["with_total"] = withTotal
+	return true
+}
+
+// withPageInfo thêm next_cursor, prev_cursor và total (null nếu không đếm) vào response.
+// key trả về vị trí (giá trị cột sắp xếp, id) của một phần tử, khớp với cột mà repository dùng để phân trang.
+func withPageInfo[T any](h gin.H, filters map[string]interface{}, items []T, key func(*T) utils.Cursor, total int) gin.H {
+	sort, _ := filters["sort"].(string)
+	limit, _ := filters["limit"].(int)
+	cursor, hasCursor := filters["cursor"].(utils.Cursor)
+	backward := hasCursor && cursor.Backward
+	full := len(items) >= limit
+
+	var next, prev interface{}
+	if len(items) > 0 {
+		first := key(&items[0])
+		last := key(&items[len(items)-1])
+		first.Sort, last.Sort = sort, sort
+		first.Backward = true
+		// Trang lùi luôn còn trang sau (chính là trang vừa rời đi); trang tiến còn trang sau khi lấy đủ limit
+		if backward || full {
+			next = utils.EncodeCursor(last)
+		}
+		if (hasCursor && !backward) || (backward && full) {
+			prev = utils.EncodeCursor(first)
+		}
+	}
+
+	h["next_cursor"] = next
+	h["prev_cursor"] = prev
+	if total >= 0 {
+		h["total"] = total
+	} else {
+		h["total"] = nil
+	}
+	return h
+}
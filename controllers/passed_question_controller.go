@@ -1,10 +1,17 @@
 package controllers
 
 import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type PassController struct {
@@ -15,23 +22,84 @@ func NewPassController(s services.PassService) *PassController {
 	return &PassController{passService: s}
 }
 
+// PassQuestion nhận body tuỳ chọn {"kind": "pass" | "not_expertise"}, mặc định là "pass"
 func (pc *PassController) PassQuestion(c *gin.Context) {
-	questionIDParam := c.Param("id")
-	questionID, err := strconv.ParseUint(questionIDParam, 10, 64)
+	questionID, ok := passQuestionID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Kind string `json:"kind"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pass, err := pc.passService.PassQuestion(c.GetUint("user_id"), questionID, models.PassKind(req.Kind))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID câu hỏi không hợp lệ"})
+		passError(c, err, "Không thể bỏ qua câu hỏi")
 		return
 	}
 
-	userID := c.GetUint("user_id")
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bỏ qua câu hỏi thành công",
+		"pass":    responses.ToPassResponse(pass, nil),
+	})
+}
+
+// SnoozeQuestion ẩn câu hỏi đến thời điểm "until" (RFC3339) hoặc trong "days" ngày
+func (pc *PassController) SnoozeQuestion(c *gin.Context) {
+	questionID, ok := passQuestionID(c)
+	if !ok {
+		return
+	}
 
-	err = pc.passService.PassQuestion(userID, uint(questionID))
+	var req struct {
+		Until *time.Time `json:"until"`
+		Days  int        `json:"days"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var until time.Time
+	switch {
+	case req.Until != nil:
+		until = *req.Until
+	case req.Days > 0:
+		until = time.Now().AddDate(0, 0, req.Days)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cần cung cấp until hoặc days"})
+		return
+	}
+
+	pass, err := pc.passService.SnoozeQuestion(c.GetUint("user_id"), questionID, until)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể bỏ qua câu hỏi"})
+		passError(c, err, "Không thể hoãn câu hỏi")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Bỏ qua câu hỏi thành công"})
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã hoãn câu hỏi",
+		"pass":    responses.ToPassResponse(pass, nil),
+	})
+}
+
+// UndoPass huỷ bỏ qua hoặc hoãn, câu hỏi xuất hiện lại trong feed ngay
+func (pc *PassController) UndoPass(c *gin.Context) {
+	questionID, ok := passQuestionID(c)
+	if !ok {
+		return
+	}
+
+	if err := pc.passService.UndoPass(c.GetUint("user_id"), questionID); err != nil {
+		passError(c, err, "Không thể hoàn tác bỏ qua câu hỏi")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã hoàn tác bỏ qua câu hỏi"})
 }
 
 func (pc *PassController) GetPassedQuestionIDs(c *gin.Context) {
@@ -45,3 +113,63 @@ func (pc *PassController) GetPassedQuestionIDs(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"passed_ids": ids})
 }
+
+// ListPasses liệt kê các câu hỏi đã bỏ qua/hoãn; ?kind= để lọc, ?all=true để gồm cả những câu hỏi đã hiện lại
+func (pc *PassController) ListPasses(c *gin.Context) {
+	filters := make(map[string]interface{})
+	if kind := c.Query("kind"); kind != "" {
+		filters["kind"] = kind
+	}
+	if all := c.Query("all"); all == "true" || all == "1" {
+		filters["all"] = true
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filters["page"] = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filters["limit"] = l
+		}
+	}
+
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
+	items, total, err := pc.passService.ListPasses(c.GetUint("user_id"), filters)
+	if err != nil {
+		passError(c, err, "Không thể lấy danh sách câu hỏi đã bỏ qua")
+		return
+	}
+
+	passes := make([]responses.PassResponse, 0, len(items))
+	for i := range items {
+		passes = append(passes, responses.ToPassResponse(&items[i].Pass, items[i].Question))
+	}
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"passes": passes,
+	}, filters, items, func(item *services.PassItem) utils.Cursor {
+		return utils.Cursor{Time: item.Pass.CreatedAt, ID: item.Pass.ID}
+	}, total))
+}
+
+func passQuestionID(c *gin.Context) (uint, bool) {
+	questionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID câu hỏi không hợp lệ"})
+		return 0, false
+	}
+	return uint(questionID), true
+}
+
+func passError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid") || strings.HasPrefix(err.Error(), "snooze"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"Forum_BE/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type PollController struct {
+	pollService services.PollService
+	permService services.PermissionService
+}
+
+func NewPollController(p services.PollService, perm services.PermissionService) *PollController {
+	return &PollController{pollService: p, permService: perm}
+}
+
+// canManage cho biết người dùng có quyền đóng/xoá bình chọn của người khác hay không
+func (pc *PollController) canManage(userID uint) bool {
+	role, err := pc.permService.GetUserRole(userID)
+	if err != nil {
+		return false
+	}
+	permission, err := pc.permService.GetPermission(string(role), "poll", "manage")
+	return err == nil && permission != nil && permission.Allowed
+}
+
+func toPollResponse(result *services.PollResult) responses.PollResponse {
+	return responses.ToPollResponse(result.Poll, result.Closed, result.ResultsHidden, result.Counts, result.TotalVoters, result.MyChoices)
+}
+
+func (pc *PollController) CreatePoll(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bài đăng không hợp lệ"})
+		return
+	}
+
+	var req struct {
+		Question               string     `json:"question" binding:"required"`
+		Options                []string   `json:"options" binding:"required"`
+		MultipleChoice         bool       `json:"multipleChoice"`
+		MaxChoices             int        `json:"maxChoices"`
+		Anonymous              bool       `json:"anonymous"`
+		HideResultsUntilClosed bool       `json:"hideResultsUntilClosed"`
+		ClosesAt               *time.Time `json:"closesAt"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := pc.pollService.CreatePoll(uint(postID), c.GetUint("user_id"), services.PollInput{
+		Question:               req.Question,
+		Options:                req.Options,
+		MultipleChoice:         req.MultipleChoice,
+		MaxChoices:             req.MaxChoices,
+		Anonymous:              req.Anonymous,
+		HideResultsUntilClosed: req.HideResultsUntilClosed,
+		ClosesAt:               req.ClosesAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tạo bình chọn thành công",
+		"poll":    toPollResponse(result),
+	})
+}
+
+func (pc *PollController) GetPostPoll(c *gin.Context) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bài đăng không hợp lệ"})
+		return
+	}
+
+	result, err := pc.pollService.GetPollByPost(uint(postID), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy bình chọn"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"poll": toPollResponse(result)})
+}
+
+func (pc *PollController) GetPoll(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bình chọn không hợp lệ"})
+		return
+	}
+
+	result, err := pc.pollService.GetPoll(uint(id), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy bình chọn"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"poll": toPollResponse(result)})
+}
+
+func (pc *PollController) Vote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bình chọn không hợp lệ"})
+		return
+	}
+
+	var req struct {
+		OptionIDs []uint `json:"optionIds" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := pc.pollService.Vote(uint(id), c.GetUint("user_id"), req.OptionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bình chọn thành công",
+		"poll":    toPollResponse(result),
+	})
+}
+
+func (pc *PollController) RetractVote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bình chọn không hợp lệ"})
+		return
+	}
+
+	result, err := pc.pollService.RetractVote(uint(id), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã rút lại phiếu bầu",
+		"poll":    toPollResponse(result),
+	})
+}
+
+func (pc *PollController) ClosePoll(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bình chọn không hợp lệ"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	result, err := pc.pollService.ClosePoll(uint(id), userID, pc.canManage(userID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã đóng bình chọn",
+		"poll":    toPollResponse(result),
+	})
+}
+
+func (pc *PollController) DeletePoll(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bình chọn không hợp lệ"})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	if err := pc.pollService.DeletePoll(uint(id), userID, pc.canManage(userID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Xoá bình chọn thành công"})
+}
+
+func (pc *PollController) ListVoters(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bình chọn không hợp lệ"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	filters := map[string]interface{}{"page": page, "limit": limit}
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
+
+	ballots, total, err := pc.pollService.ListVoters(uint(id), c.GetUint("user_id"), filters)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	voters := []responses.PollVoterResponse{}
+	for i := range ballots {
+		voters = append(voters, responses.ToPollVoterResponse(&ballots[i]))
+	}
+
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"voters": voters,
+	}, filters, ballots, func(b *models.PollBallot) utils.Cursor {
+		return utils.Cursor{Time: b.CreatedAt, ID: b.ID}
+	}, total))
+}
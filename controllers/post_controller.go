@@ -4,26 +4,31 @@ import (
 	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type PostController struct {
-	postService services.PostService
+	postService     services.PostService
+	bookmarkService services.BookmarkService
+	reactionCounter services.ReactionCounter
 }
 
-func NewPostController(p services.PostService) *PostController {
-	return &PostController{p}
+func NewPostController(p services.PostService, b services.BookmarkService, r services.ReactionCounter) *PostController {
+	return &PostController{p, b, r}
 }
 
 func (pc *PostController) CreatePost(c *gin.Context) {
 	var req struct {
-		Title   string            `json:"title"`
-		Content string            `json:"content" binding:"required"`
-		Tags    []uint            `json:"tags"`
-		Status  models.PostStatus `json:"status" default:"pending"`
+		Title         string     `json:"title"`
+		Content       string     `json:"content" binding:"required"`
+		Tags          []uint     `json:"tags"`
+		PublishAt     *time.Time `json:"publishAt"`     // Hẹn giờ đăng (tuỳ chọn)
+		ContentFormat string     `json:"contentFormat"` // html (mặc định) hoặc markdown
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -33,7 +38,7 @@ func (pc *PostController) CreatePost(c *gin.Context) {
 
 	userID := c.GetUint("user_id")
 
-	post, err := pc.postService.CreatePost(req.Content, userID, req.Title, req.Tags, req.Status)
+	post, err := pc.postService.CreatePost(req.Content, userID, req.Title, req.Tags, req.PublishAt, req.ContentFormat)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -45,23 +50,31 @@ func (pc *PostController) CreatePost(c *gin.Context) {
 	})
 }
 
+// GetPostById nhận ID hoặc slug; khi truy cập bằng slug cũ, canonicalSlug cho biết slug hiện tại để giao diện chuyển hướng
 func (pc *PostController) GetPostById(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 64)
+	ref := c.Param("id")
+	id, err := pc.postService.ResolvePostID(ref)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID bài đăng không hợp lệ"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy bài đăng"})
 		return
 	}
 
-	post, err := pc.postService.GetPostByID(uint(id))
+	post, err := pc.postService.GetPostByID(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy bài đăng"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"post": responses.ToPostResponse(post),
-	})
+	response := responses.ToPostResponse(post)
+	response.Bookmarked = pc.bookmarkService.BookmarkedIDs(c.GetUint("user_id"), models.BookmarkPost, []uint{post.ID})[post.ID]
+	reactions := pc.reactionCounter.Counts(models.ReactionTargetPost, []uint{post.ID})[post.ID]
+	response.Reactions, response.ReactionCount = reactions, int(reactions.Total())
+
+	result := gin.H{"post": response}
+	if ref != strconv.FormatUint(uint64(post.ID), 10) && ref != post.Slug && post.Slug != "" {
+		result["canonicalSlug"] = post.Slug
+	}
+	c.JSON(http.StatusOK, result)
 }
 
 func (pc *PostController) DeletePost(c *gin.Context) {
@@ -90,10 +103,10 @@ func (pc *PostController) UpdatePost(c *gin.Context) {
 	}
 
 	var req struct {
-		Title   string `json:"title"`
-		Content string `json:"content" binding:"required"`
-		Status  string `json:"status"`
-		Tags    []uint `json:"tags"`
+		Title         string `json:"title"`
+		Content       string `json:"content" binding:"required"`
+		Tags          []uint `json:"tags"`
+		ContentFormat string `json:"contentFormat"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -101,7 +114,7 @@ func (pc *PostController) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	post, err := pc.postService.UpdatePost(uint(id), req.Title, req.Content, models.PostStatus(req.Status), req.Tags)
+	post, err := pc.postService.UpdatePost(uint(id), req.Title, req.Content, req.Tags, req.ContentFormat)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -173,6 +186,9 @@ func (pc *PostController) ListPosts(c *gin.Context) {
 		}
 	}
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	posts, total, err := pc.postService.ListPosts(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê các bài đăng"})
@@ -183,11 +199,18 @@ func (pc *PostController) ListPosts(c *gin.Context) {
 	for _, post := range posts {
 		response = append(response, responses.ToPostResponse(&post))
 	}
+	markBookmarked(pc.bookmarkService, c.GetUint("user_id"), models.BookmarkPost, response, func(r *responses.PostResponse) (uint, *bool) {
+		return r.ID, &r.Bookmarked
+	})
+	markReactionCounts(pc.reactionCounter, models.ReactionTargetPost, response, func(r *responses.PostResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"posts": response,
-		"total": total,
-	})
+	}, filters, posts, func(item *models.Post) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (pc *PostController) GetAllPosts(c *gin.Context) {
@@ -234,6 +257,9 @@ func (pc *PostController) GetAllPosts(c *gin.Context) {
 		}
 	}
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	posts, total, err := pc.postService.GetAllPosts(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy tất cả bài đăng"})
@@ -244,9 +270,16 @@ func (pc *PostController) GetAllPosts(c *gin.Context) {
 	for _, post := range posts {
 		response = append(response, responses.ToPostResponse(&post))
 	}
+	markBookmarked(pc.bookmarkService, c.GetUint("user_id"), models.BookmarkPost, response, func(r *responses.PostResponse) (uint, *bool) {
+		return r.ID, &r.Bookmarked
+	})
+	markReactionCounts(pc.reactionCounter, models.ReactionTargetPost, response, func(r *responses.PostResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"posts": response,
-		"total": total,
-	})
+	}, filters, posts, func(item *models.Post) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
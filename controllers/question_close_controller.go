@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type QuestionCloseController struct {
+	closeService services.QuestionCloseService
+}
+
+func NewQuestionCloseController(s services.QuestionCloseService) *QuestionCloseController {
+	return &QuestionCloseController{closeService: s}
+}
+
+func questionIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID câu hỏi không hợp lệ"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func toCloseVoteStatusResponse(status *services.CloseVoteStatus) responses.CloseVoteStatusResponse {
+	reasons := make(map[string]int, len(status.Reasons))
+	for reason, count := range status.Reasons {
+		reasons[string(reason)] = count
+	}
+	return responses.CloseVoteStatusResponse{
+		QuestionID:      status.QuestionID,
+		Closed:          status.Closed,
+		Locked:          status.Locked,
+		CloseVotes:      status.CloseVotes,
+		CloseThreshold:  status.CloseThreshold,
+		ReopenVotes:     status.ReopenVotes,
+		ReopenThreshold: status.ReopenThreshold,
+		HasVotedClose:   status.HasVotedClose,
+		HasVotedReopen:  status.HasVotedReopen,
+		Reasons:         reasons,
+	}
+}
+
+// CloseQuestion đóng câu hỏi ngay lập tức (dành cho người kiểm duyệt)
+func (cc *QuestionCloseController) CloseQuestion(c *gin.Context) {
+	id, ok := questionIDParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Reason        string `json:"reason" binding:"required"`
+		DuplicateOfID *uint  `json:"duplicateOfId"`
+		Note          string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question, err := cc.closeService.CloseQuestion(id, c.GetUint("user_id"), models.CloseReason(req.Reason), req.DuplicateOfID, req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Đóng câu hỏi thành công",
+		"question": responses.ToQuestionResponse(question),
+	})
+}
+
+func (cc *QuestionCloseController) ReopenQuestion(c *gin.Context) {
+	id, ok := questionIDParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Note string `json:"note"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	question, err := cc.closeService.ReopenQuestion(id, c.GetUint("user_id"), req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Mở lại câu hỏi thành công",
+		"question": responses.ToQuestionResponse(question),
+	})
+}
+
+// LockQuestion khoá câu hỏi; durationHours bằng 0 hoặc bỏ trống nghĩa là khoá vô thời hạn
+func (cc *QuestionCloseController) LockQuestion(c *gin.Context) {
+	id, ok := questionIDParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		DurationHours int    `json:"durationHours"`
+		Note          string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question, err := cc.closeService.LockQuestion(id, c.GetUint("user_id"), time.Duration(req.DurationHours)*time.Hour, req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Khoá câu hỏi thành công",
+		"question": responses.ToQuestionResponse(question),
+	})
+}
+
+func (cc *QuestionCloseController) UnlockQuestion(c *gin.Context) {
+	id, ok := questionIDParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Note string `json:"note"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	question, err := cc.closeService.UnlockQuestion(id, c.GetUint("user_id"), req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Mở khoá câu hỏi thành công",
+		"question": responses.ToQuestionResponse(question),
+	})
+}
+
+func (cc *QuestionCloseController) GetVoteStatus(c *gin.Context) {
+	id, ok := questionIDParam(c)
+	if !ok {
+		return
+	}
+
+	status, err := cc.closeService.GetVoteStatus(id, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy câu hỏi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"votes": toCloseVoteStatusResponse(status)})
+}
+
+// VoteToClose bỏ phiếu đóng câu hỏi; câu hỏi tự đóng khi đủ ngưỡng phiếu
+func (cc *QuestionCloseController) VoteToClose(c *gin.Context) {
+	id, ok := questionIDParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Reason        string `json:"reason" binding:"required"`
+		DuplicateOfID *uint  `json:"duplicateOfId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, err := cc.closeService.VoteToClose(id, c.GetUint("user_id"), models.CloseReason(req.Reason), req.DuplicateOfID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã ghi nhận phiếu đóng câu hỏi",
+		"votes":   toCloseVoteStatusResponse(status),
+	})
+}
+
+func (cc *QuestionCloseController) VoteToReopen(c *gin.Context) {
+	id, ok := questionIDParam(c)
+	if !ok {
+		return
+	}
+
+	status, err := cc.closeService.VoteToReopen(id, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã ghi nhận phiếu mở lại câu hỏi",
+		"votes":   toCloseVoteStatusResponse(status),
+	})
+}
+
+func (cc *QuestionCloseController) RetractVote(c *gin.Context) {
+	id, ok := questionIDParam(c)
+	if !ok {
+		return
+	}
+
+	status, err := cc.closeService.RetractVote(id, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã rút phiếu",
+		"votes":   toCloseVoteStatusResponse(status),
+	})
+}
+
+func (cc *QuestionCloseController) GetTimeline(c *gin.Context) {
+	id, ok := questionIDParam(c)
+	if !ok {
+		return
+	}
+
+	events, err := cc.closeService.GetTimeline(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy câu hỏi"})
+		return
+	}
+
+	timeline := []responses.QuestionEventResponse{}
+	for i := range events {
+		timeline = append(timeline, responses.ToQuestionEventResponse(&events[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timeline": timeline})
+}
@@ -4,26 +4,32 @@ import (
 	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type QuestionController struct {
 	questionService services.QuestionService
+	bookmarkService services.BookmarkService
+	reactionCounter services.ReactionCounter
 }
 
-func NewQuestionController(q services.QuestionService) *QuestionController {
-	return &QuestionController{questionService: q}
+func NewQuestionController(q services.QuestionService, b services.BookmarkService, r services.ReactionCounter) *QuestionController {
+	return &QuestionController{questionService: q, bookmarkService: b, reactionCounter: r}
 }
 
 func (qc *QuestionController) CreateQuestion(c *gin.Context) {
 	var req struct {
-		Title       string `json:"title" binding:"required"`
-		Description string `json:"description"`
-		TopicID     uint   `json:"topicId" binding:"required"`
-		Status      string `json:"status"`
+		Title         string     `json:"title" binding:"required"`
+		Description   string     `json:"description"`
+		TopicID       uint       `json:"topicId" binding:"required"`
+		PublishAt     *time.Time `json:"publishAt"`     // Hẹn giờ đăng (tuỳ chọn)
+		ContentFormat string     `json:"contentFormat"` // html (mặc định) hoặc markdown
+		Anonymous     bool       `json:"anonymous"`     // Ẩn danh tính với người xem thông thường
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -33,7 +39,7 @@ func (qc *QuestionController) CreateQuestion(c *gin.Context) {
 
 	userID := c.GetUint("user_id")
 
-	question, err := qc.questionService.CreateQuestion(req.Title, req.Description, userID, req.TopicID, req.Status)
+	question, err := qc.questionService.CreateQuestion(req.Title, req.Description, userID, req.TopicID, req.PublishAt, req.ContentFormat, req.Anonymous)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -45,23 +51,31 @@ func (qc *QuestionController) CreateQuestion(c *gin.Context) {
 	})
 }
 
+// GetQuestion nhận ID hoặc slug; khi truy cập bằng slug cũ, canonicalSlug cho biết slug hiện tại để giao diện chuyển hướng
 func (qc *QuestionController) GetQuestion(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := strconv.ParseUint(idParam, 10, 64)
+	ref := c.Param("id")
+	id, err := qc.questionService.ResolveQuestionID(ref)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "ID câu hỏi không hợp lệ"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy câu hỏi"})
 		return
 	}
 
-	question, err := qc.questionService.GetQuestionByID(uint(id))
+	question, err := qc.questionService.GetQuestionByID(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy câu hỏi"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"question": responses.ToQuestionResponse(question),
-	})
+	response := questionResponse(c, question)
+	response.Bookmarked = qc.bookmarkService.BookmarkedIDs(c.GetUint("user_id"), models.BookmarkQuestion, []uint{question.ID})[question.ID]
+	reactions := qc.reactionCounter.Counts(models.ReactionTargetQuestion, []uint{question.ID})[question.ID]
+	response.Reactions, response.ReactionCount = reactions, int(reactions.Total())
+
+	result := gin.H{"question": response}
+	if ref != strconv.FormatUint(uint64(question.ID), 10) && ref != question.Slug && question.Slug != "" {
+		result["canonicalSlug"] = question.Slug
+	}
+	c.JSON(http.StatusOK, result)
 }
 
 func (qc *QuestionController) UpdateQuestion(c *gin.Context) {
@@ -73,9 +87,10 @@ func (qc *QuestionController) UpdateQuestion(c *gin.Context) {
 	}
 
 	var req struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		TopicID     uint   `json:"topic_id"`
+		Title         string `json:"title"`
+		Description   string `json:"description"`
+		TopicID       uint   `json:"topic_id"`
+		ContentFormat string `json:"contentFormat"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -83,7 +98,7 @@ func (qc *QuestionController) UpdateQuestion(c *gin.Context) {
 		return
 	}
 
-	question, err := qc.questionService.UpdateQuestion(uint(id), req.Title, req.Description, req.TopicID)
+	question, err := qc.questionService.UpdateQuestion(uint(id), req.Title, req.Description, req.TopicID, req.ContentFormat)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -149,6 +164,9 @@ func (qc *QuestionController) ListQuestions(c *gin.Context) {
 	}
 	filters["user_id"] = userID
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	questions, total, err := qc.questionService.ListQuestions(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê danh sách câu hỏi"})
@@ -157,13 +175,24 @@ func (qc *QuestionController) ListQuestions(c *gin.Context) {
 
 	var responseQuestions []responses.QuestionResponse
 	for _, question := range questions {
-		responseQuestions = append(responseQuestions, responses.ToQuestionResponse(&question))
+		responseQuestions = append(responseQuestions, questionResponse(c, &question))
 	}
+	markBookmarked(qc.bookmarkService, c.GetUint("user_id"), models.BookmarkQuestion, responseQuestions, func(r *responses.QuestionResponse) (uint, *bool) {
+		return r.ID, &r.Bookmarked
+	})
+	markReactionCounts(qc.reactionCounter, models.ReactionTargetQuestion, responseQuestions, func(r *responses.QuestionResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"questions": responseQuestions,
-		"total":     total,
-	})
+	}, filters, questions, func(item *models.Question) utils.Cursor {
+		// Feed cá nhân có thể xếp theo FeedRankAt (câu hỏi bị hạ hạng) thay vì CreatedAt
+		if item.FeedRankAt != nil {
+			return utils.Cursor{Time: *item.FeedRankAt, ID: item.ID}
+		}
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (qc *QuestionController) GetAllQuestion(c *gin.Context) {
@@ -199,7 +228,11 @@ func (qc *QuestionController) GetAllQuestion(c *gin.Context) {
 			filters["limit"] = l
 		}
 	}
+	excludeAnonymousFromProfile(c, filters)
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	questions, total, err := qc.questionService.GetAllQuestion(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê danh sách câu hỏi"})
@@ -208,13 +241,20 @@ func (qc *QuestionController) GetAllQuestion(c *gin.Context) {
 
 	var responseQuestions []responses.QuestionResponse
 	for _, question := range questions {
-		responseQuestions = append(responseQuestions, responses.ToQuestionResponse(&question))
+		responseQuestions = append(responseQuestions, questionResponse(c, &question))
 	}
+	markBookmarked(qc.bookmarkService, c.GetUint("user_id"), models.BookmarkQuestion, responseQuestions, func(r *responses.QuestionResponse) (uint, *bool) {
+		return r.ID, &r.Bookmarked
+	})
+	markReactionCounts(qc.reactionCounter, models.ReactionTargetQuestion, responseQuestions, func(r *responses.QuestionResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"questions": responseQuestions,
-		"total":     total,
-	})
+	}, filters, questions, func(item *models.Question) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (qc *QuestionController) UpdateQuestionStatus(c *gin.Context) {
@@ -302,6 +342,9 @@ func (qc *QuestionController) SuggestQuestions(c *gin.Context) {
 		}
 	}
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	questions, total, err := qc.questionService.ListQuestions(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể gợi ý câu hỏi"})
@@ -310,13 +353,20 @@ func (qc *QuestionController) SuggestQuestions(c *gin.Context) {
 
 	var responseQuestions []responses.QuestionResponse
 	for _, question := range questions {
-		responseQuestions = append(responseQuestions, responses.ToQuestionResponse(&question))
+		responseQuestions = append(responseQuestions, questionResponse(c, &question))
 	}
+	markBookmarked(qc.bookmarkService, c.GetUint("user_id"), models.BookmarkQuestion, responseQuestions, func(r *responses.QuestionResponse) (uint, *bool) {
+		return r.ID, &r.Bookmarked
+	})
+	markReactionCounts(qc.reactionCounter, models.ReactionTargetQuestion, responseQuestions, func(r *responses.QuestionResponse) (uint, *models.ReactionCounts, *int) {
+		return r.ID, &r.Reactions, &r.ReactionCount
+	})
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"questions": responseQuestions,
-		"total":     total,
-	})
+	}, filters, questions, func(item *models.Question) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (qc *QuestionController) SyncQuestionsToRAG(c *gin.Context) {
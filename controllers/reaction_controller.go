@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
@@ -18,16 +20,18 @@ func NewReactionController(s services.ReactionService) *ReactionController {
 
 func (rc *ReactionController) CreateReaction(c *gin.Context) {
 	var req struct {
-		PostID    *uint `json:"post_id"`
-		CommentID *uint `json:"comment_id"`
-		AnswerID  *uint `json:"answer_id"`
+		Type       string `json:"type"` // Loại tương tác, mặc định "like"
+		PostID     *uint  `json:"post_id"`
+		CommentID  *uint  `json:"comment_id"`
+		AnswerID   *uint  `json:"answer_id"`
+		QuestionID *uint  `json:"question_id"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Yêu cầu không hợp lệ: " + err.Error()})
 		return
 	}
 	userID := c.GetUint("user_id")
-	reaction, err := rc.reactionService.CreateReaction(userID, req.PostID, req.CommentID, req.AnswerID)
+	reaction, err := rc.reactionService.CreateReaction(userID, req.Type, req.PostID, req.CommentID, req.AnswerID, req.QuestionID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -61,16 +65,18 @@ func (rc *ReactionController) UpdateReaction(c *gin.Context) {
 		return
 	}
 	var req struct {
-		PostID    *uint `json:"post_id"`
-		CommentID *uint `json:"comment_id"`
-		AnswerID  *uint `json:"answer_id"`
+		Type       string `json:"type"` // Loại tương tác, mặc định "like"
+		PostID     *uint  `json:"post_id"`
+		CommentID  *uint  `json:"comment_id"`
+		AnswerID   *uint  `json:"answer_id"`
+		QuestionID *uint  `json:"question_id"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Yêu cầu không hợp lệ: " + err.Error()})
 		return
 	}
 	userID := c.GetUint("user_id")
-	reaction, err := rc.reactionService.UpdateReaction(uint(id), userID, req.PostID, req.CommentID, req.AnswerID)
+	reaction, err := rc.reactionService.UpdateReaction(uint(id), userID, req.Type, req.PostID, req.CommentID, req.AnswerID, req.QuestionID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -119,6 +125,14 @@ func (rc *ReactionController) ListReactions(c *gin.Context) {
 			filters["answer_id"] = uint(aID)
 		}
 	}
+	if questionID := c.Query("question_id"); questionID != "" {
+		if qID, err := strconv.ParseUint(questionID, 10, 64); err == nil {
+			filters["question_id"] = uint(qID)
+		}
+	}
+	if reactionType := c.Query("type"); reactionType != "" {
+		filters["type"] = reactionType
+	}
 	if page := c.Query("page"); page != "" {
 		if p, err := strconv.Atoi(page); err == nil {
 			filters["page"] = p
@@ -129,6 +143,9 @@ func (rc *ReactionController) ListReactions(c *gin.Context) {
 			filters["limit"] = l
 		}
 	}
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	reactions, total, err := rc.reactionService.ListReactions(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -138,14 +155,15 @@ func (rc *ReactionController) ListReactions(c *gin.Context) {
 	for _, reaction := range reactions {
 		responseReactions = append(responseReactions, responses.ToReactionResponse(&reaction))
 	}
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"reactions": responseReactions,
-		"total":     total,
-	})
+	}, filters, reactions, func(item *models.Reaction) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (rc *ReactionController) GetReactionCount(c *gin.Context) {
-	var postID, commentID, answerID *uint
+	var postID, commentID, answerID, questionID *uint
 	if pID := c.Query("post_id"); pID != "" {
 		if id, err := strconv.ParseUint(pID, 10, 64); err == nil {
 			postID = new(uint)
@@ -164,19 +182,26 @@ func (rc *ReactionController) GetReactionCount(c *gin.Context) {
 			*answerID = uint(id)
 		}
 	}
-	count, err := rc.reactionService.GetReactionCount(postID, commentID, answerID)
+	if qID := c.Query("question_id"); qID != "" {
+		if id, err := strconv.ParseUint(qID, 10, 64); err == nil {
+			questionID = new(uint)
+			*questionID = uint(id)
+		}
+	}
+	counts, err := rc.reactionService.GetReactionCount(postID, commentID, answerID, questionID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"count": count,
+		"count":  counts.Total(),
+		"counts": counts,
 	})
 }
 
 func (rc *ReactionController) CheckUserReaction(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	var postID, commentID, answerID *uint
+	var postID, commentID, answerID, questionID *uint
 	if pID := c.Query("post_id"); pID != "" {
 		if id, err := strconv.ParseUint(pID, 10, 64); err == nil {
 			postID = new(uint)
@@ -204,7 +229,16 @@ func (rc *ReactionController) CheckUserReaction(c *gin.Context) {
 			return
 		}
 	}
-	hasReacted, reaction, err := rc.reactionService.CheckUserReaction(userID, postID, commentID, answerID)
+	if qID := c.Query("question_id"); qID != "" {
+		if id, err := strconv.ParseUint(qID, 10, 64); err == nil {
+			questionID = new(uint)
+			*questionID = uint(id)
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "question_id không hợp lệ"})
+			return
+		}
+	}
+	hasReacted, reaction, err := rc.reactionService.CheckUserReaction(userID, postID, commentID, answerID, questionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -220,7 +254,7 @@ func (rc *ReactionController) CheckUserReaction(c *gin.Context) {
 
 func (rc *ReactionController) GetReactionStatus(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	var postID, commentID, answerID *uint
+	var postID, commentID, answerID, questionID *uint
 	if pID := c.Query("post_id"); pID != "" {
 		if id, err := strconv.ParseUint(pID, 10, 64); err == nil {
 			postID = new(uint)
@@ -248,19 +282,28 @@ func (rc *ReactionController) GetReactionStatus(c *gin.Context) {
 			return
 		}
 	}
+	if qID := c.Query("question_id"); qID != "" {
+		if id, err := strconv.ParseUint(qID, 10, 64); err == nil {
+			questionID = new(uint)
+			*questionID = uint(id)
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "question_id không hợp lệ"})
+			return
+		}
+	}
 
-	if err := rc.reactionService.ValidateReactionID(postID, commentID, answerID); err != nil { // Sửa lại gọi qua reactionService
+	if err := rc.reactionService.ValidateReactionID(postID, commentID, answerID, questionID); err != nil { // Sửa lại gọi qua reactionService
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	hasReacted, reaction, err := rc.reactionService.CheckUserReaction(userID, postID, commentID, answerID)
+	hasReacted, reaction, err := rc.reactionService.CheckUserReaction(userID, postID, commentID, answerID, questionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	count, err := rc.reactionService.GetReactionCount(postID, commentID, answerID)
+	counts, err := rc.reactionService.GetReactionCount(postID, commentID, answerID, questionID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -268,10 +311,36 @@ func (rc *ReactionController) GetReactionStatus(c *gin.Context) {
 
 	response := gin.H{
 		"has_reacted": hasReacted,
-		"count":       count,
+		"count":       counts.Total(),
+		"counts":      counts,
 	}
 	if hasReacted {
 		response["reaction"] = responses.ToReactionResponse(reaction)
 	}
 	c.JSON(http.StatusOK, response)
 }
+
+// ListReactionTypes trả về tập loại tương tác đang bật để giao diện hiển thị bảng chọn
+func (rc *ReactionController) ListReactionTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"types": rc.reactionService.ReactionTypes(),
+	})
+}
+
+// markReactionCounts điền số tương tác theo loại cho một danh sách response bằng một lần đọc bộ đếm
+func markReactionCounts[T any](counter services.ReactionCounter, targetType models.ReactionTargetType, items []T, item func(*T) (uint, *models.ReactionCounts, *int)) {
+	if counter == nil || len(items) == 0 {
+		return
+	}
+	ids := make([]uint, 0, len(items))
+	for i := range items {
+		id, _, _ := item(&items[i])
+		ids = append(ids, id)
+	}
+	counts := counter.Counts(targetType, ids)
+	for i := range items {
+		id, reactions, total := item(&items[i])
+		*reactions = counts[id]
+		*total = int(counts[id].Total())
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 type ReportController struct {
@@ -222,6 +224,18 @@ func (rc *ReportController) ListReports(c *gin.Context) {
 		}
 	}
 
+	// Cursor chỉ áp dụng khi sắp xếp theo cột thời gian; sort_by khác vẫn phân trang bằng page
+	sortBy, _ := filters["sort_by"].(string)
+	useCursor := sortBy == "" || sortBy == "created_at" || sortBy == "updated_at"
+	if useCursor {
+		if order, ok := filters["order"].(string); ok {
+			filters["sort"] = strings.ToLower(order)
+		}
+		if !bindCursor(c, filters, "desc") {
+			return
+		}
+	}
+
 	reports, total, err := rc.reportService.ListReports(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê báo cáo"})
@@ -238,10 +252,21 @@ func (rc *ReportController) ListReports(c *gin.Context) {
 		response = append(response, resp)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	if !useCursor {
+		c.JSON(http.StatusOK, gin.H{
+			"reports": response,
+			"total":   total,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"reports": response,
-		"total":   total,
-	})
+	}, filters, reports, func(item *models.Report) utils.Cursor {
+		if sortBy == "updated_at" {
+			return utils.Cursor{Time: item.UpdatedAt, Ref: item.ID}
+		}
+		return utils.Cursor{Time: item.CreatedAt, Ref: item.ID}
+	}, total))
 }
 
 func (rc *ReportController) BatchDeleteReports(c *gin.Context) {
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ScheduleController struct {
+	questionService services.QuestionService
+	postService     services.PostService
+	permService     services.PermissionService
+}
+
+func NewScheduleController(q services.QuestionService, p services.PostService, perm services.PermissionService) *ScheduleController {
+	return &ScheduleController{questionService: q, postService: p, permService: perm}
+}
+
+// ownerScope trả về userID dùng để giới hạn thao tác; 0 nghĩa là người dùng có quyền quản lý mọi lịch đăng
+func (sc *ScheduleController) ownerScope(c *gin.Context) uint {
+	userID := c.GetUint("user_id")
+	role, err := sc.permService.GetUserRole(userID)
+	if err != nil {
+		return userID
+	}
+	permission, err := sc.permService.GetPermission(string(role), "schedule", "manage")
+	if err == nil && permission != nil && permission.Allowed {
+		return 0
+	}
+	return userID
+}
+
+func (sc *ScheduleController) ListScheduled(c *gin.Context) {
+	itemType := c.Query("type")
+	if itemType != "" && itemType != "question" && itemType != "post" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Loại nội dung không hợp lệ, chỉ chấp nhận 'question' hoặc 'post'"})
+		return
+	}
+	scope := sc.ownerScope(c)
+
+	questionResponses := []responses.QuestionResponse{}
+	if itemType == "" || itemType == "question" {
+		questions, err := sc.questionService.ListScheduledQuestions(scope)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê câu hỏi hẹn giờ"})
+			return
+		}
+		for i := range questions {
+			questionResponses = append(questionResponses, responses.ToQuestionResponse(&questions[i]))
+		}
+	}
+
+	postResponses := []responses.PostResponse{}
+	if itemType == "" || itemType == "post" {
+		posts, err := sc.postService.ListScheduledPosts(scope)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê bài viết hẹn giờ"})
+			return
+		}
+		for i := range posts {
+			postResponses = append(postResponses, responses.ToPostResponse(&posts[i]))
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"questions": questionResponses,
+		"posts":     postResponses,
+	})
+}
+
+func (sc *ScheduleController) Reschedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID không hợp lệ"})
+		return
+	}
+
+	var req struct {
+		PublishAt time.Time `json:"publishAt" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scope := sc.ownerScope(c)
+	switch c.Param("type") {
+	case "question":
+		question, err := sc.questionService.RescheduleQuestion(uint(id), scope, req.PublishAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Đổi lịch đăng câu hỏi thành công",
+			"question": responses.ToQuestionResponse(question),
+		})
+	case "post":
+		post, err := sc.postService.ReschedulePost(uint(id), scope, req.PublishAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Đổi lịch đăng bài viết thành công",
+			"post":    responses.ToPostResponse(post),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Loại nội dung không hợp lệ, chỉ chấp nhận 'question' hoặc 'post'"})
+	}
+}
+
+func (sc *ScheduleController) Cancel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID không hợp lệ"})
+		return
+	}
+
+	scope := sc.ownerScope(c)
+	switch c.Param("type") {
+	case "question":
+		err = sc.questionService.CancelScheduledQuestion(uint(id), scope)
+	case "post":
+		err = sc.postService.CancelScheduledPost(uint(id), scope)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Loại nội dung không hợp lệ, chỉ chấp nhận 'question' hoặc 'post'"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Huỷ lịch đăng thành công"})
+}
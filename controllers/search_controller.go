@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"Forum_BE/repositories"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type SearchController struct {
+	searchService      services.SearchService
+	searchIndexService services.SearchIndexService
+}
+
+func NewSearchController(s services.SearchService, si services.SearchIndexService) *SearchController {
+	return &SearchController{searchService: s, searchIndexService: si}
+}
+
+func (sc *SearchController) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vui lòng nhập từ khoá tìm kiếm"})
+		return
+	}
+
+	filters := map[string]interface{}{"search": query}
+
+	if types := c.Query("type"); types != "" {
+		var selected []string
+		for _, t := range strings.Split(types, ",") {
+			t = strings.TrimSpace(t)
+			valid := false
+			for _, known := range repositories.SearchTypes {
+				if t == known {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Loại nội dung không hợp lệ: " + t})
+				return
+			}
+			selected = append(selected, t)
+		}
+		filters["typefilter"] = selected
+	}
+	if topicID := c.Query("topic_id"); topicID != "" {
+		id, err := strconv.ParseUint(topicID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID chủ đề không hợp lệ"})
+			return
+		}
+		filters["topic_id"] = uint(id)
+	}
+	if tagID := c.Query("tag_id"); tagID != "" {
+		id, err := strconv.ParseUint(tagID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID thẻ không hợp lệ"})
+			return
+		}
+		filters["tag_id"] = uint(id)
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filters["page"] = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filters["limit"] = l
+		}
+	}
+
+	result, err := sc.searchService.Search(filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tìm kiếm"})
+		return
+	}
+
+	hits := make([]responses.SearchHitResponse, 0, len(result.Hits))
+	for i := range result.Hits {
+		hits = append(hits, responses.ToSearchHitResponse(&result.Hits[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": hits,
+		"total":   result.Total,
+		"facets": gin.H{
+			"types":  result.TypeCounts,
+			"topics": responses.ToSearchFacetResponses(result.TopicFacets),
+			"tags":   responses.ToSearchFacetResponses(result.TagFacets),
+		},
+	})
+}
+
+func (sc *SearchController) Reindex(c *gin.Context) {
+	if !sc.searchIndexService.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Chỉ mục tìm kiếm chưa được khởi tạo"})
+		return
+	}
+
+	count, err := sc.searchIndexService.Reindex()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể dựng lại chỉ mục tìm kiếm"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Dựng lại chỉ mục tìm kiếm thành công",
+		"documents": count,
+	})
+}
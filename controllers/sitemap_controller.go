@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type SitemapController struct {
+	sitemapService services.SitemapService
+}
+
+func NewSitemapController(s services.SitemapService) *SitemapController {
+	return &SitemapController{sitemapService: s}
+}
+
+// Index trả về sitemap index trỏ tới các khối /sitemaps/<tên>.xml trên chính máy chủ này
+func (sc *SitemapController) Index(c *gin.Context) {
+	body, err := sc.sitemapService.Index(requestScheme(c) + "://" + c.Request.Host)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể tạo sitemap"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+func (sc *SitemapController) Segment(c *gin.Context) {
+	name := strings.TrimSuffix(c.Param("name"), ".xml")
+	body, lastMod, err := sc.sitemapService.Segment(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy sitemap"})
+		return
+	}
+	c.Header("Last-Modified", lastMod.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type SpamClassifierController struct {
+	spamClassifierService services.SpamClassifierService
+}
+
+func NewSpamClassifierController(s services.SpamClassifierService) *SpamClassifierController {
+	return &SpamClassifierController{spamClassifierService: s}
+}
+
+// Report trả về trạng thái mô hình đang chạy cùng kết quả đánh giá của các phiên bản gần nhất, phiên bản mới nhất lên trước
+func (sc *SpamClassifierController) Report(c *gin.Context) {
+	status, versions, err := sc.spamClassifierService.Report()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể lấy báo cáo bộ phân loại spam"})
+		return
+	}
+
+	responseVersions := []responses.SpamModelVersionResponse{}
+	for i := range versions {
+		responseVersions = append(responseVersions, responses.ToSpamModelVersionResponse(&versions[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"current":  status,
+		"versions": responseVersions,
+	})
+}
+
+// Train huấn luyện lại ngay từ toàn bộ quyết định kiểm duyệt và kích hoạt phiên bản mới
+func (sc *SpamClassifierController) Train(c *gin.Context) {
+	actorID := c.GetUint("user_id")
+	version, err := sc.spamClassifierService.Train(&actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Không thể huấn luyện bộ phân loại spam: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Huấn luyện bộ phân loại spam thành công",
+		"version": responses.ToSpamModelVersionResponse(version),
+	})
+}
+
+// Activate chuyển sang một phiên bản đã huấn luyện, dùng để quay lại phiên bản cũ
+func (sc *SpamClassifierController) Activate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID phiên bản không hợp lệ"})
+		return
+	}
+
+	version, err := sc.spamClassifierService.Activate(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy phiên bản mô hình"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Đã kích hoạt phiên bản mô hình",
+		"version": responses.ToSpamModelVersionResponse(version),
+	})
+}
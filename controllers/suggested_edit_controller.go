@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/responses"
+	"Forum_BE/services"
+	"Forum_BE/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type SuggestedEditController struct {
+	editService services.SuggestedEditService
+}
+
+func NewSuggestedEditController(s services.SuggestedEditService) *SuggestedEditController {
+	return &SuggestedEditController{editService: s}
+}
+
+func (ec *SuggestedEditController) SuggestEdit(c *gin.Context) {
+	var req struct {
+		TargetType string `json:"targetType" binding:"required"`
+		TargetID   uint   `json:"targetId" binding:"required"`
+		Title      string `json:"title"`
+		Content    string `json:"content" binding:"required"`
+		TopicID    uint   `json:"topicId"`
+		TagIDs     []uint `json:"tagIds"`
+		Summary    string `json:"summary"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	edit, err := ec.editService.SuggestEdit(c.GetUint("user_id"), req.TargetType, req.TargetID, req.Title, req.Content, req.TopicID, req.TagIDs, req.Summary)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Gửi đề xuất chỉnh sửa thành công",
+		"suggestedEdit": responses.ToSuggestedEditResponse(edit),
+	})
+}
+
+// ListSuggestedEdits trả về hàng đợi đề xuất (mặc định các đề xuất đang chờ); mine=true chỉ lấy đề xuất của người dùng hiện tại
+func (ec *SuggestedEditController) ListSuggestedEdits(c *gin.Context) {
+	filters := map[string]interface{}{"status": "pending"}
+	if status, ok := c.GetQuery("status"); ok {
+		filters["status"] = status
+	}
+	if targetType := c.Query("targetType"); targetType != "" {
+		filters["target_type"] = targetType
+	}
+	if targetID := c.Query("targetId"); targetID != "" {
+		if id, err := strconv.ParseUint(targetID, 10, 64); err == nil {
+			filters["target_id"] = uint(id)
+		}
+	}
+	if c.Query("mine") == "true" {
+		filters["editor_id"] = c.GetUint("user_id")
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filters["page"] = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filters["limit"] = l
+		}
+	}
+
+	if !bindCursor(c, filters, "asc") {
+		return
+	}
+	edits, total, err := ec.editService.ListSuggestedEdits(filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê đề xuất chỉnh sửa"})
+		return
+	}
+
+	responseEdits := []responses.SuggestedEditResponse{}
+	for i := range edits {
+		responseEdits = append(responseEdits, responses.ToSuggestedEditResponse(&edits[i]))
+	}
+
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"suggestedEdits": responseEdits,
+	}, filters, edits, func(item *models.SuggestedEdit) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
+}
+
+func (ec *SuggestedEditController) GetSuggestedEdit(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID đề xuất không hợp lệ"})
+		return
+	}
+
+	edit, err := ec.editService.GetSuggestedEdit(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Không tìm thấy đề xuất chỉnh sửa"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suggestedEdit": responses.ToSuggestedEditResponse(edit),
+	})
+}
+
+// ApproveSuggestedEdit duyệt đề xuất; nếu gửi kèm title/content/topicId/tagIds thì người duyệt đang chỉnh lại (improve) trước khi áp dụng
+func (ec *SuggestedEditController) ApproveSuggestedEdit(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID đề xuất không hợp lệ"})
+		return
+	}
+
+	var req struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+		TopicID uint   `json:"topicId"`
+		TagIDs  []uint `json:"tagIds"`
+		Note    string `json:"note"`
+		Force   bool   `json:"force"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	var override *services.SuggestedEditOverride
+	if req.Title != "" || req.Content != "" || req.TopicID != 0 || len(req.TagIDs) > 0 {
+		override = &services.SuggestedEditOverride{
+			Title:   req.Title,
+			Content: req.Content,
+			TopicID: req.TopicID,
+			TagIDs:  req.TagIDs,
+		}
+	}
+
+	edit, err := ec.editService.ApproveSuggestedEdit(uint(id), c.GetUint("user_id"), override, req.Note, req.Force)
+	if errors.Is(err, services.ErrSuggestedEditConflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         "Nội dung đã được chỉnh sửa sau khi đề xuất được gửi, hãy xem lại hoặc gửi force=true",
+			"suggestedEdit": responses.ToSuggestedEditResponse(edit),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Duyệt đề xuất chỉnh sửa thành công",
+		"suggestedEdit": responses.ToSuggestedEditResponse(edit),
+	})
+}
+
+func (ec *SuggestedEditController) RejectSuggestedEdit(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID đề xuất không hợp lệ"})
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	edit, err := ec.editService.RejectSuggestedEdit(uint(id), c.GetUint("user_id"), req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Đã từ chối đề xuất chỉnh sửa",
+		"suggestedEdit": responses.ToSuggestedEditResponse(edit),
+	})
+}
+
+func (ec *SuggestedEditController) WithdrawSuggestedEdit(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID đề xuất không hợp lệ"})
+		return
+	}
+
+	if err := ec.editService.WithdrawSuggestedEdit(uint(id), c.GetUint("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Đã rút lại đề xuất chỉnh sửa"})
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
@@ -123,6 +125,9 @@ func (tc *TagController) ListTags(c *gin.Context) {
 		}
 	}
 
+	if !bindCursor(c, filters, "asc") {
+		return
+	}
 	tags, total, err := tc.tagService.ListTags(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê các nhãn"})
@@ -134,10 +139,11 @@ func (tc *TagController) ListTags(c *gin.Context) {
 		responseTags = append(responseTags, responses.ToTagResponse(&tag))
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"tags":  responseTags,
-		"total": total,
-	})
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
+		"tags": responseTags,
+	}, filters, tags, func(item *models.Tag) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (tc *TagController) GetTagsByPostID(c *gin.Context) {
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"Forum_BE/models"
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"log"
@@ -11,16 +13,17 @@ import (
 )
 
 type TopicController struct {
-	topicService  services.TopicService
-	followService services.FollowService
+	topicService      services.TopicService
+	followService     services.FollowService
+	classifierService services.TopicClassifierService
 }
 
 func NewTopicController(t services.TopicService) *TopicController {
 	return &TopicController{topicService: t}
 }
 
-func NewTopicControllerWithDB(db *gorm.DB, t services.TopicService) *TopicController {
-	return &TopicController{topicService: t}
+func NewTopicControllerWithDB(db *gorm.DB, t services.TopicService, classifier services.TopicClassifierService) *TopicController {
+	return &TopicController{topicService: t, classifierService: classifier}
 }
 
 func (tc *TopicController) CreateTopic(c *gin.Context) {
@@ -71,6 +74,34 @@ func (tc *TopicController) ProposeTopic(c *gin.Context) {
 	})
 }
 
+func (tc *TopicController) SuggestTopics(c *gin.Context) {
+	var req struct {
+		Title       string `json:"title" binding:"required"`
+		Description string `json:"description"`
+		Limit       int    `json:"limit"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	suggestions, err := tc.classifierService.Suggest(req.Title, utils.StripHTML(req.Description), req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể gợi ý chủ đề"})
+		return
+	}
+
+	responseSuggestions := make([]responses.TopicSuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		responseSuggestions = append(responseSuggestions, responses.ToTopicSuggestionResponse(suggestion.TopicID, suggestion.Name, suggestion.Confidence))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"suggestions": responseSuggestions,
+	})
+}
+
 func (tc *TopicController) GetTopic(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 64)
@@ -167,6 +198,9 @@ func (tc *TopicController) ListTopics(c *gin.Context) {
 		}
 	}
 
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	topics, total, err := tc.topicService.ListTopics(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Không thể liệt kê các chủ đề"})
@@ -178,10 +212,11 @@ func (tc *TopicController) ListTopics(c *gin.Context) {
 		responseTopics = append(responseTopics, responses.ToTopicResponse(&topic))
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"topics": responseTopics,
-		"total":  total,
-	})
+	}, filters, topics, func(item *models.Topic) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, total))
 }
 
 func (tc *TopicController) AddQuestionToTopic(c *gin.Context) {
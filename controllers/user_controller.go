@@ -8,9 +8,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"Forum_BE/models"
 	"Forum_BE/repositories" // Add this import
 	"Forum_BE/responses"
 	"Forum_BE/services"
+	"Forum_BE/utils"
 
 )
 
@@ -159,6 +161,9 @@ func (uc *UserController) GetAllUsers(c *gin.Context) {
 			filters["limit"] = l
 		}
 	}
+	if !bindCursor(c, filters, "desc") {
+		return
+	}
 	users, total, err := uc.userService.GetAllUsers(filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, Response{Message: "Không thể liêt kê tất cả người dùng"})
@@ -169,10 +174,11 @@ func (uc *UserController) GetAllUsers(c *gin.Context) {
 	for _, user := range users {
 		responseUsers = append(responseUsers, responses.ToUserResponse(&user))
 	}
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, withPageInfo(gin.H{
 		"users": responseUsers,
-		"total": total,
-	})
+	}, filters, users, func(item *models.User) utils.Cursor {
+		return utils.Cursor{Time: item.CreatedAt, ID: item.ID}
+	}, int(total)))
 }
 
 func (uc *UserController) ModifyUserStatus(c *gin.Context) {
@@ -6,7 +6,7 @@ import (
 	"log"
 )
 
-func StartCronJobs(qs services.QuestionService) {
+func StartCronJobs(qs services.QuestionService, ps services.PostService, classifier services.TopicClassifierService, drafts services.DraftService, questionClose services.QuestionCloseService, sitemap services.SitemapService, passes services.PassService, voteFraud services.VoteFraudService, spamClassifier services.SpamClassifierService) *cron.Cron {
 	c := cron.New()
 	c.AddFunc("0 3 * * *", func() {
 
@@ -17,5 +17,76 @@ func StartCronJobs(qs services.QuestionService) {
 		}
 		log.Println("Synced", len(questions), "questions to RAG")
 	})
+	// Đăng các câu hỏi và bài viết hẹn giờ đã đến hạn, mở khoá các câu hỏi hết hạn khoá
+	c.AddFunc("@every 1m", func() {
+		if count, err := qs.PublishDueQuestions(); err != nil {
+			log.Println("Failed to publish scheduled questions:", err)
+		} else if count > 0 {
+			log.Println("Published", count, "scheduled questions")
+		}
+		if count, err := ps.PublishDuePosts(); err != nil {
+			log.Println("Failed to publish scheduled posts:", err)
+		} else if count > 0 {
+			log.Println("Published", count, "scheduled posts")
+		}
+		if count, err := questionClose.ExpireLocks(); err != nil {
+			log.Println("Failed to expire question locks:", err)
+		} else if count > 0 {
+			log.Println("Unlocked", count, "questions with expired locks")
+		}
+	})
+	// Huấn luyện lại bộ phân loại chủ đề từ các câu hỏi đã duyệt
+	c.AddFunc("30 3 * * *", func() {
+		if err := classifier.Train(); err != nil {
+			log.Println("Failed to retrain topic classifier:", err)
+		}
+	})
+	// Huấn luyện lại bộ phân loại spam thành phiên bản mới từ các quyết định kiểm duyệt
+	c.AddFunc("40 3 * * *", func() {
+		if _, err := spamClassifier.Train(nil); err != nil {
+			log.Println("Failed to retrain spam classifier:", err)
+		}
+	})
+	// Xoá các bản nháp đã lâu không được cập nhật
+	c.AddFunc("0 4 * * *", func() {
+		count, err := drafts.PruneDrafts()
+		if err != nil {
+			log.Println("Failed to prune drafts:", err)
+			return
+		}
+		log.Println("Pruned", count, "stale drafts")
+	})
+	// Đưa trở lại feed các câu hỏi bị bỏ qua đã lâu mà vẫn chưa có câu trả lời
+	c.AddFunc("15 4 * * *", func() {
+		count, err := passes.ResurfaceStale()
+		if err != nil {
+			log.Println("Failed to resurface passed questions:", err)
+			return
+		}
+		log.Println("Resurfaced", count, "passed questions")
+	})
+	// Phát hiện bỏ phiếu hàng loạt và vòng bỏ phiếu, huỷ các lượt ủng hộ vi phạm
+	c.AddFunc("45 4 * * *", func() {
+		result, err := voteFraud.Detect()
+		if err != nil {
+			log.Println("Failed to detect vote fraud:", err)
+			return
+		}
+		if result.SerialCases+result.RingCases > 0 {
+			log.Println("Vote fraud:", result.SerialCases, "serial and", result.RingCases, "ring cases,", result.InvalidatedVotes, "votes and", result.InvalidatedReactions, "reactions invalidated")
+		}
+	})
+	// Sinh lại các khối sitemap có nội dung thay đổi
+	c.AddFunc("@every 30m", func() {
+		count, err := sitemap.Regenerate()
+		if err != nil {
+			log.Println("Failed to regenerate sitemap:", err)
+			return
+		}
+		if count > 0 {
+			log.Println("Regenerated", count, "sitemap segments")
+		}
+	})
 	c.Start()
+	return c
 }
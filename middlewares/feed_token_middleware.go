@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// FeedTokenMiddleware xác thực trình đọc feed bằng token riêng trong query (?token=...)
+// thay cho JWT, rồi gán user_id để CheckPermission hoạt động như các route khác.
+func FeedTokenMiddleware(feedService services.FeedService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := feedService.Authenticate(c.Query("token"))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid feed token"})
+			c.Abort()
+			return
+		}
+		c.Set("user_id", userID)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RevealAnonymous không chặn request mà chỉ đánh dấu reveal_anonymous khi người dùng có quyền
+// ("anonymous", "reveal") để controller gắn danh tính thật vào nội dung ẩn danh.
+func RevealAnonymous(permService services.PermissionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID := c.GetUint("user_id"); userID != 0 {
+			if role, err := permService.GetUserRole(userID); err == nil {
+				permission, err := permService.GetPermission(string(role), "anonymous", "reveal")
+				c.Set("reveal_anonymous", err == nil && permission != nil && permission.Allowed)
+			}
+		}
+
+		c.Next()
+	}
+}
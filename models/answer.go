@@ -9,7 +9,7 @@ import (
 type Answer struct {
 	ID             uint            `gorm:"primaryKey" json:"id"`
 	Content        string          `gorm:"type:text" json:"content"`
-	Title          string          `gorm:"type:text" json:"title"`
+	Title          string          `gorm:"type:text;index:idx_answers_fulltext,class:FULLTEXT" json:"title"`
 	UserID         uint            `gorm:"not null;index" json:"user_id"`
 	QuestionID     uint            `gorm:"not null;index" json:"question_id"`
 	Status         string          `gorm:"type:ENUM('approved','pending','rejected');default:'pending'" json:"status"`
@@ -17,10 +17,14 @@ type Answer struct {
 	RootCommentID  *uint           `json:"root_comment_id,omitempty" gorm:"index"`
 	Metadata       json.RawMessage `gorm:"type:json" json:"metadata,omitempty"`
 	HasEditHistory bool            `gorm:"default:false" json:"has_edit_history"`
+	Anonymous      bool            `gorm:"default:false;index" json:"anonymous"`
+	Pseudonym      string          `gorm:"type:varchar(64)" json:"pseudonym,omitempty"`
 	CreatedAt      time.Time       `json:"created_at"`
 	UpdatedAt      time.Time       `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"-"`
-	PlainContent   string          `gorm:"type:text"`
+	PlainContent   string          `gorm:"type:text;index:idx_answers_fulltext,class:FULLTEXT"`
+	ContentFormat  ContentFormat   `gorm:"type:varchar(16);default:'html'" json:"content_format"`
+	ContentSource  string          `gorm:"type:longtext" json:"content_source,omitempty"` // Nguồn markdown gốc, rỗng với nội dung html
 
 	User      User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Question  Question   `json:"question,omitempty" gorm:"foreignKey:QuestionID"`
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type AnswerRequestStatus string
+
+const (
+	AnswerRequestPending   AnswerRequestStatus = "pending"
+	AnswerRequestDeclined  AnswerRequestStatus = "declined"
+	AnswerRequestFulfilled AnswerRequestStatus = "fulfilled"
+)
+
+func IsValidAnswerRequestStatus(status AnswerRequestStatus) bool {
+	switch status {
+	case AnswerRequestPending, AnswerRequestDeclined, AnswerRequestFulfilled:
+		return true
+	}
+	return false
+}
+
+// AnswerRequest là lời nhờ một người dùng trả lời câu hỏi ("ask to answer").
+// Mỗi người chỉ được nhờ một lần cho mỗi câu hỏi, dù ai là người nhờ.
+type AnswerRequest struct {
+	ID          uint                `gorm:"primaryKey" json:"id"`
+	QuestionID  uint                `gorm:"not null;uniqueIndex:idx_answer_requests_question_user" json:"question_id"`
+	UserID      uint                `gorm:"not null;uniqueIndex:idx_answer_requests_question_user;index:idx_answer_requests_user_status,priority:1" json:"user_id"`
+	RequesterID uint                `gorm:"not null;index" json:"requester_id"`
+	Status      AnswerRequestStatus `gorm:"type:varchar(16);not null;default:'pending';index:idx_answer_requests_user_status,priority:2" json:"status"`
+	AnswerID    *uint               `json:"answer_id,omitempty"`
+	RespondedAt *time.Time          `json:"responded_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+
+	Question  *Question `gorm:"foreignKey:QuestionID" json:"question,omitempty"`
+	User      *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Requester *User     `gorm:"foreignKey:RequesterID" json:"requester,omitempty"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AutoModerationAction string
+
+const (
+	AutoModerationApprove AutoModerationAction = "approve"
+	AutoModerationHold    AutoModerationAction = "hold"
+	AutoModerationSpam    AutoModerationAction = "spam"
+	AutoModerationReject  AutoModerationAction = "reject"
+)
+
+// Severity xếp hạng mức độ của hành động; khi nhiều luật cùng khớp, hành động nặng nhất được áp dụng
+func (a AutoModerationAction) Severity() int {
+	switch a {
+	case AutoModerationApprove:
+		return 1
+	case AutoModerationHold:
+		return 2
+	case AutoModerationSpam:
+		return 3
+	case AutoModerationReject:
+		return 4
+	}
+	return 0
+}
+
+const (
+	AutoModerationEventCreate = "create"
+	AutoModerationEventUpdate = "update"
+)
+
+// AutoModerationVelocity khớp khi người dùng đã đăng ít nhất Count nội dung trong Minutes phút gần nhất
+type AutoModerationVelocity struct {
+	Count   int `json:"count"`
+	Minutes int `json:"minutes"`
+}
+
+// AutoModerationConditions là điều kiện của một luật; mọi điều kiện được đặt phải cùng khớp.
+// ContentTypes và Events giới hạn phạm vi áp dụng, để trống nghĩa là mọi loại nội dung / cả tạo và sửa.
+type AutoModerationConditions struct {
+	ContentTypes       []string                `json:"content_types,omitempty"`
+	Events             []string                `json:"events,omitempty"`
+	BannedWords        []string                `json:"banned_words,omitempty"`
+	Patterns           []string                `json:"patterns,omitempty"`
+	MinLinks           *int                    `json:"min_links,omitempty"`
+	MaxAccountAgeHours *int                    `json:"max_account_age_hours,omitempty"`
+	MinAccountAgeHours *int                    `json:"min_account_age_hours,omitempty"`
+	MaxReputation      *uint                   `json:"max_reputation,omitempty"`
+	MinReputation      *uint                   `json:"min_reputation,omitempty"`
+	Velocity           *AutoModerationVelocity `json:"velocity,omitempty"`
+	DuplicateHours     *int                    `json:"duplicate_hours,omitempty"`
+}
+
+// AutoModerationRule là luật kiểm duyệt tự động do quản trị viên cấu hình.
+// Luật DryRun chỉ ghi lại hành động lẽ ra đã thực hiện, không thay đổi trạng thái nội dung.
+type AutoModerationRule struct {
+	ID          uint                 `gorm:"primaryKey" json:"id"`
+	Name        string               `gorm:"type:varchar(100);not null" json:"name"`
+	Description string               `gorm:"type:varchar(500)" json:"description,omitempty"`
+	Conditions  json.RawMessage      `gorm:"type:json" json:"conditions"`
+	Action      AutoModerationAction `gorm:"type:varchar(16);not null" json:"action"`
+	Reason      string               `gorm:"type:varchar(500)" json:"reason,omitempty"`
+	Enabled     bool                 `gorm:"index" json:"enabled"`
+	DryRun      bool                 `json:"dry_run"`
+	CreatedByID uint                 `gorm:"not null" json:"created_by_id"`
+	UpdatedByID uint                 `json:"updated_by_id"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// AutoModerationLog ghi lại mỗi lần một luật khớp với nội dung, kể cả luật đang chạy thử.
+// Applied cho biết hành động của luật này có quyết định trạng thái cuối cùng hay không.
+// Tên luật được lưu kèm để nhật ký vẫn đọc được sau khi luật bị xoá.
+type AutoModerationLog struct {
+	ID          uint                 `gorm:"primaryKey" json:"id"`
+	RuleID      uint                 `gorm:"not null;index" json:"rule_id"`
+	RuleName    string               `gorm:"type:varchar(100)" json:"rule_name"`
+	ContentType string               `gorm:"type:varchar(16);not null;index:idx_auto_moderation_logs_content" json:"content_type"`
+	ContentID   uint                 `gorm:"not null;index:idx_auto_moderation_logs_content" json:"content_id"`
+	UserID      uint                 `gorm:"not null;index" json:"user_id"`
+	Event       string               `gorm:"type:varchar(16);not null" json:"event"`
+	Action      AutoModerationAction `gorm:"type:varchar(16);not null" json:"action"`
+	DryRun      bool                 `gorm:"index" json:"dry_run"`
+	Applied     bool                 `json:"applied"`
+	Details     string               `gorm:"type:varchar(1000)" json:"details,omitempty"`
+	CreatedAt   time.Time            `gorm:"index" json:"created_at"`
+}
+
+// ContentFingerprint lưu hash nội dung đã chuẩn hoá để phát hiện nội dung đăng trùng
+type ContentFingerprint struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ContentType string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_content_fingerprints_content" json:"content_type"`
+	ContentID   uint      `gorm:"not null;uniqueIndex:idx_content_fingerprints_content" json:"content_id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	Hash        string    `gorm:"type:char(64);not null;index" json:"hash"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `gorm:"index" json:"updated_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+type BookmarkTargetType string
+
+const (
+	BookmarkQuestion BookmarkTargetType = "question"
+	BookmarkAnswer   BookmarkTargetType = "answer"
+	BookmarkPost     BookmarkTargetType = "post"
+)
+
+func IsValidBookmarkTargetType(t BookmarkTargetType) bool {
+	switch t {
+	case BookmarkQuestion, BookmarkAnswer, BookmarkPost:
+		return true
+	}
+	return false
+}
+
+// BookmarkCollection là bộ sưu tập do người dùng đặt tên; IsPublic = true thì người khác có thể xem qua ID
+type BookmarkCollection struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	Description string    `gorm:"type:varchar(500)" json:"description"`
+	IsPublic    bool      `gorm:"default:false" json:"is_public"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// Bookmark lưu một câu hỏi, câu trả lời hoặc bài viết để đọc sau; mỗi người dùng lưu mỗi nội dung một lần.
+// CollectionID nil nghĩa là nằm trong mục "Đã lưu" mặc định. Position là thứ tự do người dùng sắp xếp trong bộ sưu tập.
+// Bookmark không bị xoá theo nội dung gốc: nội dung đã xoá hoặc bị từ chối được trả về dưới dạng placeholder.
+type Bookmark struct {
+	ID           uint               `gorm:"primaryKey" json:"id"`
+	UserID       uint               `gorm:"not null;uniqueIndex:idx_bookmarks_user_target" json:"user_id"`
+	TargetType   BookmarkTargetType `gorm:"type:varchar(16);not null;uniqueIndex:idx_bookmarks_user_target" json:"target_type"`
+	TargetID     uint               `gorm:"not null;uniqueIndex:idx_bookmarks_user_target" json:"target_id"`
+	CollectionID *uint              `gorm:"index" json:"collection_id,omitempty"`
+	Note         string             `gorm:"type:text" json:"note"`
+	Position     int                `gorm:"not null;default:0" json:"position"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
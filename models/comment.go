@@ -8,27 +8,54 @@ import (
 )
 
 type Comment struct {
-	ID        uint            `gorm:"primaryKey" json:"id"`
-	Content   string          `gorm:"type:text" json:"content"`
-	UserID    uint            `gorm:"not null;index" json:"user_id"`
-	PostID    *uint           `json:"post_id,omitempty" gorm:"index"`
-	AnswerID  *uint           `json:"answer_id,omitempty" gorm:"index"`
-	ParentID  *uint           `json:"parent_id,omitempty" gorm:"index"`
-	Status    string          `gorm:"type:ENUM('approved','pending','spam');default:'pending'" json:"status"`
-	Metadata  json.RawMessage `gorm:"type:json" json:"metadata,omitempty"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
-	DeletedAt gorm.DeletedAt  `gorm:"index" json:"-"`
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	Content       string          `gorm:"type:text" json:"content"`
+	PlainContent  string          `gorm:"type:text;index:idx_comments_fulltext,class:FULLTEXT" json:"-"`
+	ContentFormat ContentFormat   `gorm:"type:varchar(16);default:'html'" json:"content_format"`
+	ContentSource string          `gorm:"type:longtext" json:"content_source,omitempty"` // Nguồn markdown gốc, rỗng với nội dung html
+	UserID        uint            `gorm:"not null;index" json:"user_id"`
+	PostID        *uint           `json:"post_id,omitempty" gorm:"index"`
+	AnswerID      *uint           `json:"answer_id,omitempty" gorm:"index"`
+	QuestionID    *uint           `json:"question_id,omitempty" gorm:"index"` // Bình luận làm rõ ngay dưới câu hỏi
+	ParentID      *uint           `json:"parent_id,omitempty" gorm:"index"`
+	Path          string          `gorm:"type:varchar(700);not null;default:'';index" json:"-"` // ID của tổ tiên và chính nó, xem CommentPathSegment
+	Depth         int             `gorm:"not null;default:0" json:"depth"`                      // 0 với bình luận gốc
+	Status        string          `gorm:"type:ENUM('approved','pending','spam');default:'pending'" json:"status"`
+	Metadata      json.RawMessage `gorm:"type:json" json:"metadata,omitempty"`
+	Anonymous     bool            `gorm:"default:false;index" json:"anonymous"`
+	Pseudonym     string          `gorm:"type:varchar(64)" json:"pseudonym,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt  `gorm:"index" json:"-"`
 
 	// Relationships
 	User     User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Post     *Post     `json:"post,omitempty" gorm:"foreignKey:PostID;references:ID"`
 	Answer   *Answer   `json:"answer,omitempty" gorm:"foreignKey:AnswerID;references:ID"`
+	Question *Question `json:"question,omitempty" gorm:"foreignKey:QuestionID;references:ID"`
 	Votes    []Vote    `json:"votes,omitempty" gorm:"polymorphic:Votable;"`
 	Parent   *Comment  `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
 	Children []Comment `json:"children,omitempty" gorm:"foreignKey:ParentID"`
 }
 
+// Độ sâu tối đa của một nhánh trả lời, giới hạn bởi độ dài cột path
+const MaxCommentDepth = 60
+
+// CommentPathSegment là đoạn path của một bình luận: ID đệm 0 đủ 10 chữ số và dấu "/".
+// Độ dài cố định nên sắp xếp theo path cũng là thứ tự duyệt cây, và mọi hậu duệ của một bình luận
+// đều có path bắt đầu bằng path của nó.
+func CommentPathSegment(id uint) string {
+	return fmt.Sprintf("%010d/", id)
+}
+
+// CommentNode là một nút của cây bình luận: Replies chỉ chứa các trả lời đã tải (giới hạn theo độ sâu
+// và số con), ReplyCount là tổng số trả lời trực tiếp để giao diện biết còn nhánh chưa tải.
+type CommentNode struct {
+	Comment    Comment
+	Replies    []*CommentNode
+	ReplyCount int64
+}
+
 func (c *Comment) BeforeCreate(tx *gorm.DB) (err error) {
 	if c.ParentID != nil && *c.ParentID == c.ID {
 		return fmt.Errorf("comment cannot reference itself as parent")
@@ -0,0 +1,15 @@
+package models
+
+// ContentFormat là định dạng người dùng soạn nội dung. Với markdown, nguồn được lưu nguyên văn
+// ở ContentSource, còn trường nội dung chính giữ HTML đã render và lọc sẵn (render một lần khi ghi,
+// các lần đọc và cache Redis dùng lại bản render này).
+type ContentFormat string
+
+const (
+	ContentFormatHTML     ContentFormat = "html"
+	ContentFormatMarkdown ContentFormat = "markdown"
+)
+
+func IsValidContentFormat(format ContentFormat) bool {
+	return format == ContentFormatHTML || format == ContentFormatMarkdown
+}
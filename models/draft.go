@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DraftTargetType string
+
+const (
+	DraftNewQuestion  DraftTargetType = "new_question"
+	DraftNewPost      DraftTargetType = "new_post"
+	DraftAnswer       DraftTargetType = "answer"        // TargetID là ID câu hỏi được trả lời
+	DraftEditQuestion DraftTargetType = "edit_question" // TargetID là ID câu hỏi đang sửa
+	DraftEditAnswer   DraftTargetType = "edit_answer"
+	DraftEditPost     DraftTargetType = "edit_post"
+)
+
+// Draft là bản nháp tự động lưu, mỗi người dùng chỉ có một bản nháp cho mỗi đích (TargetType, TargetID)
+type Draft struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	UserID     uint            `gorm:"not null;uniqueIndex:idx_drafts_user_target" json:"user_id"`
+	TargetType DraftTargetType `gorm:"type:varchar(32);not null;uniqueIndex:idx_drafts_user_target" json:"target_type"`
+	TargetID   uint            `gorm:"not null;default:0;uniqueIndex:idx_drafts_user_target" json:"target_id"`
+	Title      string          `gorm:"type:text" json:"title"`
+	Content    string          `gorm:"type:longtext" json:"content"`
+	// Định dạng nội dung khi đăng; rỗng nghĩa là html
+	ContentFormat ContentFormat   `gorm:"type:varchar(16)" json:"content_format,omitempty"`
+	TopicID       uint            `json:"topic_id"`
+	TagIDs        json.RawMessage `gorm:"type:json" json:"tag_ids,omitempty"`
+	Version       uint            `gorm:"not null;default:1" json:"version"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `gorm:"index" json:"updated_at"`
+}
+
+func IsValidDraftTargetType(t DraftTargetType) bool {
+	switch t {
+	case DraftNewQuestion, DraftNewPost, DraftAnswer, DraftEditQuestion, DraftEditAnswer, DraftEditPost:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// FeedToken là khoá riêng của người dùng để đọc feed RSS/Atom mà không cần JWT
+// (trình đọc feed không gửi được header Authorization). Đổi token sẽ vô hiệu hoá các URL feed cũ.
+type FeedToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	Token      string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	MentionTargetQuestion = "question"
+	MentionTargetAnswer   = "answer"
+	MentionTargetPost     = "post"
+	MentionTargetComment  = "comment"
+)
+
+// Mention ghi nhận một người dùng được nhắc (@username) trong nội dung.
+// Khi nội dung bị sửa bỏ lời nhắc, bản ghi bị xoá mềm; nhắc lại sẽ khôi phục bản ghi
+// thay vì tạo mới nên người được nhắc không bị thông báo lần nữa.
+type Mention struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	TargetType     string         `gorm:"type:varchar(16);not null;uniqueIndex:idx_mentions_target_user" json:"target_type"`
+	TargetID       uint           `gorm:"not null;uniqueIndex:idx_mentions_target_user" json:"target_id"`
+	UserID         uint           `gorm:"not null;index;uniqueIndex:idx_mentions_target_user" json:"user_id"`
+	MentionerID    uint           `gorm:"not null" json:"mentioner_id"`
+	MentionerAlias string         `gorm:"type:varchar(64)" json:"mentioner_alias,omitempty"` // Bí danh khi nội dung ẩn danh, thay cho Mentioner thật
+	CreatedAt      time.Time      `json:"created_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+
+	User      *User `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+	Mentioner *User `gorm:"foreignKey:MentionerID;references:ID" json:"mentioner,omitempty"`
+}
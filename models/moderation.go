@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+const (
+	ModerationQuestion = "question"
+	ModerationAnswer   = "answer"
+	ModerationPost     = "post"
+	ModerationComment  = "comment"
+)
+
+type ModerationAction string
+
+const (
+	ModerationApprove ModerationAction = "approve"
+	ModerationReject  ModerationAction = "reject"
+)
+
+func IsValidModerationContentType(contentType string) bool {
+	switch contentType {
+	case ModerationQuestion, ModerationAnswer, ModerationPost, ModerationComment:
+		return true
+	}
+	return false
+}
+
+// ModerationItem là một mục trong hàng đợi kiểm duyệt, dựng từ các bảng nội dung chứ không lưu riêng.
+// QueueRef ("<loại>-<id>") là khoá duy nhất giữa các loại, dùng để phân trang ổn định.
+type ModerationItem struct {
+	ContentType    string     `json:"content_type"`
+	ContentID      uint       `json:"content_id"`
+	QueueRef       string     `json:"-"`
+	UserID         uint       `json:"user_id"`
+	Title          string     `json:"title"`
+	Content        string     `json:"content"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ClaimedBy      *uint      `json:"claimed_by,omitempty"`
+	ClaimExpiresAt *time.Time `json:"claim_expires_at,omitempty"`
+
+	User *User `gorm:"-" json:"user,omitempty"`
+}
+
+// ModerationClaim giữ một mục cho một người kiểm duyệt đến ExpiresAt để hai người không duyệt trùng.
+// Mỗi mục chỉ có một claim; claim hết hạn được coi như không tồn tại và có thể bị người khác nhận.
+type ModerationClaim struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ContentType string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_moderation_claims_content" json:"content_type"`
+	ContentID   uint      `gorm:"not null;uniqueIndex:idx_moderation_claims_content" json:"content_id"`
+	ModeratorID uint      `gorm:"not null;index" json:"moderator_id"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ModerationLog ghi lại mỗi quyết định duyệt/từ chối; ItemCreatedAt giúp tính thời gian chờ duyệt
+type ModerationLog struct {
+	ID            uint             `gorm:"primaryKey" json:"id"`
+	ModeratorID   uint             `gorm:"not null;index" json:"moderator_id"`
+	ContentType   string           `gorm:"type:varchar(16);not null;index:idx_moderation_logs_content" json:"content_type"`
+	ContentID     uint             `gorm:"not null;index:idx_moderation_logs_content" json:"content_id"`
+	Action        ModerationAction `gorm:"type:varchar(16);not null" json:"action"`
+	FromStatus    string           `gorm:"type:varchar(16)" json:"from_status"`
+	ToStatus      string           `gorm:"type:varchar(16)" json:"to_status"`
+	Reason        string           `gorm:"type:varchar(500)" json:"reason,omitempty"`
+	ItemCreatedAt time.Time        `json:"item_created_at"`
+	CreatedAt     time.Time        `gorm:"index" json:"created_at"`
+
+	Moderator User `json:"moderator,omitempty" gorm:"foreignKey:ModeratorID"`
+}
+
+// ModerationStat là số liệu xử lý của một người kiểm duyệt trong khoảng thời gian thống kê
+type ModerationStat struct {
+	ModeratorID    uint      `json:"moderator_id"`
+	Approved       int64     `json:"approved"`
+	Rejected       int64     `json:"rejected"`
+	Total          int64     `json:"total"`
+	AvgWaitSeconds float64   `json:"avg_wait_seconds"`
+	FirstActionAt  time.Time `json:"first_action_at"`
+	LastActionAt   time.Time `json:"last_action_at"`
+
+	Moderator *User `gorm:"-" json:"moderator,omitempty"`
+}
@@ -4,9 +4,37 @@ import (
 	"time"
 )
 
+type PassKind string
+
+const (
+	PassKindPass         PassKind = "pass"          // bỏ qua
+	PassKindSnooze       PassKind = "snooze"        // ẩn đến SnoozeUntil
+	PassKindNotExpertise PassKind = "not_expertise" // không thuộc chuyên môn, đồng thời hạ các câu hỏi cùng chủ đề trong feed
+)
+
+func IsValidPassKind(kind PassKind) bool {
+	switch kind {
+	case PassKindPass, PassKindSnooze, PassKindNotExpertise:
+		return true
+	}
+	return false
+}
+
+const (
+	ResurfaceUnanswered = "unanswered" // câu hỏi vẫn chưa có câu trả lời sau thời gian dài
+	ResurfaceBounty     = "bounty"     // câu hỏi được treo thưởng
+)
+
+// PassedQuestion là một lần người dùng ẩn câu hỏi khỏi feed của mình.
+// Câu hỏi bị ẩn khi ResurfacedAt rỗng và (SnoozeUntil rỗng hoặc chưa tới).
 type PassedQuestion struct {
-	ID         uint `gorm:"primaryKey"`
-	UserID     uint `gorm:"index"`
-	QuestionID uint `gorm:"index"`
-	CreatedAt  time.Time
+	ID              uint     `gorm:"primaryKey"`
+	UserID          uint     `gorm:"index;index:idx_passed_questions_user_question,priority:1"`
+	QuestionID      uint     `gorm:"index;index:idx_passed_questions_user_question,priority:2"`
+	Kind            PassKind `gorm:"type:varchar(20);not null;default:'pass'"`
+	SnoozeUntil     *time.Time
+	TopicID         uint `gorm:"index"` // chủ đề của câu hỏi lúc bỏ qua, dùng cho not_expertise
+	ResurfacedAt    *time.Time
+	ResurfaceReason string `gorm:"type:varchar(20)"`
+	CreatedAt       time.Time
 }
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Poll là bình chọn gắn với một bài viết (mỗi bài viết tối đa một bình chọn).
+// Phiếu bầu lưu ở bảng riêng nên không ảnh hưởng tới số lượng Reaction của bài viết.
+type Poll struct {
+	ID                     uint       `gorm:"primaryKey" json:"id"`
+	PostID                 uint       `gorm:"not null;uniqueIndex" json:"post_id"`
+	Question               string     `gorm:"type:varchar(500);not null" json:"question"`
+	MultipleChoice         bool       `gorm:"default:false" json:"multiple_choice"`
+	MaxChoices             int        `gorm:"default:0" json:"max_choices"` // 0 nghĩa là không giới hạn khi chọn nhiều
+	Anonymous              bool       `gorm:"default:false" json:"anonymous"`
+	HideResultsUntilClosed bool       `gorm:"default:false" json:"hide_results_until_closed"`
+	ClosesAt               *time.Time `gorm:"index" json:"closes_at,omitempty"`
+	ClosedAt               *time.Time `json:"closed_at,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+	UpdatedAt              time.Time  `json:"updated_at"`
+
+	Options []PollOption `json:"options,omitempty" gorm:"foreignKey:PollID"`
+}
+
+// IsClosed cho biết bình chọn đã bị đóng thủ công hoặc đã quá thời điểm đóng
+func (p *Poll) IsClosed(now time.Time) bool {
+	if p.ClosedAt != nil {
+		return true
+	}
+	return p.ClosesAt != nil && !p.ClosesAt.After(now)
+}
+
+type PollOption struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	PollID   uint   `gorm:"not null;index" json:"poll_id"`
+	Text     string `gorm:"type:varchar(255);not null" json:"text"`
+	Position int    `gorm:"default:0" json:"position"`
+}
+
+// PollBallot là lá phiếu của một người dùng; unique (poll_id, user_id) đảm bảo mỗi người chỉ bầu một lần
+type PollBallot struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PollID    uint      `gorm:"not null;uniqueIndex:idx_poll_ballots_poll_user" json:"poll_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_poll_ballots_poll_user" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	User    User               `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Choices []PollBallotChoice `json:"choices,omitempty" gorm:"foreignKey:BallotID"`
+}
+
+type PollBallotChoice struct {
+	ID       uint `gorm:"primaryKey" json:"id"`
+	BallotID uint `gorm:"not null;uniqueIndex:idx_poll_choices_ballot_option" json:"ballot_id"`
+	OptionID uint `gorm:"not null;uniqueIndex:idx_poll_choices_ballot_option;index" json:"option_id"`
+	PollID   uint `gorm:"not null;index" json:"poll_id"`
+}
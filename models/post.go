@@ -16,16 +16,20 @@ const (
 )
 
 type Post struct {
-	ID           uint            `gorm:"primaryKey" json:"id"`
-	Title        string          `gorm:"type:varchar(255);index" json:"title,omitempty"`
-	Content      string          `gorm:"type:text" json:"content"`
-	PlainContent string          `gorm:"type:text"`
-	UserID       uint            `gorm:"not null;index" json:"user_id"`
-	Status       PostStatus      `gorm:"type:ENUM('approved','pending','rejected');default:'pending'" json:"status"`
-	Metadata     json.RawMessage `gorm:"type:json" json:"metadata,omitempty"`
-	CreatedAt    time.Time       `json:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt  `gorm:"index" json:"-"`
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	Title         string          `gorm:"type:varchar(255);index;index:idx_posts_fulltext,class:FULLTEXT" json:"title,omitempty"`
+	Slug          string          `gorm:"type:varchar(100);index" json:"slug"`
+	Content       string          `gorm:"type:text" json:"content"`
+	PlainContent  string          `gorm:"type:text;index:idx_posts_fulltext,class:FULLTEXT"`
+	ContentFormat ContentFormat   `gorm:"type:varchar(16);default:'html'" json:"content_format"`
+	ContentSource string          `gorm:"type:longtext" json:"content_source,omitempty"` // Nguồn markdown gốc, rỗng với nội dung html
+	UserID        uint            `gorm:"not null;index" json:"user_id"`
+	Status        PostStatus      `gorm:"type:ENUM('approved','pending','rejected');default:'pending'" json:"status"`
+	Metadata      json.RawMessage `gorm:"type:json" json:"metadata,omitempty"`
+	PublishAt     *time.Time      `gorm:"index" json:"publish_at,omitempty"` // Hẹn giờ đăng, nil khi đã hiển thị
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt  `gorm:"index" json:"-"`
 
 	User          User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Notifications []Notification `json:"notifications,omitempty" gorm:"polymorphic:Entity;"`
@@ -22,16 +22,29 @@ const (
 
 type Question struct {
 	ID                uint              `gorm:"primaryKey" json:"id"`
-	Title             string            `gorm:"not null;index" json:"title"`
+	Title             string            `gorm:"not null;index;index:idx_questions_fulltext,class:FULLTEXT" json:"title"`
+	Slug              string            `gorm:"type:varchar(100);index" json:"slug"` // Slug hiện tại, các slug cũ nằm trong SlugHistory
 	Description       string            `gorm:"type:longtext" json:"description,omitempty"`
+	PlainContent      string            `gorm:"type:text;index:idx_questions_fulltext,class:FULLTEXT" json:"-"`
+	ContentFormat     ContentFormat     `gorm:"type:varchar(16);default:'html'" json:"content_format"`
+	ContentSource     string            `gorm:"type:longtext" json:"content_source,omitempty"` // Nguồn markdown gốc, rỗng với nội dung html
 	UserID            uint              `gorm:"not null;index" json:"user_id"`
 	TopicID           uint              `gorm:"index" json:"topic_id"` // Liên kết với một topic duy nhất
 	ReportCount       int               `gorm:"default:0" json:"report_count"`
 	Status            QuestionStatus    `gorm:"type:ENUM('approved','pending','rejected');default:'pending'" json:"status"`
 	InteractionStatus InteractionStatus `gorm:"type:ENUM('opened','solved','closed');default:'opened'" json:"interaction_status"`
+	PublishAt         *time.Time        `gorm:"index" json:"publish_at,omitempty"` // Hẹn giờ đăng, nil khi đã hiển thị
+	Anonymous         bool              `gorm:"default:false;index" json:"anonymous"`
+	Pseudonym         string            `gorm:"type:varchar(64)" json:"pseudonym,omitempty"` // Bí danh hiển thị khi ẩn danh, ổn định trong cả luồng câu hỏi
+	CloseReason       CloseReason       `gorm:"type:varchar(32)" json:"close_reason,omitempty"`
+	DuplicateOfID     *uint             `gorm:"index" json:"duplicate_of_id,omitempty"`
+	ClosedAt          *time.Time        `json:"closed_at,omitempty"`
+	LockedAt          *time.Time        `json:"locked_at,omitempty"`
+	LockedUntil       *time.Time        `gorm:"index" json:"locked_until,omitempty"` // nil khi khoá vô thời hạn
 	CreatedAt         time.Time         `json:"created_at"`
 	UpdatedAt         time.Time         `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt    `gorm:"index" json:"-"`
+	FeedRankAt        *time.Time        `gorm:"-" json:"feed_rank_at,omitempty"` // Thời điểm dùng để xếp hạng trong feed cá nhân, khác CreatedAt khi câu hỏi bị hạ hạng
 
 	User          User             `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Topic         Topic            `json:"topic,omitempty" gorm:"foreignKey:TopicID"`
@@ -39,3 +52,15 @@ type Question struct {
 	Follows       []QuestionFollow `json:"follows,omitempty" gorm:"foreignKey:QuestionID"`
 	Notifications []Notification   `json:"notifications,omitempty" gorm:"polymorphic:Entity;"`
 }
+
+func (q *Question) IsClosed() bool {
+	return q.InteractionStatus == InteractionClosed
+}
+
+// IsLocked cho biết câu hỏi còn đang bị khoá tại thời điểm now (khoá có thời hạn sẽ tự hết hiệu lực)
+func (q *Question) IsLocked(now time.Time) bool {
+	if q.LockedAt == nil {
+		return false
+	}
+	return q.LockedUntil == nil || q.LockedUntil.After(now)
+}
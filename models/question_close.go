@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+type CloseReason string
+
+const (
+	CloseReasonOffTopic     CloseReason = "off_topic"
+	CloseReasonDuplicate    CloseReason = "duplicate"
+	CloseReasonNeedsDetail  CloseReason = "needs_detail"
+	CloseReasonOpinionBased CloseReason = "opinion_based"
+)
+
+func IsValidCloseReason(reason CloseReason) bool {
+	switch reason {
+	case CloseReasonOffTopic, CloseReasonDuplicate, CloseReasonNeedsDetail, CloseReasonOpinionBased:
+		return true
+	}
+	return false
+}
+
+type CloseVoteType string
+
+const (
+	CloseVoteClose  CloseVoteType = "close"
+	CloseVoteReopen CloseVoteType = "reopen"
+)
+
+// QuestionCloseVote là phiếu bầu đóng/mở lại câu hỏi của cộng đồng; bị xoá khi trạng thái câu hỏi thay đổi
+type QuestionCloseVote struct {
+	ID            uint          `gorm:"primaryKey" json:"id"`
+	QuestionID    uint          `gorm:"not null;uniqueIndex:idx_close_votes_question_user_type" json:"question_id"`
+	UserID        uint          `gorm:"not null;uniqueIndex:idx_close_votes_question_user_type" json:"user_id"`
+	VoteType      CloseVoteType `gorm:"type:varchar(16);not null;uniqueIndex:idx_close_votes_question_user_type" json:"vote_type"`
+	Reason        CloseReason   `gorm:"type:varchar(32)" json:"reason,omitempty"`
+	DuplicateOfID *uint         `json:"duplicate_of_id,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type QuestionEventAction string
+
+const (
+	QuestionEventClosed   QuestionEventAction = "closed"
+	QuestionEventReopened QuestionEventAction = "reopened"
+	QuestionEventLocked   QuestionEventAction = "locked"
+	QuestionEventUnlocked QuestionEventAction = "unlocked"
+)
+
+// QuestionEvent là một mục trong dòng thời gian đóng/mở/khoá của câu hỏi.
+// ActorID nil nghĩa là hệ thống (ví dụ khoá hết hạn).
+type QuestionEvent struct {
+	ID            uint                `gorm:"primaryKey" json:"id"`
+	QuestionID    uint                `gorm:"not null;index" json:"question_id"`
+	ActorID       *uint               `gorm:"index" json:"actor_id,omitempty"`
+	Action        QuestionEventAction `gorm:"type:varchar(16);not null" json:"action"`
+	Reason        CloseReason         `gorm:"type:varchar(32)" json:"reason,omitempty"`
+	DuplicateOfID *uint               `json:"duplicate_of_id,omitempty"`
+	Note          string              `gorm:"type:text" json:"note,omitempty"`
+	ByCommunity   bool                `gorm:"default:false" json:"by_community"`
+	VoteCount     int                 `gorm:"default:0" json:"vote_count"`
+	LockedUntil   *time.Time          `json:"locked_until,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+
+	Actor *User `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+}
@@ -6,20 +6,66 @@ import (
 	"time"
 )
 
+// ReactionTargetType là loại nội dung nhận tương tác; cột khoá ngoại tương ứng là <loại>_id
+type ReactionTargetType string
+
+const (
+	ReactionTargetPost     ReactionTargetType = "post"
+	ReactionTargetComment  ReactionTargetType = "comment"
+	ReactionTargetAnswer   ReactionTargetType = "answer"
+	ReactionTargetQuestion ReactionTargetType = "question"
+)
+
+func (t ReactionTargetType) Column() string {
+	return string(t) + "_id"
+}
+
+// ReactionLike là loại tương tác mặc định, cũng là loại của mọi tương tác tạo trước khi có phân loại
+const ReactionLike = "like"
+
+// ReactionCounts là số tương tác theo từng loại của một nội dung
+type ReactionCounts map[string]int64
+
+func (c ReactionCounts) Total() int64 {
+	var total int64
+	for _, count := range c {
+		total += count
+	}
+	return total
+}
+
 type Reaction struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	UserID    uint           `gorm:"not null;index" json:"user_id"`
-	PostID    *uint          `json:"post_id,omitempty" gorm:"index"`
-	CommentID *uint          `json:"comment_id,omitempty" gorm:"index"`
-	AnswerID  *uint          `json:"answer_id,omitempty" gorm:"index"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-
-	User    User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	Post    *Post    `json:"post,omitempty" gorm:"foreignKey:PostID;references:ID"`
-	Comment *Comment `json:"comment,omitempty" gorm:"foreignKey:CommentID;references:ID"`
-	Answer  *Answer  `json:"answer,omitempty" gorm:"foreignKey:AnswerID;references:ID"`
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	UserID     uint           `gorm:"not null;index" json:"user_id"`
+	Type       string         `gorm:"type:varchar(32);not null;default:'like';index" json:"type"`
+	PostID     *uint          `json:"post_id,omitempty" gorm:"index"`
+	CommentID  *uint          `json:"comment_id,omitempty" gorm:"index"`
+	AnswerID   *uint          `json:"answer_id,omitempty" gorm:"index"`
+	QuestionID *uint          `json:"question_id,omitempty" gorm:"index"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+
+	User     User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Post     *Post     `json:"post,omitempty" gorm:"foreignKey:PostID;references:ID"`
+	Comment  *Comment  `json:"comment,omitempty" gorm:"foreignKey:CommentID;references:ID"`
+	Answer   *Answer   `json:"answer,omitempty" gorm:"foreignKey:AnswerID;references:ID"`
+	Question *Question `json:"question,omitempty" gorm:"foreignKey:QuestionID;references:ID"`
+}
+
+// Target trả về loại và ID của nội dung mà tương tác gắn vào
+func (r *Reaction) Target() (ReactionTargetType, uint) {
+	switch {
+	case r.PostID != nil:
+		return ReactionTargetPost, *r.PostID
+	case r.CommentID != nil:
+		return ReactionTargetComment, *r.CommentID
+	case r.AnswerID != nil:
+		return ReactionTargetAnswer, *r.AnswerID
+	case r.QuestionID != nil:
+		return ReactionTargetQuestion, *r.QuestionID
+	}
+	return "", 0
 }
 
 func (r *Reaction) BeforeCreate(tx *gorm.DB) (err error) {
@@ -33,8 +79,14 @@ func (r *Reaction) BeforeCreate(tx *gorm.DB) (err error) {
 	if r.AnswerID != nil {
 		count++
 	}
+	if r.QuestionID != nil {
+		count++
+	}
 	if count != 1 {
-		return fmt.Errorf("exactly one of post_id, comment_id, or answer_id must be provided")
+		return fmt.Errorf("exactly one of post_id, comment_id, answer_id, or question_id must be provided")
+	}
+	if r.Type == "" {
+		r.Type = ReactionLike
 	}
 	return nil
 }
@@ -14,11 +14,18 @@ const (
 	DismissedStatus ReportStatus = "dismissed"
 )
 
+// Nguồn của báo cáo: người dùng gửi hoặc hệ thống tự phát hiện (khi đó không có người báo cáo)
+const (
+	ReportSourceUser      = "user"
+	ReportSourceVoteFraud = "vote_fraud"
+)
+
 type Report struct {
 	ID             string         `gorm:"primaryKey;type:varchar(255)" json:"id"`
 	Reason         string         `gorm:"type:varchar(255);not null" json:"reason"`
 	Details        string         `gorm:"type:text" json:"details,omitempty"`
-	ReporterID     uint           `gorm:"not null;index" json:"reporter_id"`
+	ReporterID     *uint          `gorm:"index" json:"reporter_id,omitempty"`
+	Source         string         `gorm:"type:varchar(32);not null;default:'user';index" json:"source"`
 	ContentType    string         `gorm:"type:ENUM('post', 'comment', 'user', 'question', 'answer');not null" json:"content_type"`
 	ContentID      string         `gorm:"type:varchar(255);not null" json:"content_id"`
 	ContentPreview string         `gorm:"type:text;not null" json:"content_preview"`
@@ -28,6 +35,6 @@ type Report struct {
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 
-	Reporter   User  `gorm:"foreignKey:ReporterID" json:"reporter,omitempty"`
+	Reporter   *User `gorm:"foreignKey:ReporterID" json:"reporter,omitempty"`
 	ResolvedBy *User `gorm:"foreignKey:ResolvedByID" json:"resolved_by,omitempty"`
 }
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	SitemapQuestions = "questions"
+	SitemapPosts     = "posts"
+	SitemapTopics    = "topics"
+	SitemapTags      = "tags"
+)
+
+// SitemapSegment lưu sẵn một tệp sitemap con (theo khối ID) để phục vụ mà không phải truy vấn lại.
+// Fingerprint (số URL + lần sửa mới nhất) dùng để biết khối nào cần sinh lại khi chạy định kỳ.
+type SitemapSegment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(64);not null;uniqueIndex" json:"name"`
+	Kind        string    `gorm:"type:varchar(16);not null" json:"kind"`
+	Number      int       `gorm:"not null" json:"number"`
+	URLCount    int       `gorm:"not null" json:"url_count"`
+	LastMod     time.Time `json:"last_mod"`
+	Fingerprint string    `gorm:"type:varchar(64)" json:"fingerprint"`
+	Content     string    `gorm:"type:longtext" json:"-"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
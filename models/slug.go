@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	SlugTargetQuestion = "question"
+	SlugTargetPost     = "post"
+)
+
+// SlugHistory ghi lại mọi slug từng được gán cho câu hỏi/bài viết (kể cả slug hiện tại),
+// để URL cũ vẫn tìm được nội dung sau khi đổi tiêu đề. Unique (target_type, slug) nên một slug
+// chỉ thuộc về một nội dung và không bị cấp lại cho nội dung khác.
+type SlugHistory struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TargetType string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_slug_histories_type_slug" json:"target_type"`
+	Slug       string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_slug_histories_type_slug" json:"slug"`
+	TargetID   uint      `gorm:"not null;index" json:"target_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
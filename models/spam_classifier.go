@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+const (
+	SpamLabelSpam = "spam"
+	SpamLabelHam  = "ham"
+)
+
+// SpamTrainingSample là một quyết định của người kiểm duyệt dùng để huấn luyện bộ phân loại spam.
+// Nội dung và đặc trưng được chụp lại lúc quyết định để mẫu vẫn dùng được khi nội dung bị sửa hoặc xoá;
+// quyết định sau ghi đè nhãn của quyết định trước trên cùng nội dung.
+type SpamTrainingSample struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ContentType string    `gorm:"type:varchar(16);not null;uniqueIndex:idx_spam_training_samples_content" json:"content_type"`
+	ContentID   uint      `gorm:"not null;uniqueIndex:idx_spam_training_samples_content" json:"content_id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	Label       string    `gorm:"type:varchar(8);not null;index" json:"label"`
+	Title       string    `gorm:"type:text" json:"title"`
+	Body        string    `gorm:"type:text" json:"body"`
+	Features    string    `gorm:"type:varchar(255)" json:"features"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SpamModelVersion là một lần huấn luyện bộ phân loại spam cùng kết quả đánh giá trên tập kiểm tra.
+// Weights chứa số đếm của mô hình để có thể quay lại phiên bản cũ; chỉ một phiên bản được Active.
+type SpamModelVersion struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	SampleCount    int       `json:"sample_count"`
+	SpamCount      int       `json:"spam_count"`
+	HamCount       int       `json:"ham_count"`
+	VocabularySize int       `json:"vocabulary_size"`
+	Threshold      float64   `json:"threshold"`
+	TestSize       int       `json:"test_size"`
+	TruePositives  int       `json:"true_positives"`
+	FalsePositives int       `json:"false_positives"`
+	TrueNegatives  int       `json:"true_negatives"`
+	FalseNegatives int       `json:"false_negatives"`
+	Precision      float64   `json:"precision"`
+	Recall         float64   `json:"recall"`
+	F1             float64   `json:"f1"`
+	Accuracy       float64   `json:"accuracy"`
+	Weights        []byte    `gorm:"type:longblob" json:"-"`
+	Active         bool      `gorm:"index" json:"active"`
+	TrainedByID    *uint     `json:"trained_by_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
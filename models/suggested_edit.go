@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SuggestedEditStatus string
+
+const (
+	SuggestedEditPending  SuggestedEditStatus = "pending"
+	SuggestedEditApproved SuggestedEditStatus = "approved"
+	SuggestedEditRejected SuggestedEditStatus = "rejected"
+)
+
+const (
+	SuggestedEditTargetQuestion = "question"
+	SuggestedEditTargetAnswer   = "answer"
+)
+
+// SuggestedEdit là bản sửa đề xuất cho câu hỏi/câu trả lời của người khác, chờ người duyệt.
+// BaseTitle/BaseContent lưu nội dung tại thời điểm đề xuất để phát hiện xung đột với các lần sửa xen giữa.
+type SuggestedEdit struct {
+	ID          uint                `gorm:"primaryKey" json:"id"`
+	TargetType  string              `gorm:"type:varchar(16);not null;index:idx_suggested_edits_target" json:"target_type"`
+	TargetID    uint                `gorm:"not null;index:idx_suggested_edits_target" json:"target_id"`
+	EditorID    uint                `gorm:"not null;index" json:"editor_id"`
+	Title       string              `gorm:"type:text" json:"title"`
+	Content     string              `gorm:"type:longtext" json:"content"`
+	TopicID     uint                `json:"topic_id,omitempty"`
+	TagIDs      json.RawMessage     `gorm:"type:json" json:"tag_ids,omitempty"`
+	Summary     string              `gorm:"type:varchar(500)" json:"summary"`
+	BaseTitle   string              `gorm:"type:text" json:"base_title"`
+	BaseContent string              `gorm:"type:longtext" json:"base_content"`
+	Status      SuggestedEditStatus `gorm:"type:ENUM('pending','approved','rejected');default:'pending';index" json:"status"`
+	ReviewerID  *uint               `gorm:"index" json:"reviewer_id,omitempty"`
+	ReviewNote  string              `gorm:"type:text" json:"review_note,omitempty"`
+	Improved    bool                `gorm:"default:false" json:"improved"`
+	ReviewedAt  *time.Time          `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+
+	// Conflict được tính khi đọc: nội dung đích đã thay đổi kể từ lúc đề xuất
+	Conflict bool `gorm:"-" json:"conflict"`
+
+	Editor   User  `json:"editor,omitempty" gorm:"foreignKey:EditorID"`
+	Reviewer *User `json:"reviewer,omitempty" gorm:"foreignKey:ReviewerID"`
+}
@@ -7,8 +7,8 @@ import (
 
 type Tag struct {
 	ID             uint           `gorm:"primaryKey" json:"id"`
-	Name           string         `gorm:"unique;not null;index" json:"name"`
-	Description    string         `gorm:"type:text" json:"description,omitempty"`
+	Name           string         `gorm:"unique;not null;index;index:idx_tags_fulltext,class:FULLTEXT" json:"name"`
+	Description    string         `gorm:"type:text;index:idx_tags_fulltext,class:FULLTEXT" json:"description,omitempty"`
 	FollowersCount int            `gorm:"default:0" json:"followers_count"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
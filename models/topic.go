@@ -15,8 +15,8 @@ const (
 
 type Topic struct {
 	ID             uint           `gorm:"primaryKey" json:"id"`
-	Name           string         `gorm:"not null;uniqueIndex;size:255" json:"name"`
-	Description    string         `json:"description"`
+	Name           string         `gorm:"not null;uniqueIndex;size:255;index:idx_topics_fulltext,class:FULLTEXT" json:"name"`
+	Description    string         `gorm:"index:idx_topics_fulltext,class:FULLTEXT" json:"description"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
@@ -15,7 +15,7 @@ const (
 
 type User struct {
 	ID             uint           `gorm:"primaryKey" json:"id"`
-	Username       string         `gorm:"unique;not null;index;size:50" json:"username"`
+	Username       string         `gorm:"unique;not null;index;size:50;index:idx_users_fulltext,class:FULLTEXT" json:"username"`
 	Email          string         `gorm:"unique;not null;index;size:100" json:"email"`
 	Password       string         `gorm:"not null" json:"-"`
 	Role           Role           `gorm:"type:enum('root','admin','employee','user');default:'user'" json:"role"`
@@ -23,7 +23,7 @@ type User struct {
 	Bio            *string        `gorm:"type:text" json:"bio,omitempty"`
 	Status         Status         `gorm:"type:enum('active','inactive','banned');default:'inactive'" json:"status"`
 	Location       *string        `json:"location,omitempty"`
-	FullName       string         `gorm:"not null" json:"fullName"`
+	FullName       string         `gorm:"not null;index:idx_users_fulltext,class:FULLTEXT" json:"fullName"`
 	Reputation     uint           `gorm:"default:0;index" json:"reputation"`
 	FollowersCount uint           `gorm:"default:0" json:"followers_count"`
 	FollowingCount uint           `gorm:"default:0" json:"following_count"`
@@ -47,3 +47,8 @@ type User struct {
 	Following        []UserFollow   `json:"following,omitempty" gorm:"foreignKey:UserID"`
 	Followers        []UserFollow   `json:"followers,omitempty" gorm:"foreignKey:FollowedUserID"`
 }
+
+// AnonymousUser là tác giả hiển thị thay cho người đăng nội dung ẩn danh, không mang thông tin nào của tài khoản thật
+func AnonymousUser(pseudonym string) User {
+	return User{Username: pseudonym, FullName: pseudonym}
+}
@@ -14,14 +14,15 @@ const (
 )
 
 type Vote struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	UserID      uint           `gorm:"not null;index" json:"user_id"`
-	VotableType string         `gorm:"type:ENUM('question','answer','comment');not null" json:"votable_type"`
-	VotableID   uint           `gorm:"not null;index" json:"votable_id"`
-	VoteType    VoteType       `gorm:"type:ENUM('upvote','downvote');not null" json:"vote_type"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	UserID        uint           `gorm:"not null;index" json:"user_id"`
+	VotableType   string         `gorm:"type:ENUM('question','answer','comment');not null" json:"votable_type"`
+	VotableID     uint           `gorm:"not null;index" json:"votable_id"`
+	VoteType      VoteType       `gorm:"type:ENUM('upvote','downvote');not null" json:"vote_type"`
+	InvalidatedAt *time.Time     `gorm:"index" json:"invalidated_at,omitempty"` // Bị huỷ do gian lận: không được tính, giữ lại làm bằng chứng
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
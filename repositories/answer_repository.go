@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"Forum_BE/models"
-	"Forum_BE/utils"
 	"gorm.io/gorm"
 	"log"
 	"strings"
@@ -36,21 +35,8 @@ func (r *answerRepository) GetAllAnswers(filters map[string]interface{}) ([]mode
 	search, ok := filters["search"].(string)
 	questiontitle, okQt := filters["questiontitle"].(string)
 	status, okStatus := filters["status"].(string)
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
 	user_id, okUserId := filters["user_id"].(uint)
 
-	// Process sort parameter
-	sortOrder := "created_at DESC"
-	if sort, ok := filters["sort"].(string); ok {
-		if sort == "asc" {
-			sortOrder = "created_at ASC"
-		} else if sort == "desc" {
-			sortOrder = "created_at DESC"
-		}
-		log.Printf("Applying sort order: %s", sortOrder)
-	}
-
 	// Process tag_ids filter
 	if tagIDs, ok := filters["tagfilter"].([]uint); ok && len(tagIDs) > 0 {
 		query = query.
@@ -59,15 +45,6 @@ func (r *answerRepository) GetAllAnswers(filters map[string]interface{}) ([]mode
 			Group("answers.id")
 	}
 
-	// Default pagination values
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
 	if okQt && questiontitle != "" {
 		query = query.Joins("JOIN questions ON questions.id = answers.question_id").
 			Where("LOWER(questions.title) LIKE LOWER(?)", "%"+questiontitle+"%")
@@ -82,24 +59,29 @@ func (r *answerRepository) GetAllAnswers(filters map[string]interface{}) ([]mode
 	if okUserId && user_id != 0 {
 		query = query.Where("user_id = ?", user_id)
 	}
+	if exclude, _ := filters["exclude_anonymous"].(bool); exclude {
+		query = query.Where("answers.anonymous = ?", false)
+	}
 
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		log.Printf("Error counting answers: %v", err)
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			log.Printf("Error counting answers: %v", err)
+			return nil, 0, err
+		}
 	}
 
-	query = query.Offset(offset).Limit(limit).Preload("User").Preload("Question").Preload("Comments").Preload("Tags").Order(sortOrder)
+	query = paginate(query, filters, "answers.created_at", "desc").Preload("User").Preload("Question").Preload("Comments").Preload("Tags")
 	if err := query.Find(&answers).Error; err != nil {
 		log.Printf("Error fetching answers: %v", err)
 		return nil, 0, err
 	}
-	log.Printf("Found %d answers with total %d, sort order: %s", len(answers), total, sortOrder)
+	reversePage(answers, filters)
+	log.Printf("Found %d answers with total %d", len(answers), total)
 	return answers, int(total), nil
 }
 
 func (r *answerRepository) CreateAnswer(answer *models.Answer, tagIDs []uint) error {
-	answer.PlainContent = utils.StripHTML(answer.Content)
 	tx := r.db.Begin()
 	if err := tx.Error; err != nil {
 		return err
@@ -145,7 +127,6 @@ func (r *answerRepository) GetAnswerByIDSimple(id uint) (*models.Answer, error)
 }
 
 func (r *answerRepository) UpdateAnswer(answer *models.Answer, tagId []uint) error {
-	answer.PlainContent = utils.StripHTML(answer.Content)
 	tx := r.db.Begin()
 	if err := tx.Error; err != nil {
 		return err
@@ -175,17 +156,6 @@ func (r *answerRepository) DeleteAnswer(id uint) error {
 func (r *answerRepository) ListAnswers(filters map[string]interface{}) ([]models.Answer, int, error) {
 	var answers []models.Answer
 
-	// Process sort parameter
-	sortOrder := "created_at DESC"
-	if sort, ok := filters["sort"].(string); ok {
-		if sort == "asc" {
-			sortOrder = "created_at ASC"
-		} else if sort == "desc" {
-			sortOrder = "created_at DESC"
-		}
-		log.Printf("Applying sort order: %s", sortOrder)
-	}
-
 	// Build count query
 	countQuery := r.db.Model(&models.Answer{})
 	if filters != nil {
@@ -196,20 +166,22 @@ func (r *answerRepository) ListAnswers(filters map[string]interface{}) ([]models
 				Group("answers.id")
 		}
 		for key, value := range filters {
-			if key != "limit" && key != "page" && key != "tag_ids" && key != "sort" {
+			if !isPaginationKey(key) && key != "tag_ids" && key != "tagfilter" {
 				countQuery = countQuery.Where(key, value)
 			}
 		}
 	}
 
-	var total int64
-	if err := countQuery.Count(&total).Error; err != nil {
-		log.Printf("Error counting answers: %v", err)
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := countQuery.Count(&total).Error; err != nil {
+			log.Printf("Error counting answers: %v", err)
+			return nil, 0, err
+		}
 	}
 
 	// Build data query
-	query := r.db.Preload("User").Preload("Question.User").Preload("Question").Preload("Comments").Preload("Tags").Order(sortOrder)
+	query := r.db.Preload("User").Preload("Question.User").Preload("Question").Preload("Comments").Preload("Tags")
 	if filters != nil {
 		if tagIDs, ok := filters["tagfilter"].([]uint); ok && len(tagIDs) > 0 {
 			query = query.
@@ -218,27 +190,21 @@ func (r *answerRepository) ListAnswers(filters map[string]interface{}) ([]models
 				Group("answers.id")
 		}
 		for key, value := range filters {
-			if key != "limit" && key != "page" && key != "tag_ids" && key != "sort" {
+			if !isPaginationKey(key) && key != "tag_ids" && key != "tagfilter" {
 				query = query.Where(key, value)
 			}
 		}
 	}
 
-	// Apply pagination
-	if limit, ok := filters["limit"].(int); ok && limit > 0 {
-		query = query.Limit(limit)
-	}
-	if page, ok := filters["page"].(int); ok && page > 0 {
-		offset := (page - 1) * filters["limit"].(int)
-		query = query.Offset(offset)
-	}
+	query = paginate(query, filters, "answers.created_at", "desc")
 
 	err := query.Find(&answers).Error
 	if err != nil {
 		log.Printf("Error fetching answers: %v", err)
 		return nil, 0, err
 	}
-	log.Printf("Found %d answers with total %d, sort order: %s", len(answers), total, sortOrder)
+	reversePage(answers, filters)
+	log.Printf("Found %d answers with total %d", len(answers), total)
 	return answers, int(total), nil
 }
 
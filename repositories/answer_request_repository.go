@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+type AnswerRequestRepository interface {
+	CreateRequests(requests []models.AnswerRequest) error
+	GetRequest(id uint) (*models.AnswerRequest, error)
+	RequestedUserIDs(questionID uint, userIDs []uint) ([]uint, error)
+	AnsweredUserIDs(questionID uint, userIDs []uint) ([]uint, error)
+	CountByRequesterSince(requesterID uint, since time.Time) (int64, error)
+	CountByQuestionAndRequester(questionID, requesterID uint) (int64, error)
+	ListForUser(userID uint, filters map[string]interface{}) ([]models.AnswerRequest, int, error)
+	ListForQuestion(questionID uint) ([]models.AnswerRequest, error)
+	UpdateStatus(id uint, status models.AnswerRequestStatus, at time.Time) error
+	MarkFulfilled(questionID, userID, answerID uint, at time.Time) (int64, error)
+}
+
+type answerRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewAnswerRequestRepository(db *gorm.DB) AnswerRequestRepository {
+	return &answerRequestRepository{db: db}
+}
+
+func (r *answerRequestRepository) CreateRequests(requests []models.AnswerRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	return r.db.Create(&requests).Error
+}
+
+func (r *answerRequestRepository) GetRequest(id uint) (*models.AnswerRequest, error) {
+	var request models.AnswerRequest
+	if err := r.db.First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// RequestedUserIDs trả về những người trong userIDs đã từng được nhờ trả lời câu hỏi
+func (r *answerRequestRepository) RequestedUserIDs(questionID uint, userIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(userIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&models.AnswerRequest{}).
+		Where("question_id = ? AND user_id IN ?", questionID, userIDs).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// AnsweredUserIDs trả về những người trong userIDs đã trả lời câu hỏi
+func (r *answerRequestRepository) AnsweredUserIDs(questionID uint, userIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(userIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&models.Answer{}).
+		Where("question_id = ? AND user_id IN ?", questionID, userIDs).
+		Distinct().Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *answerRequestRepository) CountByRequesterSince(requesterID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AnswerRequest{}).
+		Where("requester_id = ? AND created_at >= ?", requesterID, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *answerRequestRepository) CountByQuestionAndRequester(questionID, requesterID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.AnswerRequest{}).
+		Where("question_id = ? AND requester_id = ?", questionID, requesterID).
+		Count(&count).Error
+	return count, err
+}
+
+// ListForUser là hộp thư lời nhờ của người dùng; filters["status"] rỗng là mọi trạng thái
+func (r *answerRequestRepository) ListForUser(userID uint, filters map[string]interface{}) ([]models.AnswerRequest, int, error) {
+	var requests []models.AnswerRequest
+	query := r.db.Model(&models.AnswerRequest{}).Where("user_id = ?", userID)
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := paginate(query, filters, "created_at", "desc").
+		Preload("Question").Preload("Requester").Find(&requests).Error; err != nil {
+		return nil, 0, err
+	}
+	reversePage(requests, filters)
+	return requests, int(total), nil
+}
+
+func (r *answerRequestRepository) ListForQuestion(questionID uint) ([]models.AnswerRequest, error) {
+	var requests []models.AnswerRequest
+	err := r.db.Preload("User").Preload("Requester").Preload("Question").
+		Where("question_id = ?", questionID).
+		Order("created_at ASC, id ASC").Find(&requests).Error
+	return requests, err
+}
+
+func (r *answerRequestRepository) UpdateStatus(id uint, status models.AnswerRequestStatus, at time.Time) error {
+	return r.db.Model(&models.AnswerRequest{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       status,
+		"responded_at": at,
+	}).Error
+}
+
+// MarkFulfilled đánh dấu lời nhờ (kể cả đã từ chối) là đã trả lời khi câu trả lời của người được nhờ được duyệt
+func (r *answerRequestRepository) MarkFulfilled(questionID, userID, answerID uint, at time.Time) (int64, error) {
+	result := r.db.Model(&models.AnswerRequest{}).
+		Where("question_id = ? AND user_id = ? AND status <> ?", questionID, userID, models.AnswerRequestFulfilled).
+		Updates(map[string]interface{}{
+			"status":       models.AnswerRequestFulfilled,
+			"answer_id":    answerID,
+			"responded_at": at,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -57,30 +57,24 @@ func (r *attachmentRepository) ListAttachments(filters map[string]interface{}) (
 	}
 	search, ok := filters["search"].(string)
 
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		log.Printf("Error counting attachments: %v", err)
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			log.Printf("Error counting attachments: %v", err)
+			return nil, 0, err
+		}
 	}
 
-	limit, okLimit := filters["limit"].(int)
-	page, okPage := filters["page"].(int)
-	if okLimit && limit > 0 {
-		query = query.Limit(limit)
-	}
-	if okPage && page > 0 {
-		offset := (page - 1) * limit
-		query = query.Offset(offset)
-	}
 	if ok && search != "" {
 		search = strings.ToLower(search)
 		query = query.Where("file_name LIKE ?", "%"+search+"%")
 	}
-	err := query.Preload("User").Find(&attachments).Error
+	err := paginate(query, filters, "created_at", "desc").Preload("User").Find(&attachments).Error
 	if err != nil {
 		log.Printf("Error fetching attachments: %v", err)
 		return nil, 0, err
 	}
+	reversePage(attachments, filters)
 
 	return attachments, int(total), nil
 }
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+// AutoModerationRuleSummary đếm số lần một luật khớp theo hành động, tách riêng các lần chạy thử
+type AutoModerationRuleSummary struct {
+	RuleID  uint                        `json:"rule_id"`
+	Action  models.AutoModerationAction `json:"action"`
+	DryRun  bool                        `json:"dry_run"`
+	Matches int64                       `json:"matches"`
+	Applied int64                       `json:"applied"`
+}
+
+type AutoModerationRepository interface {
+	ListRules(enabledOnly bool) ([]models.AutoModerationRule, error)
+	GetRule(id uint) (*models.AutoModerationRule, error)
+	CreateRule(rule *models.AutoModerationRule) error
+	UpdateRule(rule *models.AutoModerationRule) error
+	DeleteRule(id uint) error
+	CountRecentContent(userID uint, since time.Time) (int64, error)
+	HasDuplicate(hash string, contentType string, contentID uint, since time.Time) (bool, error)
+	SaveFingerprint(fingerprint *models.ContentFingerprint) error
+	CreateLogs(entries []models.AutoModerationLog) error
+	ListLogs(filters map[string]interface{}) ([]models.AutoModerationLog, int, error)
+	SummarizeLogs(ruleIDs []uint, since time.Time) ([]AutoModerationRuleSummary, error)
+}
+
+type autoModerationRepository struct {
+	db *gorm.DB
+}
+
+func NewAutoModerationRepository(db *gorm.DB) AutoModerationRepository {
+	return &autoModerationRepository{db: db}
+}
+
+func (r *autoModerationRepository) ListRules(enabledOnly bool) ([]models.AutoModerationRule, error) {
+	var rules []models.AutoModerationRule
+	query := r.db.Order("id ASC")
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+	if err := query.Find(&rules).Error; err != nil {
+		log.Printf("Error listing auto-moderation rules: %v", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *autoModerationRepository) GetRule(id uint) (*models.AutoModerationRule, error) {
+	var rule models.AutoModerationRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *autoModerationRepository) CreateRule(rule *models.AutoModerationRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *autoModerationRepository) UpdateRule(rule *models.AutoModerationRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *autoModerationRepository) DeleteRule(id uint) error {
+	result := r.db.Delete(&models.AutoModerationRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountRecentContent đếm số câu hỏi, câu trả lời, bài viết và bình luận người dùng đã tạo từ since
+func (r *autoModerationRepository) CountRecentContent(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Raw(`SELECT
+		(SELECT COUNT(*) FROM questions WHERE user_id = ? AND created_at >= ?) +
+		(SELECT COUNT(*) FROM answers WHERE user_id = ? AND created_at >= ?) +
+		(SELECT COUNT(*) FROM posts WHERE user_id = ? AND created_at >= ?) +
+		(SELECT COUNT(*) FROM comments WHERE user_id = ? AND created_at >= ?)`,
+		userID, since, userID, since, userID, since, userID, since).Scan(&count).Error
+	return count, err
+}
+
+// HasDuplicate cho biết đã có nội dung khác cùng hash được đăng hoặc sửa từ since
+func (r *autoModerationRepository) HasDuplicate(hash string, contentType string, contentID uint, since time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ContentFingerprint{}).
+		Where("hash = ? AND updated_at >= ?", hash, since).
+		Where("NOT (content_type = ? AND content_id = ?)", contentType, contentID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SaveFingerprint ghi hash mới nhất của nội dung; sửa nội dung thì ghi đè hash cũ
+func (r *autoModerationRepository) SaveFingerprint(fingerprint *models.ContentFingerprint) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "content_type"}, {Name: "content_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hash", "updated_at"}),
+	}).Create(fingerprint).Error
+}
+
+func (r *autoModerationRepository) CreateLogs(entries []models.AutoModerationLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.Create(&entries).Error
+}
+
+func (r *autoModerationRepository) ListLogs(filters map[string]interface{}) ([]models.AutoModerationLog, int, error) {
+	var entries []models.AutoModerationLog
+
+	query := r.db.Model(&models.AutoModerationLog{})
+	if ruleID, ok := filters["rule_id"].(uint); ok && ruleID > 0 {
+		query = query.Where("rule_id = ?", ruleID)
+	}
+	if dryRun, ok := filters["dry_run"].(bool); ok {
+		query = query.Where("dry_run = ?", dryRun)
+	}
+	if action, ok := filters["action"].(string); ok && action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if contentType, ok := filters["content_type"].(string); ok && contentType != "" {
+		query = query.Where("content_type = ?", contentType)
+	}
+	if userID, ok := filters["user_id"].(uint); ok && userID > 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			log.Printf("Error counting auto-moderation logs: %v", err)
+			return nil, 0, err
+		}
+	}
+
+	if err := paginate(query, filters, "created_at", "desc").Find(&entries).Error; err != nil {
+		log.Printf("Error listing auto-moderation logs: %v", err)
+		return nil, 0, err
+	}
+	reversePage(entries, filters)
+	return entries, int(total), nil
+}
+
+func (r *autoModerationRepository) SummarizeLogs(ruleIDs []uint, since time.Time) ([]AutoModerationRuleSummary, error) {
+	var summaries []AutoModerationRuleSummary
+	if len(ruleIDs) == 0 {
+		return summaries, nil
+	}
+	err := r.db.Model(&models.AutoModerationLog{}).
+		Select("rule_id, action, dry_run, COUNT(*) AS matches, SUM(CASE WHEN applied THEN 1 ELSE 0 END) AS applied").
+		Where("rule_id IN ? AND created_at >= ?", ruleIDs, since).
+		Group("rule_id, action, dry_run").
+		Scan(&summaries).Error
+	if err != nil {
+		log.Printf("Error summarizing auto-moderation logs: %v", err)
+		return nil, err
+	}
+	return summaries, nil
+}
@@ -0,0 +1,258 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/utils"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+)
+
+type BookmarkRepository interface {
+	CreateBookmark(bookmark *models.Bookmark) error
+	GetBookmarkByID(id uint) (*models.Bookmark, error)
+	GetBookmarkByTarget(userID uint, targetType models.BookmarkTargetType, targetID uint) (*models.Bookmark, error)
+	UpdateBookmark(bookmark *models.Bookmark) error
+	DeleteBookmark(id uint) error
+	ListBookmarks(userID uint, filters map[string]interface{}) ([]models.Bookmark, int, error)
+	NextPosition(userID uint, collectionID *uint) (int, error)
+	ReorderBookmarks(userID uint, collectionID *uint, ids []uint) error
+	BookmarkedTargetIDs(userID uint, targetType models.BookmarkTargetType, targetIDs []uint) ([]uint, error)
+
+	CreateCollection(collection *models.BookmarkCollection) error
+	GetCollectionByID(id uint) (*models.BookmarkCollection, error)
+	UpdateCollection(collection *models.BookmarkCollection) error
+	DeleteCollection(id uint) error
+	ListCollections(userID uint, publicOnly bool) ([]models.BookmarkCollection, error)
+	CountByCollection(userID uint) (map[uint]int, error)
+
+	GetQuestionsByIDs(ids []uint) ([]models.Question, error)
+	GetAnswersByIDs(ids []uint) ([]models.Answer, error)
+	GetPostsByIDs(ids []uint) ([]models.Post, error)
+}
+
+type bookmarkRepository struct {
+	db *gorm.DB
+}
+
+func NewBookmarkRepository(db *gorm.DB) BookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+// inCollection lọc theo bộ sưu tập; nil là mục "Đã lưu" mặc định
+func inCollection(query *gorm.DB, collectionID *uint) *gorm.DB {
+	if collectionID == nil {
+		return query.Where("collection_id IS NULL")
+	}
+	return query.Where("collection_id = ?", *collectionID)
+}
+
+func (r *bookmarkRepository) CreateBookmark(bookmark *models.Bookmark) error {
+	return r.db.Create(bookmark).Error
+}
+
+func (r *bookmarkRepository) GetBookmarkByID(id uint) (*models.Bookmark, error) {
+	var bookmark models.Bookmark
+	if err := r.db.First(&bookmark, id).Error; err != nil {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+func (r *bookmarkRepository) GetBookmarkByTarget(userID uint, targetType models.BookmarkTargetType, targetID uint) (*models.Bookmark, error) {
+	var bookmark models.Bookmark
+	err := r.db.Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).First(&bookmark).Error
+	if err != nil {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+func (r *bookmarkRepository) UpdateBookmark(bookmark *models.Bookmark) error {
+	return r.db.Save(bookmark).Error
+}
+
+func (r *bookmarkRepository) DeleteBookmark(id uint) error {
+	return r.db.Delete(&models.Bookmark{}, id).Error
+}
+
+// ListBookmarks liệt kê bookmark của người dùng. Khi lọc theo bộ sưu tập ("collection_id", 0 là mục mặc định)
+// thì phân trang theo (position, id) là thứ tự người dùng đã sắp xếp; ngược lại theo (created_at, id) mới nhất trước.
+func (r *bookmarkRepository) ListBookmarks(userID uint, filters map[string]interface{}) ([]models.Bookmark, int, error) {
+	var bookmarks []models.Bookmark
+
+	query := r.db.Model(&models.Bookmark{}).Where("user_id = ?", userID)
+	collectionID, byCollection := filters["collection_id"].(uint)
+	if byCollection {
+		if collectionID == 0 {
+			query = inCollection(query, nil)
+		} else {
+			query = inCollection(query, &collectionID)
+		}
+	}
+	if targetType, ok := filters["typefilter"].(string); ok && targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			log.Printf("Error counting bookmarks: %v", err)
+			return nil, 0, err
+		}
+	}
+
+	// Cursor của danh sách theo bộ sưu tập mang position, của danh sách chung mang thời gian lưu
+	if cursor, ok := filters["cursor"].(utils.Cursor); ok && (cursor.Position != nil) != byCollection {
+		return nil, 0, utils.ErrInvalidCursor
+	}
+	if byCollection {
+		query = paginate(query, filters, "position", "asc")
+	} else {
+		query = paginate(query, filters, "created_at", "desc")
+	}
+	if err := query.Find(&bookmarks).Error; err != nil {
+		log.Printf("Error fetching bookmarks: %v", err)
+		return nil, 0, err
+	}
+	reversePage(bookmarks, filters)
+	return bookmarks, int(total), nil
+}
+
+// NextPosition trả về vị trí cuối cùng + 1 trong bộ sưu tập để bookmark mới nằm cuối danh sách
+func (r *bookmarkRepository) NextPosition(userID uint, collectionID *uint) (int, error) {
+	var maxPosition *int
+	query := inCollection(r.db.Model(&models.Bookmark{}).Where("user_id = ?", userID), collectionID)
+	if err := query.Select("MAX(position)").Scan(&maxPosition).Error; err != nil {
+		return 0, err
+	}
+	if maxPosition == nil {
+		return 0, nil
+	}
+	return *maxPosition + 1, nil
+}
+
+// ReorderBookmarks gán lại position theo thứ tự ids; ids phải là toàn bộ bookmark của bộ sưu tập
+func (r *bookmarkRepository) ReorderBookmarks(userID uint, collectionID *uint, ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		query := inCollection(tx.Model(&models.Bookmark{}).Where("user_id = ?", userID), collectionID)
+		if err := query.Count(&count).Error; err != nil {
+			return err
+		}
+		var matched int64
+		if err := inCollection(tx.Model(&models.Bookmark{}).Where("user_id = ? AND id IN ?", userID, ids), collectionID).
+			Count(&matched).Error; err != nil {
+			return err
+		}
+		if int(count) != len(ids) || int(matched) != len(ids) {
+			return errors.New("bookmark ids do not match the collection")
+		}
+		for position, id := range ids {
+			if err := tx.Model(&models.Bookmark{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *bookmarkRepository) BookmarkedTargetIDs(userID uint, targetType models.BookmarkTargetType, targetIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(targetIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&models.Bookmark{}).
+		Where("user_id = ? AND target_type = ? AND target_id IN ?", userID, targetType, targetIDs).
+		Pluck("target_id", &ids).Error
+	return ids, err
+}
+
+func (r *bookmarkRepository) CreateCollection(collection *models.BookmarkCollection) error {
+	return r.db.Create(collection).Error
+}
+
+func (r *bookmarkRepository) GetCollectionByID(id uint) (*models.BookmarkCollection, error) {
+	var collection models.BookmarkCollection
+	if err := r.db.Preload("User").First(&collection, id).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func (r *bookmarkRepository) UpdateCollection(collection *models.BookmarkCollection) error {
+	return r.db.Omit("User").Save(collection).Error
+}
+
+// DeleteCollection xoá bộ sưu tập và chuyển các bookmark bên trong về mục mặc định
+func (r *bookmarkRepository) DeleteCollection(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Bookmark{}).Where("collection_id = ?", id).Update("collection_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.BookmarkCollection{}, id).Error
+	})
+}
+
+func (r *bookmarkRepository) ListCollections(userID uint, publicOnly bool) ([]models.BookmarkCollection, error) {
+	var collections []models.BookmarkCollection
+	query := r.db.Preload("User").Where("user_id = ?", userID)
+	if publicOnly {
+		query = query.Where("is_public = ?", true)
+	}
+	err := query.Order("created_at ASC").Find(&collections).Error
+	return collections, err
+}
+
+// CountByCollection đếm số bookmark trong từng bộ sưu tập của người dùng; key 0 là mục mặc định
+func (r *bookmarkRepository) CountByCollection(userID uint) (map[uint]int, error) {
+	var rows []struct {
+		CollectionID *uint
+		Total        int
+	}
+	err := r.db.Model(&models.Bookmark{}).
+		Select("collection_id, COUNT(*) AS total").
+		Where("user_id = ?", userID).
+		Group("collection_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		var key uint
+		if row.CollectionID != nil {
+			key = *row.CollectionID
+		}
+		counts[key] = row.Total
+	}
+	return counts, nil
+}
+
+// Các hàm Get...ByIDs không trả về bản ghi đã bị xoá mềm; nội dung thiếu trong kết quả được coi là đã xoá
+func (r *bookmarkRepository) GetQuestionsByIDs(ids []uint) ([]models.Question, error) {
+	var questions []models.Question
+	if len(ids) == 0 {
+		return questions, nil
+	}
+	err := r.db.Preload("User").Preload("Topic").Where("id IN ?", ids).Find(&questions).Error
+	return questions, err
+}
+
+func (r *bookmarkRepository) GetAnswersByIDs(ids []uint) ([]models.Answer, error) {
+	var answers []models.Answer
+	if len(ids) == 0 {
+		return answers, nil
+	}
+	err := r.db.Preload("User").Preload("Question").Preload("Tags").Where("id IN ?", ids).Find(&answers).Error
+	return answers, err
+}
+
+func (r *bookmarkRepository) GetPostsByIDs(ids []uint) ([]models.Post, error) {
+	var posts []models.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	err := r.db.Preload("User").Preload("Tags").Where("id IN ?", ids).Find(&posts).Error
+	return posts, err
+}
@@ -19,6 +19,13 @@ type CommentRepository interface {
 	GetAllComments(filters map[string]interface{}) ([]models.Comment, int64, error) // Thêm method mới
 	UpdateCommentStatus(id uint, status string) error
 	GetAllChildCommentIDs(parentID uint) ([]uint, error)
+	CountDescendants(id uint) (int64, error)
+	ListThreadPage(filters map[string]interface{}) ([]models.Comment, int64, error)
+	ListChildren(parentIDs []uint, perParent int, status string) ([]models.Comment, error)
+	CountChildren(parentIDs []uint, status string) (map[uint]int64, error)
+	BackfillPaths() (int64, error)
+	ListMissingPlainContent(afterID uint, limit int) ([]PlainContentRow, error)
+	UpdatePlainContent(id uint, plain string) error
 }
 
 type commentRepository struct {
@@ -30,7 +37,22 @@ func NewCommentRepository(db *gorm.DB) CommentRepository {
 }
 
 func (r *commentRepository) CreateComment(comment *models.Comment) error {
-	return r.db.Create(comment).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		// Path chứa chính ID nên chỉ tính được sau khi tạo
+		path, depth := models.CommentPathSegment(comment.ID), 0
+		if comment.ParentID != nil {
+			var parent models.Comment
+			if err := tx.Select("id", "path", "depth").First(&parent, *comment.ParentID).Error; err != nil {
+				return err
+			}
+			path, depth = parent.Path+path, parent.Depth+1
+		}
+		comment.Path, comment.Depth = path, depth
+		return tx.Model(comment).UpdateColumns(map[string]interface{}{"path": path, "depth": depth}).Error
+	})
 }
 
 func (r *commentRepository) GetCommentByID(id uint) (*models.Comment, error) {
@@ -47,65 +69,172 @@ func (r *commentRepository) GetCommentByID(id uint) (*models.Comment, error) {
 func (r *commentRepository) UpdateComment(comment *models.Comment) error {
 	return r.db.Save(comment).Error
 }
-func (r *commentRepository) DeleteComment(id uint) error {
-	childIDs, err := r.GetAllChildCommentIDs(id)
-	if err != nil {
-		return fmt.Errorf("failed to get child comments: %v", err)
-	}
 
-	allIDs := append(childIDs, id)
+// DeleteComment xoá mềm bình luận cùng toàn bộ nhánh con của nó trong một câu lệnh theo path
+func (r *commentRepository) DeleteComment(id uint) error {
+	return r.db.Exec(`UPDATE comments AS c JOIN comments AS root ON root.id = ?
+		SET c.deleted_at = NOW()
+		WHERE root.path <> '' AND c.path LIKE CONCAT(root.path, '%') AND c.deleted_at IS NULL`, id).Error
+}
 
-	return r.db.Model(&models.Comment{}).
-		Where("id IN ?", allIDs).
-		Update("deleted_at", gorm.Expr("NOW()")).Error
+// subtree trả về query các hậu duệ (không gồm chính nó) chưa bị xoá của một bình luận.
+// Bình luận chưa có path (chưa backfill) được coi như không có con thay vì khớp toàn bảng.
+func (r *commentRepository) subtree(id uint) *gorm.DB {
+	return r.db.Table("comments AS c").
+		Joins("JOIN comments AS root ON root.id = ?", id).
+		Where("root.path <> '' AND c.path LIKE CONCAT(root.path, '%') AND c.id <> root.id AND c.deleted_at IS NULL")
 }
 
 func (r *commentRepository) GetAllChildCommentIDs(parentID uint) ([]uint, error) {
 	var childIDs []uint
-	var queue []uint
-	queue = append(queue, parentID)
+	if err := r.subtree(parentID).Pluck("c.id", &childIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get child comments of %d: %v", parentID, err)
+	}
+	return childIDs, nil
+}
 
-	for len(queue) > 0 {
-		currentID := queue[0]
-		queue = queue[1:]
+func (r *commentRepository) CountDescendants(id uint) (int64, error) {
+	var count int64
+	err := r.subtree(id).Count(&count).Error
+	return count, err
+}
 
-		var directChildren []uint
-		err := r.db.Model(&models.Comment{}).
-			Where("parent_id = ? AND deleted_at IS NULL", currentID).
-			Pluck("id", &directChildren).Error
-		if err != nil {
-			return nil, fmt.Errorf("failed to get direct children for comment %d: %v", currentID, err)
+// ListThreadPage trả về một trang các nút ở đầu cây: bình luận gốc của bài viết/câu trả lời
+// (filters post_id, answer_id hoặc question_id), hoặc các trả lời trực tiếp của filters["parent_id"]
+func (r *commentRepository) ListThreadPage(filters map[string]interface{}) ([]models.Comment, int64, error) {
+	var comments []models.Comment
+	query := r.db.Model(&models.Comment{})
+	if parentID, ok := filters["parent_id"].(uint); ok {
+		query = query.Where("parent_id = ?", parentID)
+	} else {
+		query = query.Where("parent_id IS NULL")
+		if postID, ok := filters["post_id"].(uint); ok {
+			query = query.Where("post_id = ?", postID)
+		}
+		if answerID, ok := filters["answer_id"].(uint); ok {
+			query = query.Where("answer_id = ?", answerID)
 		}
+		if questionID, ok := filters["question_id"].(uint); ok {
+			query = query.Where("question_id = ?", questionID)
+		}
+	}
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
 
-		// Thêm các comment con vào childIDs và queue
-		childIDs = append(childIDs, directChildren...)
-		queue = append(queue, directChildren...)
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to count thread comments: %v", err)
+		}
+	}
+	if err := paginate(query, filters, "created_at", "asc").Preload("User").Find(&comments).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list thread comments: %v", err)
 	}
+	reversePage(comments, filters)
+	return comments, total, nil
+}
 
-	return childIDs, nil
+// ListChildren lấy tối đa perParent trả lời đầu tiên (cũ nhất trước) của mỗi bình luận trong parentIDs
+// bằng một truy vấn, dùng để dựng từng tầng của cây
+func (r *commentRepository) ListChildren(parentIDs []uint, perParent int, status string) ([]models.Comment, error) {
+	var comments []models.Comment
+	if len(parentIDs) == 0 || perParent < 1 {
+		return comments, nil
+	}
+	ranked := r.db.Model(&models.Comment{}).
+		Select("comments.*, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY created_at ASC, id ASC) AS sibling_rank").
+		Where("parent_id IN ?", parentIDs)
+	if status != "" {
+		ranked = ranked.Where("status = ?", status)
+	}
+	err := r.db.Table("(?) AS comments", ranked).
+		Where("sibling_rank <= ?", perParent).
+		Order("parent_id ASC, created_at ASC, id ASC").
+		Preload("User").Find(&comments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list child comments: %v", err)
+	}
+	return comments, nil
 }
+
+// CountChildren đếm số trả lời trực tiếp chưa bị xoá của từng bình luận
+func (r *commentRepository) CountChildren(parentIDs []uint, status string) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(parentIDs))
+	if len(parentIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ParentID uint
+		Total    int64
+	}
+	query := r.db.Model(&models.Comment{}).Select("parent_id, COUNT(*) AS total").Where("parent_id IN ?", parentIDs)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Group("parent_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count child comments: %v", err)
+	}
+	for _, row := range rows {
+		counts[row.ParentID] = row.Total
+	}
+	return counts, nil
+}
+
+// BackfillPaths tính path và depth cho các bình luận tạo trước khi có cây bình luận:
+// bình luận gốc trước, sau đó lần lượt từng tầng con cho tới khi không còn bản ghi nào được cập nhật.
+// Bình luận có cha đã bị xoá cứng sẽ không có path và không xuất hiện trong cây.
+func (r *commentRepository) BackfillPaths() (int64, error) {
+	result := r.db.Exec(`UPDATE comments SET path = CONCAT(LPAD(id, 10, '0'), '/'), depth = 0
+		WHERE (path = '' OR path IS NULL) AND parent_id IS NULL`)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	total := result.RowsAffected
+	for {
+		result = r.db.Exec(`UPDATE comments AS c JOIN comments AS p ON p.id = c.parent_id
+			SET c.path = CONCAT(p.path, LPAD(c.id, 10, '0'), '/'), c.depth = p.depth + 1
+			WHERE (c.path = '' OR c.path IS NULL) AND p.path <> ''`)
+		if result.Error != nil {
+			return total, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return total, nil
+		}
+		total += result.RowsAffected
+	}
+}
+
+// ListMissingPlainContent trả về các bình luận tạo trước khi có cột plain_content
+func (r *commentRepository) ListMissingPlainContent(afterID uint, limit int) ([]PlainContentRow, error) {
+	var rows []PlainContentRow
+	err := r.db.Model(&models.Comment{}).
+		Select("id, content").
+		Where("id > ? AND (plain_content IS NULL OR plain_content = '') AND content <> ''", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *commentRepository) UpdatePlainContent(id uint, plain string) error {
+	return r.db.Model(&models.Comment{}).Where("id = ?", id).UpdateColumn("plain_content", plain).Error
+}
+
 func (r *commentRepository) ListComments(filters map[string]interface{}) ([]models.Comment, int64, error) {
 	var comments []models.Comment
 
 	query := r.db.Preload("User").
 		Where("parent_id IS NULL").
 		Where("deleted_at IS NULL")
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
 	status, okStatus := filters["status"].(string)
 	if okStatus && status != "" {
 		query = query.Where("status = ?", status)
 	}
-	// Default pagination values
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
 	allowedFilters := map[string]bool{
-		"post_id":   true,
-		"answer_id": true,
+		"post_id":     true,
+		"answer_id":   true,
+		"question_id": true,
 		//"user_id":   true,
 	}
 	if filters != nil {
@@ -118,18 +247,20 @@ func (r *commentRepository) ListComments(filters map[string]interface{}) ([]mode
 		}
 	}
 
-	var total int64
-	if err := query.Model(&models.Comment{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count comments: %v", err)
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Model(&models.Comment{}).Count(&total).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to count comments: %v", err)
+		}
 	}
 
-	offset := (page - 1) * limit
-	err := query.Limit(limit).Offset(offset).Order("created_at DESC").
+	err := paginate(query, filters, "comments.created_at", "desc").
 		Select("comments.*, EXISTS (SELECT 1 FROM comments c WHERE c.parent_id = comments.id AND c.deleted_at IS NULL) AS has_replies").
 		Find(&comments).Error
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to fetch comments: %v", err)
 	}
+	reversePage(comments, filters)
 
 	for i := range comments {
 		var hasReplies bool
@@ -146,30 +277,22 @@ func (r *commentRepository) ListReplies(parentID uint, filters map[string]interf
 	var comments []models.Comment
 	query := r.db.Preload("User").
 		Where("parent_id = ? AND deleted_at IS NULL", parentID)
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
 	status, okStatus := filters["status"].(string)
 	if okStatus && status != "" {
 		query = query.Where("status = ?", status)
 	}
-	// Default pagination values
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	var total int64
-	if err := query.Model(&models.Comment{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count replies: %v", err)
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Model(&models.Comment{}).Count(&total).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to count replies: %v", err)
+		}
 	}
 
-	offset := (page - 1) * limit
-	err := query.Limit(limit).Order("created_at DESC").
-		Offset(offset).Find(&comments).Error
+	err := paginate(query, filters, "created_at", "desc").Find(&comments).Error
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list replies: %v", err)
 	}
+	reversePage(comments, filters)
 
 	for i := range comments {
 		var hasReplies bool
@@ -187,15 +310,7 @@ func (r *commentRepository) GetAllComments(filters map[string]interface{}) ([]mo
 	typefilter, okType := filters["typefilter"].(string)
 	status, okStatus := filters["status"].(string)
 	search, ok := filters["search"].(string)
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
 
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
 	if okStatus && status != "" {
 		query = query.Where("status = ?", status)
 	}
@@ -209,22 +324,26 @@ func (r *commentRepository) GetAllComments(filters map[string]interface{}) ([]mo
 			query = query.Where("post_id IS NOT NULL")
 		case "answer_id":
 			query = query.Where("answer_id IS NOT NULL")
+		case "question_id":
+			query = query.Where("question_id IS NOT NULL")
 		case "parent_id":
 			query = query.Where("parent_id IS NOT NULL")
 		}
 	}
-	var total int64
-	if err := query.Model(&models.Comment{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count all comments: %v", err)
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Model(&models.Comment{}).Count(&total).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to count all comments: %v", err)
+		}
 	}
 
-	offset := (page - 1) * limit
-	query = query.Offset(offset).Limit(limit).Preload("User").Order("created_at DESC").
-		Preload("Post").Preload("Answer").Preload("Parent")
+	query = paginate(query, filters, "created_at", "desc").Preload("User").
+		Preload("Post").Preload("Answer").Preload("Question").Preload("Parent")
 	if err := query.Find(&comments).Error; err != nil {
 		log.Printf("Error fetching comment: %v", err)
 		return nil, 0, err
 	}
+	reversePage(comments, filters)
 
 	for i := range comments {
 		var hasReplies bool
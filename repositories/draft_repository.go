@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+type DraftRepository interface {
+	SaveDraft(draft *models.Draft) error
+	GetDraftByID(id uint) (*models.Draft, error)
+	GetDraftByTarget(userID uint, targetType models.DraftTargetType, targetID uint) (*models.Draft, error)
+	ListDrafts(userID uint, filters map[string]interface{}) ([]models.Draft, int, error)
+	DeleteDraft(id uint) error
+	DeleteDraftsBefore(before time.Time) (int64, error)
+}
+
+type draftRepository struct {
+	db *gorm.DB
+}
+
+func NewDraftRepository(db *gorm.DB) DraftRepository {
+	return &draftRepository{db: db}
+}
+
+// SaveDraft ghi đè bản nháp theo (user, target) và tăng version (last-write-wins).
+// Sau khi lưu, draft được nạp lại với ID và version hiện tại.
+func (r *draftRepository) SaveDraft(draft *models.Draft) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"title":          draft.Title,
+			"content":        draft.Content,
+			"content_format": draft.ContentFormat,
+			"topic_id":       draft.TopicID,
+			"tag_ids":        draft.TagIDs,
+			"version":        gorm.Expr("version + 1"),
+			"updated_at":     time.Now(),
+		}
+		result := tx.Model(&models.Draft{}).
+			Where("user_id = ? AND target_type = ? AND target_id = ?", draft.UserID, draft.TargetType, draft.TargetID).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			draft.Version = 1
+			if err := tx.Create(draft).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("user_id = ? AND target_type = ? AND target_id = ?", draft.UserID, draft.TargetType, draft.TargetID).
+			First(draft).Error
+	})
+}
+
+func (r *draftRepository) GetDraftByID(id uint) (*models.Draft, error) {
+	var draft models.Draft
+	if err := r.db.First(&draft, id).Error; err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (r *draftRepository) GetDraftByTarget(userID uint, targetType models.DraftTargetType, targetID uint) (*models.Draft, error) {
+	var draft models.Draft
+	err := r.db.Where("user_id = ? AND target_type = ? AND target_id = ?", userID, targetType, targetID).First(&draft).Error
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (r *draftRepository) ListDrafts(userID uint, filters map[string]interface{}) ([]models.Draft, int, error) {
+	var drafts []models.Draft
+
+	query := r.db.Model(&models.Draft{}).Where("user_id = ?", userID)
+	if targetType, ok := filters["typefilter"].(string); ok && targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			log.Printf("Error counting drafts: %v", err)
+			return nil, 0, err
+		}
+	}
+
+	if err := paginate(query, filters, "updated_at", "desc").Find(&drafts).Error; err != nil {
+		log.Printf("Error fetching drafts: %v", err)
+		return nil, 0, err
+	}
+	reversePage(drafts, filters)
+	return drafts, int(total), nil
+}
+
+func (r *draftRepository) DeleteDraft(id uint) error {
+	return r.db.Delete(&models.Draft{}, id).Error
+}
+
+func (r *draftRepository) DeleteDraftsBefore(before time.Time) (int64, error) {
+	result := r.db.Where("updated_at < ?", before).Delete(&models.Draft{})
+	return result.RowsAffected, result.Error
+}
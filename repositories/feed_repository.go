@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+type FeedRepository interface {
+	GetTokenByUser(userID uint) (*models.FeedToken, error)
+	GetTokenByValue(token string) (*models.FeedToken, error)
+	SaveToken(token *models.FeedToken) error
+	TouchToken(id uint, at time.Time) error
+
+	ListQuestions(topicID, userID uint, limit int) ([]models.Question, error)
+	ListAnswers(questionID uint, limit int) ([]models.Answer, error)
+	ListTaggedAnswers(tagID uint, limit int) ([]models.Answer, error)
+	ListTaggedPosts(tagID uint, limit int) ([]models.Post, error)
+}
+
+type feedRepository struct {
+	db *gorm.DB
+}
+
+func NewFeedRepository(db *gorm.DB) FeedRepository {
+	return &feedRepository{db: db}
+}
+
+func (r *feedRepository) GetTokenByUser(userID uint) (*models.FeedToken, error) {
+	var token models.FeedToken
+	if err := r.db.Where("user_id = ?", userID).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *feedRepository) GetTokenByValue(value string) (*models.FeedToken, error) {
+	var token models.FeedToken
+	if err := r.db.Where("token = ?", value).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *feedRepository) SaveToken(token *models.FeedToken) error {
+	return r.db.Save(token).Error
+}
+
+func (r *feedRepository) TouchToken(id uint, at time.Time) error {
+	return r.db.Model(&models.FeedToken{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
+
+// ListQuestions trả về câu hỏi đã duyệt và đã đăng (không còn hẹn giờ), mới nhất trước.
+// topicID, userID bằng 0 nghĩa là không lọc; khi lọc theo người dùng thì bỏ qua câu hỏi ẩn danh.
+func (r *feedRepository) ListQuestions(topicID, userID uint, limit int) ([]models.Question, error) {
+	var questions []models.Question
+	query := r.db.Preload("User").Preload("Topic").
+		Where("status = ? AND publish_at IS NULL", models.StatusApproved)
+	if topicID != 0 {
+		query = query.Where("topic_id = ?", topicID)
+	}
+	if userID != 0 {
+		query = query.Where("user_id = ? AND anonymous = ?", userID, false)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&questions).Error
+	return questions, err
+}
+
+func (r *feedRepository) ListAnswers(questionID uint, limit int) ([]models.Answer, error) {
+	var answers []models.Answer
+	err := r.db.Preload("User").Preload("Question").
+		Where("question_id = ? AND status = ?", questionID, "approved").
+		Order("created_at DESC, id DESC").Limit(limit).Find(&answers).Error
+	return answers, err
+}
+
+func (r *feedRepository) ListTaggedAnswers(tagID uint, limit int) ([]models.Answer, error) {
+	var answers []models.Answer
+	err := r.db.Preload("User").Preload("Question").Preload("Tags").
+		Joins("JOIN answer_tags ON answer_tags.answer_id = answers.id").
+		Where("answer_tags.tag_id = ? AND answers.status = ?", tagID, "approved").
+		Order("answers.created_at DESC, answers.id DESC").Limit(limit).Find(&answers).Error
+	return answers, err
+}
+
+func (r *feedRepository) ListTaggedPosts(tagID uint, limit int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Preload("User").Preload("Tags").
+		Joins("JOIN post_tags ON post_tags.post_id = posts.id").
+		Where("post_tags.tag_id = ? AND posts.status = ? AND posts.publish_at IS NULL", tagID, models.Approved).
+		Order("posts.created_at DESC, posts.id DESC").Limit(limit).Find(&posts).Error
+	return posts, err
+}
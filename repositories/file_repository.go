@@ -58,22 +58,18 @@ func (r *fileRepository) ListFiles(filters map[string]interface{}) ([]models.Att
 		query = query.Where("file_type = ?", fileType)
 	}
 
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	if page, ok := filters["page"].(int); ok && page > 0 {
-		query = query.Offset((page - 1) * 10)
-	}
-	if limit, ok := filters["limit"].(int); ok && limit > 0 {
-		query = query.Limit(limit)
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
 	}
 
-	err := query.Find(&files).Error
+	err := paginate(query, filters, "created_at", "desc").Find(&files).Error
 	if err != nil {
 		return nil, 0, err
 	}
+	reversePage(files, filters)
 
 	return files, total, nil
 }
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"gorm.io/gorm"
+)
+
+type MentionRepository interface {
+	SyncMentions(targetType string, targetID, mentionerID uint, mentionerAlias string, userIDs []uint) ([]uint, error)
+	ListMentionsForUser(userID uint, filters map[string]interface{}) ([]models.Mention, int, error)
+}
+
+type mentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) MentionRepository {
+	return &mentionRepository{db: db}
+}
+
+// SyncMentions đồng bộ danh sách người được nhắc của một nội dung với userIDs:
+// tạo bản ghi cho người mới, khôi phục bản ghi đã xoá mềm và xoá mềm những người không còn được nhắc.
+// Trả về ID những người lần đầu được nhắc trong nội dung này.
+func (r *mentionRepository) SyncMentions(targetType string, targetID, mentionerID uint, mentionerAlias string, userIDs []uint) ([]uint, error) {
+	var created []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.Mention
+		if err := tx.Unscoped().Where("target_type = ? AND target_id = ?", targetType, targetID).Find(&existing).Error; err != nil {
+			return err
+		}
+		byUser := make(map[uint]models.Mention, len(existing))
+		for _, mention := range existing {
+			byUser[mention.UserID] = mention
+		}
+
+		wanted := make(map[uint]bool, len(userIDs))
+		for _, userID := range userIDs {
+			wanted[userID] = true
+			mention, ok := byUser[userID]
+			if !ok {
+				if err := tx.Create(&models.Mention{
+					TargetType:     targetType,
+					TargetID:       targetID,
+					UserID:         userID,
+					MentionerID:    mentionerID,
+					MentionerAlias: mentionerAlias,
+				}).Error; err != nil {
+					return err
+				}
+				created = append(created, userID)
+				continue
+			}
+			if mention.DeletedAt.Valid {
+				if err := tx.Unscoped().Model(&models.Mention{}).Where("id = ?", mention.ID).
+					Update("deleted_at", nil).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for userID, mention := range byUser {
+			if !wanted[userID] && !mention.DeletedAt.Valid {
+				if err := tx.Delete(&models.Mention{}, mention.ID).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *mentionRepository) ListMentionsForUser(userID uint, filters map[string]interface{}) ([]models.Mention, int, error) {
+	var mentions []models.Mention
+	var total int64
+
+	query := r.db.Model(&models.Mention{}).Where("user_id = ?", userID)
+	if targetType, ok := filters["type"].(string); ok && targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	total = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := paginate(query, filters, "created_at", "desc").Preload("Mentioner").Find(&mentions).Error; err != nil {
+		return nil, 0, err
+	}
+	reversePage(mentions, filters)
+	return mentions, int(total), nil
+}
@@ -0,0 +1,316 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"strings"
+	"time"
+)
+
+var (
+	ErrModerationClaimed    = errors.New("item is claimed by another moderator")
+	ErrModerationNotPending = errors.New("item has already been reviewed")
+)
+
+type ModerationRepository interface {
+	ListQueue(filters map[string]interface{}) ([]models.ModerationItem, int, error)
+	GetQueueItem(contentType string, contentID uint) (*models.ModerationItem, error)
+	Claim(claim *models.ModerationClaim) error
+	ReleaseClaim(contentType string, contentID uint, moderatorID uint) error
+	ReviewItem(entry *models.ModerationLog) error
+	Stats(filters map[string]interface{}) ([]models.ModerationStat, error)
+}
+
+type moderationRepository struct {
+	db *gorm.DB
+}
+
+func NewModerationRepository(db *gorm.DB) ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+// moderationSources mô tả cách lấy tiêu đề của từng loại nội dung; bình luận không có tiêu đề
+var moderationSources = []struct {
+	contentType string
+	table       string
+	title       string
+}{
+	{models.ModerationQuestion, "questions", "title"},
+	{models.ModerationAnswer, "answers", "title"},
+	{models.ModerationPost, "posts", "title"},
+	{models.ModerationComment, "comments", "''"},
+}
+
+// defaultModerationStatuses là các trạng thái cần người kiểm duyệt xử lý; "spam" chỉ có ở bình luận
+var defaultModerationStatuses = []string{"pending", "spam"}
+
+// queueSource dựng hàng đợi bằng UNION ALL các bảng nội dung, chỉ lấy những loại và trạng thái được lọc
+func (r *moderationRepository) queueSource(contentType string, statuses []string) *gorm.DB {
+	var parts []interface{}
+	for _, src := range moderationSources {
+		if contentType != "" && contentType != src.contentType {
+			continue
+		}
+		parts = append(parts, r.db.Raw(fmt.Sprintf(
+			"SELECT '%[1]s' AS content_type, id AS content_id, CONCAT('%[1]s-', id) AS queue_ref, user_id, %[2]s AS title, "+
+				"LEFT(plain_content, 300) AS content, status, created_at FROM %[3]s WHERE deleted_at IS NULL AND status IN ?",
+			src.contentType, src.title, src.table), statuses))
+	}
+	union := strings.TrimSuffix(strings.Repeat("? UNION ALL ", len(parts)), " UNION ALL ")
+	return r.db.Table("(?) AS queue", r.db.Raw(union, parts...)).
+		Joins("LEFT JOIN moderation_claims mc ON mc.content_type = queue.content_type AND mc.content_id = queue.content_id AND mc.expires_at > ?", time.Now())
+}
+
+func applyQueueFilters(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
+	if userID, ok := filters["user_id"].(uint); ok && userID > 0 {
+		query = query.Where("queue.user_id = ?", userID)
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		query = query.Where("queue.title LIKE ? OR queue.content LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+	// Mặc định ẩn các mục người khác đang giữ để hai người không duyệt trùng
+	moderatorID, _ := filters["moderator_id"].(uint)
+	claimed, _ := filters["claimed"].(string)
+	switch claimed {
+	case "all":
+	case "mine":
+		query = query.Where("mc.moderator_id = ?", moderatorID)
+	case "unclaimed":
+		query = query.Where("mc.id IS NULL")
+	default:
+		query = query.Where("mc.id IS NULL OR mc.moderator_id = ?", moderatorID)
+	}
+	return query
+}
+
+func (r *moderationRepository) ListQueue(filters map[string]interface{}) ([]models.ModerationItem, int, error) {
+	var items []models.ModerationItem
+
+	statuses := defaultModerationStatuses
+	if status, ok := filters["status"].(string); ok && status != "" {
+		statuses = []string{status}
+	}
+	contentType, _ := filters["content_type"].(string)
+
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := applyQueueFilters(r.queueSource(contentType, statuses), filters).Count(&total).Error; err != nil {
+			log.Printf("Error counting moderation queue: %v", err)
+			return nil, 0, err
+		}
+	}
+
+	// Mục chờ lâu nhất lên đầu; queue_ref phân biệt các mục cùng thời điểm giữa các bảng
+	query := applyQueueFilters(r.queueSource(contentType, statuses), filters).
+		Select("queue.*, mc.moderator_id AS claimed_by, mc.expires_at AS claim_expires_at")
+	query = paginateBy(query, filters, "queue.created_at", "queue.queue_ref", "asc")
+	if err := query.Scan(&items).Error; err != nil {
+		log.Printf("Error listing moderation queue: %v", err)
+		return nil, 0, err
+	}
+	reversePage(items, filters)
+
+	if err := r.attachUsers(items); err != nil {
+		return nil, 0, err
+	}
+	return items, int(total), nil
+}
+
+func (r *moderationRepository) GetQueueItem(contentType string, contentID uint) (*models.ModerationItem, error) {
+	var items []models.ModerationItem
+	// Lấy mục ở mọi trạng thái để service phân biệt "không tồn tại" với "đã được xử lý"
+	statuses := append([]string{"approved", "rejected"}, defaultModerationStatuses...)
+	query := r.queueSource(contentType, statuses).
+		Where("queue.content_id = ?", contentID).
+		Select("queue.*, mc.moderator_id AS claimed_by, mc.expires_at AS claim_expires_at")
+	if err := query.Scan(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &items[0], nil
+}
+
+func (r *moderationRepository) attachUsers(items []models.ModerationItem) error {
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.UserID)
+	}
+	users, err := r.usersByID(ids)
+	if err != nil {
+		log.Printf("Error loading moderation queue authors: %v", err)
+		return err
+	}
+	for i := range items {
+		items[i].User = users[items[i].UserID]
+	}
+	return nil
+}
+
+func (r *moderationRepository) usersByID(ids []uint) (map[uint]*models.User, error) {
+	byID := make(map[uint]*models.User, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+	var users []models.User
+	if err := r.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+	return byID, nil
+}
+
+// Claim nhận (hoặc gia hạn) một mục cho người kiểm duyệt; trả về ErrModerationClaimed nếu người khác đang giữ
+func (r *moderationRepository) Claim(claim *models.ModerationClaim) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.ModerationClaim
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("content_type = ? AND content_id = ?", claim.ContentType, claim.ContentID).
+			First(&existing).Error
+		if err == nil {
+			if existing.ModeratorID != claim.ModeratorID && existing.ExpiresAt.After(time.Now()) {
+				return ErrModerationClaimed
+			}
+			claim.ID = existing.ID
+			return tx.Model(&existing).Updates(map[string]interface{}{
+				"moderator_id": claim.ModeratorID,
+				"expires_at":   claim.ExpiresAt,
+				"created_at":   time.Now(),
+			}).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err := tx.Create(claim).Error; err != nil {
+			// Hai người cùng nhận một mục: bản ghi của người kia đã chiếm unique index
+			var count int64
+			tx.Model(&models.ModerationClaim{}).
+				Where("content_type = ? AND content_id = ? AND moderator_id <> ?", claim.ContentType, claim.ContentID, claim.ModeratorID).
+				Count(&count)
+			if count > 0 {
+				return ErrModerationClaimed
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *moderationRepository) ReleaseClaim(contentType string, contentID uint, moderatorID uint) error {
+	result := r.db.Where("content_type = ? AND content_id = ? AND moderator_id = ?", contentType, contentID, moderatorID).
+		Delete(&models.ModerationClaim{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReviewItem khoá dòng nội dung rồi mới kiểm tra lại trạng thái và người giữ mục, sau đó đổi trạng thái,
+// ghi log và xoá lượt nhận trong cùng transaction, để khi hai người cùng duyệt một mục chỉ một người thành công.
+// FromStatus và ItemCreatedAt của entry được điền từ dòng đã khoá.
+func (r *moderationRepository) ReviewItem(entry *models.ModerationLog) error {
+	table := ""
+	for _, src := range moderationSources {
+		if src.contentType == entry.ContentType {
+			table = src.table
+		}
+	}
+	if table == "" {
+		return fmt.Errorf("unknown content type %q", entry.ContentType)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			Status    string
+			CreatedAt time.Time
+		}
+		err := tx.Table(table).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("status, created_at").
+			Where("id = ? AND deleted_at IS NULL", entry.ContentID).
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if rows[0].Status != "pending" && rows[0].Status != "spam" {
+			return ErrModerationNotPending
+		}
+
+		var claimed int64
+		if err := tx.Model(&models.ModerationClaim{}).
+			Where("content_type = ? AND content_id = ? AND moderator_id <> ? AND expires_at > ?", entry.ContentType, entry.ContentID, entry.ModeratorID, time.Now()).
+			Count(&claimed).Error; err != nil {
+			return err
+		}
+		if claimed > 0 {
+			return ErrModerationClaimed
+		}
+
+		if err := tx.Table(table).Where("id = ?", entry.ContentID).Updates(map[string]interface{}{
+			"status":     entry.ToStatus,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		entry.FromStatus = rows[0].Status
+		entry.ItemCreatedAt = rows[0].CreatedAt
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return tx.Where("content_type = ? AND content_id = ?", entry.ContentType, entry.ContentID).
+			Delete(&models.ModerationClaim{}).Error
+	})
+}
+
+func (r *moderationRepository) Stats(filters map[string]interface{}) ([]models.ModerationStat, error) {
+	var stats []models.ModerationStat
+
+	query := r.db.Model(&models.ModerationLog{}).
+		Select("moderator_id, " +
+			"SUM(CASE WHEN action = 'approve' THEN 1 ELSE 0 END) AS approved, " +
+			"SUM(CASE WHEN action = 'reject' THEN 1 ELSE 0 END) AS rejected, " +
+			"COUNT(*) AS total, " +
+			"AVG(TIMESTAMPDIFF(SECOND, item_created_at, created_at)) AS avg_wait_seconds, " +
+			"MIN(created_at) AS first_action_at, MAX(created_at) AS last_action_at")
+	if from, ok := filters["from"].(time.Time); ok {
+		query = query.Where("created_at >= ?", from)
+	}
+	if to, ok := filters["to"].(time.Time); ok {
+		query = query.Where("created_at < ?", to)
+	}
+	if moderatorID, ok := filters["moderator_id"].(uint); ok && moderatorID > 0 {
+		query = query.Where("moderator_id = ?", moderatorID)
+	}
+	if contentType, ok := filters["content_type"].(string); ok && contentType != "" {
+		query = query.Where("content_type = ?", contentType)
+	}
+	if err := query.Group("moderator_id").Order("total DESC").Scan(&stats).Error; err != nil {
+		log.Printf("Error aggregating moderation stats: %v", err)
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(stats))
+	for _, stat := range stats {
+		ids = append(ids, stat.ModeratorID)
+	}
+	moderators, err := r.usersByID(ids)
+	if err != nil {
+		log.Printf("Error loading moderators for stats: %v", err)
+		return nil, err
+	}
+	for i := range stats {
+		stats[i].Moderator = moderators[stats[i].ModeratorID]
+	}
+	return stats, nil
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"Forum_BE/utils"
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+)
+
+const defaultPageLimit = 10
+
+// paginate sắp xếp và phân trang query danh sách theo (column, id).
+// Nếu filters có "cursor" thì dùng keyset (WHERE theo vị trí của cursor) nên không bị trùng hoặc sót
+// khi có bản ghi mới chen vào giữa các lần lấy trang; ngược lại dùng page/limit với OFFSET như trước.
+// Với cursor lùi (trang trước), kết quả được lấy theo thứ tự ngược và cần đảo lại bằng reversePage.
+func paginate(query *gorm.DB, filters map[string]interface{}, column, defaultSort string) *gorm.DB {
+	idColumn := "id"
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		idColumn = column[:i+1] + "id"
+	}
+	return paginateBy(query, filters, column, idColumn, defaultSort)
+}
+
+// paginateBy giống paginate nhưng cho phép column là biểu thức SQL, khi đó cột id phải được chỉ rõ
+func paginateBy(query *gorm.DB, filters map[string]interface{}, column, idColumn, defaultSort string) *gorm.DB {
+	limit, ok := filters["limit"].(int)
+	if !ok || limit < 1 {
+		limit = defaultPageLimit
+	}
+	desc := defaultSort == "desc"
+	if sort, ok := filters["sort"].(string); ok && (sort == "asc" || sort == "desc") {
+		desc = sort == "desc"
+	}
+	cursor, ok := filters["cursor"].(utils.Cursor)
+	if !ok {
+		page, okPage := filters["page"].(int)
+		if !okPage || page < 1 {
+			page = 1
+		}
+		direction := "ASC"
+		if desc {
+			direction = "DESC"
+		}
+		return query.Order(fmt.Sprintf("%s %s, %s %s", column, direction, idColumn, direction)).
+			Offset((page - 1) * limit).
+			Limit(limit)
+	}
+
+	operator, direction := ">", "ASC"
+	if desc != cursor.Backward {
+		operator, direction = "<", "DESC"
+	}
+	var id interface{} = cursor.ID
+	if cursor.Ref != "" {
+		id = cursor.Ref
+	}
+	return query.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", column, operator, column, idColumn, operator),
+		cursor.Value(), cursor.Value(), id).
+		Order(fmt.Sprintf("%s %s, %s %s", column, direction, idColumn, direction)).
+		Limit(limit)
+}
+
+// reversePage đảo lại thứ tự kết quả của trang lấy bằng cursor lùi để trả về đúng thứ tự hiển thị
+func reversePage[T any](items []T, filters map[string]interface{}) {
+	if cursor, ok := filters["cursor"].(utils.Cursor); ok && cursor.Backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+}
+
+// isPaginationKey cho biết key trong filters là tham số phân trang, không phải điều kiện lọc
+func isPaginationKey(key string) bool {
+	switch key {
+	case "limit", "page", "sort", "cursor", "with_total":
+		return true
+	}
+	return false
+}
+
+// wantsTotal cho biết có cần đếm tổng số bản ghi không; mặc định có, trừ khi controller tắt
+// (trang lấy bằng cursor không cần COUNT). Khi không đếm, repository trả về tổng là -1.
+func wantsTotal(filters map[string]interface{}) bool {
+	withTotal, ok := filters["with_total"].(bool)
+	return !ok || withTotal
+}
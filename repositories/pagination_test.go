@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/utils"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB sinh SQL mà không cần kết nối MySQL
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user:pass@tcp(localhost:1)/forum", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, SkipDefaultTransaction: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	return db
+}
+
+func TestPaginate(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	position := 4
+
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		column  string
+		sort    string
+		want    string
+		vars    []interface{}
+	}{
+		{
+			name:    "page and limit use offset",
+			filters: map[string]interface{}{"page": 3, "limit": 20},
+			column:  "created_at", sort: "desc",
+			want: "ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?",
+			vars: []interface{}{20, 40},
+		},
+		{
+			name:    "sort filter overrides default",
+			filters: map[string]interface{}{"sort": "asc"},
+			column:  "created_at", sort: "desc",
+			want: "ORDER BY created_at ASC, id ASC LIMIT ?",
+			vars: []interface{}{defaultPageLimit},
+		},
+		{
+			name:    "forward cursor descending",
+			filters: map[string]interface{}{"sort": "desc", "cursor": utils.Cursor{Time: at, ID: 9, Sort: "desc"}},
+			column:  "created_at", sort: "desc",
+			want: "WHERE (created_at < ? OR (created_at = ? AND id < ?)) ORDER BY created_at DESC, id DESC LIMIT ?",
+			vars: []interface{}{at, at, uint(9), defaultPageLimit},
+		},
+		{
+			name:    "backward cursor descending",
+			filters: map[string]interface{}{"sort": "desc", "cursor": utils.Cursor{Time: at, ID: 9, Sort: "desc", Backward: true}},
+			column:  "created_at", sort: "desc",
+			want: "WHERE (created_at > ? OR (created_at = ? AND id > ?)) ORDER BY created_at ASC, id ASC LIMIT ?",
+			vars: []interface{}{at, at, uint(9), defaultPageLimit},
+		},
+		{
+			name:    "qualified column uses qualified id",
+			filters: map[string]interface{}{"sort": "asc", "cursor": utils.Cursor{Time: at, ID: 9, Sort: "asc"}},
+			column:  "questions.created_at", sort: "asc",
+			want: "WHERE (questions.created_at > ? OR (questions.created_at = ? AND questions.id > ?)) ORDER BY questions.created_at ASC, questions.id ASC",
+			vars: []interface{}{at, at, uint(9), defaultPageLimit},
+		},
+		{
+			name:    "string ref replaces id",
+			filters: map[string]interface{}{"sort": "asc", "cursor": utils.Cursor{Time: at, Ref: "answer:3", Sort: "asc"}},
+			column:  "created_at", sort: "asc",
+			want: "WHERE (created_at > ? OR (created_at = ? AND id > ?))",
+			vars: []interface{}{at, at, "answer:3", defaultPageLimit},
+		},
+		{
+			name:    "position cursor",
+			filters: map[string]interface{}{"sort": "asc", "limit": 5, "cursor": utils.Cursor{Position: &position, ID: 9, Sort: "asc"}},
+			column:  "position", sort: "asc",
+			want: "WHERE (position > ? OR (position = ? AND id > ?)) ORDER BY position ASC, id ASC LIMIT ?",
+			vars: []interface{}{4, 4, uint(9), 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := paginate(dryRunDB(t).Model(&models.Bookmark{}), tt.filters, tt.column, tt.sort).
+				Find(&[]models.Bookmark{}).Statement
+			if sql := stmt.SQL.String(); !strings.Contains(sql, tt.want) {
+				t.Errorf("SQL = %s\nwant it to contain %s", sql, tt.want)
+			}
+			if !reflect.DeepEqual(stmt.Vars, tt.vars) {
+				t.Errorf("vars = %v, want %v", stmt.Vars, tt.vars)
+			}
+		})
+	}
+}
+
+func TestReversePage(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		want    []int
+	}{
+		{"no cursor", map[string]interface{}{}, []int{1, 2, 3}},
+		{"forward cursor", map[string]interface{}{"cursor": utils.Cursor{Sort: "asc"}}, []int{1, 2, 3}},
+		{"backward cursor", map[string]interface{}{"cursor": utils.Cursor{Sort: "asc", Backward: true}}, []int{3, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := []int{1, 2, 3}
+			reversePage(items, tt.filters)
+			for i := range items {
+				if items[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", items, tt.want)
+				}
+			}
+		})
+	}
+}
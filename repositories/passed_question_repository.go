@@ -3,12 +3,26 @@ package repositories
 import (
 	"Forum_BE/models"
 	"gorm.io/gorm"
+	"log"
+	"time"
 )
 
+// Câu hỏi thuộc chủ đề người dùng đánh dấu "không thuộc chuyên môn" bị xếp như thể đăng sớm hơn ngần này
+const notExpertiseDemotion = 72 * time.Hour
+
+// activePassCondition: lần bỏ qua còn hiệu lực (chưa được đưa trở lại và không còn trong thời gian hoãn)
+const activePassCondition = "resurfaced_at IS NULL AND (snooze_until IS NULL OR snooze_until > ?)"
+
 type PassRepository interface {
-	PassQuestion(userID, questionID uint) error
+	PassQuestion(pass *models.PassedQuestion) error
+	GetPass(userID, questionID uint) (*models.PassedQuestion, error)
+	UndoPass(userID, questionID uint) (int64, error)
 	IsPassed(userID, questionID uint) (bool, error)
 	GetPassedQuestionIDs(userID uint) ([]uint, error)
+	ListPasses(userID uint, filters map[string]interface{}) ([]models.PassedQuestion, int, error)
+	GetQuestionsByIDs(ids []uint) ([]models.Question, error)
+	ResurfaceQuestion(questionID uint, reason string, at time.Time) (int64, error)
+	ResurfaceUnanswered(passedBefore, at time.Time) (int64, error)
 }
 
 type passRepository struct {
@@ -19,31 +33,125 @@ func NewPassRepository(db *gorm.DB) PassRepository {
 	return &passRepository{db: db}
 }
 
-func (r *passRepository) PassQuestion(userID, questionID uint) error {
-	pass := &models.PassedQuestion{
-		UserID:     userID,
-		QuestionID: questionID,
+// PassQuestion thay mọi lần bỏ qua trước đó của người dùng với câu hỏi bằng lần mới,
+// nên bỏ qua lại một câu hỏi đã hoãn hoặc đã được đưa trở lại là hợp lệ
+func (r *passRepository) PassQuestion(pass *models.PassedQuestion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND question_id = ?", pass.UserID, pass.QuestionID).
+			Delete(&models.PassedQuestion{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(pass).Error
+	})
+}
+
+func (r *passRepository) GetPass(userID, questionID uint) (*models.PassedQuestion, error) {
+	var pass models.PassedQuestion
+	err := r.db.Where("user_id = ? AND question_id = ?", userID, questionID).
+		Order("id DESC").First(&pass).Error
+	if err != nil {
+		return nil, err
 	}
-	return r.db.Create(pass).Error
+	return &pass, nil
+}
+
+func (r *passRepository) UndoPass(userID, questionID uint) (int64, error) {
+	result := r.db.Where("user_id = ? AND question_id = ?", userID, questionID).Delete(&models.PassedQuestion{})
+	return result.RowsAffected, result.Error
 }
 
 func (r *passRepository) IsPassed(userID, questionID uint) (bool, error) {
 	var count int64
 	err := r.db.Model(&models.PassedQuestion{}).
 		Where("user_id = ? AND question_id = ?", userID, questionID).
+		Where(activePassCondition, time.Now()).
 		Count(&count).Error
 	return count > 0, err
 }
 
 func (r *passRepository) GetPassedQuestionIDs(userID uint) ([]uint, error) {
-	var passed []models.PassedQuestion
-	err := r.db.Where("user_id = ?", userID).Find(&passed).Error
-	if err != nil {
-		return nil, err
-	}
 	var ids []uint
-	for _, p := range passed {
-		ids = append(ids, p.QuestionID)
+	err := r.db.Model(&models.PassedQuestion{}).
+		Where("user_id = ?", userID).
+		Where(activePassCondition, time.Now()).
+		Distinct().Pluck("question_id", &ids).Error
+	return ids, err
+}
+
+// ListPasses liệt kê các lần bỏ qua của người dùng; mặc định chỉ những lần còn đang ẩn câu hỏi,
+// filters["all"] = true để lấy cả những câu hỏi đã hết hạn hoãn hoặc đã được đưa trở lại
+func (r *passRepository) ListPasses(userID uint, filters map[string]interface{}) ([]models.PassedQuestion, int, error) {
+	var passes []models.PassedQuestion
+	query := r.db.Model(&models.PassedQuestion{}).Where("user_id = ?", userID)
+	if all, _ := filters["all"].(bool); !all {
+		query = query.Where(activePassCondition, time.Now())
 	}
-	return ids, nil
+	if kind, ok := filters["kind"].(string); ok && kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			log.Printf("Error counting passed questions: %v", err)
+			return nil, 0, err
+		}
+	}
+
+	if err := paginate(query, filters, "created_at", "desc").Find(&passes).Error; err != nil {
+		log.Printf("Error fetching passed questions: %v", err)
+		return nil, 0, err
+	}
+	reversePage(passes, filters)
+	return passes, int(total), nil
+}
+
+func (r *passRepository) GetQuestionsByIDs(ids []uint) ([]models.Question, error) {
+	var questions []models.Question
+	if len(ids) == 0 {
+		return questions, nil
+	}
+	err := r.db.Preload("User").Preload("Topic").Where("id IN ?", ids).Find(&questions).Error
+	return questions, err
+}
+
+// ResurfaceQuestion đưa câu hỏi trở lại feed của mọi người đã bỏ qua hoặc đang hoãn nó
+func (r *passRepository) ResurfaceQuestion(questionID uint, reason string, at time.Time) (int64, error) {
+	result := r.db.Model(&models.PassedQuestion{}).
+		Where("question_id = ? AND resurfaced_at IS NULL", questionID).
+		Updates(map[string]interface{}{"resurfaced_at": at, "resurface_reason": reason})
+	return result.RowsAffected, result.Error
+}
+
+// ResurfaceUnanswered đưa trở lại các câu hỏi bị bỏ qua trước passedBefore mà vẫn đang mở
+// và chưa có câu trả lời nào được duyệt. Lần hoãn có hạn riêng nên không bị ảnh hưởng.
+func (r *passRepository) ResurfaceUnanswered(passedBefore, at time.Time) (int64, error) {
+	unanswered := r.db.Model(&models.Question{}).Select("questions.id").
+		Where("questions.status = ? AND questions.interaction_status = ?", models.StatusApproved, models.InteractionOpened).
+		Where("NOT EXISTS (?)", r.db.Model(&models.Answer{}).Select("1").
+			Where("answers.question_id = questions.id AND answers.status = ?", "approved"))
+	result := r.db.Model(&models.PassedQuestion{}).
+		Where("resurfaced_at IS NULL AND kind IN ? AND created_at < ?",
+			[]models.PassKind{models.PassKindPass, models.PassKindNotExpertise}, passedBefore).
+		Where("question_id IN (?)", unanswered).
+		Updates(map[string]interface{}{"resurfaced_at": at, "resurface_reason": models.ResurfaceUnanswered})
+	return result.RowsAffected, result.Error
+}
+
+// excludePassed loại các câu hỏi người dùng đang ẩn bằng anti-join (LEFT JOIN ... IS NULL) trên
+// index (user_id, question_id), thay cho NOT IN với danh sách ngày càng dài
+func excludePassed(query *gorm.DB, userID uint, now time.Time) *gorm.DB {
+	return query.
+		Joins("LEFT JOIN passed_questions ON passed_questions.question_id = questions.id AND passed_questions.user_id = ? "+
+			"AND passed_questions.resurfaced_at IS NULL AND (passed_questions.snooze_until IS NULL OR passed_questions.snooze_until > ?)", userID, now).
+		Where("passed_questions.id IS NULL")
+}
+
+// demotedTopicIDs trả về các chủ đề người dùng từng đánh dấu "không thuộc chuyên môn"
+func demotedTopicIDs(db *gorm.DB, userID uint) ([]uint, error) {
+	var ids []uint
+	err := db.Model(&models.PassedQuestion{}).
+		Where("user_id = ? AND kind = ? AND topic_id <> 0", userID, models.PassKindNotExpertise).
+		Distinct().Pluck("topic_id", &ids).Error
+	return ids, err
 }
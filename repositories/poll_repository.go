@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"gorm.io/gorm"
+)
+
+type PollOptionCount struct {
+	OptionID uint
+	Count    int
+}
+
+type PollRepository interface {
+	CreatePoll(poll *models.Poll) error
+	GetPollByID(id uint) (*models.Poll, error)
+	GetPollByPostID(postID uint) (*models.Poll, error)
+	ClosePoll(poll *models.Poll) error
+	DeletePoll(id uint) error
+	CreateBallot(ballot *models.PollBallot, optionIDs []uint) error
+	GetBallot(pollID, userID uint) (*models.PollBallot, error)
+	DeleteBallot(ballot *models.PollBallot) error
+	CountVotes(pollID uint) ([]PollOptionCount, int, error)
+	ListBallots(pollID uint, filters map[string]interface{}) ([]models.PollBallot, int, error)
+}
+
+type pollRepository struct {
+	db *gorm.DB
+}
+
+func NewPollRepository(db *gorm.DB) PollRepository {
+	return &pollRepository{db: db}
+}
+
+func (r *pollRepository) CreatePoll(poll *models.Poll) error {
+	return r.db.Create(poll).Error
+}
+
+func (r *pollRepository) GetPollByID(id uint) (*models.Poll, error) {
+	var poll models.Poll
+	err := r.db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, id ASC")
+	}).First(&poll, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+func (r *pollRepository) GetPollByPostID(postID uint) (*models.Poll, error) {
+	var poll models.Poll
+	err := r.db.Preload("Options", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, id ASC")
+	}).Where("post_id = ?", postID).First(&poll).Error
+	if err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+func (r *pollRepository) ClosePoll(poll *models.Poll) error {
+	return r.db.Model(&models.Poll{}).Where("id = ?", poll.ID).Update("closed_at", poll.ClosedAt).Error
+}
+
+func (r *pollRepository) DeletePoll(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("poll_id = ?", id).Delete(&models.PollBallotChoice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("poll_id = ?", id).Delete(&models.PollBallot{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("poll_id = ?", id).Delete(&models.PollOption{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Poll{}, id).Error
+	})
+}
+
+// CreateBallot ghi lá phiếu và các lựa chọn trong một transaction; unique index (poll_id, user_id) chặn bầu hai lần
+func (r *pollRepository) CreateBallot(ballot *models.PollBallot, optionIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User", "Choices").Create(ballot).Error; err != nil {
+			return err
+		}
+		choices := make([]models.PollBallotChoice, 0, len(optionIDs))
+		for _, optionID := range optionIDs {
+			choices = append(choices, models.PollBallotChoice{BallotID: ballot.ID, OptionID: optionID, PollID: ballot.PollID})
+		}
+		if err := tx.Create(&choices).Error; err != nil {
+			return err
+		}
+		ballot.Choices = choices
+		return nil
+	})
+}
+
+func (r *pollRepository) GetBallot(pollID, userID uint) (*models.PollBallot, error) {
+	var ballot models.PollBallot
+	err := r.db.Preload("Choices").Where("poll_id = ? AND user_id = ?", pollID, userID).First(&ballot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ballot, nil
+}
+
+func (r *pollRepository) DeleteBallot(ballot *models.PollBallot) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ballot_id = ?", ballot.ID).Delete(&models.PollBallotChoice{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.PollBallot{}, ballot.ID).Error
+	})
+}
+
+// CountVotes trả về số phiếu của từng lựa chọn và tổng số người đã bầu
+func (r *pollRepository) CountVotes(pollID uint) ([]PollOptionCount, int, error) {
+	var counts []PollOptionCount
+	err := r.db.Model(&models.PollBallotChoice{}).
+		Select("option_id, COUNT(*) AS count").
+		Where("poll_id = ?", pollID).
+		Group("option_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var voters int64
+	if err := r.db.Model(&models.PollBallot{}).Where("poll_id = ?", pollID).Count(&voters).Error; err != nil {
+		return nil, 0, err
+	}
+	return counts, int(voters), nil
+}
+
+func (r *pollRepository) ListBallots(pollID uint, filters map[string]interface{}) ([]models.PollBallot, int, error) {
+	var total int64 = -1
+	query := r.db.Model(&models.PollBallot{}).Where("poll_id = ?", pollID)
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var ballots []models.PollBallot
+	err := paginate(query, filters, "created_at", "desc").Preload("User").Preload("Choices").
+		Find(&ballots).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	reversePage(ballots, filters)
+	return ballots, int(total), nil
+}
@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"Forum_BE/models"
-	"Forum_BE/utils"
 	"gorm.io/gorm"
 	"log"
 	"time"
@@ -12,11 +11,16 @@ type PostRepository interface {
 	CreatePost(post *models.Post, tagIds []uint) error
 	GetPostByID(id uint) (*models.Post, error)
 	GetPostByIDSimple(id uint) (*models.Post, error)
+	GetPostIDBySlug(slug string) (uint, error)
 	UpdatePost(post *models.Post, tagId []uint) error
 	UpdatePostStatus(id uint, status string) error
 	DeletePost(id uint) error
 	List(filters map[string]interface{}) ([]models.Post, int, error)
 	GetAllPosts(filters map[string]interface{}) ([]models.Post, int, error)
+	ListScheduledPosts(userID uint) ([]models.Post, error)
+	ListDueScheduledPosts(now time.Time) ([]models.Post, error)
+	UpdatePostPublishAt(id uint, publishAt time.Time) error
+	MarkPostPublished(id uint, publishedAt time.Time) error
 }
 
 type postRepository struct {
@@ -28,7 +32,6 @@ func NewPostRepository(db *gorm.DB) PostRepository {
 }
 
 func (r *postRepository) CreatePost(post *models.Post, tagIds []uint) error {
-	post.PlainContent = utils.StripHTML(post.Content)
 	tx := r.db.Begin()
 	if err := tx.Error; err != nil {
 		return err
@@ -39,6 +42,17 @@ func (r *postRepository) CreatePost(post *models.Post, tagIds []uint) error {
 		return err
 	}
 
+	slug, err := assignSlug(tx, models.SlugTargetPost, post.ID, postSlugTitle(post), "", "bai-viet")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	post.Slug = slug
+	if err := tx.Model(post).UpdateColumn("slug", slug).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if len(tagIds) > 0 {
 		var tags []models.Tag
 		if err := tx.Where("id IN ?", tagIds).Find(&tags).Error; err != nil {
@@ -73,13 +87,23 @@ func (r *postRepository) GetPostByIDSimple(id uint) (*models.Post, error) {
 	return &post, nil
 }
 
+func (r *postRepository) GetPostIDBySlug(slug string) (uint, error) {
+	return resolveSlug(r.db, models.SlugTargetPost, slug)
+}
+
 func (r *postRepository) UpdatePost(post *models.Post, tagId []uint) error {
-	post.PlainContent = utils.StripHTML(post.Content)
 	tx := r.db.Begin()
 	if err := tx.Error; err != nil {
 		return err
 	}
 
+	slug, err := assignSlug(tx, models.SlugTargetPost, post.ID, postSlugTitle(post), post.Slug, "bai-viet")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	post.Slug = slug
+
 	if err := tx.Save(post).Error; err != nil {
 		tx.Rollback()
 		return err
@@ -114,43 +138,35 @@ func (r *postRepository) DeletePost(id uint) error {
 func (r *postRepository) List(filters map[string]interface{}) ([]models.Post, int, error) {
 	var posts []models.Post
 
-	// Process pagination parameters
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
 	// Build count query
-	countQuery := r.db.Model(&models.Post{})
+	countQuery := r.db.Model(&models.Post{}).Where("publish_at IS NULL")
 	for key, value := range filters {
-		if key != "limit" && key != "page" {
+		if !isPaginationKey(key) {
 			countQuery = countQuery.Where(key, value)
 		}
 	}
-	var total int64
-	if err := countQuery.Count(&total).Error; err != nil {
-		log.Printf("Error counting posts: %v", err)
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := countQuery.Count(&total).Error; err != nil {
+			log.Printf("Error counting posts: %v", err)
+			return nil, 0, err
+		}
 	}
 
 	// Build data query
-	query := r.db.Preload("User").Preload("Tags").Preload("Comments")
+	query := r.db.Preload("User").Preload("Tags").Preload("Comments").Where("publish_at IS NULL")
 	for key, value := range filters {
-		if key != "limit" && key != "page" {
+		if !isPaginationKey(key) {
 			query = query.Where(key, value)
 		}
 	}
 
-	query = query.Offset(offset).Limit(limit).Order("created_at DESC")
+	query = paginate(query, filters, "created_at", "desc")
 	if err := query.Find(&posts).Error; err != nil {
 		log.Printf("Error fetching posts: %v", err)
 		return nil, 0, err
 	}
+	reversePage(posts, filters)
 
 	log.Printf("Found %d posts with total %d", len(posts), total)
 	return posts, int(total), nil
@@ -176,40 +192,56 @@ func (r *postRepository) GetAllPosts(filters map[string]interface{}) ([]models.P
 			Where("post_tags.tag_id IN ?", tagIDs).
 			Group("posts.id")
 	}
-	sortOrder := "created_at DESC" // mặc định
-	if s, ok := filters["sort"].(string); ok {
-		if s == "asc" {
-			sortOrder = "created_at ASC"
-		} else if s == "desc" {
-			sortOrder = "created_at DESC"
-		}
-	}
-
-	// Process pagination
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
 	// Count total
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		log.Printf("Error counting posts: %v", err)
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			log.Printf("Error counting posts: %v", err)
+			return nil, 0, err
+		}
 	}
 
 	// Fetch data
-	query = query.Offset(offset).Limit(limit).Preload("User").Preload("Tags").Preload("Comments").Order(sortOrder)
+	query = paginate(query, filters, "posts.created_at", "desc").Preload("User").Preload("Tags").Preload("Comments")
 	if err := query.Find(&posts).Error; err != nil {
 		log.Printf("Error fetching posts: %v", err)
 		return nil, 0, err
 	}
+	reversePage(posts, filters)
 
 	log.Printf("Found %d posts with total %d", len(posts), total)
 	return posts, int(total), nil
 }
+
+// ListScheduledPosts trả về các bài viết đang hẹn giờ đăng; userID = 0 để lấy tất cả
+func (r *postRepository) ListScheduledPosts(userID uint) ([]models.Post, error) {
+	var posts []models.Post
+	query := r.db.Preload("User").Preload("Tags").Where("publish_at IS NOT NULL")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("publish_at ASC").Find(&posts).Error
+	return posts, err
+}
+
+func (r *postRepository) ListDueScheduledPosts(now time.Time) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Where("publish_at IS NOT NULL AND publish_at <= ?", now).Order("publish_at ASC").Find(&posts).Error
+	return posts, err
+}
+
+func (r *postRepository) UpdatePostPublishAt(id uint, publishAt time.Time) error {
+	return r.db.Model(&models.Post{}).Where("id = ? AND publish_at IS NOT NULL", id).Updates(map[string]interface{}{
+		"publish_at": publishAt,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// MarkPostPublished hiển thị bài viết và đặt created_at theo thời điểm đăng để nó nằm đầu danh sách
+func (r *postRepository) MarkPostPublished(id uint, publishedAt time.Time) error {
+	return r.db.Model(&models.Post{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"publish_at": nil,
+		"created_at": publishedAt,
+		"updated_at": time.Now(),
+	}).Error
+}
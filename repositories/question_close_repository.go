@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	ErrCloseVoteExists      = errors.New("close vote already exists")
+	ErrQuestionStateCurrent = errors.New("question is already in the requested state")
+)
+
+type QuestionCloseRepository interface {
+	AddVote(vote *models.QuestionCloseVote) error
+	DeleteVote(questionID, userID uint, voteType models.CloseVoteType) (int64, error)
+	ListVotes(questionID uint, voteType models.CloseVoteType) ([]models.QuestionCloseVote, error)
+	HasVoted(questionID, userID uint, voteType models.CloseVoteType) (bool, error)
+	ApplyStateChange(questionID uint, updates map[string]interface{}, clearVotes models.CloseVoteType, event *models.QuestionEvent) error
+	ListEvents(questionID uint) ([]models.QuestionEvent, error)
+	ListExpiredLocks(now time.Time) ([]models.Question, error)
+}
+
+type questionCloseRepository struct {
+	db *gorm.DB
+}
+
+func NewQuestionCloseRepository(db *gorm.DB) QuestionCloseRepository {
+	return &questionCloseRepository{db: db}
+}
+
+// AddVote trả về ErrCloseVoteExists khi người dùng đã có phiếu cùng loại (kể cả khi hai yêu cầu gửi cùng lúc)
+func (r *questionCloseRepository) AddVote(vote *models.QuestionCloseVote) error {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(vote)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCloseVoteExists
+	}
+	return nil
+}
+
+func (r *questionCloseRepository) DeleteVote(questionID, userID uint, voteType models.CloseVoteType) (int64, error) {
+	result := r.db.Where("question_id = ? AND user_id = ? AND vote_type = ?", questionID, userID, voteType).
+		Delete(&models.QuestionCloseVote{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *questionCloseRepository) ListVotes(questionID uint, voteType models.CloseVoteType) ([]models.QuestionCloseVote, error) {
+	var votes []models.QuestionCloseVote
+	err := r.db.Where("question_id = ? AND vote_type = ?", questionID, voteType).
+		Order("created_at ASC").
+		Find(&votes).Error
+	return votes, err
+}
+
+func (r *questionCloseRepository) HasVoted(questionID, userID uint, voteType models.CloseVoteType) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.QuestionCloseVote{}).
+		Where("question_id = ? AND user_id = ? AND vote_type = ?", questionID, userID, voteType).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ApplyStateChange cập nhật trạng thái câu hỏi, xoá phiếu bầu đã dùng (nếu có) và ghi sự kiện vào dòng thời gian trong một transaction.
+// Khi đổi interaction_status, chỉ lần cập nhật thật sự đổi trạng thái được ghi; các lần đồng thời khác nhận ErrQuestionStateCurrent.
+func (r *questionCloseRepository) ApplyStateChange(questionID uint, updates map[string]interface{}, clearVotes models.CloseVoteType, event *models.QuestionEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates["updated_at"] = time.Now()
+		query := tx.Model(&models.Question{}).Where("id = ?", questionID)
+		status, changesStatus := updates["interaction_status"]
+		if changesStatus {
+			query = query.Where("interaction_status <> ?", status)
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if changesStatus && result.RowsAffected == 0 {
+			return ErrQuestionStateCurrent
+		}
+		if clearVotes != "" {
+			if err := tx.Where("question_id = ? AND vote_type = ?", questionID, clearVotes).
+				Delete(&models.QuestionCloseVote{}).Error; err != nil {
+				return err
+			}
+		}
+		event.QuestionID = questionID
+		return tx.Create(event).Error
+	})
+}
+
+func (r *questionCloseRepository) ListEvents(questionID uint) ([]models.QuestionEvent, error) {
+	var events []models.QuestionEvent
+	err := r.db.Preload("Actor").
+		Where("question_id = ?", questionID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	return events, err
+}
+
+func (r *questionCloseRepository) ListExpiredLocks(now time.Time) ([]models.Question, error) {
+	var questions []models.Question
+	err := r.db.Where("locked_at IS NOT NULL AND locked_until IS NOT NULL AND locked_until <= ?", now).
+		Find(&questions).Error
+	return questions, err
+}
@@ -2,10 +2,12 @@ package repositories
 
 import (
 	"Forum_BE/models"
+	"Forum_BE/utils"
 	"fmt"
 	"gorm.io/gorm"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,9 +22,22 @@ type QuestionRepository interface {
 	UpdateInteractionStatus(id uint, status string) error
 	UpdateQuestionStatus(id uint, status string) error
 	GetQuestionByIDMinimal(id uint) (*models.Question, error)
+	GetQuestionIDBySlug(slug string) (uint, error)
 	GetAllQuestion(filters map[string]interface{}) ([]models.Question, int, error)
 	GetQuestionsByIDs(ids []int) ([]models.Question, error)
 	GetApprovedQuestions() ([]models.Question, error)
+	ListScheduledQuestions(userID uint) ([]models.Question, error)
+	ListDueScheduledQuestions(now time.Time) ([]models.Question, error)
+	UpdateQuestionPublishAt(id uint, publishAt time.Time) error
+	MarkQuestionPublished(id uint, publishedAt time.Time) error
+	ListMissingPlainContent(afterID uint, limit int) ([]PlainContentRow, error)
+	UpdatePlainContent(id uint, plain string) error
+}
+
+// PlainContentRow là nội dung HTML của một bản ghi chưa có plain_content, dùng khi bổ sung dữ liệu cũ
+type PlainContentRow struct {
+	ID      uint
+	Content string
 }
 
 type questionRepository struct {
@@ -34,7 +49,23 @@ func NewQuestionRepository(db *gorm.DB) QuestionRepository {
 }
 
 func (r *questionRepository) CreateQuestion(question *models.Question) error {
-	return r.db.Create(question).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(question).Error; err != nil {
+			return err
+		}
+		slug, err := assignSlug(tx, models.SlugTargetQuestion, question.ID, question.Title, "", "cau-hoi")
+		if err != nil {
+			return err
+		}
+		question.Slug = slug
+		columns := map[string]interface{}{"slug": slug}
+		if question.Anonymous {
+			// Bí danh phụ thuộc ID nên chỉ tính được sau khi tạo
+			question.Pseudonym = utils.Pseudonym(utils.QuestionThread(question.ID), question.UserID)
+			columns["pseudonym"] = question.Pseudonym
+		}
+		return tx.Model(question).UpdateColumns(columns).Error
+	})
 }
 
 func (r *questionRepository) GetQuestionByID(id uint) (*models.Question, error) {
@@ -59,8 +90,19 @@ func (r *questionRepository) GetQuestionByIDMinimal(id uint) (*models.Question,
 	return &question, nil
 }
 
+func (r *questionRepository) GetQuestionIDBySlug(slug string) (uint, error) {
+	return resolveSlug(r.db, models.SlugTargetQuestion, slug)
+}
+
 func (r *questionRepository) UpdateQuestion(question *models.Question) error {
-	return r.db.Save(question).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		slug, err := assignSlug(tx, models.SlugTargetQuestion, question.ID, question.Title, question.Slug, "cau-hoi")
+		if err != nil {
+			return err
+		}
+		question.Slug = slug
+		return tx.Save(question).Error
+	})
 }
 
 func (r *questionRepository) DeleteQuestion(id uint) error {
@@ -69,29 +111,8 @@ func (r *questionRepository) DeleteQuestion(id uint) error {
 
 func (r *questionRepository) ListQuestions(filters map[string]interface{}) ([]models.Question, int, error) {
 	var questions []models.Question
-	// Process pagination parameters
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
-	// Process sort parameter
-	sortOrder := "created_at DESC"
-	if sort, ok := filters["sort"].(string); ok {
-		if sort == "asc" {
-			sortOrder = "created_at ASC"
-		} else if sort == "desc" {
-			sortOrder = "created_at DESC"
-		}
-	}
-
 	// Query for counting total
-	countQuery := r.db.Model(&models.Question{})
+	countQuery := r.db.Model(&models.Question{}).Where("publish_at IS NULL")
 	if search, ok := filters["title_search"]; ok {
 		countQuery = countQuery.Where("title LIKE ?", "%"+search.(string)+"%")
 	}
@@ -113,14 +134,17 @@ func (r *questionRepository) ListQuestions(filters map[string]interface{}) ([]mo
 		}
 	}
 
-	var total int64
-	if err := countQuery.Count(&total).Error; err != nil {
-		log.Printf("Error counting questions: %v", err)
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := countQuery.Count(&total).Error; err != nil {
+			log.Printf("Error counting questions: %v", err)
+			return nil, 0, err
+		}
 	}
 
 	// Apply filters and pagination
-	query := r.db.Model(&models.Question{}).Preload("User").Preload("Topic").Preload("Answers").Preload("Follows")
+	query := r.db.Model(&models.Question{}).Preload("User").Preload("Topic").Preload("Answers").Preload("Follows").
+		Where("publish_at IS NULL")
 	if search, ok := filters["title_search"]; ok {
 		query = query.Where("title LIKE ?", "%"+search.(string)+"%")
 	}
@@ -141,39 +165,19 @@ func (r *questionRepository) ListQuestions(filters map[string]interface{}) ([]mo
 			query = query.Where("topic_id IN ?", topicIDList)
 		}
 	}
-	query = query.Offset(offset).Limit(limit).Order(sortOrder)
+	query = paginate(query, filters, "created_at", "desc")
 	err := query.Find(&questions).Error
 	if err != nil {
 		log.Printf("Error fetching questions: %v", err)
 		return nil, 0, err
 	}
+	reversePage(questions, filters)
 	log.Printf("Found %d questions with total %d", len(questions), total)
 	return questions, int(total), nil
 }
 
 func (r *questionRepository) GetAllQuestion(filters map[string]interface{}) ([]models.Question, int, error) {
 	var questions []models.Question
-	// Process pagination parameters
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
-	// Process sort parameter
-	sortOrder := "created_at DESC"
-	if sort, ok := filters["sort"].(string); ok {
-		if sort == "asc" {
-			sortOrder = "created_at ASC"
-		} else if sort == "desc" {
-			sortOrder = "created_at DESC"
-		}
-	}
-
 	// Query for counting total
 	countQuery := r.db.Model(&models.Question{})
 	if search, ok := filters["title_search"]; ok {
@@ -199,11 +203,16 @@ func (r *questionRepository) GetAllQuestion(filters map[string]interface{}) ([]m
 	if user_id, okUserId := filters["user_id"]; okUserId {
 		countQuery = countQuery.Where("user_id = ?", user_id)
 	}
+	if exclude, _ := filters["exclude_anonymous"].(bool); exclude {
+		countQuery = countQuery.Where("anonymous = ?", false)
+	}
 
-	var total int64
-	if err := countQuery.Count(&total).Error; err != nil {
-		log.Printf("Error counting questions: %v", err)
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := countQuery.Count(&total).Error; err != nil {
+			log.Printf("Error counting questions: %v", err)
+			return nil, 0, err
+		}
 	}
 
 	// Apply filters and pagination
@@ -231,113 +240,116 @@ func (r *questionRepository) GetAllQuestion(filters map[string]interface{}) ([]m
 	if user_id, okUserId := filters["user_id"]; okUserId {
 		query = query.Where("user_id = ?", user_id)
 	}
-	query = query.Offset(offset).Limit(limit).Order(sortOrder)
+	// Trang hồ sơ công khai không được lộ câu hỏi ẩn danh của người dùng
+	if exclude, _ := filters["exclude_anonymous"].(bool); exclude {
+		query = query.Where("anonymous = ?", false)
+	}
+	query = paginate(query, filters, "created_at", "desc")
 	err := query.Find(&questions).Error
 	if err != nil {
 		log.Printf("Error fetching questions: %v", err)
 		return nil, 0, err
 	}
+	reversePage(questions, filters)
 	log.Printf("Found %d questions with total %d", len(questions), total)
 	return questions, int(total), nil
 }
 
+// ListQuestionsExcludingPassed là feed cá nhân: loại câu hỏi người dùng đang ẩn (bỏ qua, hoãn)
+// và hạ hạng câu hỏi thuộc chủ đề họ đánh dấu "không thuộc chuyên môn"
 func (r *questionRepository) ListQuestionsExcludingPassed(filters map[string]interface{}) ([]models.Question, int, error) {
 	var questions []models.Question
-	// Process pagination parameters
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
-	// Process sort parameter
-	sortOrder := "created_at DESC"
-	if sort, ok := filters["sort"].(string); ok {
-		if sort == "asc" {
-			sortOrder = "created_at ASC"
-		} else if sort == "desc" {
-			sortOrder = "created_at DESC"
-		}
-	}
-
 	// Extract user_id for passed questions filtering
 	userID, ok := filters["user_id"].(uint)
 	if !ok || userID == 0 {
 		return nil, 0, fmt.Errorf("user_id is required for excluding passed questions")
 	}
+	now := time.Now()
 
-	// Query for counting total
-	countQuery := r.db.Model(&models.Question{})
-	countQuery = countQuery.Where("questions.id NOT IN (?)",
-		r.db.Table("passed_questions").Select("question_id").Where("user_id = ?", userID))
-	if search, ok := filters["title_search"]; ok {
-		countQuery = countQuery.Where("title LIKE ?", "%"+search.(string)+"%")
-	}
-	if status, ok := filters["status"]; ok {
-		countQuery = countQuery.Where("status = ?", status)
-	}
-	if interstatus, ok := filters["interstatus"]; ok {
-		countQuery = countQuery.Where("interaction_status = ?", interstatus)
-	}
-	if topicIDs, ok := filters["topic_id"].([]string); ok && len(topicIDs) > 0 {
-		topicIDList := make([]uint, 0, len(topicIDs))
-		for _, id := range topicIDs {
-			if topicID, err := strconv.ParseUint(id, 10, 64); err == nil {
-				topicIDList = append(topicIDList, uint(topicID))
+	// Các cột đều ghi rõ bảng vì passed_questions được join vào và cũng có topic_id, created_at
+	applyFilters := func(query *gorm.DB) *gorm.DB {
+		query = excludePassed(query.Where("questions.publish_at IS NULL"), userID, now)
+		if search, ok := filters["title_search"]; ok {
+			query = query.Where("questions.title LIKE ?", "%"+search.(string)+"%")
+		}
+		if status, ok := filters["status"]; ok {
+			query = query.Where("questions.status = ?", status)
+		}
+		if interstatus, ok := filters["interstatus"]; ok {
+			query = query.Where("questions.interaction_status = ?", interstatus)
+		}
+		if topicIDs, ok := filters["topic_id"].([]string); ok && len(topicIDs) > 0 {
+			topicIDList := make([]uint, 0, len(topicIDs))
+			for _, id := range topicIDs {
+				if topicID, err := strconv.ParseUint(id, 10, 64); err == nil {
+					topicIDList = append(topicIDList, uint(topicID))
+				}
+			}
+			if len(topicIDList) > 0 {
+				query = query.Where("questions.topic_id IN ?", topicIDList)
 			}
 		}
-		if len(topicIDList) > 0 {
-			countQuery = countQuery.Where("topic_id IN ?", topicIDList)
+		return query
+	}
+
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := applyFilters(r.db.Model(&models.Question{})).Count(&total).Error; err != nil {
+			log.Printf("Error counting questions excluding passed: %v", err)
+			return nil, 0, err
 		}
 	}
 
-	var total int64
-	if err := countQuery.Count(&total).Error; err != nil {
-		log.Printf("Error counting questions excluding passed: %v", err)
-		return nil, 0, err
+	demoted, err := demotedTopicIDs(r.db, userID)
+	if err != nil {
+		log.Printf("Error loading demoted topics for user %d: %v", userID, err)
+		demoted = nil
 	}
 
 	// Apply filters and pagination
-	query := r.db.Model(&models.Question{}).Preload("User").Preload("Topic").Preload("Answers").Preload("Follows")
-	query = query.Where("questions.id NOT IN (?)",
-		r.db.Table("passed_questions").Select("question_id").Where("user_id = ?", userID))
-	if search, ok := filters["title_search"]; ok {
-		query = query.Where("title LIKE ?", "%"+search.(string)+"%")
-	}
-	if status, ok := filters["status"]; ok {
-		query = query.Where("status = ?", status)
-	}
-	if interstatus, ok := filters["interstatus"]; ok {
-		query = query.Where("interaction_status = ?", interstatus)
-	}
-	if topicIDs, ok := filters["topic_id"].([]string); ok && len(topicIDs) > 0 {
-		topicIDList := make([]uint, 0, len(topicIDs))
-		for _, id := range topicIDs {
-			if topicID, err := strconv.ParseUint(id, 10, 64); err == nil {
-				topicIDList = append(topicIDList, uint(topicID))
-			}
-		}
-		if len(topicIDList) > 0 {
-			query = query.Where("topic_id IN ?", topicIDList)
+	query := applyFilters(r.db.Model(&models.Question{}).Preload("User").Preload("Topic").Preload("Answers").Preload("Follows"))
+	if len(demoted) > 0 {
+		// Xếp theo thời điểm "hiệu lực": câu hỏi ở chủ đề bị hạ được coi như đăng sớm hơn notExpertiseDemotion.
+		// Cursor của trang lấy theo FeedRankAt nên phân trang keyset vẫn đúng.
+		ids := make([]string, len(demoted))
+		for i, id := range demoted {
+			ids[i] = strconv.FormatUint(uint64(id), 10)
 		}
+		rank := fmt.Sprintf("(CASE WHEN questions.topic_id IN (%s) THEN questions.created_at - INTERVAL %d SECOND ELSE questions.created_at END)",
+			strings.Join(ids, ","), int(notExpertiseDemotion.Seconds()))
+		query = paginateBy(query, filters, rank, "questions.id", "desc")
+	} else {
+		query = paginate(query, filters, "questions.created_at", "desc")
 	}
-	query = query.Offset(offset).Limit(limit).Order(sortOrder)
-	err := query.Find(&questions).Error
-	if err != nil {
+	if err := query.Find(&questions).Error; err != nil {
 		log.Printf("Error fetching questions excluding passed: %v", err)
 		return nil, 0, err
 	}
+	reversePage(questions, filters)
+
+	if len(demoted) > 0 {
+		isDemoted := make(map[uint]bool, len(demoted))
+		for _, id := range demoted {
+			isDemoted[id] = true
+		}
+		for i := range questions {
+			rankAt := questions[i].CreatedAt
+			if isDemoted[questions[i].TopicID] {
+				rankAt = rankAt.Add(-notExpertiseDemotion)
+			}
+			questions[i].FeedRankAt = &rankAt
+		}
+	}
 	log.Printf("Found %d questions excluding passed with total %d", len(questions), total)
 	return questions, int(total), nil
 }
 
 func (r *questionRepository) GetPassedQuestionIDs(userID uint) ([]uint, error) {
 	var ids []uint
-	err := r.db.Table("passed_questions").Where("user_id = ?", userID).Pluck("question_id", &ids).Error
+	err := r.db.Model(&models.PassedQuestion{}).
+		Where("user_id = ?", userID).
+		Where(activePassCondition, time.Now()).
+		Distinct().Pluck("question_id", &ids).Error
 	if err != nil {
 		return nil, err
 	}
@@ -392,3 +404,53 @@ func (r *questionRepository) GetApprovedQuestions() ([]models.Question, error) {
 	err := r.db.Where("status = ?", models.StatusApproved).Find(&questions).Error
 	return questions, err
 }
+
+// ListScheduledQuestions trả về các câu hỏi đang hẹn giờ đăng; userID = 0 để lấy tất cả
+func (r *questionRepository) ListScheduledQuestions(userID uint) ([]models.Question, error) {
+	var questions []models.Question
+	query := r.db.Preload("User").Preload("Topic").Where("publish_at IS NOT NULL")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("publish_at ASC").Find(&questions).Error
+	return questions, err
+}
+
+func (r *questionRepository) ListDueScheduledQuestions(now time.Time) ([]models.Question, error) {
+	var questions []models.Question
+	err := r.db.Where("publish_at IS NOT NULL AND publish_at <= ?", now).Order("publish_at ASC").Find(&questions).Error
+	return questions, err
+}
+
+func (r *questionRepository) UpdateQuestionPublishAt(id uint, publishAt time.Time) error {
+	return r.db.Model(&models.Question{}).Where("id = ? AND publish_at IS NOT NULL", id).Updates(map[string]interface{}{
+		"publish_at": publishAt,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// MarkQuestionPublished hiển thị câu hỏi và đặt created_at theo thời điểm đăng để nó nằm đầu danh sách
+func (r *questionRepository) MarkQuestionPublished(id uint, publishedAt time.Time) error {
+	return r.db.Model(&models.Question{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"publish_at": nil,
+		"created_at": publishedAt,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// ListMissingPlainContent trả về các câu hỏi tạo trước khi có cột plain_content
+func (r *questionRepository) ListMissingPlainContent(afterID uint, limit int) ([]PlainContentRow, error) {
+	var rows []PlainContentRow
+	err := r.db.Model(&models.Question{}).
+		Select("id, description AS content").
+		Where("id > ? AND (plain_content IS NULL OR plain_content = '') AND description <> ''", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// UpdatePlainContent không đổi updated_at vì nội dung câu hỏi không thay đổi
+func (r *questionRepository) UpdatePlainContent(id uint, plain string) error {
+	return r.db.Model(&models.Question{}).Where("id = ?", id).UpdateColumn("plain_content", plain).Error
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"gorm.io/gorm"
 	"log"
+	"time"
 )
 
 type ReactionRepository interface {
@@ -14,8 +15,22 @@ type ReactionRepository interface {
 	UpdateReaction(reaction *models.Reaction) error
 	DeleteReaction(id uint) error
 	ListReactions(filters map[string]interface{}) ([]models.Reaction, int, error)
-	GetReactionCount(postID, commentID, answerID *uint) (int64, error)
-	ValidateReactionID(postID, commentID, answerID *uint) error
+	GetReactionCount(postID, commentID, answerID, questionID *uint) (models.ReactionCounts, error)
+	CountByTargets(targetType models.ReactionTargetType, targetIDs []uint) ([]ReactionTypeCount, error)
+	ValidateReactionID(postID, commentID, answerID, questionID *uint) error
+	ReactionEdges(since time.Time) ([]VoteEdge, error)
+	ReactionsBetween(userID, authorID uint, since time.Time) ([]models.Reaction, error)
+	InvalidateReactions(ids []uint) error
+}
+
+// reactionAuthorExpr là tác giả của nội dung nhận tương tác
+const reactionAuthorExpr = "COALESCE(p.user_id, c.user_id, a.user_id, q.user_id)"
+
+// ReactionTypeCount là số tương tác của một loại trên một nội dung
+type ReactionTypeCount struct {
+	TargetID uint
+	Type     string
+	Count    int64
 }
 
 type reactionRepository struct {
@@ -45,7 +60,7 @@ func (r *reactionRepository) GetReactionByID(id uint) (*models.Reaction, error)
 	log.Printf("Getting reaction %d with db: %v", id, r.db != nil)
 	var reaction models.Reaction
 	query := r.db.Preload("User").Where("deleted_at IS NULL")
-	if err := query.Preload("Post").Preload("Comment").Preload("Answer").First(&reaction, id).Error; err != nil {
+	if err := query.Preload("Post").Preload("Comment").Preload("Answer").Preload("Question").First(&reaction, id).Error; err != nil {
 		return nil, err
 	}
 	return &reaction, nil
@@ -75,7 +90,7 @@ func (r *reactionRepository) ListReactions(filters map[string]interface{}) ([]mo
 	}
 	log.Printf("Listing reactions with db: %v", r.db != nil)
 	var reactions []models.Reaction
-	query := r.db.Model(&models.Reaction{}).Preload("User").Preload("Post").Preload("Comment").Preload("Answer").Where("deleted_at IS NULL")
+	query := r.db.Model(&models.Reaction{}).Preload("User").Preload("Post").Preload("Comment").Preload("Answer").Preload("Question").Where("deleted_at IS NULL")
 
 	if userID, ok := filters["user_id"].(uint); ok {
 		query = query.Where("user_id = ?", userID)
@@ -89,52 +104,72 @@ func (r *reactionRepository) ListReactions(filters map[string]interface{}) ([]mo
 	if answerID, ok := filters["answer_id"].(uint); ok {
 		query = query.Where("answer_id = ?", answerID)
 	}
-
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
-	if !okPage || page < 1 {
-		page = 1
+	if questionID, ok := filters["question_id"].(uint); ok {
+		query = query.Where("question_id = ?", questionID)
 	}
-	if !okLimit || limit < 1 {
-		limit = 10
+	if reactionType, ok := filters["type"].(string); ok && reactionType != "" {
+		query = query.Where("type = ?", reactionType)
 	}
 
-	var total int64
-	if err := query.Model(&models.Reaction{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Model(&models.Reaction{}).Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
 	}
 
-	offset := (page - 1) * limit
-	if err := query.Limit(limit).Offset(offset).Order("created_at DESC").Find(&reactions).Error; err != nil {
+	if err := paginate(query, filters, "created_at", "desc").Find(&reactions).Error; err != nil {
 		return nil, 0, err
 	}
+	reversePage(reactions, filters)
 
 	return reactions, int(total), nil
 }
 
-func (r *reactionRepository) GetReactionCount(postID, commentID, answerID *uint) (int64, error) {
+func (r *reactionRepository) GetReactionCount(postID, commentID, answerID, questionID *uint) (models.ReactionCounts, error) {
 	if r.db == nil {
-		return 0, fmt.Errorf("database connection is not initialized")
+		return nil, fmt.Errorf("database connection is not initialized")
 	}
-	log.Printf("Getting reaction count with db: %v", r.db != nil)
-	var count int64
-	query := r.db.Model(&models.Reaction{}).Where("deleted_at IS NULL")
+	var targetType models.ReactionTargetType
+	var targetID uint
 	if postID != nil {
-		query = query.Where("post_id = ?", *postID)
+		targetType, targetID = models.ReactionTargetPost, *postID
 	} else if commentID != nil {
-		query = query.Where("comment_id = ?", *commentID)
+		targetType, targetID = models.ReactionTargetComment, *commentID
 	} else if answerID != nil {
-		query = query.Where("answer_id = ?", *answerID)
+		targetType, targetID = models.ReactionTargetAnswer, *answerID
+	} else if questionID != nil {
+		targetType, targetID = models.ReactionTargetQuestion, *questionID
 	} else {
-		return 0, errors.New("at least one of post_id, comment_id, or answer_id must be provided")
+		return nil, errors.New("at least one of post_id, comment_id, answer_id, or question_id must be provided")
 	}
-	if err := query.Count(&count).Error; err != nil {
-		return 0, err
+	rows, err := r.CountByTargets(targetType, []uint{targetID})
+	if err != nil {
+		return nil, err
+	}
+	counts := models.ReactionCounts{}
+	for _, row := range rows {
+		counts[row.Type] = row.Count
 	}
-	return count, nil
+	return counts, nil
 }
 
-func (r *reactionRepository) ValidateReactionID(postID, commentID, answerID *uint) error {
+// CountByTargets đếm tương tác theo loại cho nhiều nội dung cùng loại trong một truy vấn
+func (r *reactionRepository) CountByTargets(targetType models.ReactionTargetType, targetIDs []uint) ([]ReactionTypeCount, error) {
+	var counts []ReactionTypeCount
+	if len(targetIDs) == 0 {
+		return counts, nil
+	}
+	column := targetType.Column()
+	err := r.db.Model(&models.Reaction{}).
+		Select(fmt.Sprintf("%s AS target_id, type, COUNT(*) AS count", column)).
+		Where(fmt.Sprintf("%s IN ?", column), targetIDs).
+		Group(fmt.Sprintf("%s, type", column)).
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *reactionRepository) ValidateReactionID(postID, commentID, answerID, questionID *uint) error {
 	if r.db == nil {
 		return fmt.Errorf("database connection is not initialized")
 	}
@@ -148,8 +183,11 @@ func (r *reactionRepository) ValidateReactionID(postID, commentID, answerID *uin
 	if answerID != nil {
 		count++
 	}
+	if questionID != nil {
+		count++
+	}
 	if count != 1 {
-		return fmt.Errorf("exactly one of post_id, comment_id, or answer_id must be provided")
+		return fmt.Errorf("exactly one of post_id, comment_id, answer_id, or question_id must be provided")
 	}
 
 	var dbCount int64
@@ -177,6 +215,51 @@ func (r *reactionRepository) ValidateReactionID(postID, commentID, answerID *uin
 		if dbCount == 0 {
 			return fmt.Errorf("answer with ID %d does not exist", *answerID)
 		}
+	} else if questionID != nil {
+		log.Printf("Validating question_id %d with db: %v", *questionID, r.db != nil)
+		if err := r.db.Model(&models.Question{}).Where("id = ? AND deleted_at IS NULL", *questionID).Count(&dbCount).Error; err != nil {
+			return err
+		}
+		if dbCount == 0 {
+			return fmt.Errorf("question with ID %d does not exist", *questionID)
+		}
 	}
 	return nil
 }
+
+func (r *reactionRepository) reactionsWithAuthor(since time.Time) *gorm.DB {
+	return r.db.Table("reactions AS r").
+		Joins("LEFT JOIN posts p ON p.id = r.post_id").
+		Joins("LEFT JOIN comments c ON c.id = r.comment_id").
+		Joins("LEFT JOIN answers a ON a.id = r.answer_id").
+		Joins("LEFT JOIN questions q ON q.id = r.question_id").
+		Where("r.deleted_at IS NULL AND r.created_at >= ?", since)
+}
+
+// ReactionEdges đếm tương tác theo cặp người tương tác - tác giả, bỏ qua tương tác với nội dung của chính mình
+func (r *reactionRepository) ReactionEdges(since time.Time) ([]VoteEdge, error) {
+	var edges []VoteEdge
+	err := r.reactionsWithAuthor(since).
+		Select("r.user_id AS voter_id, " + reactionAuthorExpr + " AS author_id, COUNT(*) AS count").
+		Where(reactionAuthorExpr + " IS NOT NULL AND r.user_id <> " + reactionAuthorExpr).
+		Group("r.user_id, " + reactionAuthorExpr).
+		Scan(&edges).Error
+	return edges, err
+}
+
+func (r *reactionRepository) ReactionsBetween(userID, authorID uint, since time.Time) ([]models.Reaction, error) {
+	var reactions []models.Reaction
+	err := r.reactionsWithAuthor(since).
+		Select("r.*").
+		Where("r.user_id = ? AND "+reactionAuthorExpr+" = ?", userID, authorID).
+		Scan(&reactions).Error
+	return reactions, err
+}
+
+// InvalidateReactions xoá mềm các tương tác gian lận để vẫn còn dấu vết khi xem lại báo cáo
+func (r *reactionRepository) InvalidateReactions(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&models.Reaction{}).Error
+}
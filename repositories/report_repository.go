@@ -75,21 +75,11 @@ func (r *reportRepository) BatchDeleteReports(ids []string) error {
 func (r *reportRepository) List(filters map[string]interface{}) ([]models.Report, int, error) {
 	var reports []models.Report
 
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
 	countQuery := r.db.Model(&models.Report{})
 	query := r.db.Preload("Reporter").Preload("ResolvedBy")
 
 	for key, value := range filters {
-		if key != "limit" && key != "page" && key != "sort_by" && key != "order" {
+		if !isPaginationKey(key) && key != "sort_by" && key != "order" {
 			switch key {
 			case "search":
 				countQuery = countQuery.Where("reason LIKE ? OR content_preview LIKE ? OR details LIKE ?", "%"+value.(string)+"%", "%"+value.(string)+"%", "%"+value.(string)+"%")
@@ -107,26 +97,43 @@ func (r *reportRepository) List(filters map[string]interface{}) ([]models.Report
 		}
 	}
 
-	if sortBy, ok := filters["sort_by"].(string); ok && sortBy != "" {
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := countQuery.Count(&total).Error; err != nil {
+			log.Printf("Error counting reports: %v", err)
+			return nil, 0, err
+		}
+	}
+
+	// Chỉ các cột thời gian hỗ trợ cursor; sắp xếp theo cột khác vẫn phân trang bằng OFFSET
+	sortBy, _ := filters["sort_by"].(string)
+	switch sortBy {
+	case "", "created_at", "updated_at":
+		if sortBy == "" {
+			sortBy = "created_at"
+		}
+		query = paginate(query, filters, sortBy, "desc")
+	default:
 		order := "DESC"
 		if ord, ok := filters["order"].(string); ok && ord == "ASC" {
 			order = "ASC"
 		}
-		query = query.Order(sortBy + " " + order)
-	} else {
-		query = query.Order("created_at DESC")
-	}
-
-	var total int64
-	if err := countQuery.Count(&total).Error; err != nil {
-		log.Printf("Error counting reports: %v", err)
-		return nil, 0, err
+		limit, ok := filters["limit"].(int)
+		if !ok || limit < 1 {
+			limit = defaultPageLimit
+		}
+		page, ok := filters["page"].(int)
+		if !ok || page < 1 {
+			page = 1
+		}
+		query = query.Order(sortBy + " " + order).Offset((page - 1) * limit).Limit(limit)
 	}
 
-	if err := query.Offset(offset).Limit(limit).Find(&reports).Error; err != nil {
+	if err := query.Find(&reports).Error; err != nil {
 		log.Printf("Error fetching reports: %v", err)
 		return nil, 0, err
 	}
+	reversePage(reports, filters)
 
 	log.Printf("Found %d reports with total %d", len(reports), total)
 	return reports, int(total), nil
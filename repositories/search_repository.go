@@ -0,0 +1,341 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"strings"
+	"time"
+)
+
+// Các loại nội dung hỗ trợ tìm kiếm
+const (
+	SearchTypeQuestion = "question"
+	SearchTypeAnswer   = "answer"
+	SearchTypePost     = "post"
+	SearchTypeComment  = "comment"
+	SearchTypeUser     = "user"
+	SearchTypeTopic    = "topic"
+	SearchTypeTag      = "tag"
+)
+
+var SearchTypes = []string{
+	SearchTypeQuestion, SearchTypeAnswer, SearchTypePost, SearchTypeComment,
+	SearchTypeUser, SearchTypeTopic, SearchTypeTag,
+}
+
+type SearchHit struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchFacet struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type SearchRepository interface {
+	Search(query string, filters map[string]interface{}) ([]SearchHit, int, error)
+	CountByType(query string, filters map[string]interface{}) (map[string]int64, error)
+	TopicFacets(query string, filters map[string]interface{}) ([]SearchFacet, error)
+	TagFacets(query string, filters map[string]interface{}) ([]SearchFacet, error)
+	GetQuestionForIndex(id uint) (*models.Question, error)
+	GetAnswerForIndex(id uint) (*models.Answer, error)
+	GetPostForIndex(id uint) (*models.Post, error)
+	ListQuestionsForIndex(afterID uint, limit int) ([]models.Question, error)
+	ListAnswersForIndex(afterID uint, limit int) ([]models.Answer, error)
+	ListPostsForIndex(afterID uint, limit int) ([]models.Post, error)
+	ListAnswerIDsForQuestion(questionID uint) ([]uint, error)
+	GetFacetNames(table string, ids []uint) (map[uint]string, error)
+}
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// searchSource mô tả cách truy vấn FULLTEXT cho từng loại nội dung.
+// Các cột trong match phải trùng khớp với FULLTEXT index khai báo trong models.
+type searchSource struct {
+	kind  string
+	table string
+	title string
+	body  string
+	match string
+	where string
+}
+
+// visibleParentQuestion loại câu trả lời của câu hỏi đã xoá, chưa duyệt hoặc đang hẹn giờ
+const visibleParentQuestion = "EXISTS (SELECT 1 FROM questions parent WHERE parent.id = answers.question_id AND parent.deleted_at IS NULL AND parent.status = 'approved' AND parent.publish_at IS NULL)"
+
+var searchSources = map[string]searchSource{
+	SearchTypeQuestion: {SearchTypeQuestion, "questions", "questions.title", "questions.plain_content", "questions.title, questions.plain_content", "questions.deleted_at IS NULL AND questions.status = 'approved' AND questions.publish_at IS NULL"},
+	SearchTypeAnswer:   {SearchTypeAnswer, "answers", "answers.title", "answers.plain_content", "answers.title, answers.plain_content", "answers.deleted_at IS NULL AND answers.status = 'approved' AND " + visibleParentQuestion},
+	SearchTypePost:     {SearchTypePost, "posts", "posts.title", "posts.plain_content", "posts.title, posts.plain_content", "posts.deleted_at IS NULL AND posts.status = 'approved' AND posts.publish_at IS NULL"},
+	SearchTypeComment:  {SearchTypeComment, "comments", "''", "comments.plain_content", "comments.plain_content", "comments.deleted_at IS NULL AND comments.status = 'approved'"},
+	SearchTypeUser:     {SearchTypeUser, "users", "users.full_name", "users.username", "users.username, users.full_name", "users.deleted_at IS NULL AND users.status <> 'banned'"},
+	SearchTypeTopic:    {SearchTypeTopic, "topics", "topics.name", "topics.description", "topics.name, topics.description", "topics.deleted_at IS NULL"},
+	SearchTypeTag:      {SearchTypeTag, "tags", "tags.name", "tags.description", "tags.name, tags.description", "tags.deleted_at IS NULL"},
+}
+
+// selectedSources trả về các nguồn phù hợp với bộ lọc.
+// Lọc theo topic chỉ áp dụng cho question/answer, lọc theo tag chỉ áp dụng cho answer/post.
+func selectedSources(filters map[string]interface{}) []searchSource {
+	types := SearchTypes
+	if t, ok := filters["typefilter"].([]string); ok && len(t) > 0 {
+		types = t
+	}
+	_, hasTopic := filters["topic_id"].(uint)
+	_, hasTag := filters["tag_id"].(uint)
+
+	var sources []searchSource
+	for _, t := range types {
+		src, ok := searchSources[t]
+		if !ok {
+			continue
+		}
+		if hasTopic && t != SearchTypeQuestion && t != SearchTypeAnswer {
+			continue
+		}
+		if hasTag && t != SearchTypeAnswer && t != SearchTypePost {
+			continue
+		}
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+// buildSourceQuery dựng câu SELECT cho một nguồn, trả về SQL và tham số
+func buildSourceQuery(src searchSource, query string, filters map[string]interface{}) (string, []interface{}) {
+	matchExpr := fmt.Sprintf("MATCH(%s) AGAINST (? IN NATURAL LANGUAGE MODE)", src.match)
+	joins := ""
+	conds := []string{src.where, matchExpr}
+	args := []interface{}{query, query}
+
+	if topicID, ok := filters["topic_id"].(uint); ok {
+		switch src.kind {
+		case SearchTypeQuestion:
+			conds = append(conds, "questions.topic_id = ?")
+		case SearchTypeAnswer:
+			joins += " JOIN questions ON questions.id = answers.question_id"
+			conds = append(conds, "questions.topic_id = ?")
+		}
+		args = append(args, topicID)
+	}
+	if tagID, ok := filters["tag_id"].(uint); ok {
+		switch src.kind {
+		case SearchTypeAnswer:
+			joins += " JOIN answer_tags ON answer_tags.answer_id = answers.id"
+			conds = append(conds, "answer_tags.tag_id = ?")
+		case SearchTypePost:
+			joins += " JOIN post_tags ON post_tags.post_id = posts.id"
+			conds = append(conds, "post_tags.tag_id = ?")
+		}
+		args = append(args, tagID)
+	}
+
+	sql := fmt.Sprintf(
+		"SELECT '%s' AS type, %s.id AS id, %s AS title, %s AS body, %s AS score, %s.created_at AS created_at FROM %s%s WHERE %s",
+		src.kind, src.table, src.title, src.body, matchExpr, src.table, src.table, joins, strings.Join(conds, " AND "),
+	)
+	return sql, args
+}
+
+func (r *searchRepository) Search(query string, filters map[string]interface{}) ([]SearchHit, int, error) {
+	page, okPage := filters["page"].(int)
+	limit, okLimit := filters["limit"].(int)
+	if !okPage || page < 1 {
+		page = 1
+	}
+	if !okLimit || limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	sources := selectedSources(filters)
+	if len(sources) == 0 {
+		return []SearchHit{}, 0, nil
+	}
+
+	var parts []string
+	var args []interface{}
+	for _, src := range sources {
+		sql, a := buildSourceQuery(src, query, filters)
+		parts = append(parts, sql)
+		args = append(args, a...)
+	}
+	union := strings.Join(parts, " UNION ALL ")
+
+	var total int64
+	if err := r.db.Raw("SELECT COUNT(*) FROM ("+union+") AS results", args...).Scan(&total).Error; err != nil {
+		log.Printf("Error counting search results: %v", err)
+		return nil, 0, err
+	}
+
+	var hits []SearchHit
+	args = append(args, limit, offset)
+	err := r.db.Raw("SELECT * FROM ("+union+") AS results ORDER BY score DESC, created_at DESC LIMIT ? OFFSET ?", args...).
+		Scan(&hits).Error
+	if err != nil {
+		log.Printf("Error searching content: %v", err)
+		return nil, 0, err
+	}
+	return hits, int(total), nil
+}
+
+func (r *searchRepository) CountByType(query string, filters map[string]interface{}) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, src := range selectedSources(filters) {
+		sql, args := buildSourceQuery(src, query, filters)
+		var count int64
+		if err := r.db.Raw("SELECT COUNT(*) FROM ("+sql+") AS results", args...).Scan(&count).Error; err != nil {
+			log.Printf("Error counting %s search results: %v", src.kind, err)
+			return nil, err
+		}
+		counts[src.kind] = count
+	}
+	return counts, nil
+}
+
+// topicFacetSources lấy topic của từng kết quả; câu trả lời thuộc topic của câu hỏi cha
+var topicFacetSources = map[string]string{
+	SearchTypeQuestion: "SELECT questions.topic_id AS facet_id FROM (%s) AS hits JOIN questions ON questions.id = hits.id",
+	SearchTypeAnswer:   "SELECT questions.topic_id AS facet_id FROM (%s) AS hits JOIN answers ON answers.id = hits.id JOIN questions ON questions.id = answers.question_id",
+}
+
+// tagFacetSources lấy các tag của từng kết quả; chỉ câu trả lời và bài viết có tag
+var tagFacetSources = map[string]string{
+	SearchTypeAnswer: "SELECT answer_tags.tag_id AS facet_id FROM (%s) AS hits JOIN answer_tags ON answer_tags.answer_id = hits.id",
+	SearchTypePost:   "SELECT post_tags.tag_id AS facet_id FROM (%s) AS hits JOIN post_tags ON post_tags.post_id = hits.id",
+}
+
+func (r *searchRepository) TopicFacets(query string, filters map[string]interface{}) ([]SearchFacet, error) {
+	facets, err := r.facetCounts(query, filters, "topics", topicFacetSources)
+	if err != nil {
+		log.Printf("Error building topic facets: %v", err)
+		return nil, err
+	}
+	return facets, nil
+}
+
+func (r *searchRepository) TagFacets(query string, filters map[string]interface{}) ([]SearchFacet, error) {
+	facets, err := r.facetCounts(query, filters, "tags", tagFacetSources)
+	if err != nil {
+		log.Printf("Error building tag facets: %v", err)
+		return nil, err
+	}
+	return facets, nil
+}
+
+// facetCounts đếm facet trên đúng tập kết quả của Search (cùng loại nội dung, topic, tag)
+// để số đếm khớp với những gì người dùng thấy
+func (r *searchRepository) facetCounts(query string, filters map[string]interface{}, table string, facetSources map[string]string) ([]SearchFacet, error) {
+	var parts []string
+	var args []interface{}
+	for _, src := range selectedSources(filters) {
+		facetSQL, ok := facetSources[src.kind]
+		if !ok {
+			continue
+		}
+		sql, a := buildSourceQuery(src, query, filters)
+		parts = append(parts, fmt.Sprintf(facetSQL, sql))
+		args = append(args, a...)
+	}
+	facets := []SearchFacet{}
+	if len(parts) == 0 {
+		return facets, nil
+	}
+
+	err := r.db.Raw(fmt.Sprintf(`
+		SELECT %[1]s.id AS id, %[1]s.name AS name, COUNT(*) AS count
+		FROM (%[2]s) AS faceted
+		JOIN %[1]s ON %[1]s.id = faceted.facet_id AND %[1]s.deleted_at IS NULL
+		GROUP BY %[1]s.id, %[1]s.name
+		ORDER BY count DESC
+		LIMIT 20`, table, strings.Join(parts, " UNION ALL ")), args...).Scan(&facets).Error
+	return facets, err
+}
+
+// Các hàm dưới đây phục vụ đồng bộ chỉ mục tìm kiếm nhúng (searchindex)
+
+func (r *searchRepository) GetQuestionForIndex(id uint) (*models.Question, error) {
+	var question models.Question
+	if err := r.db.First(&question, id).Error; err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+func (r *searchRepository) GetAnswerForIndex(id uint) (*models.Answer, error) {
+	var answer models.Answer
+	if err := r.db.Preload("Tags").Preload("Question").First(&answer, id).Error; err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+func (r *searchRepository) GetPostForIndex(id uint) (*models.Post, error) {
+	var post models.Post
+	if err := r.db.Preload("Tags").First(&post, id).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (r *searchRepository) ListQuestionsForIndex(afterID uint, limit int) ([]models.Question, error) {
+	var questions []models.Question
+	err := r.db.Where("id > ? AND status = ? AND publish_at IS NULL", afterID, models.StatusApproved).
+		Order("id ASC").Limit(limit).Find(&questions).Error
+	return questions, err
+}
+
+func (r *searchRepository) ListAnswersForIndex(afterID uint, limit int) ([]models.Answer, error) {
+	var answers []models.Answer
+	err := r.db.Preload("Tags").Preload("Question").
+		Joins("JOIN questions ON questions.id = answers.question_id AND questions.deleted_at IS NULL").
+		Where("answers.id > ? AND answers.status = ?", afterID, "approved").
+		Where("questions.status = ? AND questions.publish_at IS NULL", models.StatusApproved).
+		Order("answers.id ASC").Limit(limit).Find(&answers).Error
+	return answers, err
+}
+
+func (r *searchRepository) ListAnswerIDsForQuestion(questionID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Answer{}).Where("question_id = ?", questionID).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *searchRepository) ListPostsForIndex(afterID uint, limit int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Preload("Tags").
+		Where("id > ? AND status = ? AND publish_at IS NULL", afterID, models.Approved).
+		Order("id ASC").Limit(limit).Find(&posts).Error
+	return posts, err
+}
+
+func (r *searchRepository) GetFacetNames(table string, ids []uint) (map[uint]string, error) {
+	names := make(map[uint]string)
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var rows []struct {
+		ID   uint
+		Name string
+	}
+	if err := r.db.Table(table).Select("id, name").Where("id IN ? AND deleted_at IS NULL", ids).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		names[row.ID] = row.Name
+	}
+	return names, nil
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// SitemapStat là số URL và lần sửa mới nhất của một khối ID
+type SitemapStat struct {
+	Number   int
+	URLCount int
+	LastMod  time.Time
+}
+
+type SitemapEntry struct {
+	ID        uint
+	Slug      string
+	UpdatedAt time.Time
+}
+
+type SitemapRepository interface {
+	SegmentStats(kind string, size int) ([]SitemapStat, error)
+	ListEntries(kind string, size, number int) ([]SitemapEntry, error)
+	BackfillSlugs(limit int) (int, error)
+
+	ListSegments() ([]models.SitemapSegment, error)
+	GetSegment(name string) (*models.SitemapSegment, error)
+	SaveSegment(segment *models.SitemapSegment) error
+	DeleteSegments(names []string) error
+}
+
+type sitemapRepository struct {
+	db *gorm.DB
+}
+
+func NewSitemapRepository(db *gorm.DB) SitemapRepository {
+	return &sitemapRepository{db: db}
+}
+
+// scope giới hạn nội dung được đưa vào sitemap: câu hỏi/bài viết đã duyệt và đã đăng, mọi chủ đề và thẻ
+func (r *sitemapRepository) scope(kind string) *gorm.DB {
+	switch kind {
+	case models.SitemapQuestions:
+		return r.db.Model(&models.Question{}).Where("status = ? AND publish_at IS NULL", models.StatusApproved)
+	case models.SitemapPosts:
+		return r.db.Model(&models.Post{}).Where("status = ? AND publish_at IS NULL", models.Approved)
+	case models.SitemapTopics:
+		return r.db.Model(&models.Topic{})
+	default:
+		return r.db.Model(&models.Tag{})
+	}
+}
+
+// SegmentStats gom nội dung theo khối ID cố định (khối n gồm ID từ n*size+1 đến (n+1)*size),
+// nhờ vậy nội dung mới chỉ làm thay đổi khối cuối và các khối cũ giữ nguyên
+func (r *sitemapRepository) SegmentStats(kind string, size int) ([]SitemapStat, error) {
+	var stats []SitemapStat
+	err := r.scope(kind).
+		Select("(id - 1) DIV ? AS number, COUNT(*) AS url_count, MAX(updated_at) AS last_mod", size).
+		Group("number").Order("number").
+		Scan(&stats).Error
+	return stats, err
+}
+
+func (r *sitemapRepository) ListEntries(kind string, size, number int) ([]SitemapEntry, error) {
+	columns := "id, slug, updated_at"
+	if kind == models.SitemapTopics || kind == models.SitemapTags {
+		columns = "id, updated_at"
+	}
+	var entries []SitemapEntry
+	err := r.scope(kind).Select(columns).
+		Where("id BETWEEN ? AND ?", number*size+1, (number+1)*size).
+		Order("id").Scan(&entries).Error
+	return entries, err
+}
+
+// BackfillSlugs gán slug cho câu hỏi/bài viết tạo trước khi có slug, tối đa limit mục mỗi loại.
+// Dùng UpdateColumn để không làm đổi updated_at của nội dung.
+func (r *sitemapRepository) BackfillSlugs(limit int) (int, error) {
+	count := 0
+
+	var questions []models.Question
+	if err := r.db.Select("id, title").Where("slug = '' OR slug IS NULL").Limit(limit).Find(&questions).Error; err != nil {
+		return count, err
+	}
+	for _, question := range questions {
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			slug, err := assignSlug(tx, models.SlugTargetQuestion, question.ID, question.Title, "", "cau-hoi")
+			if err != nil {
+				return err
+			}
+			return tx.Model(&models.Question{}).Where("id = ?", question.ID).UpdateColumn("slug", slug).Error
+		})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	var posts []models.Post
+	if err := r.db.Select("id, title, plain_content").Where("slug = '' OR slug IS NULL").Limit(limit).Find(&posts).Error; err != nil {
+		return count, err
+	}
+	for i := range posts {
+		post := &posts[i]
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			slug, err := assignSlug(tx, models.SlugTargetPost, post.ID, postSlugTitle(post), "", "bai-viet")
+			if err != nil {
+				return err
+			}
+			return tx.Model(&models.Post{}).Where("id = ?", post.ID).UpdateColumn("slug", slug).Error
+		})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ListSegments trả về thông tin các khối, không kèm nội dung XML
+func (r *sitemapRepository) ListSegments() ([]models.SitemapSegment, error) {
+	var segments []models.SitemapSegment
+	err := r.db.Omit("content").Order("kind, number").Find(&segments).Error
+	return segments, err
+}
+
+func (r *sitemapRepository) GetSegment(name string) (*models.SitemapSegment, error) {
+	var segment models.SitemapSegment
+	if err := r.db.Where("name = ?", name).First(&segment).Error; err != nil {
+		return nil, err
+	}
+	return &segment, nil
+}
+
+// SaveSegment ghi đè khối cùng tên nếu đã có
+func (r *sitemapRepository) SaveSegment(segment *models.SitemapSegment) error {
+	var existing models.SitemapSegment
+	if err := r.db.Select("id").Where("name = ?", segment.Name).First(&existing).Error; err == nil {
+		segment.ID = existing.ID
+	}
+	return r.db.Save(segment).Error
+}
+
+func (r *sitemapRepository) DeleteSegments(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	return r.db.Where("name IN ?", names).Delete(&models.SitemapSegment{}).Error
+}
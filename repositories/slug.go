@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/utils"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Số lần thử hậu tố -2, -3... trước khi bỏ cuộc khi slug bị trùng
+const maxSlugAttempts = 50
+
+// Các đường dẫn tĩnh cùng cấp với /questions/:id, /posts/:id; slug trùng tên sẽ bị route tĩnh che mất
+var reservedSlugs = map[string]bool{"all": true, "suggest": true, "scheduled": true}
+
+// assignSlug trả về slug cho nội dung theo tiêu đề và ghi slug mới vào SlugHistory.
+// Slug hiện tại được giữ nguyên khi tiêu đề vẫn cho ra cùng slug gốc (kể cả dạng có hậu tố -N),
+// nhờ vậy sửa nội dung không làm đổi URL. Slug không bao giờ chỉ gồm chữ số để không nhầm với ID.
+func assignSlug(db *gorm.DB, targetType string, targetID uint, title, current, fallback string) (string, error) {
+	base := utils.Slugify(title)
+	if base == "" {
+		base = fallback
+	} else if isDigits(base) || reservedSlugs[base] {
+		base = fallback + "-" + base
+	}
+	if current == base || (strings.HasPrefix(current, base+"-") && isDigits(current[len(base)+1:])) {
+		return current, nil
+	}
+
+	for n := 1; n <= maxSlugAttempts; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+		var owner models.SlugHistory
+		err := db.Where("target_type = ? AND slug = ?", targetType, candidate).First(&owner).Error
+		if err == nil {
+			// Slug từng thuộc về chính nội dung này (đổi tiêu đề rồi đổi lại) thì dùng lại
+			if owner.TargetID == targetID {
+				return candidate, nil
+			}
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+		// Có thể bị nội dung khác giành mất giữa lúc kiểm tra và ghi (unique index), khi đó thử hậu tố tiếp theo
+		if err := db.Create(&models.SlugHistory{TargetType: targetType, Slug: candidate, TargetID: targetID}).Error; err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("cannot find a free slug for %s %d", targetType, targetID)
+}
+
+// resolveSlug tìm ID nội dung theo slug hiện tại hoặc slug cũ
+func resolveSlug(db *gorm.DB, targetType, slug string) (uint, error) {
+	var history models.SlugHistory
+	if err := db.Where("target_type = ? AND slug = ?", targetType, slug).First(&history).Error; err != nil {
+		return 0, err
+	}
+	return history.TargetID, nil
+}
+
+// postSlugTitle: bài viết có thể không có tiêu đề, khi đó lấy đoạn đầu nội dung
+func postSlugTitle(post *models.Post) string {
+	if strings.TrimSpace(post.Title) != "" {
+		return post.Title
+	}
+	plain := post.PlainContent
+	if utf8.RuneCountInString(plain) > 100 {
+		plain = string([]rune(plain)[:100])
+	}
+	return plain
+}
+
+func isDigits(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
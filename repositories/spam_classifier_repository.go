@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+// SpamContent là nội dung cần gán nhãn cùng thông tin tác giả dùng làm đặc trưng
+type SpamContent struct {
+	UserID           uint
+	Title            string
+	PlainContent     string
+	RawContent       string
+	CreatedAt        time.Time
+	AuthorCreatedAt  time.Time
+	AuthorReputation uint
+}
+
+type SpamClassifierRepository interface {
+	LoadContent(contentType string, contentID uint) (*SpamContent, error)
+	GetSample(contentType string, contentID uint) (*models.SpamTrainingSample, error)
+	SaveSample(sample *models.SpamTrainingSample) error
+	ListSamples(afterID uint, limit int) ([]models.SpamTrainingSample, error)
+	ListSamplesSince(since time.Time) ([]models.SpamTrainingSample, error)
+	CreateVersion(version *models.SpamModelVersion) error
+	GetVersion(id uint) (*models.SpamModelVersion, error)
+	GetActiveVersion() (*models.SpamModelVersion, error)
+	GetActiveVersionID() (uint, error)
+	ActivateVersion(id uint) error
+	ListVersions(limit int) ([]models.SpamModelVersion, error)
+}
+
+type spamClassifierRepository struct {
+	db *gorm.DB
+}
+
+func NewSpamClassifierRepository(db *gorm.DB) SpamClassifierRepository {
+	return &spamClassifierRepository{db: db}
+}
+
+// spamContentSources mô tả tiêu đề và cột nội dung gốc (còn HTML, để đếm liên kết) của từng loại nội dung
+var spamContentSources = map[string]struct {
+	table string
+	title string
+	raw   string
+}{
+	models.ModerationQuestion: {"questions", "title", "description"},
+	models.ModerationAnswer:   {"answers", "title", "content"},
+	models.ModerationPost:     {"posts", "title", "content"},
+	models.ModerationComment:  {"comments", "''", "content"},
+}
+
+// LoadContent lấy cả nội dung đã xoá mềm, vì người kiểm duyệt thường xoá nội dung spam ngay sau khi từ chối
+func (r *spamClassifierRepository) LoadContent(contentType string, contentID uint) (*SpamContent, error) {
+	src, ok := spamContentSources[contentType]
+	if !ok {
+		return nil, fmt.Errorf("unknown content type %q", contentType)
+	}
+	var content SpamContent
+	result := r.db.Raw(fmt.Sprintf(
+		"SELECT c.user_id, %[2]s AS title, c.plain_content, c.%[3]s AS raw_content, c.created_at, "+
+			"u.created_at AS author_created_at, u.reputation AS author_reputation "+
+			"FROM %[1]s c JOIN users u ON u.id = c.user_id WHERE c.id = ?",
+		src.table, src.title, src.raw), contentID).Scan(&content)
+	if result.Error != nil {
+		log.Printf("Error loading %s %d for spam classifier: %v", contentType, contentID, result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &content, nil
+}
+
+func (r *spamClassifierRepository) GetSample(contentType string, contentID uint) (*models.SpamTrainingSample, error) {
+	var sample models.SpamTrainingSample
+	if err := r.db.Where("content_type = ? AND content_id = ?", contentType, contentID).First(&sample).Error; err != nil {
+		return nil, err
+	}
+	return &sample, nil
+}
+
+// SaveSample ghi mẫu mới hoặc ghi đè nhãn và nội dung của mẫu đã có cho cùng nội dung
+func (r *spamClassifierRepository) SaveSample(sample *models.SpamTrainingSample) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "content_type"}, {Name: "content_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "label", "title", "body", "features", "updated_at"}),
+	}).Create(sample).Error
+}
+
+func (r *spamClassifierRepository) ListSamples(afterID uint, limit int) ([]models.SpamTrainingSample, error) {
+	var samples []models.SpamTrainingSample
+	if err := r.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&samples).Error; err != nil {
+		log.Printf("Error fetching spam training samples: %v", err)
+		return nil, err
+	}
+	return samples, nil
+}
+
+// ListSamplesSince trả về các mẫu được tạo sau since, tức chưa có trong phiên bản mô hình huấn luyện lúc since
+func (r *spamClassifierRepository) ListSamplesSince(since time.Time) ([]models.SpamTrainingSample, error) {
+	var samples []models.SpamTrainingSample
+	if err := r.db.Where("created_at > ?", since).Order("id ASC").Find(&samples).Error; err != nil {
+		log.Printf("Error fetching spam training samples since %v: %v", since, err)
+		return nil, err
+	}
+	return samples, nil
+}
+
+// CreateVersion lưu phiên bản mới; nếu phiên bản được đánh dấu Active thì các phiên bản khác bị tắt trong cùng transaction
+func (r *spamClassifierRepository) CreateVersion(version *models.SpamModelVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if version.Active {
+			if err := tx.Model(&models.SpamModelVersion{}).Where("active = ?", true).Update("active", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(version).Error
+	})
+}
+
+func (r *spamClassifierRepository) GetVersion(id uint) (*models.SpamModelVersion, error) {
+	var version models.SpamModelVersion
+	if err := r.db.First(&version, id).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+func (r *spamClassifierRepository) GetActiveVersion() (*models.SpamModelVersion, error) {
+	var version models.SpamModelVersion
+	if err := r.db.Where("active = ?", true).Order("id DESC").First(&version).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// GetActiveVersionID chỉ đọc ID để kiểm tra nhanh phiên bản đang dùng có bị đổi ở tiến trình khác không; trả về 0 nếu chưa có
+func (r *spamClassifierRepository) GetActiveVersionID() (uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.SpamModelVersion{}).Where("active = ?", true).Order("id DESC").Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+func (r *spamClassifierRepository) ActivateVersion(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.SpamModelVersion{}).Where("id = ?", id).Update("active", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			if err := tx.Model(&models.SpamModelVersion{}).Where("id = ?", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return tx.Model(&models.SpamModelVersion{}).Where("id <> ? AND active = ?", id, true).Update("active", false).Error
+	})
+}
+
+// ListVersions không tải Weights vì chỉ dùng cho báo cáo
+func (r *spamClassifierRepository) ListVersions(limit int) ([]models.SpamModelVersion, error) {
+	var versions []models.SpamModelVersion
+	err := r.db.Omit("weights").Order("id DESC").Limit(limit).Find(&versions).Error
+	if err != nil {
+		log.Printf("Error listing spam model versions: %v", err)
+		return nil, err
+	}
+	return versions, nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"Forum_BE/models"
+	"gorm.io/gorm"
+	"log"
+)
+
+type SuggestedEditRepository interface {
+	CreateSuggestedEdit(edit *models.SuggestedEdit) error
+	GetSuggestedEditByID(id uint) (*models.SuggestedEdit, error)
+	GetPendingEdit(editorID uint, targetType string, targetID uint) (*models.SuggestedEdit, error)
+	ListSuggestedEdits(filters map[string]interface{}) ([]models.SuggestedEdit, int, error)
+	UpdateSuggestedEdit(edit *models.SuggestedEdit) error
+	ApproveSuggestedEdit(edit *models.SuggestedEdit, reputation uint) error
+	DeleteSuggestedEdit(id uint) error
+}
+
+type suggestedEditRepository struct {
+	db *gorm.DB
+}
+
+func NewSuggestedEditRepository(db *gorm.DB) SuggestedEditRepository {
+	return &suggestedEditRepository{db: db}
+}
+
+func (r *suggestedEditRepository) CreateSuggestedEdit(edit *models.SuggestedEdit) error {
+	return r.db.Create(edit).Error
+}
+
+func (r *suggestedEditRepository) GetSuggestedEditByID(id uint) (*models.SuggestedEdit, error) {
+	var edit models.SuggestedEdit
+	err := r.db.Preload("Editor").Preload("Reviewer").First(&edit, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &edit, nil
+}
+
+func (r *suggestedEditRepository) GetPendingEdit(editorID uint, targetType string, targetID uint) (*models.SuggestedEdit, error) {
+	var edit models.SuggestedEdit
+	err := r.db.Where("editor_id = ? AND target_type = ? AND target_id = ? AND status = ?",
+		editorID, targetType, targetID, models.SuggestedEditPending).
+		First(&edit).Error
+	if err != nil {
+		return nil, err
+	}
+	return &edit, nil
+}
+
+func (r *suggestedEditRepository) ListSuggestedEdits(filters map[string]interface{}) ([]models.SuggestedEdit, int, error) {
+	var edits []models.SuggestedEdit
+
+	query := r.db.Model(&models.SuggestedEdit{})
+	if status, ok := filters["status"].(string); ok && status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if targetType, ok := filters["target_type"].(string); ok && targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if targetID, ok := filters["target_id"].(uint); ok && targetID != 0 {
+		query = query.Where("target_id = ?", targetID)
+	}
+	if editorID, ok := filters["editor_id"].(uint); ok && editorID != 0 {
+		query = query.Where("editor_id = ?", editorID)
+	}
+
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			log.Printf("Error counting suggested edits: %v", err)
+			return nil, 0, err
+		}
+	}
+
+	// Hàng đợi duyệt: đề xuất cũ nhất lên trước
+	if err := paginate(query, filters, "created_at", "asc").Preload("Editor").Preload("Reviewer").
+		Find(&edits).Error; err != nil {
+		log.Printf("Error fetching suggested edits: %v", err)
+		return nil, 0, err
+	}
+	reversePage(edits, filters)
+	return edits, int(total), nil
+}
+
+func (r *suggestedEditRepository) UpdateSuggestedEdit(edit *models.SuggestedEdit) error {
+	return r.db.Omit("Editor", "Reviewer").Save(edit).Error
+}
+
+// ApproveSuggestedEdit lưu kết quả duyệt và cộng điểm uy tín cho người đề xuất trong cùng transaction
+func (r *suggestedEditRepository) ApproveSuggestedEdit(edit *models.SuggestedEdit, reputation uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Editor", "Reviewer").Save(edit).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", edit.EditorID).
+			UpdateColumn("reputation", gorm.Expr("reputation + ?", reputation)).Error
+	})
+}
+
+func (r *suggestedEditRepository) DeleteSuggestedEdit(id uint) error {
+	return r.db.Delete(&models.SuggestedEdit{}, id).Error
+}
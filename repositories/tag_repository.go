@@ -58,26 +58,17 @@ func (r *tagRepository) DeleteTag(id uint) error {
 func (r *tagRepository) ListTags(filters map[string]interface{}) ([]models.Tag, int, error) {
 	var tags []models.Tag
 
-	// Process pagination parameters
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
 	// Query for counting total
 	countQuery := r.db.Model(&models.Tag{})
 	if search, ok := filters["search"]; ok {
 		countQuery = countQuery.Where("name LIKE ? OR description LIKE ?", "%"+search.(string)+"%", "%"+search.(string)+"%")
 	}
-	var total int64
-	if err := countQuery.Count(&total).Error; err != nil {
-		log.Printf("Error counting tags: %v", err)
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := countQuery.Count(&total).Error; err != nil {
+			log.Printf("Error counting tags: %v", err)
+			return nil, 0, err
+		}
 	}
 
 	// Apply filters and pagination
@@ -86,12 +77,13 @@ func (r *tagRepository) ListTags(filters map[string]interface{}) ([]models.Tag,
 		query = query.Where("name LIKE ? OR description LIKE ?", "%"+search.(string)+"%", "%"+search.(string)+"%")
 	}
 
-	query = query.Offset(offset).Limit(limit)
+	query = paginate(query, filters, "created_at", "asc")
 	err := query.Find(&tags).Error
 	if err != nil {
 		log.Printf("Error fetching tags: %v", err)
 		return nil, 0, err
 	}
+	reversePage(tags, filters)
 
 	log.Printf("Found %d tags with total %d", len(tags), total)
 	return tags, int(total), nil
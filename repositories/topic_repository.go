@@ -15,6 +15,16 @@ type TopicRepository interface {
 	ListTopics(filters map[string]interface{}) ([]models.Topic, int, error)
 	AddQuestionToTopic(questionID, topicID uint) error
 	RemoveQuestionFromTopic(questionID, topicID uint) error
+	ListTrainingSamples(afterID uint, limit int) ([]TopicTrainingSample, error)
+}
+
+// TopicTrainingSample là một câu hỏi đã duyệt cùng chủ đề của nó, dùng để huấn luyện bộ phân loại
+type TopicTrainingSample struct {
+	QuestionID   uint
+	TopicID      uint
+	TopicName    string
+	Title        string
+	PlainContent string
 }
 
 type topicRepository struct {
@@ -58,28 +68,6 @@ func (r *topicRepository) DeleteTopic(id uint) error {
 func (r *topicRepository) ListTopics(filters map[string]interface{}) ([]models.Topic, int, error) {
 	var topics []models.Topic
 
-	// Process pagination parameters
-	page, okPage := filters["page"].(int)
-	limit, okLimit := filters["limit"].(int)
-	if !okPage || page < 1 {
-		page = 1
-	}
-	if !okLimit || limit < 1 {
-		limit = 10
-	}
-	offset := (page - 1) * limit
-
-	// Process sort parameter
-	sortOrder := "created_at DESC"
-	if sort, ok := filters["sort"].(string); ok {
-		if sort == "asc" {
-			sortOrder = "created_at ASC"
-		} else if sort == "desc" {
-			sortOrder = "created_at DESC"
-		}
-		log.Printf("Applying sort order: %s", sortOrder)
-	}
-
 	// Query for counting total
 	countQuery := r.db.Model(&models.Topic{})
 	if search, ok := filters["search"]; ok {
@@ -88,10 +76,12 @@ func (r *topicRepository) ListTopics(filters map[string]interface{}) ([]models.T
 	if status, ok := filters["status"]; ok {
 		countQuery = countQuery.Where("status = ?", status)
 	}
-	var total int64
-	if err := countQuery.Count(&total).Error; err != nil {
-		log.Printf("Error counting topics: %v", err)
-		return nil, 0, err
+	var total int64 = -1
+	if wantsTotal(filters) {
+		if err := countQuery.Count(&total).Error; err != nil {
+			log.Printf("Error counting topics: %v", err)
+			return nil, 0, err
+		}
 	}
 
 	// Apply filters and pagination
@@ -103,14 +93,15 @@ func (r *topicRepository) ListTopics(filters map[string]interface{}) ([]models.T
 		query = query.Where("status = ?", status)
 	}
 
-	query = query.Offset(offset).Limit(limit).Order(sortOrder)
+	query = paginate(query, filters, "created_at", "desc")
 	err := query.Find(&topics).Error
 	if err != nil {
 		log.Printf("Error fetching topics: %v", err)
 		return nil, 0, err
 	}
+	reversePage(topics, filters)
 
-	log.Printf("Found %d topics with total %d, sort order: %s", len(topics), total, sortOrder)
+	log.Printf("Found %d topics with total %d", len(topics), total)
 	return topics, int(total), nil
 }
 
@@ -121,3 +112,19 @@ func (r *topicRepository) AddQuestionToTopic(questionID, topicID uint) error {
 func (r *topicRepository) RemoveQuestionFromTopic(questionID, topicID uint) error {
 	return r.db.Exec("DELETE FROM question_topics WHERE question_id = ? AND topic_id = ?", questionID, topicID).Error
 }
+
+func (r *topicRepository) ListTrainingSamples(afterID uint, limit int) ([]TopicTrainingSample, error) {
+	var samples []TopicTrainingSample
+	err := r.db.Table("questions").
+		Select("questions.id AS question_id, questions.topic_id, topics.name AS topic_name, questions.title, questions.plain_content").
+		Joins("JOIN topics ON topics.id = questions.topic_id AND topics.deleted_at IS NULL").
+		Where("questions.id > ? AND questions.status = ? AND questions.deleted_at IS NULL", afterID, models.StatusApproved).
+		Order("questions.id ASC").
+		Limit(limit).
+		Scan(&samples).Error
+	if err != nil {
+		log.Printf("Error fetching topic training samples: %v", err)
+		return nil, err
+	}
+	return samples, nil
+}
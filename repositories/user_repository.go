@@ -5,6 +5,7 @@ import (
 	"errors"
 	"gorm.io/gorm"
 	"log/slog"
+	"strings"
 )
 
 var (
@@ -20,6 +21,8 @@ type UserRepository interface {
 	DeleteUser(id uint) error
 	GetAllUsers(filters map[string]interface{}) ([]models.User, int64, error)
 	GetUserByIDWithPassword(id uint) (*models.User, error)
+	SearchUsersByPrefix(prefix string, limit int) ([]models.User, error)
+	ListUsersByRoles(roles []models.Role) ([]models.User, error)
 }
 
 type userRepository struct {
@@ -44,7 +47,7 @@ func (r *userRepository) GetUserByID(id uint) (*models.User, error) {
 		return nil, err
 	}
 
-	// Đếm số lượng posts, answers, questions
+	// Đếm số lượng posts, answers, questions (không tính nội dung đăng ẩn danh)
 	type Counts struct {
 		PostCount     int64
 		AnswerCount   int64
@@ -52,8 +55,8 @@ func (r *userRepository) GetUserByID(id uint) (*models.User, error) {
 	}
 	var counts Counts
 	r.db.Model(&models.Post{}).Where("user_id = ?", id).Count(&counts.PostCount)
-	r.db.Model(&models.Answer{}).Where("user_id = ?", id).Count(&counts.AnswerCount)
-	r.db.Model(&models.Question{}).Where("user_id = ?", id).Count(&counts.QuestionCount)
+	r.db.Model(&models.Answer{}).Where("user_id = ? AND anonymous = ?", id, false).Count(&counts.AnswerCount)
+	r.db.Model(&models.Question{}).Where("user_id = ? AND anonymous = ?", id, false).Count(&counts.QuestionCount)
 
 	// Gán vào struct User
 	user.PostCount = counts.PostCount
@@ -98,7 +101,7 @@ func (r *userRepository) DeleteUser(id uint) error {
 
 func (r *userRepository) GetAllUsers(filters map[string]interface{}) ([]models.User, int64, error) {
 	var users []models.User
-	var total int64
+	var total int64 = -1
 
 	query := r.db.Model(&models.User{}).Where("deleted_at IS NULL")
 
@@ -109,22 +112,17 @@ func (r *userRepository) GetAllUsers(filters map[string]interface{}) ([]models.U
 	}
 
 	// Count total
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// Apply pagination
-	if limit, ok := filters["limit"].(int); ok && limit > 0 {
-		query = query.Limit(limit)
-	}
-	if offset, ok := filters["offset"].(int); ok && offset >= 0 {
-		query = query.Offset(offset)
+	if wantsTotal(filters) {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, 0, err
+		}
 	}
 
 	// Execute query
-	if err := query.Find(&users).Error; err != nil {
+	if err := paginate(query, filters, "created_at", "desc").Find(&users).Error; err != nil {
 		return nil, 0, err
 	}
+	reversePage(users, filters)
 
 	return users, total, nil
 }
@@ -143,3 +141,27 @@ func (r *userRepository) GetUserByIDWithPassword(id uint) (*models.User, error)
 
 	return &user, nil
 }
+
+// SearchUsersByPrefix tìm người dùng chưa bị cấm có username hoặc họ tên bắt đầu bằng prefix, ưu tiên uy tín cao
+func (r *userRepository) SearchUsersByPrefix(prefix string, limit int) ([]models.User, error) {
+	var users []models.User
+	pattern := likeEscaper.Replace(prefix) + "%"
+	err := r.db.Model(&models.User{}).
+		Select("id", "username", "full_name", "avatar", "reputation").
+		Where("deleted_at IS NULL AND status <> ?", models.StatusBanned).
+		Where("username LIKE ? OR full_name LIKE ?", pattern, pattern).
+		Order("reputation DESC, username ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// likeEscaper thoát các ký tự đại diện của LIKE để "_" trong username được so khớp đúng nghĩa đen
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// ListUsersByRoles trả về các tài khoản có một trong các vai trò, tài khoản cũ nhất trước
+func (r *userRepository) ListUsersByRoles(roles []models.Role) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("role IN ?", roles).Order("id ASC").Find(&users).Error
+	return users, err
+}
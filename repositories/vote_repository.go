@@ -3,6 +3,7 @@ package repositories
 import (
 	"Forum_BE/models"
 	"gorm.io/gorm"
+	"time"
 )
 
 type VoteRepository interface {
@@ -13,8 +14,21 @@ type VoteRepository interface {
 	ListVotes() ([]models.Vote, error)
 	GetVoteByUserAndVotable(userID uint, votableType string, votableID uint) (*models.Vote, error)
 	GetVoteCount(votableType string, votableID uint) (int64, error)
+	UpvoteEdges(since time.Time) ([]VoteEdge, error)
+	UpvoteIDsBetween(voterID, authorID uint, since time.Time) ([]uint, error)
+	InvalidateVotes(ids []uint, at time.Time) error
 }
 
+// VoteEdge là số lượt ủng hộ (upvote hoặc tương tác) VoterID dành cho nội dung của AuthorID
+type VoteEdge struct {
+	VoterID  uint
+	AuthorID uint
+	Count    int64
+}
+
+// voteAuthorExpr là tác giả của nội dung được bỏ phiếu, lấy từ bảng tương ứng với votable_type
+const voteAuthorExpr = "COALESCE(q.user_id, a.user_id, c.user_id)"
+
 type voteRepository struct {
 	db *gorm.DB
 }
@@ -65,10 +79,46 @@ func (r *voteRepository) GetVoteByUserAndVotable(userID uint, votableType string
 func (r *voteRepository) GetVoteCount(votableType string, votableID uint) (int64, error) {
 	var count int64
 	err := r.db.Model(&models.Vote{}).
-		Where("votable_type = ? AND votable_id = ?", votableType, votableID).
+		Where("votable_type = ? AND votable_id = ? AND invalidated_at IS NULL", votableType, votableID).
 		Count(&count).Error
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+// upvotesWithAuthor là các upvote còn hiệu lực từ thời điểm since, kèm tác giả của nội dung được bỏ phiếu
+func (r *voteRepository) upvotesWithAuthor(since time.Time) *gorm.DB {
+	return r.db.Table("votes AS v").
+		Joins("LEFT JOIN questions q ON v.votable_type = 'question' AND q.id = v.votable_id").
+		Joins("LEFT JOIN answers a ON v.votable_type = 'answer' AND a.id = v.votable_id").
+		Joins("LEFT JOIN comments c ON v.votable_type = 'comment' AND c.id = v.votable_id").
+		Where("v.deleted_at IS NULL AND v.invalidated_at IS NULL").
+		Where("v.vote_type = ? AND v.created_at >= ?", models.VoteUp, since)
+}
+
+// UpvoteEdges đếm upvote theo cặp người bỏ phiếu - tác giả, bỏ qua phiếu cho chính mình
+func (r *voteRepository) UpvoteEdges(since time.Time) ([]VoteEdge, error) {
+	var edges []VoteEdge
+	err := r.upvotesWithAuthor(since).
+		Select("v.user_id AS voter_id, " + voteAuthorExpr + " AS author_id, COUNT(*) AS count").
+		Where(voteAuthorExpr + " IS NOT NULL AND v.user_id <> " + voteAuthorExpr).
+		Group("v.user_id, " + voteAuthorExpr).
+		Scan(&edges).Error
+	return edges, err
+}
+
+func (r *voteRepository) UpvoteIDsBetween(voterID, authorID uint, since time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.upvotesWithAuthor(since).
+		Where("v.user_id = ? AND "+voteAuthorExpr+" = ?", voterID, authorID).
+		Pluck("v.id", &ids).Error
+	return ids, err
+}
+
+func (r *voteRepository) InvalidateVotes(ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&models.Vote{}).Where("id IN ?", ids).UpdateColumn("invalidated_at", at).Error
+}
@@ -36,7 +36,11 @@ func ToActivityResponses(activities []models.ActivityItem) []ActivityResponse {
 			if comment.Post != nil {
 				postTitle = comment.Post.Title // Preload Post
 			}
-			desc = fmt.Sprintf("%s bình luận trên \"%s\"", comment.User.FullName, postTitle)
+			commenter := comment.User.FullName
+			if comment.Anonymous {
+				commenter = comment.Pseudonym
+			}
+			desc = fmt.Sprintf("%s bình luận trên \"%s\"", commenter, postTitle)
 			iconColor = "green"
 		case models.ActivityTopicCreated:
 			topic := act.Data.(*models.Topic)
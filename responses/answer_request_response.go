@@ -0,0 +1,50 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"time"
+)
+
+type AnswerRequestResponse struct {
+	ID            uint                    `json:"id"`
+	QuestionID    uint                    `json:"questionId"`
+	QuestionTitle string                  `json:"questionTitle,omitempty"`
+	QuestionSlug  string                  `json:"questionSlug,omitempty"`
+	Status        string                  `json:"status"`
+	AnswerID      *uint                   `json:"answerId,omitempty"`
+	User          *UserSuggestionResponse `json:"user,omitempty"`
+	Requester     *UserSuggestionResponse `json:"requester,omitempty"`
+	RespondedAt   *string                 `json:"respondedAt,omitempty"`
+	CreatedAt     string                  `json:"createdAt"`
+}
+
+func ToAnswerRequestResponse(request *models.AnswerRequest) AnswerRequestResponse {
+	response := AnswerRequestResponse{
+		ID:         request.ID,
+		QuestionID: request.QuestionID,
+		Status:     string(request.Status),
+		AnswerID:   request.AnswerID,
+		CreatedAt:  request.CreatedAt.Format(time.RFC3339),
+	}
+	if request.Question != nil {
+		response.QuestionTitle = request.Question.Title
+		response.QuestionSlug = request.Question.Slug
+	}
+	if request.User != nil {
+		user := ToUserSuggestionResponse(request.User)
+		response.User = &user
+	}
+	if request.Question != nil && request.Question.Anonymous && request.RequesterID == request.Question.UserID {
+		// Tác giả câu hỏi ẩn danh nhờ trả lời thì chỉ hiện bí danh
+		alias := request.Question.Pseudonym
+		response.Requester = &UserSuggestionResponse{Username: alias, FullName: alias}
+	} else if request.Requester != nil {
+		requester := ToUserSuggestionResponse(request.Requester)
+		response.Requester = &requester
+	}
+	if request.RespondedAt != nil {
+		at := request.RespondedAt.Format(time.RFC3339)
+		response.RespondedAt = &at
+	}
+	return response
+}
@@ -6,21 +6,27 @@ import (
 )
 
 type AnswerResponse struct {
-	ID             uint              `json:"id"`
-	Content        string            `json:"content"`
-	Title          string            `json:"title"`
-	QuestionID     uint              `json:"questionId"`
-	CreatedAt      string            `json:"createdAt"`
-	UpdatedAt      string            `json:"updatedAt"`
-	Comments       []CommentResponse `json:"comments,omitempty"`
-	Status         string            `gorm:"type:ENUM('approved','pending','rejected');default:'pending'" json:"status"`
-	Accepted       bool              `gorm:"default:false" json:"isAccepted"`
-	RootCommentID  *uint             `json:"root_comment_id,omitempty" gorm:"index"`
-	HasEditHistory bool              `gorm:"default:false" json:"has_edit_history"`
-	Author         models.User       `json:"author"`
-	Question       QuestionResponse  `json:"question"`
-	ReactionCount  int               `json:"reactionsCount"`
-	Tags           []TagResponse     `json:"tags,omitempty"` // Thêm trường Tags
+	ID             uint                  `json:"id"`
+	Content        string                `json:"content"`
+	ContentFormat  string                `json:"contentFormat"`
+	Source         string                `json:"source,omitempty"` // Nguồn markdown dùng khi chỉnh sửa
+	Title          string                `json:"title"`
+	QuestionID     uint                  `json:"questionId"`
+	CreatedAt      string                `json:"createdAt"`
+	UpdatedAt      string                `json:"updatedAt"`
+	Comments       []CommentResponse     `json:"comments,omitempty"`
+	Status         string                `gorm:"type:ENUM('approved','pending','rejected');default:'pending'" json:"status"`
+	Accepted       bool                  `gorm:"default:false" json:"isAccepted"`
+	RootCommentID  *uint                 `json:"root_comment_id,omitempty" gorm:"index"`
+	HasEditHistory bool                  `gorm:"default:false" json:"has_edit_history"`
+	Author         models.User           `json:"author"`
+	Anonymous      bool                  `json:"anonymous"`
+	RealAuthor     *models.User          `json:"realAuthor,omitempty"`
+	Question       QuestionResponse      `json:"question"`
+	ReactionCount  int                   `json:"reactionsCount"`
+	Reactions      models.ReactionCounts `json:"reactions"`      // Số tương tác theo loại, controller điền
+	Tags           []TagResponse         `json:"tags,omitempty"` // Thêm trường Tags
+	Bookmarked     bool                  `json:"bookmarked"`
 }
 
 func ToAnswerResponse(answer *models.Answer) AnswerResponse {
@@ -33,10 +39,16 @@ func ToAnswerResponse(answer *models.Answer) AnswerResponse {
 		tags = append(tags, TagResponse{ID: tag.ID, Name: tag.Name}) // Giả sử TagResponse có ID và Name
 	}
 	var question = ToQuestionResponse(&answer.Question)
+	author := answer.User
+	if answer.Anonymous {
+		author = models.AnonymousUser(answer.Pseudonym)
+	}
 	return AnswerResponse{
 		ID:             answer.ID,
 		Title:          answer.Title,
 		Content:        answer.Content,
+		ContentFormat:  contentFormat(answer.ContentFormat),
+		Source:         answer.ContentSource,
 		QuestionID:     answer.QuestionID,
 		CreatedAt:      answer.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:      answer.UpdatedAt.Format(time.RFC3339),
@@ -46,8 +58,21 @@ func ToAnswerResponse(answer *models.Answer) AnswerResponse {
 		ReactionCount:  len(answer.Reactions),
 		Comments:       comments,
 		Status:         answer.Status,
-		Author:         answer.User,
+		Author:         author,
+		Anonymous:      answer.Anonymous,
 		Question:       question,
 		Tags:           tags,
 	}
 }
+
+// RevealAuthor gắn danh tính thật cho câu trả lời ẩn danh cùng câu hỏi và bình luận ẩn danh đi kèm
+func (r *AnswerResponse) RevealAuthor(answer *models.Answer) {
+	if answer.Anonymous {
+		user := answer.User
+		r.RealAuthor = &user
+	}
+	r.Question.RevealAuthor(&answer.Question)
+	for i := range r.Comments {
+		r.Comments[i].RevealAuthor(&answer.Comments[i])
+	}
+}
@@ -0,0 +1,100 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"encoding/json"
+	"time"
+)
+
+// AutoModerationRuleStats là số lần luật khớp trong khoảng thống kê; DryRunMatches là số lần luật lẽ ra đã hành động khi chạy thử
+type AutoModerationRuleStats struct {
+	Matches       int64 `json:"matches"`
+	Applied       int64 `json:"applied"`
+	DryRunMatches int64 `json:"dryRunMatches"`
+}
+
+type AutoModerationRuleResponse struct {
+	ID          uint                            `json:"id"`
+	Name        string                          `json:"name"`
+	Description string                          `json:"description,omitempty"`
+	Conditions  models.AutoModerationConditions `json:"conditions"`
+	Action      string                          `json:"action"`
+	Reason      string                          `json:"reason,omitempty"`
+	Enabled     bool                            `json:"enabled"`
+	DryRun      bool                            `json:"dryRun"`
+	CreatedByID uint                            `json:"createdById"`
+	UpdatedByID uint                            `json:"updatedById"`
+	Stats       *AutoModerationRuleStats        `json:"stats,omitempty"`
+	CreatedAt   string                          `json:"createdAt"`
+	UpdatedAt   string                          `json:"updatedAt"`
+}
+
+type AutoModerationLogResponse struct {
+	ID          uint   `json:"id"`
+	RuleID      uint   `json:"ruleId"`
+	RuleName    string `json:"ruleName"`
+	ContentType string `json:"contentType"`
+	ContentID   uint   `json:"contentId"`
+	UserID      uint   `json:"userId"`
+	Event       string `json:"event"`
+	Action      string `json:"action"`
+	DryRun      bool   `json:"dryRun"`
+	Applied     bool   `json:"applied"`
+	Details     string `json:"details,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// ToAutoModerationRuleResponse chuyển luật sang response; summaries có thể chứa số liệu của nhiều luật, chỉ phần của luật này được dùng
+func ToAutoModerationRuleResponse(rule *models.AutoModerationRule, summaries []repositories.AutoModerationRuleSummary) AutoModerationRuleResponse {
+	var conditions models.AutoModerationConditions
+	_ = json.Unmarshal(rule.Conditions, &conditions)
+
+	resp := AutoModerationRuleResponse{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Description: rule.Description,
+		Conditions:  conditions,
+		Action:      string(rule.Action),
+		Reason:      rule.Reason,
+		Enabled:     rule.Enabled,
+		DryRun:      rule.DryRun,
+		CreatedByID: rule.CreatedByID,
+		UpdatedByID: rule.UpdatedByID,
+		CreatedAt:   rule.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   rule.UpdatedAt.Format(time.RFC3339),
+	}
+	if summaries != nil {
+		stats := &AutoModerationRuleStats{}
+		for _, summary := range summaries {
+			if summary.RuleID != rule.ID {
+				continue
+			}
+			if summary.DryRun {
+				stats.DryRunMatches += summary.Matches
+				continue
+			}
+			stats.Matches += summary.Matches
+			stats.Applied += summary.Applied
+		}
+		resp.Stats = stats
+	}
+	return resp
+}
+
+func ToAutoModerationLogResponse(entry *models.AutoModerationLog) AutoModerationLogResponse {
+	return AutoModerationLogResponse{
+		ID:          entry.ID,
+		RuleID:      entry.RuleID,
+		RuleName:    entry.RuleName,
+		ContentType: entry.ContentType,
+		ContentID:   entry.ContentID,
+		UserID:      entry.UserID,
+		Event:       entry.Event,
+		Action:      string(entry.Action),
+		DryRun:      entry.DryRun,
+		Applied:     entry.Applied,
+		Details:     entry.Details,
+		CreatedAt:   entry.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,79 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"time"
+)
+
+// Nội dung hiển thị thay cho bookmark có nội dung gốc đã bị xoá hoặc bị từ chối
+const BookmarkPlaceholder = "Nội dung này đã bị xoá hoặc không còn khả dụng"
+
+type BookmarkResponse struct {
+	ID           uint              `json:"id"`
+	TargetType   string            `json:"targetType"`
+	TargetID     uint              `json:"targetId"`
+	CollectionID *uint             `json:"collectionId"`
+	Note         string            `json:"note,omitempty"`
+	Position     int               `json:"position"`
+	Available    bool              `json:"available"`
+	Placeholder  string            `json:"placeholder,omitempty"`
+	Question     *QuestionResponse `json:"question,omitempty"`
+	Answer       *AnswerResponse   `json:"answer,omitempty"`
+	Post         *PostResponse     `json:"post,omitempty"`
+	CreatedAt    string            `json:"createdAt"`
+}
+
+type BookmarkCollectionResponse struct {
+	ID          uint        `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	IsPublic    bool        `json:"isPublic"`
+	ItemCount   *int        `json:"itemCount,omitempty"`
+	Owner       models.User `json:"owner"`
+	CreatedAt   string      `json:"createdAt"`
+	UpdatedAt   string      `json:"updatedAt"`
+}
+
+// ToBookmarkResponse nhận nội dung gốc đã nạp sẵn; available = false thì trả về placeholder thay cho nội dung
+func ToBookmarkResponse(bookmark *models.Bookmark, available bool, question *models.Question, answer *models.Answer, post *models.Post) BookmarkResponse {
+	response := BookmarkResponse{
+		ID:           bookmark.ID,
+		TargetType:   string(bookmark.TargetType),
+		TargetID:     bookmark.TargetID,
+		CollectionID: bookmark.CollectionID,
+		Note:         bookmark.Note,
+		Position:     bookmark.Position,
+		Available:    available,
+		CreatedAt:    bookmark.CreatedAt.Format(time.RFC3339),
+	}
+	if !available {
+		response.Placeholder = BookmarkPlaceholder
+		return response
+	}
+	if question != nil {
+		item := ToQuestionResponse(question)
+		response.Question = &item
+	}
+	if answer != nil {
+		item := ToAnswerResponse(answer)
+		response.Answer = &item
+	}
+	if post != nil {
+		item := ToPostResponse(post)
+		response.Post = &item
+	}
+	return response
+}
+
+func ToBookmarkCollectionResponse(collection *models.BookmarkCollection, itemCount *int) BookmarkCollectionResponse {
+	return BookmarkCollectionResponse{
+		ID:          collection.ID,
+		Name:        collection.Name,
+		Description: collection.Description,
+		IsPublic:    collection.IsPublic,
+		ItemCount:   itemCount,
+		Owner:       collection.User,
+		CreatedAt:   collection.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   collection.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -8,18 +8,37 @@ import (
 )
 
 type CommentResponse struct {
-	ID          uint        `json:"id"`
-	Content     string      `json:"content"`
-	User        models.User `json:"author"`
-	PostID      *uint       `json:"postId,omitempty"`
-	AnswerID    *uint       `json:"answerId,omitempty"`
-	PostTitle   string      `json:"postTitle,omitempty"`
-	AnswerTitle string      `json:"answerTitle,omitempty"`
-	Status      string      `json:"status"`
-	HasReply    bool        `json:"has_replies"`
-	CreatedAt   string      `json:"createdAt"`
-	UpdatedAt   string      `json:"updatedAt"`
-	ParentTitle string      `json:"parentTitle,omitempty"`
+	ID            uint                  `json:"id"`
+	Content       string                `json:"content"`
+	ContentFormat string                `json:"contentFormat"`
+	Source        string                `json:"source,omitempty"` // Nguồn markdown dùng khi chỉnh sửa
+	User          models.User           `json:"author"`
+	Anonymous     bool                  `json:"anonymous"`
+	RealAuthor    *models.User          `json:"realAuthor,omitempty"`
+	PostID        *uint                 `json:"postId,omitempty"`
+	AnswerID      *uint                 `json:"answerId,omitempty"`
+	QuestionID    *uint                 `json:"questionId,omitempty"`
+	PostTitle     string                `json:"postTitle,omitempty"`
+	AnswerTitle   string                `json:"answerTitle,omitempty"`
+	QuestionTitle string                `json:"questionTitle,omitempty"`
+	Status        string                `json:"status"`
+	HasReply      bool                  `json:"has_replies"`
+	CreatedAt     string                `json:"createdAt"`
+	UpdatedAt     string                `json:"updatedAt"`
+	ParentTitle   string                `json:"parentTitle,omitempty"`
+	Depth         int                   `json:"depth"`
+	ReactionCount int                   `json:"reactionsCount"`
+	Reactions     models.ReactionCounts `json:"reactions"` // Số tương tác theo loại, controller điền
+}
+
+// CommentThreadResponse là một nút của cây bình luận. Khi hasMoreReplies = true, giao diện tải tiếp
+// trả lời của nút qua /comments/:id/thread với nextCursor (không có cursor nghĩa là tải từ đầu).
+type CommentThreadResponse struct {
+	CommentResponse
+	ReplyCount     int64                   `json:"replyCount"`
+	HasMoreReplies bool                    `json:"hasMoreReplies"`
+	NextCursor     string                  `json:"nextCursor,omitempty"`
+	Replies        []CommentThreadResponse `json:"replies"`
 }
 
 func ToCommentResponse(comment *models.Comment) CommentResponse {
@@ -33,29 +52,71 @@ func ToCommentResponse(comment *models.Comment) CommentResponse {
 		}
 	}
 
-	var postTitle, AnswerTitle, parentTitle string
+	var postTitle, AnswerTitle, questionTitle, parentTitle string
 	if comment.Post != nil {
 		postTitle = utils.StripHTML(comment.Post.Title)
 	}
 	if comment.Answer != nil {
 		AnswerTitle = utils.StripHTML(comment.Answer.Content)
 	}
+	if comment.Question != nil {
+		questionTitle = utils.StripHTML(comment.Question.Title)
+	}
 	if comment.Parent != nil {
 		parentTitle = utils.StripHTML(comment.Parent.Content)
 	}
 
+	author := comment.User
+	if comment.Anonymous {
+		author = models.AnonymousUser(comment.Pseudonym)
+	}
+
 	return CommentResponse{
-		ID:          comment.ID,
-		Content:     comment.Content,
-		User:        comment.User,
-		PostID:      comment.PostID,
-		AnswerID:    comment.AnswerID,
-		PostTitle:   postTitle,
-		AnswerTitle: AnswerTitle,
-		Status:      comment.Status,
-		HasReply:    hasReply,
-		ParentTitle: parentTitle,
-		CreatedAt:   comment.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   comment.UpdatedAt.Format(time.RFC3339),
+		ID:            comment.ID,
+		Content:       comment.Content,
+		ContentFormat: contentFormat(comment.ContentFormat),
+		Source:        comment.ContentSource,
+		User:          author,
+		Anonymous:     comment.Anonymous,
+		PostID:        comment.PostID,
+		AnswerID:      comment.AnswerID,
+		QuestionID:    comment.QuestionID,
+		PostTitle:     postTitle,
+		AnswerTitle:   AnswerTitle,
+		QuestionTitle: questionTitle,
+		Status:        comment.Status,
+		HasReply:      hasReply,
+		ParentTitle:   parentTitle,
+		Depth:         comment.Depth,
+		CreatedAt:     comment.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     comment.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (r *CommentResponse) RevealAuthor(comment *models.Comment) {
+	if comment.Anonymous {
+		user := comment.User
+		r.RealAuthor = &user
+	}
+}
+
+// ToCommentThreadResponse chuyển cây bình luận; reveal gắn danh tính thật cho bình luận ẩn danh
+func ToCommentThreadResponse(node *models.CommentNode, reveal bool) CommentThreadResponse {
+	response := CommentThreadResponse{
+		CommentResponse: ToCommentResponse(&node.Comment),
+		ReplyCount:      node.ReplyCount,
+		HasMoreReplies:  node.ReplyCount > int64(len(node.Replies)),
+		Replies:         make([]CommentThreadResponse, 0, len(node.Replies)),
+	}
+	if reveal {
+		response.RevealAuthor(&node.Comment)
+	}
+	for _, reply := range node.Replies {
+		response.Replies = append(response.Replies, ToCommentThreadResponse(reply, reveal))
+	}
+	if response.HasMoreReplies && len(node.Replies) > 0 {
+		last := node.Replies[len(node.Replies)-1].Comment
+		response.NextCursor = utils.EncodeCursor(utils.Cursor{Time: last.CreatedAt, ID: last.ID, Sort: "asc"})
 	}
+	return response
 }
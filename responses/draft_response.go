@@ -0,0 +1,42 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"encoding/json"
+	"time"
+)
+
+type DraftResponse struct {
+	ID            uint   `json:"id"`
+	TargetType    string `json:"targetType"`
+	TargetID      uint   `json:"targetId"`
+	Title         string `json:"title"`
+	Content       string `json:"content"`
+	ContentFormat string `json:"contentFormat,omitempty"`
+	TopicID       uint   `json:"topicId,omitempty"`
+	TagIDs        []uint `json:"tagIds"`
+	Version       uint   `json:"version"`
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
+}
+
+func ToDraftResponse(draft *models.Draft) DraftResponse {
+	tagIDs := []uint{}
+	if len(draft.TagIDs) > 0 {
+		_ = json.Unmarshal(draft.TagIDs, &tagIDs)
+	}
+
+	return DraftResponse{
+		ID:            draft.ID,
+		TargetType:    string(draft.TargetType),
+		TargetID:      draft.TargetID,
+		Title:         draft.Title,
+		Content:       draft.Content,
+		ContentFormat: string(draft.ContentFormat),
+		TopicID:       draft.TopicID,
+		TagIDs:        tagIDs,
+		Version:       draft.Version,
+		CreatedAt:     draft.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     draft.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,48 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"time"
+)
+
+type UserSuggestionResponse struct {
+	ID         uint    `json:"id"`
+	Username   string  `json:"username"`
+	FullName   string  `json:"fullName"`
+	Avatar     *string `json:"avatar,omitempty"`
+	Reputation uint    `json:"reputation"`
+}
+
+type MentionResponse struct {
+	ID         uint                    `json:"id"`
+	TargetType string                  `json:"targetType"`
+	TargetID   uint                    `json:"targetId"`
+	Mentioner  *UserSuggestionResponse `json:"mentioner,omitempty"`
+	CreatedAt  string                  `json:"createdAt"`
+}
+
+func ToUserSuggestionResponse(user *models.User) UserSuggestionResponse {
+	return UserSuggestionResponse{
+		ID:         user.ID,
+		Username:   user.Username,
+		FullName:   user.FullName,
+		Avatar:     user.Avatar,
+		Reputation: user.Reputation,
+	}
+}
+
+func ToMentionResponse(mention *models.Mention) MentionResponse {
+	response := MentionResponse{
+		ID:         mention.ID,
+		TargetType: mention.TargetType,
+		TargetID:   mention.TargetID,
+		CreatedAt:  mention.CreatedAt.Format(time.RFC3339),
+	}
+	if mention.MentionerAlias != "" {
+		response.Mentioner = &UserSuggestionResponse{Username: mention.MentionerAlias, FullName: mention.MentionerAlias}
+	} else if mention.Mentioner != nil {
+		mentioner := ToUserSuggestionResponse(mention.Mentioner)
+		response.Mentioner = &mentioner
+	}
+	return response
+}
@@ -0,0 +1,86 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"time"
+)
+
+type ModerationItemResponse struct {
+	ContentType    string            `json:"contentType"`
+	ContentID      uint              `json:"contentId"`
+	Title          string            `json:"title,omitempty"`
+	Preview        string            `json:"preview"`
+	Status         string            `json:"status"`
+	Author         *UserDataResponse `json:"author,omitempty"`
+	CreatedAt      string            `json:"createdAt"`
+	WaitingSeconds int64             `json:"waitingSeconds"`
+	ClaimedBy      *uint             `json:"claimedBy,omitempty"`
+	ClaimExpiresAt string            `json:"claimExpiresAt,omitempty"`
+}
+
+type ModerationClaimResponse struct {
+	ContentType string `json:"contentType"`
+	ContentID   uint   `json:"contentId"`
+	ModeratorID uint   `json:"moderatorId"`
+	ExpiresAt   string `json:"expiresAt"`
+}
+
+type ModerationStatResponse struct {
+	Moderator      UserDataResponse `json:"moderator"`
+	Approved       int64            `json:"approved"`
+	Rejected       int64            `json:"rejected"`
+	Total          int64            `json:"total"`
+	AvgWaitSeconds int64            `json:"avgWaitSeconds"`
+	FirstActionAt  string           `json:"firstActionAt"`
+	LastActionAt   string           `json:"lastActionAt"`
+}
+
+func ToModerationItemResponse(item *models.ModerationItem) ModerationItemResponse {
+	resp := ModerationItemResponse{
+		ContentType:    item.ContentType,
+		ContentID:      item.ContentID,
+		Title:          item.Title,
+		Preview:        item.Content,
+		Status:         item.Status,
+		CreatedAt:      item.CreatedAt.Format(time.RFC3339),
+		WaitingSeconds: int64(time.Since(item.CreatedAt).Seconds()),
+		ClaimedBy:      item.ClaimedBy,
+	}
+	if item.User != nil {
+		resp.Author = &UserDataResponse{
+			ID:       item.User.ID,
+			Username: item.User.Username,
+			FullName: item.User.FullName,
+		}
+	}
+	if item.ClaimExpiresAt != nil {
+		resp.ClaimExpiresAt = item.ClaimExpiresAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func ToModerationClaimResponse(claim *models.ModerationClaim) ModerationClaimResponse {
+	return ModerationClaimResponse{
+		ContentType: claim.ContentType,
+		ContentID:   claim.ContentID,
+		ModeratorID: claim.ModeratorID,
+		ExpiresAt:   claim.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+func ToModerationStatResponse(stat *models.ModerationStat) ModerationStatResponse {
+	moderator := UserDataResponse{ID: stat.ModeratorID}
+	if stat.Moderator != nil {
+		moderator.Username = stat.Moderator.Username
+		moderator.FullName = stat.Moderator.FullName
+	}
+	return ModerationStatResponse{
+		Moderator:      moderator,
+		Approved:       stat.Approved,
+		Rejected:       stat.Rejected,
+		Total:          stat.Total,
+		AvgWaitSeconds: int64(stat.AvgWaitSeconds),
+		FirstActionAt:  stat.FirstActionAt.Format(time.RFC3339),
+		LastActionAt:   stat.LastActionAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,41 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"time"
+)
+
+type PassResponse struct {
+	QuestionID      uint              `json:"questionId"`
+	Kind            string            `json:"kind"`
+	SnoozeUntil     *string           `json:"snoozeUntil,omitempty"`
+	Hidden          bool              `json:"hidden"` // câu hỏi còn bị ẩn khỏi feed hay không
+	ResurfacedAt    *string           `json:"resurfacedAt,omitempty"`
+	ResurfaceReason string            `json:"resurfaceReason,omitempty"`
+	Question        *QuestionResponse `json:"question,omitempty"`
+	CreatedAt       string            `json:"createdAt"`
+}
+
+// ToPassResponse nhận câu hỏi đã nạp sẵn (nil nếu câu hỏi đã bị xoá)
+func ToPassResponse(pass *models.PassedQuestion, question *models.Question) PassResponse {
+	response := PassResponse{
+		QuestionID:      pass.QuestionID,
+		Kind:            string(pass.Kind),
+		Hidden:          pass.ResurfacedAt == nil && (pass.SnoozeUntil == nil || pass.SnoozeUntil.After(time.Now())),
+		ResurfaceReason: pass.ResurfaceReason,
+		CreatedAt:       pass.CreatedAt.Format(time.RFC3339),
+	}
+	if pass.SnoozeUntil != nil {
+		until := pass.SnoozeUntil.Format(time.RFC3339)
+		response.SnoozeUntil = &until
+	}
+	if pass.ResurfacedAt != nil {
+		at := pass.ResurfacedAt.Format(time.RFC3339)
+		response.ResurfacedAt = &at
+	}
+	if question != nil {
+		q := ToQuestionResponse(question)
+		response.Question = &q
+	}
+	return response
+}
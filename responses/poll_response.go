@@ -0,0 +1,97 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"math"
+	"time"
+)
+
+type PollOptionResponse struct {
+	ID      uint     `json:"id"`
+	Text    string   `json:"text"`
+	Votes   *int     `json:"votes,omitempty"`
+	Percent *float64 `json:"percent,omitempty"`
+}
+
+type PollResponse struct {
+	ID                     uint                 `json:"id"`
+	PostID                 uint                 `json:"postId"`
+	Question               string               `json:"question"`
+	MultipleChoice         bool                 `json:"multipleChoice"`
+	MaxChoices             int                  `json:"maxChoices"`
+	Anonymous              bool                 `json:"anonymous"`
+	HideResultsUntilClosed bool                 `json:"hideResultsUntilClosed"`
+	Closed                 bool                 `json:"closed"`
+	ClosesAt               string               `json:"closesAt,omitempty"`
+	ResultsHidden          bool                 `json:"resultsHidden"`
+	TotalVoters            *int                 `json:"totalVoters,omitempty"`
+	Options                []PollOptionResponse `json:"options"`
+	MyChoices              []uint               `json:"myChoices"`
+	CreatedAt              string               `json:"createdAt"`
+}
+
+type PollVoterResponse struct {
+	User      models.User `json:"user"`
+	OptionIDs []uint      `json:"optionIds"`
+	VotedAt   string      `json:"votedAt"`
+}
+
+// ToPollResponse nhận kết quả đã được tính sẵn; khi hidden = true thì không trả về số phiếu
+func ToPollResponse(poll *models.Poll, closed, hidden bool, counts map[uint]int, totalVoters int, myChoices []uint) PollResponse {
+	options := make([]PollOptionResponse, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		item := PollOptionResponse{ID: option.ID, Text: option.Text}
+		if !hidden {
+			votes := counts[option.ID]
+			percent := 0.0
+			if totalVoters > 0 {
+				percent = math.Round(float64(votes)*1000/float64(totalVoters)) / 10
+			}
+			item.Votes = &votes
+			item.Percent = &percent
+		}
+		options = append(options, item)
+	}
+
+	var closesAt string
+	if poll.ClosedAt != nil {
+		closesAt = poll.ClosedAt.Format(time.RFC3339)
+	} else if poll.ClosesAt != nil {
+		closesAt = poll.ClosesAt.Format(time.RFC3339)
+	}
+	if myChoices == nil {
+		myChoices = []uint{}
+	}
+
+	response := PollResponse{
+		ID:                     poll.ID,
+		PostID:                 poll.PostID,
+		Question:               poll.Question,
+		MultipleChoice:         poll.MultipleChoice,
+		MaxChoices:             poll.MaxChoices,
+		Anonymous:              poll.Anonymous,
+		HideResultsUntilClosed: poll.HideResultsUntilClosed,
+		Closed:                 closed,
+		ClosesAt:               closesAt,
+		ResultsHidden:          hidden,
+		Options:                options,
+		MyChoices:              myChoices,
+		CreatedAt:              poll.CreatedAt.Format(time.RFC3339),
+	}
+	if !hidden {
+		response.TotalVoters = &totalVoters
+	}
+	return response
+}
+
+func ToPollVoterResponse(ballot *models.PollBallot) PollVoterResponse {
+	optionIDs := make([]uint, 0, len(ballot.Choices))
+	for _, choice := range ballot.Choices {
+		optionIDs = append(optionIDs, choice.OptionID)
+	}
+	return PollVoterResponse{
+		User:      ballot.User,
+		OptionIDs: optionIDs,
+		VotedAt:   ballot.CreatedAt.Format(time.RFC3339),
+	}
+}
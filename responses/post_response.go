@@ -6,16 +6,22 @@ import (
 )
 
 type PostResponse struct {
-	ID            uint              `json:"id"`
-	Content       string            `json:"content"`
-	Title         string            `json:"title"`
-	Author        models.User       `json:"author"`
-	Status        string            `json:"status"`
-	Comments      []CommentResponse `json:"comments,omitempty"`
-	ReactionCount int               `json:"reactionsCount"`
-	CreatedAt     string            `json:"createdAt"`
-	UpdatedAt     string            `json:"updatedAt"`
-	Tags          []models.Tag      `json:"tags,omitempty"`
+	ID            uint                  `json:"id"`
+	Content       string                `json:"content"`
+	ContentFormat string                `json:"contentFormat"`
+	Source        string                `json:"source,omitempty"` // Nguồn markdown dùng khi chỉnh sửa
+	Title         string                `json:"title"`
+	Slug          string                `json:"slug"`
+	Author        models.User           `json:"author"`
+	Status        string                `json:"status"`
+	Comments      []CommentResponse     `json:"comments,omitempty"`
+	ReactionCount int                   `json:"reactionsCount"`
+	Reactions     models.ReactionCounts `json:"reactions"` // Số tương tác theo loại, controller điền
+	CreatedAt     string                `json:"createdAt"`
+	UpdatedAt     string                `json:"updatedAt"`
+	Tags          []models.Tag          `json:"tags,omitempty"`
+	PublishAt     string                `json:"publishAt,omitempty"`
+	Bookmarked    bool                  `json:"bookmarked"`
 }
 
 func ToPostResponse(post *models.Post) PostResponse {
@@ -24,10 +30,19 @@ func ToPostResponse(post *models.Post) PostResponse {
 		comments = append(comments, ToCommentResponse(&comment))
 	}
 
+	var publishAt string
+	if post.PublishAt != nil {
+		publishAt = post.PublishAt.Format(time.RFC3339)
+	}
+
 	return PostResponse{
 		ID:            post.ID,
+		PublishAt:     publishAt,
 		Title:         post.Title,
+		Slug:          post.Slug,
 		Content:       post.Content,
+		ContentFormat: contentFormat(post.ContentFormat),
+		Source:        post.ContentSource,
 		Author:        post.User,
 		Status:        string(post.Status),
 		ReactionCount: len(post.Reactions),
@@ -0,0 +1,52 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"time"
+)
+
+type CloseVoteStatusResponse struct {
+	QuestionID      uint           `json:"questionId"`
+	Closed          bool           `json:"closed"`
+	Locked          bool           `json:"locked"`
+	CloseVotes      int            `json:"closeVotes"`
+	CloseThreshold  int            `json:"closeThreshold"`
+	ReopenVotes     int            `json:"reopenVotes"`
+	ReopenThreshold int            `json:"reopenThreshold"`
+	HasVotedClose   bool           `json:"hasVotedClose"`
+	HasVotedReopen  bool           `json:"hasVotedReopen"`
+	Reasons         map[string]int `json:"reasons"`
+}
+
+type QuestionEventResponse struct {
+	ID            uint         `json:"id"`
+	Action        string       `json:"action"`
+	Actor         *models.User `json:"actor,omitempty"`
+	Reason        string       `json:"reason,omitempty"`
+	DuplicateOfID *uint        `json:"duplicateOfId,omitempty"`
+	Note          string       `json:"note,omitempty"`
+	ByCommunity   bool         `json:"byCommunity"`
+	VoteCount     int          `json:"voteCount,omitempty"`
+	LockedUntil   string       `json:"lockedUntil,omitempty"`
+	CreatedAt     string       `json:"createdAt"`
+}
+
+func ToQuestionEventResponse(event *models.QuestionEvent) QuestionEventResponse {
+	var lockedUntil string
+	if event.LockedUntil != nil {
+		lockedUntil = event.LockedUntil.Format(time.RFC3339)
+	}
+
+	return QuestionEventResponse{
+		ID:            event.ID,
+		Action:        string(event.Action),
+		Actor:         event.Actor,
+		Reason:        string(event.Reason),
+		DuplicateOfID: event.DuplicateOfID,
+		Note:          event.Note,
+		ByCommunity:   event.ByCommunity,
+		VoteCount:     event.VoteCount,
+		LockedUntil:   lockedUntil,
+		CreatedAt:     event.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -6,18 +6,32 @@ import (
 )
 
 type QuestionResponse struct {
-	ID                uint         `json:"id"`
-	Title             string       `json:"title"`
-	Description       string       `json:"description,omitempty"` // Uses Description field
-	AnswerCount       int          `json:"answersCount"`
-	LastFollowed      string       `json:"lastFollowed"`
-	FollowCount       int          `json:"followsCount"`
-	Topic             models.Topic `json:"topic"`
-	Status            string       `json:"status"`
-	InteractionStatus string       `json:"interactionStatus"`
-	Author            models.User  `json:"author"`
-	CreatedAt         string       `json:"createdAt"`
-	UpdatedAt         string       `json:"updatedAt"`
+	ID                uint                  `json:"id"`
+	Title             string                `json:"title"`
+	Slug              string                `json:"slug"`
+	Description       string                `json:"description,omitempty"` // Uses Description field
+	ContentFormat     string                `json:"contentFormat"`
+	Source            string                `json:"source,omitempty"` // Nguồn markdown dùng khi chỉnh sửa
+	AnswerCount       int                   `json:"answersCount"`
+	LastFollowed      string                `json:"lastFollowed"`
+	FollowCount       int                   `json:"followsCount"`
+	Topic             models.Topic          `json:"topic"`
+	Status            string                `json:"status"`
+	InteractionStatus string                `json:"interactionStatus"`
+	Author            models.User           `json:"author"` // Bí danh khi câu hỏi ẩn danh
+	Anonymous         bool                  `json:"anonymous"`
+	RealAuthor        *models.User          `json:"realAuthor,omitempty"` // Chỉ có khi người xem được phép thấy danh tính thật
+	CreatedAt         string                `json:"createdAt"`
+	UpdatedAt         string                `json:"updatedAt"`
+	PublishAt         string                `json:"publishAt,omitempty"`
+	CloseReason       string                `json:"closeReason,omitempty"`
+	DuplicateOfID     *uint                 `json:"duplicateOfId,omitempty"`
+	ClosedAt          string                `json:"closedAt,omitempty"`
+	Locked            bool                  `json:"locked"`
+	LockedUntil       string                `json:"lockedUntil,omitempty"`
+	Bookmarked        bool                  `json:"bookmarked"` // Người dùng hiện tại đã lưu hay chưa, controller điền
+	ReactionCount     int                   `json:"reactionsCount"`
+	Reactions         models.ReactionCounts `json:"reactions"` // Số tương tác theo loại, controller điền
 }
 
 func ToQuestionResponse(question *models.Question) QuestionResponse {
@@ -31,11 +45,31 @@ func ToQuestionResponse(question *models.Question) QuestionResponse {
 		}
 		lastFollowed = latest.Format(time.RFC3339)
 	}
+	var publishAt string
+	if question.PublishAt != nil {
+		publishAt = question.PublishAt.Format(time.RFC3339)
+	}
+	var closedAt, lockedUntil string
+	if question.ClosedAt != nil {
+		closedAt = question.ClosedAt.Format(time.RFC3339)
+	}
+	locked := question.IsLocked(time.Now())
+	if locked && question.LockedUntil != nil {
+		lockedUntil = question.LockedUntil.Format(time.RFC3339)
+	}
+	author := question.User
+	if question.Anonymous {
+		author = models.AnonymousUser(question.Pseudonym)
+	}
 	return QuestionResponse{
 		ID:                question.ID,
 		Title:             question.Title,
+		Slug:              question.Slug,
 		Description:       question.Description,
-		Author:            question.User,
+		ContentFormat:     contentFormat(question.ContentFormat),
+		Source:            question.ContentSource,
+		Author:            author,
+		Anonymous:         question.Anonymous,
 		AnswerCount:       len(question.Answers),
 		LastFollowed:      lastFollowed,
 		FollowCount:       len(question.Follows),
@@ -44,5 +78,27 @@ func ToQuestionResponse(question *models.Question) QuestionResponse {
 		InteractionStatus: string(question.InteractionStatus),
 		CreatedAt:         question.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:         question.UpdatedAt.Format(time.RFC3339),
+		PublishAt:         publishAt,
+		CloseReason:       string(question.CloseReason),
+		DuplicateOfID:     question.DuplicateOfID,
+		ClosedAt:          closedAt,
+		Locked:            locked,
+		LockedUntil:       lockedUntil,
+	}
+}
+
+// RevealAuthor gắn danh tính thật cho câu hỏi ẩn danh; controller chỉ gọi khi người xem có quyền
+func (r *QuestionResponse) RevealAuthor(question *models.Question) {
+	if question.Anonymous {
+		user := question.User
+		r.RealAuthor = &user
+	}
+}
+
+// contentFormat trả về định dạng nội dung, bản ghi cũ chưa có giá trị được coi là html
+func contentFormat(format models.ContentFormat) string {
+	if format == "" {
+		return string(models.ContentFormatHTML)
 	}
+	return string(format)
 }
@@ -16,7 +16,8 @@ type ReportResponse struct {
 	ID             string            `json:"id"`
 	Reason         string            `json:"reason"`
 	Details        string            `json:"details,omitempty"`
-	Reporter       UserDataResponse  `json:"reporter"`
+	Reporter       *UserDataResponse `json:"reporter,omitempty"`
+	Source         string            `json:"source"`
 	ContentType    string            `json:"contentType"`
 	ContentID      string            `json:"contentId"`
 	ContentPreview string            `json:"contentPreview"`
@@ -27,14 +28,17 @@ type ReportResponse struct {
 }
 
 func ToReportResponse(report *models.Report) (ReportResponse, error) {
-	if report.Reporter.ID == 0 {
-		return ReportResponse{}, errors.New("reporter data not loaded")
-	}
-
-	reporter := UserDataResponse{
-		ID:       report.Reporter.ID,
-		Username: report.Reporter.Username,
-		FullName: report.Reporter.FullName,
+	// Báo cáo do hệ thống tạo không có người báo cáo
+	var reporter *UserDataResponse
+	if report.ReporterID != nil {
+		if report.Reporter == nil || report.Reporter.ID == 0 {
+			return ReportResponse{}, errors.New("reporter data not loaded")
+		}
+		reporter = &UserDataResponse{
+			ID:       report.Reporter.ID,
+			Username: report.Reporter.Username,
+			FullName: report.Reporter.FullName,
+		}
 	}
 
 	var resolvedBy *UserDataResponse
@@ -56,6 +60,7 @@ func ToReportResponse(report *models.Report) (ReportResponse, error) {
 		Reason:         report.Reason,
 		Details:        report.Details,
 		Reporter:       reporter,
+		Source:         report.Source,
 		ContentType:    report.ContentType,
 		ContentID:      report.ContentID,
 		ContentPreview: report.ContentPreview,
@@ -0,0 +1,40 @@
+package responses
+
+import (
+	"Forum_BE/repositories"
+	"time"
+)
+
+type SearchHitResponse struct {
+	Type      string  `json:"type"`
+	ID        uint    `json:"id"`
+	Title     string  `json:"title"`
+	Snippet   string  `json:"snippet"`
+	Score     float64 `json:"score"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+type SearchFacetResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+func ToSearchHitResponse(hit *repositories.SearchHit) SearchHitResponse {
+	return SearchHitResponse{
+		Type:      hit.Type,
+		ID:        hit.ID,
+		Title:     hit.Title,
+		Snippet:   hit.Body,
+		Score:     hit.Score,
+		CreatedAt: hit.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToSearchFacetResponses(facets []repositories.SearchFacet) []SearchFacetResponse {
+	res := make([]SearchFacetResponse, 0, len(facets))
+	for _, f := range facets {
+		res = append(res, SearchFacetResponse{ID: f.ID, Name: f.Name, Count: f.Count})
+	}
+	return res
+}
@@ -0,0 +1,57 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"time"
+)
+
+// SpamModelEvaluation là kết quả chấm tập kiểm tra (mẫu không dùng để huấn luyện) tại ngưỡng của phiên bản
+type SpamModelEvaluation struct {
+	TestSize       int     `json:"testSize"`
+	TruePositives  int     `json:"truePositives"`
+	FalsePositives int     `json:"falsePositives"`
+	TrueNegatives  int     `json:"trueNegatives"`
+	FalseNegatives int     `json:"falseNegatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+	Accuracy       float64 `json:"accuracy"`
+}
+
+type SpamModelVersionResponse struct {
+	ID             uint                `json:"id"`
+	SampleCount    int                 `json:"sampleCount"`
+	SpamCount      int                 `json:"spamCount"`
+	HamCount       int                 `json:"hamCount"`
+	VocabularySize int                 `json:"vocabularySize"`
+	Threshold      float64             `json:"threshold"`
+	Evaluation     SpamModelEvaluation `json:"evaluation"`
+	Active         bool                `json:"active"`
+	TrainedByID    *uint               `json:"trainedById,omitempty"`
+	CreatedAt      string              `json:"createdAt"`
+}
+
+func ToSpamModelVersionResponse(version *models.SpamModelVersion) SpamModelVersionResponse {
+	return SpamModelVersionResponse{
+		ID:             version.ID,
+		SampleCount:    version.SampleCount,
+		SpamCount:      version.SpamCount,
+		HamCount:       version.HamCount,
+		VocabularySize: version.VocabularySize,
+		Threshold:      version.Threshold,
+		Evaluation: SpamModelEvaluation{
+			TestSize:       version.TestSize,
+			TruePositives:  version.TruePositives,
+			FalsePositives: version.FalsePositives,
+			TrueNegatives:  version.TrueNegatives,
+			FalseNegatives: version.FalseNegatives,
+			Precision:      version.Precision,
+			Recall:         version.Recall,
+			F1:             version.F1,
+			Accuracy:       version.Accuracy,
+		},
+		Active:      version.Active,
+		TrainedByID: version.TrainedByID,
+		CreatedAt:   version.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,62 @@
+package responses
+
+import (
+	"Forum_BE/models"
+	"encoding/json"
+	"time"
+)
+
+type SuggestedEditResponse struct {
+	ID          uint         `json:"id"`
+	TargetType  string       `json:"targetType"`
+	TargetID    uint         `json:"targetId"`
+	Editor      models.User  `json:"editor"`
+	Title       string       `json:"title"`
+	Content     string       `json:"content"`
+	TopicID     uint         `json:"topicId,omitempty"`
+	TagIDs      []uint       `json:"tagIds"`
+	Summary     string       `json:"summary"`
+	BaseTitle   string       `json:"baseTitle"`
+	BaseContent string       `json:"baseContent"`
+	Status      string       `json:"status"`
+	Conflict    bool         `json:"conflict"`
+	Improved    bool         `json:"improved"`
+	Reviewer    *models.User `json:"reviewer,omitempty"`
+	ReviewNote  string       `json:"reviewNote,omitempty"`
+	ReviewedAt  string       `json:"reviewedAt,omitempty"`
+	CreatedAt   string       `json:"createdAt"`
+	UpdatedAt   string       `json:"updatedAt"`
+}
+
+func ToSuggestedEditResponse(edit *models.SuggestedEdit) SuggestedEditResponse {
+	tagIDs := []uint{}
+	if len(edit.TagIDs) > 0 {
+		_ = json.Unmarshal(edit.TagIDs, &tagIDs)
+	}
+	var reviewedAt string
+	if edit.ReviewedAt != nil {
+		reviewedAt = edit.ReviewedAt.Format(time.RFC3339)
+	}
+
+	return SuggestedEditResponse{
+		ID:          edit.ID,
+		TargetType:  edit.TargetType,
+		TargetID:    edit.TargetID,
+		Editor:      edit.Editor,
+		Title:       edit.Title,
+		Content:     edit.Content,
+		TopicID:     edit.TopicID,
+		TagIDs:      tagIDs,
+		Summary:     edit.Summary,
+		BaseTitle:   edit.BaseTitle,
+		BaseContent: edit.BaseContent,
+		Status:      string(edit.Status),
+		Conflict:    edit.Conflict,
+		Improved:    edit.Improved,
+		Reviewer:    edit.Reviewer,
+		ReviewNote:  edit.ReviewNote,
+		ReviewedAt:  reviewedAt,
+		CreatedAt:   edit.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   edit.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -26,3 +26,17 @@ func ToTopicResponse(topic *models.Topic) TopicResponse {
 		FollowersCount: topic.FollowersCount,
 	}
 }
+
+type TopicSuggestionResponse struct {
+	ID         uint    `json:"id"`
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+func ToTopicSuggestionResponse(topicID uint, name string, confidence float64) TopicSuggestionResponse {
+	return TopicSuggestionResponse{
+		ID:         topicID,
+		Name:       name,
+		Confidence: confidence,
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func AnswerRequestRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, novuClient *notification.NovuClient) {
+	answerRequestService := services.NewAnswerRequestService(
+		repositories.NewAnswerRequestRepository(db),
+		repositories.NewQuestionRepository(db),
+		repositories.NewUserRepository(db),
+		novuClient,
+	)
+	answerRequestController := controllers.NewAnswerRequestController(answerRequestService)
+
+	authorized.POST("/questions/:id/answer-requests", middlewares.CheckPermission(permService, "answer_request", "create"), answerRequestController.RequestAnswers)
+	authorized.GET("/questions/:id/answer-requests", middlewares.CheckPermission(permService, "answer_request", "view"), answerRequestController.ListForQuestion)
+
+	requests := authorized.Group("/answer-requests")
+	{
+		requests.GET("/", middlewares.CheckPermission(permService, "answer_request", "view"), answerRequestController.ListInbox)
+		requests.PUT("/:id/decline", middlewares.CheckPermission(permService, "answer_request", "edit"), answerRequestController.DeclineRequest)
+	}
+}
@@ -11,17 +11,20 @@ import (
 	"gorm.io/gorm"
 )
 
-func AnswerRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient) {
+func AnswerRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient, searchIndexService services.SearchIndexService, topicClassifierService services.TopicClassifierService, autoModerationService services.AutoModerationService) {
 	topicRepo := repositories.NewTopicRepository(db)
 	userRepo := repositories.NewUserRepository(db)
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), userRepo, novuClient)
 	topicService := services.NewTopicService(topicRepo, redisClient, db)
 	questionRepo := repositories.NewQuestionRepository(db)
-	questionService := services.NewQuestionService(questionRepo, topicService, redisClient, userRepo, novuClient)
+	questionService := services.NewQuestionService(questionRepo, topicService, redisClient, userRepo, novuClient, searchIndexService, topicClassifierService, mentionService, autoModerationService)
 	answerRepo := repositories.NewAnswerRepository(db)
-	answerService := services.NewAnswerService(answerRepo, questionRepo, questionService, userRepo, redisClient, novuClient)
-	answerController := controllers.NewAnswerController(answerService)
+	answerRequestService := services.NewAnswerRequestService(repositories.NewAnswerRequestRepository(db), questionRepo, userRepo, novuClient)
+	answerService := services.NewAnswerService(answerRepo, questionRepo, questionService, userRepo, redisClient, novuClient, searchIndexService, mentionService, answerRequestService, autoModerationService)
+	bookmarkService := services.NewBookmarkService(repositories.NewBookmarkRepository(db))
+	answerController := controllers.NewAnswerController(answerService, bookmarkService, services.NewReactionCounter(repositories.NewReactionRepository(db), redisClient))
 
-	answers := authorized.Group("/answers")
+	answers := authorized.Group("/answers", middlewares.RevealAnonymous(permService))
 	{
 		answers.POST("/", middlewares.CheckPermission(permService, "answer", "create"), answerController.CreateAnswer)
 		answers.GET("/:id", middlewares.CheckPermission(permService, "answer", "view"), answerController.GetAnswer)
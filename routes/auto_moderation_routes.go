@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+)
+
+func AutoModerationRoutes(authorized *gin.RouterGroup, permService services.PermissionService, autoModerationService services.AutoModerationService) {
+	autoModerationController := controllers.NewAutoModerationController(autoModerationService)
+
+	// Luật kiểm duyệt tự động áp dụng khi tạo và sửa câu hỏi, câu trả lời, bài viết, bình luận
+	autoModeration := authorized.Group("/auto-moderation")
+	{
+		autoModeration.GET("/rules", middlewares.CheckPermission(permService, "auto_moderation", "view"), autoModerationController.ListRules)
+		autoModeration.GET("/rules/:id", middlewares.CheckPermission(permService, "auto_moderation", "view"), autoModerationController.GetRule)
+		autoModeration.POST("/rules", middlewares.CheckPermission(permService, "auto_moderation", "manage"), autoModerationController.CreateRule)
+		autoModeration.PUT("/rules/:id", middlewares.CheckPermission(permService, "auto_moderation", "manage"), autoModerationController.UpdateRule)
+		autoModeration.DELETE("/rules/:id", middlewares.CheckPermission(permService, "auto_moderation", "manage"), autoModerationController.DeleteRule)
+		autoModeration.POST("/rules/test", middlewares.CheckPermission(permService, "auto_moderation", "view"), autoModerationController.TestRules)
+		autoModeration.GET("/logs", middlewares.CheckPermission(permService, "auto_moderation", "view"), autoModerationController.ListLogs)
+	}
+}
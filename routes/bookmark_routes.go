@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func BookmarkRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService) {
+	bookmarkService := services.NewBookmarkService(repositories.NewBookmarkRepository(db))
+	bookmarkController := controllers.NewBookmarkController(bookmarkService)
+
+	bookmarks := authorized.Group("/bookmarks")
+	{
+		bookmarks.POST("/", middlewares.CheckPermission(permService, "bookmark", "create"), bookmarkController.AddBookmark)
+		bookmarks.GET("/", middlewares.CheckPermission(permService, "bookmark", "view"), bookmarkController.ListBookmarks)
+		bookmarks.PUT("/reorder", middlewares.CheckPermission(permService, "bookmark", "edit"), bookmarkController.ReorderBookmarks)
+		bookmarks.DELETE("/target", middlewares.CheckPermission(permService, "bookmark", "delete"), bookmarkController.RemoveBookmarkByTarget)
+		bookmarks.PUT("/:id", middlewares.CheckPermission(permService, "bookmark", "edit"), bookmarkController.UpdateBookmark)
+		bookmarks.DELETE("/:id", middlewares.CheckPermission(permService, "bookmark", "delete"), bookmarkController.RemoveBookmark)
+
+		// Bộ sưu tập có tên; bộ sưu tập công khai xem được bởi người khác
+		bookmarks.GET("/collections", middlewares.CheckPermission(permService, "bookmark", "view"), bookmarkController.ListCollections)
+		bookmarks.POST("/collections", middlewares.CheckPermission(permService, "bookmark", "create"), bookmarkController.CreateCollection)
+		bookmarks.GET("/collections/:id", middlewares.CheckPermission(permService, "bookmark", "view"), bookmarkController.GetCollection)
+		bookmarks.PUT("/collections/:id", middlewares.CheckPermission(permService, "bookmark", "edit"), bookmarkController.UpdateCollection)
+		bookmarks.DELETE("/collections/:id", middlewares.CheckPermission(permService, "bookmark", "delete"), bookmarkController.DeleteCollection)
+	}
+	authorized.GET("/users/:id/collections", middlewares.CheckPermission(permService, "bookmark", "view"), bookmarkController.ListUserCollections)
+}
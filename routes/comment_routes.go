@@ -11,17 +11,20 @@ import (
 	"gorm.io/gorm"
 )
 
-func CommentRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient) {
+func CommentRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient, autoModerationService services.AutoModerationService) {
 	voteRepo := repositories.NewVoteRepository(db)
 	voteService := services.NewVoteService(voteRepo)
 	postRepo := repositories.NewPostRepository(db)
 	answerRepo := repositories.NewAnswerRepository(db)
 	commentRepo := repositories.NewCommentRepository(db)
 	userRepo := repositories.NewUserRepository(db)
-	commentService := services.NewCommentService(commentRepo, postRepo, answerRepo, userRepo, redisClient, db, novuClient)
-	commentController := controllers.NewCommentController(commentService, voteService)
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), userRepo, novuClient)
+	questionRepo := repositories.NewQuestionRepository(db)
+	followRepo := repositories.NewQuestionFollowRepository(db)
+	commentService := services.NewCommentService(commentRepo, postRepo, answerRepo, questionRepo, followRepo, userRepo, redisClient, db, novuClient, mentionService, autoModerationService)
+	commentController := controllers.NewCommentController(commentService, voteService, services.NewReactionCounter(repositories.NewReactionRepository(db), redisClient))
 
-	comments := authorized.Group("/comments")
+	comments := authorized.Group("/comments", middlewares.RevealAnonymous(permService))
 	{
 		comments.POST("/", middlewares.CheckPermission(permService, "comment", "create"), commentController.CreateComment)
 		comments.GET("/:id", middlewares.CheckPermission(permService, "comment", "view"), commentController.GetComment)
@@ -29,6 +32,8 @@ func CommentRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService service
 		comments.DELETE("/:id", middlewares.CheckPermission(permService, "comment", "delete"), commentController.DeleteComment)
 		comments.GET("/", middlewares.CheckPermission(permService, "comment", "view"), commentController.ListComments)
 		comments.GET("/:id/replies", middlewares.CheckPermission(permService, "comment", "view"), commentController.ListReplies)
+		comments.GET("/thread", middlewares.CheckPermission(permService, "comment", "view"), commentController.GetThread)
+		comments.GET("/:id/thread", middlewares.CheckPermission(permService, "comment", "view"), commentController.GetCommentThread)
 		comments.GET("/all", middlewares.CheckPermission(permService, "comment", "view"), commentController.GetAllComments)
 		comments.PUT("status/:id", middlewares.CheckPermission(permService, "comment", "edit"), commentController.UpdateStatus)
 	}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+func DraftRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient, searchIndexService services.SearchIndexService, topicClassifierService services.TopicClassifierService, autoModerationService services.AutoModerationService) {
+	userRepo := repositories.NewUserRepository(db)
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), userRepo, novuClient)
+	topicRepo := repositories.NewTopicRepository(db)
+	topicService := services.NewTopicService(topicRepo, redisClient, db)
+	questionRepo := repositories.NewQuestionRepository(db)
+	questionService := services.NewQuestionService(questionRepo, topicService, redisClient, userRepo, novuClient, searchIndexService, topicClassifierService, mentionService, autoModerationService)
+	answerRequestService := services.NewAnswerRequestService(repositories.NewAnswerRequestRepository(db), questionRepo, userRepo, novuClient)
+	answerService := services.NewAnswerService(repositories.NewAnswerRepository(db), questionRepo, questionService, userRepo, redisClient, novuClient, searchIndexService, mentionService, answerRequestService, autoModerationService)
+	postService := services.NewPostService(repositories.NewPostRepository(db), redisClient, userRepo, novuClient, searchIndexService, mentionService, autoModerationService)
+	draftService := services.NewDraftService(repositories.NewDraftRepository(db), questionService, answerService, postService)
+	draftController := controllers.NewDraftController(draftService)
+
+	// Bản nháp tự động lưu, mỗi người dùng một bản cho mỗi đích (targetType + targetId)
+	drafts := authorized.Group("/drafts")
+	{
+		drafts.PUT("/", middlewares.CheckPermission(permService, "draft", "create"), draftController.SaveDraft)
+		drafts.GET("/", middlewares.CheckPermission(permService, "draft", "view"), draftController.ListDrafts)
+		drafts.GET("/target", middlewares.CheckPermission(permService, "draft", "view"), draftController.GetDraftByTarget)
+		drafts.GET("/:id", middlewares.CheckPermission(permService, "draft", "view"), draftController.GetDraft)
+		drafts.DELETE("/:id", middlewares.CheckPermission(permService, "draft", "delete"), draftController.DeleteDraft)
+		drafts.POST("/:id/publish", middlewares.CheckPermission(permService, "draft", "create"), draftController.PublishDraft)
+	}
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FeedRoutes đăng ký feed Atom/RSS. Trình đọc feed không gửi được JWT nên các feed nằm ngoài nhóm authorized
+// và xác thực bằng feed token riêng (?token=...); token được lấy/đổi qua các route cần đăng nhập.
+func FeedRoutes(r *gin.Engine, db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService) {
+	feedService := services.NewFeedService(
+		repositories.NewFeedRepository(db),
+		repositories.NewUserRepository(db),
+		repositories.NewTopicRepository(db),
+		repositories.NewTagRepository(db),
+		repositories.NewQuestionRepository(db),
+	)
+	feedController := controllers.NewFeedController(feedService)
+
+	authorized.GET("/feeds/token", middlewares.CheckPermission(permService, "feed", "view"), feedController.GetToken)
+	authorized.POST("/feeds/token/rotate", middlewares.CheckPermission(permService, "feed", "view"), feedController.RotateToken)
+
+	feeds := r.Group("/api/feeds")
+	feeds.Use(middlewares.FeedTokenMiddleware(feedService), middlewares.CheckPermission(permService, "feed", "view"))
+	{
+		feeds.GET("/questions", feedController.QuestionsFeed)
+		feeds.GET("/questions/:id/answers", feedController.AnswersFeed)
+		feeds.GET("/topics/:id", feedController.TopicFeed)
+		feeds.GET("/tags/:id", feedController.TagFeed)
+		feeds.GET("/users/:id", feedController.UserFeed)
+	}
+}
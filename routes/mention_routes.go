@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func MentionRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, novuClient *notification.NovuClient) {
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), repositories.NewUserRepository(db), novuClient)
+	mentionController := controllers.NewMentionController(mentionService)
+
+	authorized.GET("/users/autocomplete", middlewares.CheckPermission(permService, "mention", "autocomplete"), mentionController.Autocomplete)
+	authorized.GET("/mentions", middlewares.CheckPermission(permService, "mention", "view"), mentionController.ListMentions)
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+func ModerationRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient, searchIndexService services.SearchIndexService, topicClassifierService services.TopicClassifierService, autoModerationService services.AutoModerationService) {
+	userRepo := repositories.NewUserRepository(db)
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), userRepo, novuClient)
+	topicService := services.NewTopicService(repositories.NewTopicRepository(db), redisClient, db)
+	questionRepo := repositories.NewQuestionRepository(db)
+	questionService := services.NewQuestionService(questionRepo, topicService, redisClient, userRepo, novuClient, searchIndexService, topicClassifierService, mentionService, autoModerationService)
+	answerRepo := repositories.NewAnswerRepository(db)
+	answerRequestService := services.NewAnswerRequestService(repositories.NewAnswerRequestRepository(db), questionRepo, userRepo, novuClient)
+	answerService := services.NewAnswerService(answerRepo, questionRepo, questionService, userRepo, redisClient, novuClient, searchIndexService, mentionService, answerRequestService, autoModerationService)
+	postRepo := repositories.NewPostRepository(db)
+	postService := services.NewPostService(postRepo, redisClient, userRepo, novuClient, searchIndexService, mentionService, autoModerationService)
+	commentService := services.NewCommentService(repositories.NewCommentRepository(db), postRepo, answerRepo, questionRepo, repositories.NewQuestionFollowRepository(db), userRepo, redisClient, db, novuClient, mentionService, autoModerationService)
+	moderationService := services.NewModerationService(repositories.NewModerationRepository(db), questionService, answerService, postService, commentService, novuClient)
+	moderationController := controllers.NewModerationController(moderationService)
+
+	// Hàng đợi kiểm duyệt chung cho câu hỏi, câu trả lời, bài viết và bình luận
+	moderation := authorized.Group("/moderation")
+	{
+		moderation.GET("/queue", middlewares.CheckPermission(permService, "moderation", "view"), moderationController.ListQueue)
+		moderation.POST("/claims", middlewares.CheckPermission(permService, "moderation", "claim"), moderationController.Claim)
+		moderation.DELETE("/claims/:type/:id", middlewares.CheckPermission(permService, "moderation", "claim"), moderationController.Release)
+		moderation.POST("/review", middlewares.CheckPermission(permService, "moderation", "review"), moderationController.Review)
+		moderation.GET("/stats", middlewares.CheckPermission(permService, "moderation", "stats"), moderationController.Stats)
+	}
+}
@@ -13,12 +13,15 @@ import (
 func PassRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client) {
 	// Khởi tạo repo, service, controller
 	passRepo := repositories.NewPassRepository(db)
-	passService := services.NewPassService(passRepo, redisClient)
+	passService := services.NewPassService(passRepo, repositories.NewQuestionRepository(db), redisClient)
 	passController := controllers.NewPassController(passService)
 
 	passes := authorized.Group("/passes")
 	{
+		passes.GET("/", middlewares.CheckPermission(permService, "pass", "view"), passController.ListPasses)
 		passes.PUT("/:id/pass", middlewares.CheckPermission(permService, "pass", "create"), passController.PassQuestion)
+		passes.PUT("/:id/snooze", middlewares.CheckPermission(permService, "pass", "create"), passController.SnoozeQuestion)
+		passes.DELETE("/:id", middlewares.CheckPermission(permService, "pass", "delete"), passController.UndoPass)
 		passes.GET("/passed-ids", middlewares.CheckPermission(permService, "pass", "view"), passController.GetPassedQuestionIDs)
 	}
 }
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+func PollRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client) {
+	pollService := services.NewPollService(repositories.NewPollRepository(db), repositories.NewPostRepository(db), redisClient)
+	pollController := controllers.NewPollController(pollService, permService)
+
+	// Bình chọn gắn với bài viết
+	authorized.POST("/posts/:id/poll", middlewares.CheckPermission(permService, "poll", "create"), pollController.CreatePoll)
+	authorized.GET("/posts/:id/poll", middlewares.CheckPermission(permService, "poll", "view"), pollController.GetPostPoll)
+
+	polls := authorized.Group("/polls")
+	{
+		polls.GET("/:id", middlewares.CheckPermission(permService, "poll", "view"), pollController.GetPoll)
+		polls.POST("/:id/vote", middlewares.CheckPermission(permService, "poll", "vote"), pollController.Vote)
+		polls.DELETE("/:id/vote", middlewares.CheckPermission(permService, "poll", "vote"), pollController.RetractVote)
+		polls.GET("/:id/voters", middlewares.CheckPermission(permService, "poll", "view"), pollController.ListVoters)
+		polls.POST("/:id/close", middlewares.CheckPermission(permService, "poll", "create"), pollController.ClosePoll)
+		polls.DELETE("/:id", middlewares.CheckPermission(permService, "poll", "create"), pollController.DeletePoll)
+	}
+}
@@ -11,12 +11,14 @@ import (
 	"gorm.io/gorm"
 )
 
-func PostRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient) {
+func PostRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient, searchIndexService services.SearchIndexService, autoModerationService services.AutoModerationService) {
 	// Post routes
 	postRepo := repositories.NewPostRepository(db)
 	userRepo := repositories.NewUserRepository(db)
-	postService := services.NewPostService(postRepo, redisClient, userRepo, novuClient)
-	postController := controllers.NewPostController(postService)
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), userRepo, novuClient)
+	postService := services.NewPostService(postRepo, redisClient, userRepo, novuClient, searchIndexService, mentionService, autoModerationService)
+	bookmarkService := services.NewBookmarkService(repositories.NewBookmarkRepository(db))
+	postController := controllers.NewPostController(postService, bookmarkService, services.NewReactionCounter(repositories.NewReactionRepository(db), redisClient))
 
 	posts := authorized.Group("/posts")
 	{
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+func QuestionCloseRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient) {
+	closeService := services.NewQuestionCloseService(repositories.NewQuestionCloseRepository(db), repositories.NewQuestionRepository(db), redisClient, novuClient)
+	closeController := controllers.NewQuestionCloseController(closeService)
+
+	questions := authorized.Group("/questions")
+	{
+		// Người kiểm duyệt đóng/mở lại/khoá trực tiếp
+		questions.POST("/:id/close", middlewares.CheckPermission(permService, "question", "close"), closeController.CloseQuestion)
+		questions.POST("/:id/reopen", middlewares.CheckPermission(permService, "question", "close"), closeController.ReopenQuestion)
+		questions.POST("/:id/lock", middlewares.CheckPermission(permService, "question", "lock"), closeController.LockQuestion)
+		questions.POST("/:id/unlock", middlewares.CheckPermission(permService, "question", "lock"), closeController.UnlockQuestion)
+		// Cộng đồng bỏ phiếu đóng/mở lại
+		questions.GET("/:id/close-votes", middlewares.CheckPermission(permService, "question", "view"), closeController.GetVoteStatus)
+		questions.POST("/:id/close-votes", middlewares.CheckPermission(permService, "question", "vote_close"), closeController.VoteToClose)
+		questions.POST("/:id/reopen-votes", middlewares.CheckPermission(permService, "question", "vote_close"), closeController.VoteToReopen)
+		questions.DELETE("/:id/close-votes", middlewares.CheckPermission(permService, "question", "vote_close"), closeController.RetractVote)
+		questions.GET("/:id/timeline", middlewares.CheckPermission(permService, "question", "view"), closeController.GetTimeline)
+	}
+}
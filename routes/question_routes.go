@@ -11,16 +11,18 @@ import (
 	"gorm.io/gorm"
 )
 
-func QuestionRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient) {
+func QuestionRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient, searchIndexService services.SearchIndexService, topicClassifierService services.TopicClassifierService, autoModerationService services.AutoModerationService) {
 	topicRepo := repositories.NewTopicRepository(db)
 	questionRepo := repositories.NewQuestionRepository(db)
 	userRepo := repositories.NewUserRepository(db)
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), userRepo, novuClient)
 	topicService := services.NewTopicService(topicRepo, redisClient, db)
-	questionService := services.NewQuestionService(questionRepo, topicService, redisClient, userRepo, novuClient)
+	questionService := services.NewQuestionService(questionRepo, topicService, redisClient, userRepo, novuClient, searchIndexService, topicClassifierService, mentionService, autoModerationService)
 
-	questionController := controllers.NewQuestionController(questionService)
+	bookmarkService := services.NewBookmarkService(repositories.NewBookmarkRepository(db))
+	questionController := controllers.NewQuestionController(questionService, bookmarkService, services.NewReactionCounter(repositories.NewReactionRepository(db), redisClient))
 
-	questions := authorized.Group("/questions")
+	questions := authorized.Group("/questions", middlewares.RevealAnonymous(permService))
 	{
 		questions.POST("/", middlewares.CheckPermission(permService, "question", "create"), questionController.CreateQuestion)
 		questions.GET("/:id", middlewares.CheckPermission(permService, "question", "view"), questionController.GetQuestion)
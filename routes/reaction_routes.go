@@ -17,7 +17,7 @@ func ReactionRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService servic
 	postRepo := repositories.NewPostRepository(db)
 	answerRepo := repositories.NewAnswerRepository(db)
 	commentRepo := repositories.NewCommentRepository(db)
-	reactionService := services.NewReactionService(reactionRepo, userRepo, answerRepo, postRepo, commentRepo, redisClient, novuClient)
+	reactionService := services.NewReactionService(reactionRepo, userRepo, answerRepo, postRepo, commentRepo, repositories.NewQuestionRepository(db), services.NewReactionCounter(reactionRepo, redisClient), redisClient, novuClient)
 	reactionController := controllers.NewReactionController(reactionService)
 
 	reactions := authorized.Group("/reactions")
@@ -27,6 +27,7 @@ func ReactionRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService servic
 		reactions.PUT("/:id", middlewares.CheckPermission(permService, "reaction", "edit"), reactionController.UpdateReaction)
 		reactions.DELETE("/:id", middlewares.CheckPermission(permService, "reaction", "delete"), reactionController.DeleteReaction)
 		reactions.GET("/", middlewares.CheckPermission(permService, "reaction", "view"), reactionController.ListReactions)
+		reactions.GET("/types", middlewares.CheckPermission(permService, "reaction", "view"), reactionController.ListReactionTypes)
 		reactions.GET("/count", middlewares.CheckPermission(permService, "reaction", "view"), reactionController.GetReactionCount)
 		reactions.GET("/check", middlewares.CheckPermission(permService, "reaction", "view"), reactionController.CheckUserReaction)
 		reactions.GET("/status", middlewares.CheckPermission(permService, "reaction", "view"), reactionController.GetReactionStatus)
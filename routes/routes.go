@@ -4,6 +4,7 @@ import (
 	"Forum_BE/config"
 	"Forum_BE/jobs"
 	"Forum_BE/notification"
+	"Forum_BE/searchindex"
 	"os"
 
 	// "Forum_BE/config"
@@ -17,16 +18,33 @@ import (
 	"log"
 )
 
-func SetupRoutes(r *gin.Engine, db *gorm.DB, jwtSecret string, redisClient *redis.Client) {
+// SetupRoutes khởi tạo service, cron job và route; hàm shutdown trả về dừng cron job
+// rồi đóng chỉ mục tìm kiếm, cần được gọi sau khi HTTP server đã dừng nhận request.
+func SetupRoutes(r *gin.Engine, db *gorm.DB, jwtSecret string, redisClient *redis.Client) (shutdown func()) {
 	userRepo := repositories.NewUserRepository(db)
 	permissionRepo := repositories.NewPermissionRepository(db)
 	permService := services.NewPermissionService(permissionRepo, userRepo)
 	novuClient := notification.NewNovuClient(os.Getenv("NOVU"))
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), userRepo, novuClient)
+	// Bộ phân loại spam giữ mô hình trong bộ nhớ nên chỉ tạo một lần và dùng chung cho mọi route
+	spamClassifierService := services.NewSpamClassifierService(repositories.NewSpamClassifierRepository(db))
+	autoModerationService := services.NewAutoModerationService(repositories.NewAutoModerationRepository(db), userRepo, redisClient, novuClient, spamClassifierService)
+	searchIndexService := services.NewSearchIndexService(repositories.NewSearchRepository(db), openSearchIndex())
 	questionRepo := repositories.NewQuestionRepository(db)
 	topicRepo := repositories.NewTopicRepository(db)
 	topicSer := services.NewTopicService(topicRepo, redisClient, db)
-	questionSer := services.NewQuestionService(questionRepo, topicSer, redisClient, userRepo, novuClient)
-	jobs.StartCronJobs(questionSer)
+	topicClassifierService := services.NewTopicClassifierService(topicRepo)
+	questionSer := services.NewQuestionService(questionRepo, topicSer, redisClient, userRepo, novuClient, searchIndexService, topicClassifierService, mentionService, autoModerationService)
+	postSer := services.NewPostService(repositories.NewPostRepository(db), redisClient, userRepo, novuClient, searchIndexService, mentionService, autoModerationService)
+	answerRequestSer := services.NewAnswerRequestService(repositories.NewAnswerRequestRepository(db), questionRepo, userRepo, novuClient)
+	answerSer := services.NewAnswerService(repositories.NewAnswerRepository(db), questionRepo, questionSer, userRepo, redisClient, novuClient, searchIndexService, mentionService, answerRequestSer, autoModerationService)
+	draftSer := services.NewDraftService(repositories.NewDraftRepository(db), questionSer, answerSer, postSer)
+	questionCloseSer := services.NewQuestionCloseService(repositories.NewQuestionCloseRepository(db), questionRepo, redisClient, novuClient)
+	sitemapSer := services.NewSitemapService(repositories.NewSitemapRepository(db))
+	passSer := services.NewPassService(repositories.NewPassRepository(db), questionRepo, redisClient)
+	reactionRepo := repositories.NewReactionRepository(db)
+	voteFraudSer := services.NewVoteFraudService(repositories.NewVoteRepository(db), reactionRepo, repositories.NewReportRepository(db), userRepo, services.NewReactionCounter(reactionRepo, redisClient), redisClient, novuClient)
+	cronJobs := jobs.StartCronJobs(questionSer, postSer, topicClassifierService, draftSer, questionCloseSer, sitemapSer, passSer, voteFraudSer, spamClassifierService)
 
 	var permissions []models.Permission
 	config.InitPermissions()
@@ -51,12 +69,13 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, jwtSecret string, redisClient *redi
 	authorized.Use(authMiddleware)
 	{
 		UserRoutes(db, authorized, permService, redisClient)
-		QuestionRoutes(db, authorized, permService, redisClient, novuClient)
-		PostRoutes(db, authorized, permService, redisClient, novuClient)
-		AnswerRoutes(db, authorized, permService, redisClient, novuClient)
-		CommentRoutes(db, authorized, permService, redisClient, novuClient)
+		QuestionRoutes(db, authorized, permService, redisClient, novuClient, searchIndexService, topicClassifierService, autoModerationService)
+		QuestionCloseRoutes(db, authorized, permService, redisClient, novuClient)
+		PostRoutes(db, authorized, permService, redisClient, novuClient, searchIndexService, autoModerationService)
+		AnswerRoutes(db, authorized, permService, redisClient, novuClient, searchIndexService, topicClassifierService, autoModerationService)
+		CommentRoutes(db, authorized, permService, redisClient, novuClient, autoModerationService)
 		TagRoutes(db, authorized, permService, redisClient)
-		TopicRoutes(db, authorized, permService, redisClient)
+		TopicRoutes(db, authorized, permService, redisClient, topicClassifierService)
 		FollowRoutes(db, authorized, permService, redisClient, novuClient)
 		GroupRoutes(db, authorized, permService, redisClient)
 		VoteRoutes(db, authorized, permService)
@@ -68,5 +87,40 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, jwtSecret string, redisClient *redi
 		AttachmentRoutes(db, authorized, permService, redisClient)
 		PassRoutes(db, authorized, permService, redisClient)
 		ReactionRoutes(db, authorized, permService, redisClient, novuClient)
+		SearchRoutes(db, authorized, permService, redisClient, searchIndexService)
+		ScheduleRoutes(db, authorized, permService, redisClient, novuClient, searchIndexService, topicClassifierService, autoModerationService)
+		DraftRoutes(db, authorized, permService, redisClient, novuClient, searchIndexService, topicClassifierService, autoModerationService)
+		SuggestedEditRoutes(db, authorized, permService, redisClient, novuClient, searchIndexService, topicClassifierService, autoModerationService)
+		PollRoutes(db, authorized, permService, redisClient)
+		MentionRoutes(db, authorized, permService, novuClient)
+		BookmarkRoutes(db, authorized, permService)
+		FeedRoutes(r, db, authorized, permService)
+		SitemapRoutes(r, sitemapSer)
+		AnswerRequestRoutes(db, authorized, permService, novuClient)
+		ModerationRoutes(db, authorized, permService, redisClient, novuClient, searchIndexService, topicClassifierService, autoModerationService)
+		AutoModerationRoutes(authorized, permService, autoModerationService)
+		SpamClassifierRoutes(authorized, permService, spamClassifierService)
 	}
+
+	return func() {
+		<-cronJobs.Stop().Done()
+		if err := searchIndexService.Close(); err != nil {
+			log.Printf("Failed to close search index: %v", err)
+		}
+	}
+}
+
+// openSearchIndex mở chỉ mục tìm kiếm nhúng tại SEARCH_INDEX_DIR (mặc định ./data/search).
+// Nếu không mở được thì trả về nil và việc đồng bộ chỉ mục sẽ bị bỏ qua.
+func openSearchIndex() searchindex.SearchIndex {
+	dir := os.Getenv("SEARCH_INDEX_DIR")
+	if dir == "" {
+		dir = "./data/search"
+	}
+	index, err := searchindex.NewDiskIndex(dir)
+	if err != nil {
+		log.Printf("Failed to open search index at %s: %v", dir, err)
+		return nil
+	}
+	return index
 }
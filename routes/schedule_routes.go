@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+func ScheduleRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient, searchIndexService services.SearchIndexService, topicClassifierService services.TopicClassifierService, autoModerationService services.AutoModerationService) {
+	userRepo := repositories.NewUserRepository(db)
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), userRepo, novuClient)
+	topicRepo := repositories.NewTopicRepository(db)
+	topicService := services.NewTopicService(topicRepo, redisClient, db)
+	questionRepo := repositories.NewQuestionRepository(db)
+	questionService := services.NewQuestionService(questionRepo, topicService, redisClient, userRepo, novuClient, searchIndexService, topicClassifierService, mentionService, autoModerationService)
+	postRepo := repositories.NewPostRepository(db)
+	postService := services.NewPostService(postRepo, redisClient, userRepo, novuClient, searchIndexService, mentionService, autoModerationService)
+	scheduleController := controllers.NewScheduleController(questionService, postService, permService)
+
+	// Quản lý câu hỏi và bài viết hẹn giờ đăng (:type là question hoặc post)
+	scheduled := authorized.Group("/scheduled")
+	{
+		scheduled.GET("/", middlewares.CheckPermission(permService, "schedule", "view"), scheduleController.ListScheduled)
+		scheduled.PUT("/:type/:id", middlewares.CheckPermission(permService, "schedule", "edit"), scheduleController.Reschedule)
+		scheduled.DELETE("/:type/:id", middlewares.CheckPermission(permService, "schedule", "delete"), scheduleController.Cancel)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"os"
+)
+
+func SearchRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, searchIndexService services.SearchIndexService) {
+	searchRepo := repositories.NewSearchRepository(db)
+	searchService := services.NewSearchService(searchRepo, redisClient, searchIndexService, os.Getenv("SEARCH_BACKEND"))
+	searchController := controllers.NewSearchController(searchService, searchIndexService)
+
+	// Tìm kiếm toàn văn trên câu hỏi, câu trả lời, bài viết, bình luận, người dùng, chủ đề và thẻ
+	authorized.GET("/search", middlewares.CheckPermission(permService, "search", "view"), searchController.Search)
+	// Dựng lại toàn bộ chỉ mục tìm kiếm nhúng
+	authorized.POST("/search/reindex", middlewares.CheckPermission(permService, "search", "reindex"), searchController.Reindex)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+)
+
+// SitemapRoutes đăng ký sitemap công khai cho máy tìm kiếm, không cần đăng nhập
+func SitemapRoutes(r *gin.Engine, sitemapService services.SitemapService) {
+	sitemapController := controllers.NewSitemapController(sitemapService)
+
+	r.GET("/sitemap.xml", sitemapController.Index)
+	r.GET("/sitemaps/:name", sitemapController.Segment)
+}
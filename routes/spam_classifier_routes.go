@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SpamClassifierRoutes(authorized *gin.RouterGroup, permService services.PermissionService, spamClassifierService services.SpamClassifierService) {
+	spamClassifierController := controllers.NewSpamClassifierController(spamClassifierService)
+
+	// Bộ phân loại spam học từ quyết định của người kiểm duyệt; mỗi lần huấn luyện tạo một phiên bản mô hình
+	spamClassifier := authorized.Group("/spam-classifier")
+	{
+		spamClassifier.GET("/report", middlewares.CheckPermission(permService, "spam_classifier", "view"), spamClassifierController.Report)
+		spamClassifier.POST("/train", middlewares.CheckPermission(permService, "spam_classifier", "manage"), spamClassifierController.Train)
+		spamClassifier.POST("/versions/:id/activate", middlewares.CheckPermission(permService, "spam_classifier", "manage"), spamClassifierController.Activate)
+	}
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"Forum_BE/controllers"
+	"Forum_BE/middlewares"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"Forum_BE/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+func SuggestedEditRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, novuClient *notification.NovuClient, searchIndexService services.SearchIndexService, topicClassifierService services.TopicClassifierService, autoModerationService services.AutoModerationService) {
+	userRepo := repositories.NewUserRepository(db)
+	mentionService := services.NewMentionService(repositories.NewMentionRepository(db), userRepo, novuClient)
+	topicRepo := repositories.NewTopicRepository(db)
+	topicService := services.NewTopicService(topicRepo, redisClient, db)
+	questionRepo := repositories.NewQuestionRepository(db)
+	questionService := services.NewQuestionService(questionRepo, topicService, redisClient, userRepo, novuClient, searchIndexService, topicClassifierService, mentionService, autoModerationService)
+	answerRepo := repositories.NewAnswerRepository(db)
+	answerRequestService := services.NewAnswerRequestService(repositories.NewAnswerRequestRepository(db), questionRepo, userRepo, novuClient)
+	answerService := services.NewAnswerService(answerRepo, questionRepo, questionService, userRepo, redisClient, novuClient, searchIndexService, mentionService, answerRequestService, autoModerationService)
+	editService := services.NewSuggestedEditService(repositories.NewSuggestedEditRepository(db), questionRepo, answerRepo, questionService, answerService, novuClient)
+	editController := controllers.NewSuggestedEditController(editService)
+
+	// Đề xuất chỉnh sửa câu hỏi/câu trả lời của người khác và hàng đợi duyệt
+	edits := authorized.Group("/suggested-edits")
+	{
+		edits.POST("/", middlewares.CheckPermission(permService, "suggested_edit", "create"), editController.SuggestEdit)
+		edits.GET("/", middlewares.CheckPermission(permService, "suggested_edit", "view"), editController.ListSuggestedEdits)
+		edits.GET("/:id", middlewares.CheckPermission(permService, "suggested_edit", "view"), editController.GetSuggestedEdit)
+		edits.DELETE("/:id", middlewares.CheckPermission(permService, "suggested_edit", "create"), editController.WithdrawSuggestedEdit)
+		edits.POST("/:id/approve", middlewares.CheckPermission(permService, "suggested_edit", "review"), editController.ApproveSuggestedEdit)
+		edits.POST("/:id/reject", middlewares.CheckPermission(permService, "suggested_edit", "review"), editController.RejectSuggestedEdit)
+	}
+}
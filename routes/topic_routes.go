@@ -10,15 +10,17 @@ import (
 	"gorm.io/gorm"
 )
 
-func TopicRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client) {
+func TopicRoutes(db *gorm.DB, authorized *gin.RouterGroup, permService services.PermissionService, redisClient *redis.Client, topicClassifierService services.TopicClassifierService) {
 	topicRepo := repositories.NewTopicRepository(db)
 	topicService := services.NewTopicService(topicRepo, redisClient, db)
-	topicController := controllers.NewTopicControllerWithDB(db, topicService)
+	topicController := controllers.NewTopicControllerWithDB(db, topicService, topicClassifierService)
 
 	topics := authorized.Group("/topics")
 	{
 		// Người dùng đề xuất Topic
 		topics.POST("/propose", middlewares.CheckPermission(permService, "topic", "propose"), topicController.ProposeTopic)
+		// Gợi ý chủ đề có sẵn cho nội dung đang soạn
+		topics.POST("/suggest", middlewares.CheckPermission(permService, "topic", "view"), topicController.SuggestTopics)
 		// Admin tạo Topic trực tiếp
 		topics.POST("/", middlewares.CheckPermission(permService, "topic", "create"), topicController.CreateTopic)
 		topics.GET("/:id", middlewares.CheckPermission(permService, "topic", "view"), topicController.GetTopic)
@@ -0,0 +1,122 @@
+package searchindex
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Tiếng Việt là ngôn ngữ đơn lập nên không có biến tố để "stem";
+// thay vào đó ta bỏ dấu (học -> hoc, đ -> d) để tìm kiếm không phân biệt dấu
+// và index thêm cặp âm tiết liền kề (học_máy) để ưu tiên từ ghép.
+// Từ tiếng Anh (không dấu) được rút gọn bằng stemEnglish.
+
+var stopWords = map[string]bool{
+	// English
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "how": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "was": true, "what": true,
+	"when": true, "where": true, "which": true, "who": true, "why": true, "with": true,
+	// Tiếng Việt (đã bỏ dấu)
+	"la": true, "cua": true, "va": true, "cac": true, "nhung": true, "cho": true, "voi": true,
+	"mot": true, "nay": true, "thi": true, "duoc": true, "trong": true, "khi": true, "de": true,
+	"ma": true, "o": true, "nhu": true, "vay": true,
+}
+
+// Analyze chuẩn hoá văn bản thành danh sách term (gồm cả bigram) dùng cho index và truy vấn.
+// Được dùng lại bởi các thành phần xử lý văn bản khác (ví dụ bộ phân loại chủ đề).
+func Analyze(text string) []string {
+	words := tokenize(text)
+	terms := make([]string, 0, len(words)*2)
+	var prev string
+	for _, w := range words {
+		if w == "" {
+			prev = ""
+			continue
+		}
+		terms = append(terms, w)
+		if prev != "" {
+			terms = append(terms, prev+"_"+w)
+		}
+		prev = w
+	}
+	return terms
+}
+
+// tokenize tách từ, bỏ dấu và stem các từ tiếng Anh.
+// Stop word được thay bằng chuỗi rỗng để analyze không ghép bigram qua nó.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := make([]string, 0, len(fields))
+	for _, f := range fields {
+		folded, hadMarks := foldVietnamese(f)
+		if folded == "" {
+			continue
+		}
+		if stopWords[folded] {
+			words = append(words, "")
+			continue
+		}
+		if !hadMarks {
+			folded = stemEnglish(folded)
+		}
+		words = append(words, folded)
+	}
+	return words
+}
+
+// foldVietnamese bỏ dấu thanh và dấu phụ, trả về true nếu từ gốc có dấu
+func foldVietnamese(s string) (string, bool) {
+	var b strings.Builder
+	hadMarks := false
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			hadMarks = true
+		case r == 'đ':
+			hadMarks = true
+			b.WriteRune('d')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), hadMarks
+}
+
+var englishSuffixes = []struct {
+	suffix, replace string
+}{
+	{"ational", "ate"}, {"ization", "ize"}, {"fulness", "ful"}, {"iveness", "ive"},
+	{"ousness", "ous"}, {"ations", "ate"}, {"ation", "ate"}, {"ments", ""}, {"ment", ""},
+	{"ness", ""}, {"ingly", ""}, {"edly", ""}, {"ings", ""}, {"ing", ""}, {"ies", "y"},
+	{"ied", "y"}, {"sses", "ss"}, {"ed", ""}, {"ly", ""}, {"es", ""}, {"s", ""},
+}
+
+// stemEnglish là bộ rút gọn hậu tố đơn giản kiểu Porter.
+// Không cần đúng ngữ pháp, chỉ cần index và truy vấn cho cùng một kết quả.
+func stemEnglish(w string) string {
+	if len(w) < 4 {
+		return w
+	}
+	for _, s := range englishSuffixes {
+		if !strings.HasSuffix(w, s.suffix) {
+			continue
+		}
+		if s.suffix == "s" && strings.HasSuffix(w, "ss") {
+			return w
+		}
+		stem := w[:len(w)-len(s.suffix)] + s.replace
+		if len(stem) < 3 {
+			return w
+		}
+		// running -> runn -> run
+		if n := len(stem); n >= 2 && (s.suffix == "ing" || s.suffix == "ed") && stem[n-1] == stem[n-2] && !strings.ContainsRune("lsz", rune(stem[n-1])) {
+			stem = stem[:n-1]
+		}
+		return stem
+	}
+	return w
+}
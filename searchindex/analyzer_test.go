@@ -0,0 +1,77 @@
+package searchindex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"folds vietnamese and adds bigrams", "Học máy", []string{"hoc", "may", "hoc_may"}},
+		{"d with stroke", "Đà Nẵng", []string{"da", "nang", "da_nang"}},
+		{"stop word breaks bigrams", "Học máy là gì?", []string{"hoc", "may", "hoc_may", "gi"}},
+		{"stems english words", "the database indexes", []string{"database", "index", "database_index"}},
+		{"doubled consonant after stemming", "classes running stopped", []string{"class", "run", "class_run", "stop", "run_stop"}},
+		{"splits on punctuation", "Go 1.23", []string{"go", "1", "go_1", "23", "1_23"}},
+		{"only stop words", "the and of", []string{}},
+		{"empty", "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Analyze(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Analyze(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	tests := []struct {
+		word, want string
+	}{
+		{"tests", "test"},
+		{"class", "class"},
+		{"queries", "query"},
+		{"running", "run"},
+		{"installed", "install"},
+		{"optimization", "optimize"},
+		{"go", "go"},
+		{"sing", "sing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := stemEnglish(tt.word); got != tt.want {
+				t.Errorf("stemEnglish(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"database", "database", 2, 0},
+		{"databse", "database", 2, 1},
+		{"dtabase", "database", 2, 1},
+		{"kitten", "sitting", 3, 3},
+		{"hoc", "hoc", 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := levenshtein(tt.a, tt.b, tt.max); got != tt.want {
+				t.Errorf("levenshtein(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+			}
+		})
+	}
+	// Khoảng cách vượt max chỉ cần được báo là lớn hơn max
+	if got := levenshtein("abcdef", "uvwxyz", 1); got <= 1 {
+		t.Errorf("levenshtein beyond max = %d, want > 1", got)
+	}
+}
@@ -0,0 +1,495 @@
+package searchindex
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	snapshotFile = "snapshot.gob"
+	journalFile  = "journal.jsonl"
+	lockFile     = "LOCK"
+
+	// Số thao tác ghi trong journal trước khi gộp lại thành snapshot
+	compactEvery = 500
+
+	titleBoost = 2.0
+	bm25K1     = 1.2
+	bm25B      = 0.75
+	// Hệ số cho term khớp gần đúng (sai chính tả) so với khớp chính xác
+	fuzzyWeight = 0.6
+)
+
+// ErrIndexLocked được trả về khi một tiến trình khác (thường là server) đang mở chỉ mục
+var ErrIndexLocked = errors.New("search index is locked by another process")
+
+type entry struct {
+	doc    Document
+	tf     map[string]float64
+	length float64
+}
+
+type journalOp struct {
+	Delete bool      `json:"delete,omitempty"`
+	Key    string    `json:"key"`
+	Doc    *Document `json:"doc,omitempty"`
+}
+
+// diskIndex là chỉ mục đảo ngược nằm trong bộ nhớ, được lưu xuống đĩa
+// dưới dạng snapshot (gob) và journal ghi nối tiếp (JSON lines).
+// Khi khởi động, snapshot được nạp rồi journal được phát lại.
+type diskIndex struct {
+	mu       sync.RWMutex
+	dir      string
+	docs     map[string]*entry
+	postings map[string]map[string]struct{}
+	// fuzzyBuckets gom term theo ký tự đầu và độ dài để tìm ứng viên sai chính tả mà không duyệt cả từ điển
+	fuzzyBuckets map[string]map[string]struct{}
+	totalLength  float64
+	journal      *os.File
+	journalOps   int
+	lock         *os.File
+}
+
+func docKey(docType string, id uint) string {
+	return fmt.Sprintf("%s:%d", docType, id)
+}
+
+// NewDiskIndex mở (hoặc tạo mới) chỉ mục trong thư mục dir.
+// Chỉ một tiến trình được mở chỉ mục tại một thời điểm; tiến trình sau nhận ErrIndexLocked cho tới khi Close.
+func NewDiskIndex(dir string) (SearchIndex, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	lock, err := lockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	idx := &diskIndex{
+		dir:          dir,
+		docs:         make(map[string]*entry),
+		postings:     make(map[string]map[string]struct{}),
+		fuzzyBuckets: make(map[string]map[string]struct{}),
+		lock:         lock,
+	}
+	if err := idx.loadSnapshot(); err != nil {
+		unlockDir(lock)
+		return nil, err
+	}
+	if err := idx.replayJournal(); err != nil {
+		unlockDir(lock)
+		return nil, err
+	}
+	journal, err := os.OpenFile(filepath.Join(dir, journalFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		unlockDir(lock)
+		return nil, err
+	}
+	idx.journal = journal
+	log.Printf("Search index loaded from %s with %d documents", dir, len(idx.docs))
+	return idx, nil
+}
+
+func (idx *diskIndex) loadSnapshot() error {
+	f, err := os.Open(filepath.Join(idx.dir, snapshotFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var docs []Document
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&docs); err != nil {
+		return fmt.Errorf("failed to decode search index snapshot: %w", err)
+	}
+	for _, doc := range docs {
+		idx.put(doc)
+	}
+	return nil
+}
+
+func (idx *diskIndex) replayJournal() error {
+	f, err := os.Open(filepath.Join(idx.dir, journalFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var op journalOp
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			// Dòng cuối có thể bị ghi dở khi tiến trình dừng đột ngột
+			log.Printf("Skipping corrupted search journal entry: %v", err)
+			continue
+		}
+		if op.Delete {
+			idx.remove(op.Key)
+		} else if op.Doc != nil {
+			idx.put(*op.Doc)
+		}
+		idx.journalOps++
+	}
+	return scanner.Err()
+}
+
+func (idx *diskIndex) put(doc Document) {
+	key := docKey(doc.Type, doc.ID)
+	idx.remove(key)
+
+	tf := make(map[string]float64)
+	for _, term := range Analyze(doc.Title) {
+		tf[term] += titleBoost
+	}
+	for _, term := range Analyze(doc.Body) {
+		tf[term]++
+	}
+	var length float64
+	for term, freq := range tf {
+		length += freq
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]struct{})
+			if bucket, ok := fuzzyBucket(term); ok {
+				if idx.fuzzyBuckets[bucket] == nil {
+					idx.fuzzyBuckets[bucket] = make(map[string]struct{})
+				}
+				idx.fuzzyBuckets[bucket][term] = struct{}{}
+			}
+		}
+		idx.postings[term][key] = struct{}{}
+	}
+	idx.docs[key] = &entry{doc: doc, tf: tf, length: length}
+	idx.totalLength += length
+}
+
+func (idx *diskIndex) remove(key string) {
+	e, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+	for term := range e.tf {
+		delete(idx.postings[term], key)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+			if bucket, ok := fuzzyBucket(term); ok {
+				delete(idx.fuzzyBuckets[bucket], term)
+				if len(idx.fuzzyBuckets[bucket]) == 0 {
+					delete(idx.fuzzyBuckets, bucket)
+				}
+			}
+		}
+	}
+	idx.totalLength -= e.length
+	delete(idx.docs, key)
+}
+
+func (idx *diskIndex) appendJournal(op journalOp) error {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	if _, err := idx.journal.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	idx.journalOps++
+	if idx.journalOps >= compactEvery {
+		return idx.compact()
+	}
+	return nil
+}
+
+// compact ghi toàn bộ document ra snapshot mới rồi làm rỗng journal.
+// Snapshot được ghi vào file tạm và rename để không bao giờ bị hỏng giữa chừng.
+func (idx *diskIndex) compact() error {
+	docs := make([]Document, 0, len(idx.docs))
+	for _, e := range idx.docs {
+		docs = append(docs, e.doc)
+	}
+
+	tmpPath := filepath.Join(idx.dir, snapshotFile+".tmp")
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := gob.NewEncoder(w).Encode(docs); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, filepath.Join(idx.dir, snapshotFile)); err != nil {
+		return err
+	}
+
+	if err := idx.journal.Truncate(0); err != nil {
+		return err
+	}
+	idx.journalOps = 0
+	return nil
+}
+
+func (idx *diskIndex) Index(doc Document) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.put(doc)
+	return idx.appendJournal(journalOp{Key: docKey(doc.Type, doc.ID), Doc: &doc})
+}
+
+func (idx *diskIndex) Delete(docType string, id uint) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	key := docKey(docType, id)
+	if _, ok := idx.docs[key]; !ok {
+		return nil
+	}
+	idx.remove(key)
+	return idx.appendJournal(journalOp{Delete: true, Key: key})
+}
+
+func (idx *diskIndex) Rebuild(docs []Document) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs = make(map[string]*entry, len(docs))
+	idx.postings = make(map[string]map[string]struct{})
+	idx.fuzzyBuckets = make(map[string]map[string]struct{})
+	idx.totalLength = 0
+	for _, doc := range docs {
+		idx.put(doc)
+	}
+	return idx.compact()
+}
+
+func (idx *diskIndex) Count() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Close gộp journal vào snapshot rồi nhả khoá thư mục; khoá luôn được nhả kể cả khi gộp lỗi
+func (idx *diskIndex) Close() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	defer unlockDir(idx.lock)
+
+	if err := idx.compact(); err != nil {
+		idx.journal.Close()
+		return err
+	}
+	return idx.journal.Close()
+}
+
+// fuzzyBucket trả về khoá nhóm (ký tự đầu + độ dài theo rune) của term; term ghép có "_" không được khớp gần đúng
+func fuzzyBucket(term string) (string, bool) {
+	runes := []rune(term)
+	if len(runes) == 0 || strings.Contains(term, "_") {
+		return "", false
+	}
+	return fmt.Sprintf("%c:%d", runes[0], len(runes)), true
+}
+
+// expandTerm trả về các term trong từ điển khớp với term truy vấn kèm trọng số.
+// Khớp chính xác có trọng số 1; nếu term đủ dài thì thêm các term cách nhau
+// tối đa 1 (hoặc 2 với từ dài) phép sửa để chịu được lỗi chính tả.
+// Ứng viên chỉ lấy từ các nhóm cùng ký tự đầu và độ dài chênh không quá số phép sửa,
+// nên lỗi gõ sai ngay ký tự đầu tiên sẽ không được sửa.
+func (idx *diskIndex) expandTerm(term string) map[string]float64 {
+	expanded := make(map[string]float64)
+	if _, ok := idx.postings[term]; ok {
+		expanded[term] = 1
+	}
+	if strings.Contains(term, "_") {
+		return expanded
+	}
+	runes := []rune(term)
+	maxDist := 0
+	switch n := len(runes); {
+	case n >= 8:
+		maxDist = 2
+	case n >= 4:
+		maxDist = 1
+	}
+	if maxDist == 0 {
+		return expanded
+	}
+	for length := len(runes) - maxDist; length <= len(runes)+maxDist; length++ {
+		for candidate := range idx.fuzzyBuckets[fmt.Sprintf("%c:%d", runes[0], length)] {
+			if candidate == term {
+				continue
+			}
+			if d := levenshtein(term, candidate, maxDist); d <= maxDist {
+				w := fuzzyWeight / float64(d)
+				if w > expanded[candidate] {
+					expanded[candidate] = w
+				}
+			}
+		}
+	}
+	return expanded
+}
+
+func (idx *diskIndex) Search(q Query) (*Result, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	result := &Result{
+		Hits:        []Hit{},
+		TypeCounts:  make(map[string]int64),
+		TopicCounts: make(map[uint]int64),
+		TagCounts:   make(map[uint]int64),
+	}
+	terms := Analyze(q.Text)
+	if len(terms) == 0 || len(idx.docs) == 0 {
+		return result, nil
+	}
+
+	types := make(map[string]bool)
+	for _, t := range q.Types {
+		types[t] = true
+	}
+
+	n := float64(len(idx.docs))
+	avgLength := idx.totalLength / n
+	scores := make(map[string]float64)
+	for _, term := range terms {
+		for candidate, weight := range idx.expandTerm(term) {
+			postings := idx.postings[candidate]
+			df := float64(len(postings))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			for key := range postings {
+				e := idx.docs[key]
+				tf := e.tf[candidate]
+				norm := tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*e.length/avgLength))
+				scores[key] += weight * idf * norm
+			}
+		}
+	}
+
+	var hits []Hit
+	for key, score := range scores {
+		doc := idx.docs[key].doc
+		if len(types) > 0 && !types[doc.Type] {
+			continue
+		}
+		if q.TopicID != 0 && doc.TopicID != q.TopicID {
+			continue
+		}
+		if q.TagID != 0 && !containsID(doc.TagIDs, q.TagID) {
+			continue
+		}
+		result.TypeCounts[doc.Type]++
+		if doc.TopicID != 0 {
+			result.TopicCounts[doc.TopicID]++
+		}
+		for _, tagID := range doc.TagIDs {
+			result.TagCounts[tagID]++
+		}
+		hits = append(hits, Hit{
+			Type:      doc.Type,
+			ID:        doc.ID,
+			Title:     doc.Title,
+			Body:      doc.Body,
+			Score:     score,
+			CreatedAt: doc.CreatedAt,
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt)
+	})
+
+	result.Total = len(hits)
+	start := q.Offset
+	if start > len(hits) {
+		start = len(hits)
+	}
+	end := len(hits)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+	result.Hits = hits[start:end]
+	return result, nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// levenshtein tính khoảng cách sửa giữa a và b, dừng sớm và trả về max+1
+// khi khoảng cách chắc chắn vượt quá max.
+func levenshtein(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,202 @@
+package searchindex
+
+import (
+	"testing"
+	"time"
+)
+
+func openTestIndex(t *testing.T, dir string) *diskIndex {
+	t.Helper()
+	index, err := NewDiskIndex(dir)
+	if err != nil {
+		t.Fatalf("NewDiskIndex: %v", err)
+	}
+	return index.(*diskIndex)
+}
+
+func hitKeys(result *Result) []string {
+	keys := make([]string, len(result.Hits))
+	for i, hit := range result.Hits {
+		keys[i] = docKey(hit.Type, hit.ID)
+	}
+	return keys
+}
+
+func seedIndex(t *testing.T, idx *diskIndex) {
+	t.Helper()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	docs := []Document{
+		{Type: "question", ID: 1, Title: "Tối ưu database MySQL", Body: "Cách đánh index cho bảng lớn", TopicID: 1, TagIDs: []uint{10}, CreatedAt: base},
+		{Type: "question", ID: 2, Title: "Học máy cơ bản", Body: "Nên bắt đầu học máy từ đâu, database nào dùng cho dữ liệu", TopicID: 2, TagIDs: []uint{20}, CreatedAt: base.Add(time.Hour)},
+		{Type: "answer", ID: 3, Title: "", Body: "Dùng database PostgreSQL hoặc MySQL đều được", TopicID: 1, TagIDs: []uint{10, 20}, CreatedAt: base.Add(2 * time.Hour)},
+		{Type: "post", ID: 4, Title: "Golang concurrency", Body: "Goroutine và channel", TopicID: 3, CreatedAt: base.Add(3 * time.Hour)},
+	}
+	for _, doc := range docs {
+		if err := idx.Index(doc); err != nil {
+			t.Fatalf("Index %s %d: %v", doc.Type, doc.ID, err)
+		}
+	}
+}
+
+func TestDiskIndexSearch(t *testing.T) {
+	idx := openTestIndex(t, t.TempDir())
+	defer idx.Close()
+	seedIndex(t, idx)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"title match ranks first", Query{Text: "database"}, []string{"question:1", "answer:3", "question:2"}},
+		{"accent insensitive", Query{Text: "hoc may"}, []string{"question:2"}},
+		{"typo tolerant", Query{Text: "databse"}, []string{"question:1", "answer:3", "question:2"}},
+		{"typo in the first letter is not corrected", Query{Text: "fatabase"}, []string{}},
+		{"short terms need an exact match", Query{Text: "gi"}, []string{}},
+		{"filter by type", Query{Text: "database", Types: []string{"answer"}}, []string{"answer:3"}},
+		{"filter by topic", Query{Text: "database", TopicID: 2}, []string{"question:2"}},
+		{"filter by tag", Query{Text: "database", TagID: 10}, []string{"question:1", "answer:3"}},
+		{"offset and limit", Query{Text: "database", Offset: 1, Limit: 1}, []string{"answer:3"}},
+		{"offset past the end", Query{Text: "database", Offset: 10}, []string{}},
+		{"no terms", Query{Text: "the of"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := idx.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			got := hitKeys(result)
+			if len(got) != len(tt.want) {
+				t.Fatalf("hits = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("hits = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDiskIndexFacets(t *testing.T) {
+	idx := openTestIndex(t, t.TempDir())
+	defer idx.Close()
+	seedIndex(t, idx)
+
+	result, err := idx.Search(Query{Text: "database", Limit: 1})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if result.Total != 3 || len(result.Hits) != 1 {
+		t.Fatalf("Total = %d, hits = %d, want 3 and 1", result.Total, len(result.Hits))
+	}
+	if result.TypeCounts["question"] != 2 || result.TypeCounts["answer"] != 1 {
+		t.Errorf("TypeCounts = %v", result.TypeCounts)
+	}
+	if result.TopicCounts[1] != 2 || result.TopicCounts[2] != 1 {
+		t.Errorf("TopicCounts = %v", result.TopicCounts)
+	}
+	if result.TagCounts[10] != 2 || result.TagCounts[20] != 2 {
+		t.Errorf("TagCounts = %v", result.TagCounts)
+	}
+}
+
+func TestDiskIndexUpdateAndDelete(t *testing.T) {
+	idx := openTestIndex(t, t.TempDir())
+	defer idx.Close()
+	seedIndex(t, idx)
+
+	if err := idx.Index(Document{Type: "post", ID: 4, Title: "Rust ownership", Body: "Borrow checker"}); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	if result, _ := idx.Search(Query{Text: "goroutine"}); result.Total != 0 {
+		t.Errorf("old content of a re-indexed document is still searchable")
+	}
+	if result, _ := idx.Search(Query{Text: "ownership"}); result.Total != 1 {
+		t.Errorf("new content of a re-indexed document is not searchable")
+	}
+
+	if err := idx.Delete("question", 1); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if idx.Count() != 3 {
+		t.Errorf("Count = %d, want 3", idx.Count())
+	}
+	if _, ok := idx.postings["mysql"]["question:1"]; ok {
+		t.Errorf("deleted document is still in the postings")
+	}
+	if result, _ := idx.Search(Query{Text: "toi uu"}); result.Total != 0 {
+		t.Errorf("deleted document is still searchable")
+	}
+}
+
+func TestDiskIndexPersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	// Close gộp journal vào snapshot
+	idx := openTestIndex(t, dir)
+	seedIndex(t, idx)
+	if err := idx.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	idx = openTestIndex(t, dir)
+	if idx.Count() != 4 {
+		t.Fatalf("Count after reopen = %d, want 4", idx.Count())
+	}
+
+	// Dừng đột ngột (không Close) thì thay đổi vẫn được phát lại từ journal
+	if err := idx.Delete("post", 4); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := idx.Index(Document{Type: "post", ID: 5, Title: "Kubernetes"}); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	idx.journal.Close()
+	unlockDir(idx.lock)
+
+	idx = openTestIndex(t, dir)
+	defer idx.Close()
+	if idx.Count() != 4 {
+		t.Errorf("Count after journal replay = %d, want 4", idx.Count())
+	}
+	if result, _ := idx.Search(Query{Text: "kubernetes"}); result.Total != 1 {
+		t.Errorf("document from the journal is not searchable")
+	}
+	if result, _ := idx.Search(Query{Text: "goroutine"}); result.Total != 0 {
+		t.Errorf("document deleted in the journal is still searchable")
+	}
+}
+
+func TestDiskIndexRebuild(t *testing.T) {
+	idx := openTestIndex(t, t.TempDir())
+	defer idx.Close()
+	seedIndex(t, idx)
+
+	if err := idx.Rebuild([]Document{{Type: "question", ID: 9, Title: "Docker compose"}}); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	if idx.Count() != 1 {
+		t.Errorf("Count = %d, want 1", idx.Count())
+	}
+	if result, _ := idx.Search(Query{Text: "database"}); result.Total != 0 {
+		t.Errorf("documents from before the rebuild are still searchable")
+	}
+}
+
+func TestDiskIndexLock(t *testing.T) {
+	dir := t.TempDir()
+	idx := openTestIndex(t, dir)
+
+	if _, err := NewDiskIndex(dir); err != ErrIndexLocked {
+		t.Fatalf("second NewDiskIndex error = %v, want ErrIndexLocked", err)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	reopened, err := NewDiskIndex(dir)
+	if err != nil {
+		t.Fatalf("NewDiskIndex after Close: %v", err)
+	}
+	reopened.Close()
+}
@@ -0,0 +1,53 @@
+package searchindex
+
+import "time"
+
+// Document là một nội dung được đưa vào chỉ mục tìm kiếm.
+// Chỉ nội dung đã được duyệt mới nên được index; phần đồng bộ nằm ở tầng services.
+type Document struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	TopicID   uint      `json:"topic_id,omitempty"`
+	TagIDs    []uint    `json:"tag_ids,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Query struct {
+	Text    string
+	Types   []string
+	TopicID uint
+	TagID   uint
+	Offset  int
+	Limit   int
+}
+
+type Hit struct {
+	Type      string
+	ID        uint
+	Title     string
+	Body      string
+	Score     float64
+	CreatedAt time.Time
+}
+
+type Result struct {
+	Hits        []Hit
+	Total       int
+	TypeCounts  map[string]int64
+	TopicCounts map[uint]int64
+	TagCounts   map[uint]int64
+}
+
+// SearchIndex là backend tìm kiếm độc lập với MySQL FULLTEXT
+type SearchIndex interface {
+	// Index thêm mới hoặc ghi đè một document (theo cặp Type + ID)
+	Index(doc Document) error
+	Delete(docType string, id uint) error
+	Search(q Query) (*Result, error)
+	// Rebuild thay toàn bộ nội dung chỉ mục bằng danh sách docs (dùng cho reindex)
+	Rebuild(docs []Document) error
+	Count() int
+	Close() error
+}
@@ -0,0 +1,23 @@
+//go:build !unix
+
+package searchindex
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// lockDir tạo file LOCK với O_EXCL trên các hệ không có flock.
+// Nếu tiến trình giữ khoá bị dừng đột ngột, cần xoá file LOCK bằng tay trước khi mở lại chỉ mục.
+func lockDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_EXCL|os.O_RDWR, 0o644)
+	if os.IsExist(err) {
+		return nil, ErrIndexLocked
+	}
+	return f, err
+}
+
+func unlockDir(f *os.File) error {
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	defaultAnswerRequestMaxUsers   = 10 // số người tối đa một người được nhờ cho mỗi câu hỏi
+	defaultAnswerRequestDailyLimit = 5  // số lời nhờ mỗi ngày với câu hỏi không phải của mình
+)
+
+// AnswerRequestService xử lý "ask to answer": nhờ người dùng cụ thể trả lời câu hỏi.
+// Tác giả câu hỏi chỉ bị giới hạn số người nhờ cho mỗi câu hỏi; người khác còn bị giới hạn số lời nhờ mỗi ngày.
+type AnswerRequestService interface {
+	RequestAnswers(questionID, requesterID uint, userIDs []uint) ([]models.AnswerRequest, error)
+	ListInbox(userID uint, filters map[string]interface{}) ([]models.AnswerRequest, int, error)
+	ListForQuestion(questionID uint) ([]models.AnswerRequest, error)
+	DeclineRequest(id, userID uint) error
+	MarkFulfilled(questionID, userID, answerID uint)
+}
+
+type answerRequestService struct {
+	requestRepo  repositories.AnswerRequestRepository
+	questionRepo repositories.QuestionRepository
+	userRepo     repositories.UserRepository
+	novuClient   *notification.NovuClient
+	maxUsers     int
+	dailyLimit   int
+}
+
+func NewAnswerRequestService(requestRepo repositories.AnswerRequestRepository, questionRepo repositories.QuestionRepository, userRepo repositories.UserRepository, novuClient *notification.NovuClient) AnswerRequestService {
+	return &answerRequestService{
+		requestRepo:  requestRepo,
+		questionRepo: questionRepo,
+		userRepo:     userRepo,
+		novuClient:   novuClient,
+		maxUsers:     envThreshold("ANSWER_REQUEST_MAX_USERS", defaultAnswerRequestMaxUsers),
+		dailyLimit:   envThreshold("ANSWER_REQUEST_DAILY_LIMIT", defaultAnswerRequestDailyLimit),
+	}
+}
+
+// RequestAnswers tạo lời nhờ cho những người chưa được nhờ và chưa trả lời câu hỏi, rồi thông báo cho họ.
+// Người không tồn tại, bị khoá, tác giả câu hỏi và chính người nhờ được bỏ qua; trả về các lời nhờ đã tạo.
+func (s *answerRequestService) RequestAnswers(questionID, requesterID uint, userIDs []uint) ([]models.AnswerRequest, error) {
+	if len(userIDs) == 0 {
+		return nil, errors.New("at least one user is required")
+	}
+	if len(userIDs) > s.maxUsers {
+		return nil, fmt.Errorf("cannot request answers from more than %d users", s.maxUsers)
+	}
+
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, errors.New("question not found")
+	}
+	if question.Status != models.StatusApproved || question.PublishAt != nil {
+		return nil, errors.New("cannot request answers for a question that is not approved")
+	}
+	if question.IsClosed() {
+		return nil, errors.New("cannot request answers for a closed question")
+	}
+
+	candidates := make([]uint, 0, len(userIDs))
+	seen := make(map[uint]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] || userID == requesterID || userID == question.UserID {
+			continue
+		}
+		seen[userID] = true
+		user, err := s.userRepo.GetUserByID(userID)
+		if err != nil || user.Status == models.StatusBanned {
+			continue
+		}
+		candidates = append(candidates, userID)
+	}
+
+	skip := make(map[uint]bool)
+	requested, err := s.requestRepo.RequestedUserIDs(questionID, candidates)
+	if err != nil {
+		return nil, err
+	}
+	answered, err := s.requestRepo.AnsweredUserIDs(questionID, candidates)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range append(requested, answered...) {
+		skip[id] = true
+	}
+
+	requests := make([]models.AnswerRequest, 0, len(candidates))
+	for _, userID := range candidates {
+		if !skip[userID] {
+			requests = append(requests, models.AnswerRequest{
+				QuestionID:  questionID,
+				UserID:      userID,
+				RequesterID: requesterID,
+				Status:      models.AnswerRequestPending,
+			})
+		}
+	}
+	if len(requests) == 0 {
+		return requests, nil
+	}
+
+	sent, err := s.requestRepo.CountByQuestionAndRequester(questionID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if int(sent)+len(requests) > s.maxUsers {
+		return nil, fmt.Errorf("cannot request answers from more than %d users for a question", s.maxUsers)
+	}
+	if requesterID != question.UserID {
+		today, err := s.requestRepo.CountByRequesterSince(requesterID, time.Now().Add(-24*time.Hour))
+		if err != nil {
+			return nil, err
+		}
+		if int(today)+len(requests) > s.dailyLimit {
+			return nil, fmt.Errorf("daily answer request limit of %d reached", s.dailyLimit)
+		}
+	}
+
+	if err := s.requestRepo.CreateRequests(requests); err != nil {
+		log.Printf("Failed to create answer requests for question %d: %v", questionID, err)
+		return nil, err
+	}
+
+	requester, err := s.userRepo.GetUserByID(requesterID)
+	if err != nil {
+		log.Printf("Không lấy được thông tin người nhờ trả lời: %v", err)
+		return requests, nil
+	}
+	requesterName := requester.FullName
+	if question.Anonymous && requesterID == question.UserID {
+		requesterName = question.Pseudonym
+	}
+	message := fmt.Sprintf("%s muốn bạn trả lời câu hỏi: %s", requesterName, question.Title)
+	for _, request := range requests {
+		if err := s.novuClient.SendNotification(request.UserID, "answer-request-notification", message); err != nil {
+			log.Printf("Gửi thông báo nhờ trả lời thất bại: %v", err)
+		}
+	}
+	return requests, nil
+}
+
+func (s *answerRequestService) ListInbox(userID uint, filters map[string]interface{}) ([]models.AnswerRequest, int, error) {
+	if status, ok := filters["status"].(string); ok && status != "" && !models.IsValidAnswerRequestStatus(models.AnswerRequestStatus(status)) {
+		return nil, 0, errors.New("invalid answer request status")
+	}
+	return s.requestRepo.ListForUser(userID, filters)
+}
+
+func (s *answerRequestService) ListForQuestion(questionID uint) ([]models.AnswerRequest, error) {
+	if _, err := s.questionRepo.GetQuestionByIDMinimal(questionID); err != nil {
+		return nil, errors.New("question not found")
+	}
+	return s.requestRepo.ListForQuestion(questionID)
+}
+
+// DeclineRequest: chỉ người được nhờ mới từ chối được, và chỉ khi lời nhờ còn chờ
+func (s *answerRequestService) DeclineRequest(id, userID uint) error {
+	request, err := s.requestRepo.GetRequest(id)
+	if err != nil || request.UserID != userID {
+		return errors.New("answer request not found")
+	}
+	if request.Status != models.AnswerRequestPending {
+		return errors.New("answer request is no longer pending")
+	}
+	return s.requestRepo.UpdateStatus(id, models.AnswerRequestDeclined, time.Now())
+}
+
+// MarkFulfilled được gọi khi câu trả lời được duyệt (lúc tạo hoặc khi người kiểm duyệt duyệt);
+// lỗi chỉ được ghi log để không làm hỏng việc trả lời
+func (s *answerRequestService) MarkFulfilled(questionID, userID, answerID uint) {
+	if _, err := s.requestRepo.MarkFulfilled(questionID, userID, answerID, time.Now()); err != nil {
+		log.Printf("Failed to fulfil answer request of user %d for question %d: %v", userID, questionID, err)
+	}
+}
@@ -15,13 +15,14 @@ import (
 )
 
 type AnswerService interface {
-	CreateAnswer(content string, userID uint, questionID uint, tagId []uint, title string, status string) (*models.Answer, error)
+	CreateAnswer(content string, userID uint, questionID uint, tagId []uint, title string, contentFormat string, anonymous bool) (*models.Answer, error)
 	GetAnswerByID(id uint) (*models.Answer, error)
-	UpdateAnswer(id uint, title, content string, status string, tagId []uint) (*models.Answer, error)
+	UpdateAnswer(id uint, title, content string, tagId []uint, contentFormat string) (*models.Answer, error)
 	DeleteAnswer(id uint) error
 	ListAnswers(filters map[string]interface{}) ([]models.Answer, int, error)
 	GetAllAnswers(filters map[string]interface{}) ([]models.Answer, int, error)
 	UpdateAnswerStatus(id uint, status string) (*models.Answer, error)
+	ApplyReviewedStatus(id uint, status string) (*models.Answer, error)
 	AcceptAnswer(id uint, userID uint) (*models.Answer, error)
 }
 
@@ -32,9 +33,13 @@ type answerService struct {
 	userRepo        repositories.UserRepository // Thêm UserRepository
 	redisClient     *redis.Client
 	novuClient      *notification.NovuClient // Thêm NovuClient
+	searchIndex     SearchIndexService
+	mentions        MentionService
+	answerRequests  AnswerRequestService
+	autoMod         AutoModerationService
 }
 
-func NewAnswerService(aRepo repositories.AnswerRepository, qRepo repositories.QuestionRepository, qService QuestionService, userRepo repositories.UserRepository, redisClient *redis.Client, novuClient *notification.NovuClient) AnswerService {
+func NewAnswerService(aRepo repositories.AnswerRepository, qRepo repositories.QuestionRepository, qService QuestionService, userRepo repositories.UserRepository, redisClient *redis.Client, novuClient *notification.NovuClient, searchIndex SearchIndexService, mentions MentionService, answerRequests AnswerRequestService, autoMod AutoModerationService) AnswerService {
 	if userRepo == nil {
 		log.Fatal("user repository is nil")
 	}
@@ -48,6 +53,10 @@ func NewAnswerService(aRepo repositories.AnswerRepository, qRepo repositories.Qu
 		userRepo:        userRepo,
 		redisClient:     redisClient,
 		novuClient:      novuClient,
+		searchIndex:     searchIndex,
+		mentions:        mentions,
+		answerRequests:  answerRequests,
+		autoMod:         autoMod,
 	}
 }
 
@@ -76,10 +85,14 @@ func (s *answerService) GetAllAnswers(filters map[string]interface{}) ([]models.
 	return answers, total, nil
 }
 
-func (s *answerService) CreateAnswer(content string, userID uint, questionID uint, tagId []uint, title string, status string) (*models.Answer, error) {
+func (s *answerService) CreateAnswer(content string, userID uint, questionID uint, tagId []uint, title string, contentFormat string, anonymous bool) (*models.Answer, error) {
 	if content == "" {
 		return nil, errors.New("Content is required")
 	}
+	format, err := resolveContentFormat(contentFormat, "")
+	if err != nil {
+		return nil, err
+	}
 
 	question, err := s.questionRepo.GetQuestionByID(questionID)
 	if err != nil {
@@ -91,19 +104,38 @@ func (s *answerService) CreateAnswer(content string, userID uint, questionID uin
 		log.Printf("Cannot answer question %d: status is %s", questionID, question.Status)
 		return nil, errors.New("cannot answer a question that is not approved")
 	}
+	if question.PublishAt != nil {
+		return nil, errors.New("cannot answer a question that is not published yet")
+	}
+	if question.IsClosed() {
+		return nil, errors.New("cannot answer a closed question")
+	}
+	if question.IsLocked(time.Now()) {
+		return nil, errors.New("cannot answer a locked question")
+	}
 
 	answer := &models.Answer{
-		Content:    content,
-		UserID:     userID,
-		QuestionID: questionID,
-		Title:      title,
-		Status:     status,
+		ContentFormat: format,
+		UserID:        userID,
+		QuestionID:    questionID,
+		Title:         title,
+		Anonymous:     anonymous,
+	}
+	if anonymous {
+		// Cùng bí danh với câu hỏi và các bình luận của cùng người trong luồng này
+		answer.Pseudonym = utils.Pseudonym(utils.QuestionThread(questionID), userID)
 	}
+	applyContentBody(format, content, &answer.Content, &answer.ContentSource, &answer.PlainContent)
+
+	subject := AutoModerationSubject{ContentType: models.ModerationAnswer, UserID: userID, Event: models.AutoModerationEventCreate, Title: title, Body: answer.Content}
+	verdict := s.autoMod.Evaluate(subject)
+	answer.Status = verdict.Status
 
 	if err := s.answerRepo.CreateAnswer(answer, tagId); err != nil {
 		log.Printf("Failed to create answer for question %d: %v", questionID, err)
 		return nil, err
 	}
+	s.autoMod.Record(subject, answer.ID, verdict)
 
 	s.invalidateCache(fmt.Sprintf("question:%d", questionID)) // Thêm dòng này
 	s.invalidateCache(fmt.Sprintf("answers:question:%d:*", questionID))
@@ -111,6 +143,11 @@ func (s *answerService) CreateAnswer(content string, userID uint, questionID uin
 	s.invalidateCache("tags:*")
 
 	log.Printf("Cache invalidated for questions:* and tags:* due to new answer for question %d", questionID)
+	s.searchIndex.SyncAnswer(answer.ID)
+	s.syncPublishedMentions(answer, question.Title)
+	if answer.Status == "approved" {
+		s.answerRequests.MarkFulfilled(questionID, userID, answer.ID)
+	}
 
 	// Send notification
 	answerer, err := s.userRepo.GetUserByID(userID)
@@ -119,7 +156,11 @@ func (s *answerService) CreateAnswer(content string, userID uint, questionID uin
 	} else {
 		if question.UserID != userID {
 			workflowID := "new-answer-question-notification"
-			message := fmt.Sprintf("%s đã trả lời câu hỏi của bạn: %s", answerer.FullName, question.Title)
+			answererName := answerer.FullName
+			if answer.Anonymous {
+				answererName = answer.Pseudonym
+			}
+			message := fmt.Sprintf("%s đã trả lời câu hỏi của bạn: %s", answererName, question.Title)
 			if err := s.novuClient.SendNotification(question.UserID, workflowID, message); err != nil {
 				log.Printf("Gửi thông báo trả lời câu hỏi thất bại: %v", err)
 			}
@@ -168,30 +209,49 @@ func (s *answerService) GetAnswerByID(id uint) (*models.Answer, error) {
 	return answer, nil
 }
 
-func (s *answerService) UpdateAnswer(id uint, title, content string, status string, tagId []uint) (*models.Answer, error) {
+func (s *answerService) UpdateAnswer(id uint, title, content string, tagId []uint, contentFormat string) (*models.Answer, error) {
 	answer, err := s.answerRepo.GetAnswerByID(id)
 	if err != nil {
 		log.Printf("Failed to get answer %d: %v", id, err)
 		return nil, err
 	}
+	format, err := resolveContentFormat(contentFormat, answer.ContentFormat)
+	if err != nil {
+		return nil, err
+	}
+	if format != answer.ContentFormat && content == "" {
+		return nil, errors.New("content is required when changing content format")
+	}
 	if title != "" {
 		answer.Title = title
 	}
 	if content != "" {
-		answer.Content = content
-	}
-	if status != "" {
-		answer.Status = status
+		answer.ContentFormat = format
+		applyContentBody(format, content, &answer.Content, &answer.ContentSource, &answer.PlainContent)
+	}
+	var subject AutoModerationSubject
+	var verdict *AutoModerationVerdict
+	if title != "" || content != "" {
+		subject = AutoModerationSubject{ContentType: models.ModerationAnswer, ContentID: id, UserID: answer.UserID, Event: models.AutoModerationEventUpdate, Title: answer.Title, Body: answer.Content}
+		verdict = s.autoMod.Evaluate(subject)
+		if verdict.Status != "" {
+			answer.Status = verdict.Status
+		}
 	}
 	if err := s.answerRepo.UpdateAnswer(answer, tagId); err != nil {
 		log.Printf("Failed to update answer %d: %v", id, err)
 		return nil, err
 	}
+	s.autoMod.Record(subject, id, verdict)
 
 	s.invalidateCache(fmt.Sprintf("answer:%d", id))
 	s.invalidateCache(fmt.Sprintf("answers:question:%d:*", answer.QuestionID))
 	s.invalidateCache("answers:*")
 	s.invalidateCache("tags:*")
+	s.searchIndex.SyncAnswer(id)
+	if content != "" {
+		s.syncPublishedMentions(answer, answer.Question.Title)
+	}
 	return answer, nil
 }
 
@@ -216,6 +276,7 @@ func (s *answerService) DeleteAnswer(id uint) error {
 	s.invalidateCache(fmt.Sprintf("question:%d", answer.QuestionID)) // Thêm dòng này
 
 	log.Printf("Cache invalidated for questions:* and tags:* due to deleted answer for question %d", answer.QuestionID)
+	s.searchIndex.SyncAnswer(id)
 
 	return nil
 }
@@ -241,6 +302,9 @@ func (s *answerService) ListAnswers(filters map[string]interface{}) ([]models.An
 		"search":      filters["content LIKE ?"],
 		"limit":       limit,
 		"page":        page,
+		"sort":        filters["sort"],
+		"cursor":      filters["cursor"],
+		"with_total":  filters["with_total"],
 	})
 	ctx := context.Background()
 
@@ -326,7 +390,12 @@ func (s *answerService) UpdateAnswerStatus(id uint, status string) (*models.Answ
 		log.Printf("Failed to update answer status %d: %v", id, err)
 		return nil, err
 	}
+	return s.ApplyReviewedStatus(id, status)
+}
 
+// ApplyReviewedStatus xử lý phần còn lại sau khi trạng thái câu trả lời đã được ghi,
+// kể cả đánh dấu lời nhờ trả lời khi câu trả lời được duyệt
+func (s *answerService) ApplyReviewedStatus(id uint, status string) (*models.Answer, error) {
 	// Lấy answer sau khi update
 	answer, err := s.answerRepo.GetAnswerByIDSimple(id)
 	if err != nil {
@@ -338,6 +407,16 @@ func (s *answerService) UpdateAnswerStatus(id uint, status string) (*models.Answ
 	s.invalidateCache(fmt.Sprintf("answer:%d", id))
 	s.invalidateCache(fmt.Sprintf("answers:question:%d:*", answer.QuestionID))
 	s.invalidateCache("tags:*")
+	s.searchIndex.SyncAnswer(id)
+	s.autoMod.Feedback(models.ModerationAnswer, id, status)
+	if answer.Status == "approved" {
+		if question, err := s.questionRepo.GetQuestionByIDMinimal(answer.QuestionID); err != nil {
+			log.Printf("Failed to get question %d of answer %d: %v", answer.QuestionID, id, err)
+		} else {
+			s.syncPublishedMentions(answer, question.Title)
+		}
+		s.answerRequests.MarkFulfilled(answer.QuestionID, answer.UserID, id)
+	}
 
 	// Gửi notification cho chủ sở hữu answer dựa trên status
 	answerOwner, err := s.userRepo.GetUserByID(answer.UserID)
@@ -406,7 +485,11 @@ func (s *answerService) AcceptAnswer(id uint, userID uint) (*models.Answer, erro
 			log.Printf("Không lấy được thông tin chủ sở hữu câu hỏi: %v", err)
 		} else {
 			workflowID := "answer-accepted"
-			message := fmt.Sprintf("Chủ câu hỏi %s đã đánh dấu câu trả lời của bạn hữu ích", questionOwner.FullName)
+			ownerName := questionOwner.FullName
+			if question.Anonymous {
+				ownerName = question.Pseudonym
+			}
+			message := fmt.Sprintf("Chủ câu hỏi %s đã đánh dấu câu trả lời của bạn hữu ích", ownerName)
 			if err := s.novuClient.SendNotification(answerOwner.ID, workflowID, message); err != nil {
 				log.Printf("Gửi notification accept answer thất bại: %v", err)
 			}
@@ -416,3 +499,12 @@ func (s *answerService) AcceptAnswer(id uint, userID uint) (*models.Answer, erro
 	log.Printf("Answer %d accepted successfully for question %d", id, answer.QuestionID)
 	return answer, nil
 }
+
+// syncPublishedMentions chỉ đồng bộ và gửi thông báo nhắc tên khi câu trả lời đã được duyệt,
+// để người được nhắc không thấy nội dung đang chờ duyệt, bị giữ hoặc bị từ chối
+func (s *answerService) syncPublishedMentions(answer *models.Answer, questionTitle string) {
+	if answer.Status != "approved" {
+		return
+	}
+	s.mentions.SyncMentions(models.MentionTargetAnswer, answer.ID, answer.UserID, answer.Pseudonym, answer.Content, questionTitle)
+}
@@ -0,0 +1,661 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"Forum_BE/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	autoModerationRulesKey = "auto_moderation:rules"
+	autoModerationRulesTTL = 5 * time.Minute
+	// Bộ đếm tăng mỗi khi luật thay đổi để các tiến trình khác biết cần biên dịch lại
+	autoModerationRulesVersionKey = "auto_moderation:rules_version"
+	// Nội dung ngắn hơn (vd "Cảm ơn bạn") trùng nhau là bình thường nên không tính hash
+	minFingerprintLength = 20
+	// Số ngày thống kê số lần khớp của mỗi luật trong danh sách luật
+	autoModerationSummaryDays = 30
+)
+
+var linkPattern = regexp.MustCompile(`(?i)https?://[^\s"'<>()\[\]]+`)
+
+// AutoModerationSubject là nội dung cần đánh giá; ContentID bằng 0 khi nội dung chưa được tạo.
+// Body luôn là HTML đã render và làm sạch (như được lưu), để khi tạo và khi sửa cùng một nội dung khớp luật và hash như nhau.
+type AutoModerationSubject struct {
+	ContentType string `json:"contentType"`
+	ContentID   uint   `json:"contentId"`
+	UserID      uint   `json:"userId"`
+	Event       string `json:"event"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+}
+
+// AutoModerationMatch là một luật đã khớp với nội dung cùng các điều kiện khớp
+type AutoModerationMatch struct {
+	RuleID   uint                        `json:"ruleId"`
+	RuleName string                      `json:"ruleName"`
+	Action   models.AutoModerationAction `json:"action"`
+	Reason   string                      `json:"reason,omitempty"`
+	DryRun   bool                        `json:"dryRun"`
+	Details  []string                    `json:"details"`
+}
+
+// AutoModerationVerdict là kết quả đánh giá. Status rỗng nghĩa là không luật nào quyết định
+// và nội dung giữ trạng thái như khi chưa có kiểm duyệt tự động.
+type AutoModerationVerdict struct {
+	Status  string                      `json:"status,omitempty"`
+	Action  models.AutoModerationAction `json:"action,omitempty"`
+	Reason  string                      `json:"reason,omitempty"`
+	RuleID  uint                        `json:"ruleId,omitempty"`
+	Matches []AutoModerationMatch       `json:"matches"`
+	// SpamScore là xác suất spam do bộ phân loại chấm khi tạo nội dung; nil khi không chấm
+	SpamScore *float64 `json:"spamScore,omitempty"`
+
+	hash string
+}
+
+// AutoModerationRuleInput là dữ liệu quản trị viên gửi khi tạo hoặc sửa luật; Enabled nil giữ nguyên (luật mới mặc định bật)
+type AutoModerationRuleInput struct {
+	Name        string
+	Description string
+	Conditions  models.AutoModerationConditions
+	Action      models.AutoModerationAction
+	Reason      string
+	Enabled     *bool
+	DryRun      bool
+}
+
+type AutoModerationService interface {
+	Evaluate(subject AutoModerationSubject) *AutoModerationVerdict
+	Record(subject AutoModerationSubject, contentID uint, verdict *AutoModerationVerdict)
+	ListRules() ([]models.AutoModerationRule, []repositories.AutoModerationRuleSummary, error)
+	GetRule(id uint) (*models.AutoModerationRule, error)
+	CreateRule(actorID uint, input AutoModerationRuleInput) (*models.AutoModerationRule, error)
+	UpdateRule(id, actorID uint, input AutoModerationRuleInput) (*models.AutoModerationRule, error)
+	DeleteRule(id uint) error
+	ListLogs(filters map[string]interface{}) ([]models.AutoModerationLog, int, error)
+	Feedback(contentType string, contentID uint, status string)
+}
+
+type autoModerationService struct {
+	autoModRepo    repositories.AutoModerationRepository
+	userRepo       repositories.UserRepository
+	redisClient    *redis.Client
+	novuClient     *notification.NovuClient
+	spamClassifier SpamClassifierService
+
+	// Bộ luật đã biên dịch, dùng lại cho tới khi phiên bản luật đổi hoặc quá autoModerationRulesTTL
+	rulesMu         sync.Mutex
+	compiled        []*compiledRule
+	compiledVersion string
+	compiledAt      time.Time
+}
+
+func NewAutoModerationService(repo repositories.AutoModerationRepository, userRepo repositories.UserRepository, redisClient *redis.Client, novuClient *notification.NovuClient, spamClassifier SpamClassifierService) AutoModerationService {
+	return &autoModerationService{autoModRepo: repo, userRepo: userRepo, redisClient: redisClient, novuClient: novuClient, spamClassifier: spamClassifier}
+}
+
+// compiledRule là luật đã giải mã điều kiện và biên dịch sẵn các biểu thức chính quy
+type compiledRule struct {
+	rule        models.AutoModerationRule
+	cond        models.AutoModerationConditions
+	bannedWords *regexp.Regexp
+	patterns    []*regexp.Regexp
+}
+
+func compileRule(rule models.AutoModerationRule) (*compiledRule, error) {
+	compiled := &compiledRule{rule: rule}
+	if len(rule.Conditions) > 0 {
+		if err := json.Unmarshal(rule.Conditions, &compiled.cond); err != nil {
+			return nil, fmt.Errorf("invalid conditions: %v", err)
+		}
+	}
+	if len(compiled.cond.BannedWords) > 0 {
+		quoted := make([]string, 0, len(compiled.cond.BannedWords))
+		for _, word := range compiled.cond.BannedWords {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+		// Khớp nguyên từ: hai bên không được là chữ hoặc số
+		compiled.bannedWords = regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(` + strings.Join(quoted, "|") + `)(?:$|[^\p{L}\p{N}])`)
+	}
+	for _, pattern := range compiled.cond.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		compiled.patterns = append(compiled.patterns, re)
+	}
+	return compiled, nil
+}
+
+// rulesVersion đọc bộ đếm phiên bản luật; trả về chuỗi rỗng khi chưa có hoặc không dùng Redis
+func (s *autoModerationService) rulesVersion() string {
+	if s.redisClient == nil {
+		return ""
+	}
+	version, err := s.redisClient.Get(context.Background(), autoModerationRulesVersionKey).Result()
+	if err != nil && err != redis.Nil {
+		log.Printf("Failed to read auto-moderation rules version: %v", err)
+	}
+	return version
+}
+
+// loadRules trả về bộ luật đã biên dịch; chỉ tải và biên dịch lại khi phiên bản luật đổi hoặc bản biên dịch đã cũ
+func (s *autoModerationService) loadRules() []*compiledRule {
+	version := s.rulesVersion()
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+	if !s.compiledAt.IsZero() && s.compiledVersion == version && time.Since(s.compiledAt) < autoModerationRulesTTL {
+		return s.compiled
+	}
+
+	ctx := context.Background()
+	var rules []models.AutoModerationRule
+	cached := false
+	if s.redisClient != nil {
+		if data, err := s.redisClient.Get(ctx, autoModerationRulesKey).Bytes(); err == nil {
+			cached = json.Unmarshal(data, &rules) == nil
+		}
+	}
+	if !cached {
+		var err error
+		rules, err = s.autoModRepo.ListRules(true)
+		if err != nil {
+			log.Printf("Failed to load auto-moderation rules: %v", err)
+			return nil
+		}
+		if s.redisClient != nil {
+			if data, err := json.Marshal(rules); err == nil {
+				if err := s.redisClient.Set(ctx, autoModerationRulesKey, data, autoModerationRulesTTL).Err(); err != nil {
+					log.Printf("Failed to cache auto-moderation rules: %v", err)
+				}
+			}
+		}
+	}
+
+	compiled := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			log.Printf("Skipping auto-moderation rule %d: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+	s.compiled = compiled
+	s.compiledVersion = version
+	s.compiledAt = time.Now()
+	return compiled
+}
+
+func (s *autoModerationService) invalidateRules() {
+	s.rulesMu.Lock()
+	s.compiledAt = time.Time{}
+	s.rulesMu.Unlock()
+	if s.redisClient == nil {
+		return
+	}
+	ctx := context.Background()
+	if err := s.redisClient.Del(ctx, autoModerationRulesKey).Err(); err != nil {
+		log.Printf("Failed to invalidate auto-moderation rules cache: %v", err)
+	}
+	if err := s.redisClient.Incr(ctx, autoModerationRulesVersionKey).Err(); err != nil {
+		log.Printf("Failed to bump auto-moderation rules version: %v", err)
+	}
+}
+
+// moderationContext tính lười các dữ liệu tốn truy vấn (người đăng, tốc độ đăng, nội dung trùng) và dùng chung cho mọi luật
+type moderationContext struct {
+	service    *autoModerationService
+	subject    AutoModerationSubject
+	plain      string
+	links      int
+	hash       string
+	author     *models.User
+	authorErr  error
+	authorDone bool
+	velocity   map[int]int64
+	duplicates map[int]bool
+}
+
+func (c *moderationContext) user() *models.User {
+	if !c.authorDone {
+		c.authorDone = true
+		c.author, c.authorErr = c.service.userRepo.GetUserByID(c.subject.UserID)
+		if c.authorErr != nil {
+			log.Printf("Auto-moderation could not load user %d: %v", c.subject.UserID, c.authorErr)
+		}
+	}
+	return c.author
+}
+
+func (c *moderationContext) recentCount(minutes int) (int64, bool) {
+	if count, ok := c.velocity[minutes]; ok {
+		return count, true
+	}
+	count, err := c.service.autoModRepo.CountRecentContent(c.subject.UserID, time.Now().Add(-time.Duration(minutes)*time.Minute))
+	if err != nil {
+		log.Printf("Auto-moderation could not count recent content of user %d: %v", c.subject.UserID, err)
+		return 0, false
+	}
+	c.velocity[minutes] = count
+	return count, true
+}
+
+func (c *moderationContext) isDuplicate(hours int) bool {
+	if c.hash == "" {
+		return false
+	}
+	if duplicate, ok := c.duplicates[hours]; ok {
+		return duplicate
+	}
+	duplicate, err := c.service.autoModRepo.HasDuplicate(c.hash, c.subject.ContentType, c.subject.ContentID, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		log.Printf("Auto-moderation could not check duplicates: %v", err)
+		return false
+	}
+	c.duplicates[hours] = duplicate
+	return duplicate
+}
+
+// Evaluate chạy các luật đang bật với nội dung và quyết định trạng thái; client không tự chọn trạng thái được.
+// Nội dung của nhân viên và quản trị viên bỏ qua các luật và được duyệt ngay khi tạo.
+// Khi nhiều luật khớp, hành động nặng nhất thắng; luật chạy thử chỉ được ghi nhận, không ảnh hưởng trạng thái.
+// Khi sửa nội dung, luật "approve" không tự duyệt lại nội dung đã bị giữ hoặc từ chối.
+// Nội dung mới mà không luật nào quyết định sẽ được bộ phân loại spam chấm điểm, còn lại chờ người kiểm duyệt.
+func (s *autoModerationService) Evaluate(subject AutoModerationSubject) *AutoModerationVerdict {
+	plain := strings.ToLower(utils.StripHTML(subject.Title + "\n" + subject.Body))
+	ctx := &moderationContext{
+		service:    s,
+		subject:    subject,
+		plain:      plain,
+		links:      countLinks(subject.Title + "\n" + subject.Body),
+		velocity:   map[int]int64{},
+		duplicates: map[int]bool{},
+	}
+	if utf8.RuneCountInString(plain) >= minFingerprintLength {
+		sum := sha256.Sum256([]byte(plain))
+		ctx.hash = hex.EncodeToString(sum[:])
+	}
+	verdict := &AutoModerationVerdict{Matches: []AutoModerationMatch{}, hash: ctx.hash}
+
+	if user := ctx.user(); user != nil && user.Role != models.RoleUser {
+		if subject.Event == models.AutoModerationEventCreate {
+			verdict.Status = "approved"
+		}
+		return verdict
+	}
+
+	decisive := -1
+	for _, rule := range s.loadRules() {
+		details, ok := rule.match(ctx)
+		if !ok {
+			continue
+		}
+		verdict.Matches = append(verdict.Matches, AutoModerationMatch{
+			RuleID:   rule.rule.ID,
+			RuleName: rule.rule.Name,
+			Action:   rule.rule.Action,
+			Reason:   rule.rule.Reason,
+			DryRun:   rule.rule.DryRun,
+			Details:  details,
+		})
+		if rule.rule.DryRun {
+			continue
+		}
+		if subject.Event == models.AutoModerationEventUpdate && rule.rule.Action == models.AutoModerationApprove {
+			continue
+		}
+		if decisive < 0 || rule.rule.Action.Severity() > verdict.Matches[decisive].Action.Severity() {
+			decisive = len(verdict.Matches) - 1
+		}
+	}
+	if decisive >= 0 {
+		best := verdict.Matches[decisive]
+		verdict.Status = autoModerationStatus(subject.ContentType, best.Action)
+		verdict.Action = best.Action
+		verdict.Reason = best.Reason
+		verdict.RuleID = best.RuleID
+	} else if subject.Event == models.AutoModerationEventCreate {
+		s.applySpamScore(ctx, verdict)
+	}
+	if verdict.Status == "" && subject.Event == models.AutoModerationEventCreate {
+		verdict.Status = "pending"
+	}
+	return verdict
+}
+
+// applySpamScore giữ lại nội dung có điểm spam từ ngưỡng trở lên: chờ duyệt với câu hỏi, câu trả lời, bài viết
+// và "spam" với bình luận. Lần chấm được ghi nhật ký như một luật có ID 0.
+func (s *autoModerationService) applySpamScore(ctx *moderationContext, verdict *AutoModerationVerdict) {
+	score, version, ok := s.spamClassifier.Score(ctx.subject.ContentType, ctx.subject.Title, ctx.subject.Body, ctx.user())
+	if !ok {
+		return
+	}
+	verdict.SpamScore = &score
+	if score < s.spamClassifier.Threshold() {
+		return
+	}
+
+	action := models.AutoModerationHold
+	if ctx.subject.ContentType == models.ModerationComment {
+		action = models.AutoModerationSpam
+	}
+	verdict.Matches = append(verdict.Matches, AutoModerationMatch{
+		RuleName: spamClassifierRuleName,
+		Action:   action,
+		Details:  []string{fmt.Sprintf("spam_score: %.3f (model v%d)", score, version)},
+	})
+	verdict.Status = autoModerationStatus(ctx.subject.ContentType, action)
+	verdict.Action = action
+}
+
+// match trả về danh sách điều kiện đã khớp nếu luật áp dụng cho nội dung
+func (r *compiledRule) match(ctx *moderationContext) ([]string, bool) {
+	cond := r.cond
+	if len(cond.ContentTypes) > 0 && !containsString(cond.ContentTypes, ctx.subject.ContentType) {
+		return nil, false
+	}
+	if len(cond.Events) > 0 && !containsString(cond.Events, ctx.subject.Event) {
+		return nil, false
+	}
+
+	var details []string
+	if r.bannedWords != nil {
+		found := r.bannedWords.FindStringSubmatch(ctx.plain)
+		if found == nil {
+			return nil, false
+		}
+		details = append(details, "banned_word: "+found[1])
+	}
+	for _, pattern := range r.patterns {
+		if !pattern.MatchString(ctx.subject.Title + "\n" + ctx.subject.Body) {
+			return nil, false
+		}
+		details = append(details, "pattern: "+pattern.String())
+	}
+	if cond.MinLinks != nil {
+		if ctx.links < *cond.MinLinks {
+			return nil, false
+		}
+		details = append(details, fmt.Sprintf("links: %d", ctx.links))
+	}
+	if cond.MaxAccountAgeHours != nil || cond.MinAccountAgeHours != nil || cond.MaxReputation != nil || cond.MinReputation != nil {
+		user := ctx.user()
+		if user == nil {
+			return nil, false
+		}
+		ageHours := int(time.Since(user.CreatedAt).Hours())
+		if cond.MaxAccountAgeHours != nil {
+			if ageHours >= *cond.MaxAccountAgeHours {
+				return nil, false
+			}
+			details = append(details, fmt.Sprintf("account_age_hours: %d", ageHours))
+		}
+		if cond.MinAccountAgeHours != nil {
+			if ageHours < *cond.MinAccountAgeHours {
+				return nil, false
+			}
+			details = append(details, fmt.Sprintf("account_age_hours: %d", ageHours))
+		}
+		if cond.MaxReputation != nil {
+			if user.Reputation > *cond.MaxReputation {
+				return nil, false
+			}
+			details = append(details, fmt.Sprintf("reputation: %d", user.Reputation))
+		}
+		if cond.MinReputation != nil {
+			if user.Reputation < *cond.MinReputation {
+				return nil, false
+			}
+			details = append(details, fmt.Sprintf("reputation: %d", user.Reputation))
+		}
+	}
+	if cond.Velocity != nil {
+		count, ok := ctx.recentCount(cond.Velocity.Minutes)
+		if !ok || count < int64(cond.Velocity.Count) {
+			return nil, false
+		}
+		details = append(details, fmt.Sprintf("recent_posts: %d in %d minutes", count, cond.Velocity.Minutes))
+	}
+	if cond.DuplicateHours != nil {
+		if !ctx.isDuplicate(*cond.DuplicateHours) {
+			return nil, false
+		}
+		details = append(details, fmt.Sprintf("duplicate within %d hours", *cond.DuplicateHours))
+	}
+	return details, true
+}
+
+// Record lưu hash nội dung để phát hiện trùng lặp, ghi nhật ký các luật đã khớp và báo lý do cho tác giả khi nội dung bị chặn
+func (s *autoModerationService) Record(subject AutoModerationSubject, contentID uint, verdict *AutoModerationVerdict) {
+	if verdict == nil {
+		return
+	}
+	if verdict.hash != "" {
+		if err := s.autoModRepo.SaveFingerprint(&models.ContentFingerprint{
+			ContentType: subject.ContentType,
+			ContentID:   contentID,
+			UserID:      subject.UserID,
+			Hash:        verdict.hash,
+		}); err != nil {
+			log.Printf("Failed to save fingerprint for %s %d: %v", subject.ContentType, contentID, err)
+		}
+	}
+	if len(verdict.Matches) == 0 {
+		return
+	}
+
+	entries := make([]models.AutoModerationLog, 0, len(verdict.Matches))
+	for _, match := range verdict.Matches {
+		details := strings.Join(match.Details, "; ")
+		if utf8.RuneCountInString(details) > 1000 {
+			details = string([]rune(details)[:1000])
+		}
+		entries = append(entries, models.AutoModerationLog{
+			RuleID:      match.RuleID,
+			RuleName:    match.RuleName,
+			ContentType: subject.ContentType,
+			ContentID:   contentID,
+			UserID:      subject.UserID,
+			Event:       subject.Event,
+			Action:      match.Action,
+			DryRun:      match.DryRun,
+			Applied:     !match.DryRun && match.RuleID == verdict.RuleID,
+			Details:     details,
+		})
+	}
+	if err := s.autoModRepo.CreateLogs(entries); err != nil {
+		log.Printf("Failed to write auto-moderation logs for %s %d: %v", subject.ContentType, contentID, err)
+	}
+
+	if (verdict.Action == models.AutoModerationReject || verdict.Action == models.AutoModerationSpam) && verdict.Reason != "" {
+		message := fmt.Sprintf("%s của bạn đã bị từ chối. Lý do: %s", moderationLabel(subject.ContentType), verdict.Reason)
+		if err := s.novuClient.SendNotification(subject.UserID, "moderation-reason-notification", message); err != nil {
+			log.Printf("Gửi thông báo moderation-reason-notification thất bại: %v", err)
+		}
+	}
+}
+
+func (s *autoModerationService) ListRules() ([]models.AutoModerationRule, []repositories.AutoModerationRuleSummary, error) {
+	rules, err := s.autoModRepo.ListRules(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]uint, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	summaries, err := s.autoModRepo.SummarizeLogs(ids, time.Now().AddDate(0, 0, -autoModerationSummaryDays))
+	if err != nil {
+		return nil, nil, err
+	}
+	return rules, summaries, nil
+}
+
+func (s *autoModerationService) GetRule(id uint) (*models.AutoModerationRule, error) {
+	rule, err := s.autoModRepo.GetRule(id)
+	if err != nil {
+		return nil, errors.New("rule not found")
+	}
+	return rule, nil
+}
+
+func (s *autoModerationService) CreateRule(actorID uint, input AutoModerationRuleInput) (*models.AutoModerationRule, error) {
+	rule := &models.AutoModerationRule{CreatedByID: actorID}
+	if err := s.applyRuleInput(rule, actorID, input); err != nil {
+		return nil, err
+	}
+	if err := s.autoModRepo.CreateRule(rule); err != nil {
+		log.Printf("Failed to create auto-moderation rule: %v", err)
+		return nil, err
+	}
+	s.invalidateRules()
+	return rule, nil
+}
+
+func (s *autoModerationService) UpdateRule(id, actorID uint, input AutoModerationRuleInput) (*models.AutoModerationRule, error) {
+	rule, err := s.autoModRepo.GetRule(id)
+	if err != nil {
+		return nil, errors.New("rule not found")
+	}
+	if err := s.applyRuleInput(rule, actorID, input); err != nil {
+		return nil, err
+	}
+	if err := s.autoModRepo.UpdateRule(rule); err != nil {
+		log.Printf("Failed to update auto-moderation rule %d: %v", id, err)
+		return nil, err
+	}
+	s.invalidateRules()
+	return rule, nil
+}
+
+func (s *autoModerationService) DeleteRule(id uint) error {
+	if err := s.autoModRepo.DeleteRule(id); err != nil {
+		return errors.New("rule not found")
+	}
+	s.invalidateRules()
+	return nil
+}
+
+func (s *autoModerationService) ListLogs(filters map[string]interface{}) ([]models.AutoModerationLog, int, error) {
+	return s.autoModRepo.ListLogs(filters)
+}
+
+// Feedback chuyển quyết định duyệt/từ chối của người kiểm duyệt cho bộ phân loại spam học thêm
+func (s *autoModerationService) Feedback(contentType string, contentID uint, status string) {
+	s.spamClassifier.Learn(contentType, contentID, status)
+}
+
+// applyRuleInput kiểm tra và chuẩn hoá dữ liệu luật trước khi lưu
+func (s *autoModerationService) applyRuleInput(rule *models.AutoModerationRule, actorID uint, input AutoModerationRuleInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return errors.New("name is required and must not exceed 100 characters")
+	}
+	if input.Action.Severity() == 0 {
+		return errors.New("invalid action")
+	}
+	if utf8.RuneCountInString(input.Reason) > 500 || utf8.RuneCountInString(input.Description) > 500 {
+		return errors.New("reason and description must not exceed 500 characters")
+	}
+
+	cond := input.Conditions
+	for _, contentType := range cond.ContentTypes {
+		if !models.IsValidModerationContentType(contentType) {
+			return fmt.Errorf("invalid content type %q", contentType)
+		}
+	}
+	for _, event := range cond.Events {
+		if event != models.AutoModerationEventCreate && event != models.AutoModerationEventUpdate {
+			return fmt.Errorf("invalid event %q", event)
+		}
+	}
+	words := make([]string, 0, len(cond.BannedWords))
+	for _, word := range cond.BannedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			words = append(words, word)
+		}
+	}
+	cond.BannedWords = words
+	for _, value := range []*int{cond.MinLinks, cond.MaxAccountAgeHours, cond.MinAccountAgeHours, cond.DuplicateHours} {
+		if value != nil && *value <= 0 {
+			return errors.New("numeric conditions must be positive")
+		}
+	}
+	if cond.Velocity != nil && (cond.Velocity.Count <= 0 || cond.Velocity.Minutes <= 0) {
+		return errors.New("velocity requires a positive count and minutes")
+	}
+	if len(cond.BannedWords) == 0 && len(cond.Patterns) == 0 && cond.MinLinks == nil &&
+		cond.MaxAccountAgeHours == nil && cond.MinAccountAgeHours == nil &&
+		cond.MaxReputation == nil && cond.MinReputation == nil &&
+		cond.Velocity == nil && cond.DuplicateHours == nil {
+		return errors.New("a rule needs at least one condition")
+	}
+
+	data, err := json.Marshal(cond)
+	if err != nil {
+		return err
+	}
+	candidate := *rule
+	candidate.Conditions = data
+	if _, err := compileRule(candidate); err != nil {
+		return err
+	}
+
+	rule.Name = name
+	rule.Description = strings.TrimSpace(input.Description)
+	rule.Conditions = data
+	rule.Action = input.Action
+	rule.Reason = strings.TrimSpace(input.Reason)
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	} else if rule.ID == 0 {
+		rule.Enabled = true
+	}
+	rule.DryRun = input.DryRun
+	rule.UpdatedByID = actorID
+	return nil
+}
+
+// autoModerationStatus chuyển hành động thành trạng thái nội dung; bình luận không có "rejected" nên dùng "spam"
+func autoModerationStatus(contentType string, action models.AutoModerationAction) string {
+	switch action {
+	case models.AutoModerationApprove:
+		return "approved"
+	case models.AutoModerationHold:
+		return "pending"
+	}
+	return moderationStatus(contentType, models.ModerationReject)
+}
+
+func countLinks(content string) int {
+	seen := make(map[string]bool)
+	for _, link := range linkPattern.FindAllString(content, -1) {
+		seen[strings.ToLower(strings.TrimRight(link, ".,;:!?"))] = true
+	}
+	return len(seen)
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/utils"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int    { return &v }
+func uintPtr(v uint) *uint { return &v }
+
+func mustCompileRule(t *testing.T, cond models.AutoModerationConditions) *compiledRule {
+	t.Helper()
+	raw, err := json.Marshal(cond)
+	if err != nil {
+		t.Fatalf("marshal conditions: %v", err)
+	}
+	rule, err := compileRule(models.AutoModerationRule{ID: 1, Name: "test", Conditions: raw, Action: models.AutoModerationHold})
+	if err != nil {
+		t.Fatalf("compileRule: %v", err)
+	}
+	return rule
+}
+
+// testModerationContext dựng context như Evaluate nhưng nạp sẵn người đăng, tốc độ đăng và kết quả trùng lặp
+// để match không phải truy vấn repository
+func testModerationContext(subject AutoModerationSubject, author *models.User, recent map[int]int64, duplicate bool) *moderationContext {
+	ctx := &moderationContext{
+		subject:    subject,
+		plain:      strings.ToLower(utils.StripHTML(subject.Title + "\n" + subject.Body)),
+		links:      countLinks(subject.Title + "\n" + subject.Body),
+		author:     author,
+		authorDone: true,
+		velocity:   recent,
+		duplicates: map[int]bool{},
+	}
+	ctx.hash = "hash"
+	for _, hours := range []int{1, 24, 72} {
+		ctx.duplicates[hours] = duplicate
+	}
+	if ctx.velocity == nil {
+		ctx.velocity = map[int]int64{}
+	}
+	return ctx
+}
+
+func TestCompiledRuleMatch(t *testing.T) {
+	newUser := &models.User{Role: models.RoleUser, Reputation: 5, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	oldUser := &models.User{Role: models.RoleUser, Reputation: 500, CreatedAt: time.Now().Add(-90 * 24 * time.Hour)}
+	answer := AutoModerationSubject{ContentType: "answer", Event: models.AutoModerationEventCreate, Title: "", Body: "<p>Mua thuốc giá rẻ tại https://spam.example/a</p>"}
+
+	tests := []struct {
+		name        string
+		cond        models.AutoModerationConditions
+		subject     AutoModerationSubject
+		author      *models.User
+		recent      map[int]int64
+		duplicate   bool
+		wantMatch   bool
+		wantDetails []string
+	}{
+		{
+			name:      "no conditions matches everything",
+			subject:   answer,
+			wantMatch: true,
+		},
+		{
+			name:      "content type out of scope",
+			cond:      models.AutoModerationConditions{ContentTypes: []string{"question"}},
+			subject:   answer,
+			wantMatch: false,
+		},
+		{
+			name:      "event out of scope",
+			cond:      models.AutoModerationConditions{Events: []string{models.AutoModerationEventUpdate}},
+			subject:   answer,
+			wantMatch: false,
+		},
+		{
+			name:        "banned word is case insensitive",
+			cond:        models.AutoModerationConditions{BannedWords: []string{"THUỐC"}},
+			subject:     answer,
+			wantMatch:   true,
+			wantDetails: []string{"banned_word: thuốc"},
+		},
+		{
+			name:      "banned word must be a whole word",
+			cond:      models.AutoModerationConditions{BannedWords: []string{"mua"}},
+			subject:   AutoModerationSubject{ContentType: "answer", Body: "<p>Trời mưa muamua</p>"},
+			wantMatch: false,
+		},
+		{
+			name:      "banned word is matched against text, not markup",
+			cond:      models.AutoModerationConditions{BannedWords: []string{"script"}},
+			subject:   AutoModerationSubject{ContentType: "answer", Body: `<p class="script">xin chào</p>`},
+			wantMatch: false,
+		},
+		{
+			name:        "pattern matches title and body",
+			cond:        models.AutoModerationConditions{Patterns: []string{`(?i)^giảm giá\n`}},
+			subject:     AutoModerationSubject{ContentType: "post", Title: "Giảm giá", Body: "<p>50%</p>"},
+			wantMatch:   true,
+			wantDetails: []string{`pattern: (?i)^giảm giá\n`},
+		},
+		{
+			name:      "every pattern must match",
+			cond:      models.AutoModerationConditions{Patterns: []string{"spam", "casino"}},
+			subject:   answer,
+			wantMatch: false,
+		},
+		{
+			name:        "min links",
+			cond:        models.AutoModerationConditions{MinLinks: intPtr(1)},
+			subject:     answer,
+			wantMatch:   true,
+			wantDetails: []string{"links: 1"},
+		},
+		{
+			name:      "duplicate links are counted once",
+			cond:      models.AutoModerationConditions{MinLinks: intPtr(2)},
+			subject:   AutoModerationSubject{ContentType: "answer", Body: "https://a.example/x https://A.example/x."},
+			wantMatch: false,
+		},
+		{
+			name:        "new account",
+			cond:        models.AutoModerationConditions{MaxAccountAgeHours: intPtr(24)},
+			subject:     answer,
+			author:      newUser,
+			wantMatch:   true,
+			wantDetails: []string{"account_age_hours: 2"},
+		},
+		{
+			name:      "old account is not new",
+			cond:      models.AutoModerationConditions{MaxAccountAgeHours: intPtr(24)},
+			subject:   answer,
+			author:    oldUser,
+			wantMatch: false,
+		},
+		{
+			name:      "account conditions need the author",
+			cond:      models.AutoModerationConditions{MaxReputation: uintPtr(10)},
+			subject:   answer,
+			wantMatch: false,
+		},
+		{
+			name:        "low reputation",
+			cond:        models.AutoModerationConditions{MaxReputation: uintPtr(10)},
+			subject:     answer,
+			author:      newUser,
+			wantMatch:   true,
+			wantDetails: []string{"reputation: 5"},
+		},
+		{
+			name:      "high reputation",
+			cond:      models.AutoModerationConditions{MinReputation: uintPtr(100)},
+			subject:   answer,
+			author:    newUser,
+			wantMatch: false,
+		},
+		{
+			name:        "velocity reached",
+			cond:        models.AutoModerationConditions{Velocity: &models.AutoModerationVelocity{Count: 3, Minutes: 10}},
+			subject:     answer,
+			recent:      map[int]int64{10: 3},
+			wantMatch:   true,
+			wantDetails: []string{"recent_posts: 3 in 10 minutes"},
+		},
+		{
+			name:      "velocity not reached",
+			cond:      models.AutoModerationConditions{Velocity: &models.AutoModerationVelocity{Count: 3, Minutes: 10}},
+			subject:   answer,
+			recent:    map[int]int64{10: 2},
+			wantMatch: false,
+		},
+		{
+			name:        "duplicate content",
+			cond:        models.AutoModerationConditions{DuplicateHours: intPtr(24)},
+			subject:     answer,
+			duplicate:   true,
+			wantMatch:   true,
+			wantDetails: []string{"duplicate within 24 hours"},
+		},
+		{
+			name:      "unique content",
+			cond:      models.AutoModerationConditions{DuplicateHours: intPtr(24)},
+			subject:   answer,
+			wantMatch: false,
+		},
+		{
+			name:        "all conditions must match",
+			cond:        models.AutoModerationConditions{BannedWords: []string{"thuốc"}, MinLinks: intPtr(1), MaxAccountAgeHours: intPtr(24)},
+			subject:     answer,
+			author:      newUser,
+			wantMatch:   true,
+			wantDetails: []string{"banned_word: thuốc", "links: 1", "account_age_hours: 2"},
+		},
+		{
+			name:      "one failing condition rejects the rule",
+			cond:      models.AutoModerationConditions{BannedWords: []string{"thuốc"}, MaxAccountAgeHours: intPtr(24)},
+			subject:   answer,
+			author:    oldUser,
+			wantMatch: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := mustCompileRule(t, tt.cond)
+			ctx := testModerationContext(tt.subject, tt.author, tt.recent, tt.duplicate)
+			details, ok := rule.match(ctx)
+			if ok != tt.wantMatch {
+				t.Fatalf("match = %v (%v), want %v", ok, details, tt.wantMatch)
+			}
+			if ok && !reflect.DeepEqual(details, tt.wantDetails) && !(len(details) == 0 && len(tt.wantDetails) == 0) {
+				t.Errorf("details = %q, want %q", details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestCompileRuleRejectsInvalidConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions string
+	}{
+		{"malformed json", `{"banned_words": [`},
+		{"invalid pattern", `{"patterns": ["(unclosed"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := compileRule(models.AutoModerationRule{Conditions: json.RawMessage(tt.conditions)}); err == nil {
+				t.Errorf("compileRule(%s) succeeded, want an error", tt.conditions)
+			}
+		})
+	}
+}
+
+func TestAutoModerationStatus(t *testing.T) {
+	tests := []struct {
+		contentType string
+		action      models.AutoModerationAction
+		want        string
+	}{
+		{"question", models.AutoModerationApprove, "approved"},
+		{"answer", models.AutoModerationHold, "pending"},
+		{"post", models.AutoModerationReject, "rejected"},
+		{"answer", models.AutoModerationSpam, "rejected"},
+		{"comment", models.AutoModerationReject, "spam"},
+		{"comment", models.AutoModerationSpam, "spam"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType+"/"+string(tt.action), func(t *testing.T) {
+			if got := autoModerationStatus(tt.contentType, tt.action); got != tt.want {
+				t.Errorf("autoModerationStatus(%q, %q) = %q, want %q", tt.contentType, tt.action, got, tt.want)
+			}
+		})
+	}
+}
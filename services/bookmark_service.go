@@ -0,0 +1,403 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"errors"
+	"log"
+	"strings"
+)
+
+// Số bộ sưu tập tối đa mỗi người dùng
+const maxBookmarkCollections = 50
+
+type BookmarkInput struct {
+	TargetType   models.BookmarkTargetType
+	TargetID     uint
+	CollectionID *uint // nil hoặc 0: mục "Đã lưu" mặc định
+	Note         string
+}
+
+// BookmarkUpdate chỉ thay đổi các trường khác nil; CollectionID = 0 chuyển về mục mặc định
+type BookmarkUpdate struct {
+	Note         *string
+	CollectionID *uint
+}
+
+type CollectionInput struct {
+	Name        string
+	Description string
+	IsPublic    bool
+}
+
+// BookmarkItem là bookmark kèm nội dung gốc. Available = false khi nội dung đã bị xoá hoặc bị từ chối,
+// khi đó không có nội dung đi kèm và client hiển thị placeholder.
+type BookmarkItem struct {
+	Bookmark  models.Bookmark
+	Available bool
+	Question  *models.Question
+	Answer    *models.Answer
+	Post      *models.Post
+}
+
+// CollectionSummary là bộ sưu tập kèm số bookmark bên trong
+type CollectionSummary struct {
+	Collection models.BookmarkCollection
+	ItemCount  int
+}
+
+// BookmarkService quản lý bookmark và bộ sưu tập. Không cache vì dữ liệu theo từng người dùng.
+type BookmarkService interface {
+	AddBookmark(userID uint, input BookmarkInput) (*models.Bookmark, error)
+	UpdateBookmark(id, userID uint, input BookmarkUpdate) (*models.Bookmark, error)
+	RemoveBookmark(id, userID uint) error
+	RemoveBookmarkByTarget(userID uint, targetType models.BookmarkTargetType, targetID uint) error
+	ListBookmarks(userID uint, filters map[string]interface{}) ([]BookmarkItem, int, error)
+	ReorderBookmarks(userID uint, collectionID uint, ids []uint) error
+	BookmarkedIDs(userID uint, targetType models.BookmarkTargetType, targetIDs []uint) map[uint]bool
+
+	CreateCollection(userID uint, input CollectionInput) (*models.BookmarkCollection, error)
+	UpdateCollection(id, userID uint, input CollectionInput) (*models.BookmarkCollection, error)
+	DeleteCollection(id, userID uint) error
+	ListCollections(ownerID, viewerID uint) ([]CollectionSummary, int, error)
+	GetCollection(id, viewerID uint, filters map[string]interface{}) (*models.BookmarkCollection, []BookmarkItem, int, error)
+}
+
+type bookmarkService struct {
+	bookmarkRepo repositories.BookmarkRepository
+}
+
+func NewBookmarkService(bookmarkRepo repositories.BookmarkRepository) BookmarkService {
+	return &bookmarkService{bookmarkRepo: bookmarkRepo}
+}
+
+// AddBookmark lưu nội dung vào bộ sưu tập; nếu đã lưu trước đó thì chuyển sang bộ sưu tập mới
+// và cập nhật ghi chú (nếu có gửi kèm)
+func (s *bookmarkService) AddBookmark(userID uint, input BookmarkInput) (*models.Bookmark, error) {
+	if !models.IsValidBookmarkTargetType(input.TargetType) {
+		return nil, errors.New("invalid bookmark target type")
+	}
+	if input.TargetID == 0 {
+		return nil, errors.New("target id is required")
+	}
+	collectionID, err := s.ownedCollection(input.CollectionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := s.loadTargets([]models.Bookmark{{TargetType: input.TargetType, TargetID: input.TargetID}})
+	if !items[0].Available {
+		return nil, errors.New("content not found")
+	}
+
+	if existing, err := s.bookmarkRepo.GetBookmarkByTarget(userID, input.TargetType, input.TargetID); err == nil {
+		update := BookmarkUpdate{CollectionID: orZero(collectionID)}
+		if input.Note != "" {
+			update.Note = &input.Note
+		}
+		return s.UpdateBookmark(existing.ID, userID, update)
+	}
+
+	position, err := s.bookmarkRepo.NextPosition(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	bookmark := &models.Bookmark{
+		UserID:       userID,
+		TargetType:   input.TargetType,
+		TargetID:     input.TargetID,
+		CollectionID: collectionID,
+		Note:         strings.TrimSpace(input.Note),
+		Position:     position,
+	}
+	if err := s.bookmarkRepo.CreateBookmark(bookmark); err != nil {
+		log.Printf("Failed to create bookmark for user %d: %v", userID, err)
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+func (s *bookmarkService) UpdateBookmark(id, userID uint, input BookmarkUpdate) (*models.Bookmark, error) {
+	bookmark, err := s.getOwnedBookmark(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Note != nil {
+		bookmark.Note = strings.TrimSpace(*input.Note)
+	}
+	if input.CollectionID != nil {
+		collectionID, err := s.ownedCollection(input.CollectionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		// Chuyển sang bộ sưu tập khác thì xếp xuống cuối bộ sưu tập đó
+		if !sameCollection(bookmark.CollectionID, collectionID) {
+			position, err := s.bookmarkRepo.NextPosition(userID, collectionID)
+			if err != nil {
+				return nil, err
+			}
+			bookmark.CollectionID = collectionID
+			bookmark.Position = position
+		}
+	}
+	if err := s.bookmarkRepo.UpdateBookmark(bookmark); err != nil {
+		log.Printf("Failed to update bookmark %d: %v", id, err)
+		return nil, err
+	}
+	return bookmark, nil
+}
+
+func (s *bookmarkService) RemoveBookmark(id, userID uint) error {
+	if _, err := s.getOwnedBookmark(id, userID); err != nil {
+		return err
+	}
+	return s.bookmarkRepo.DeleteBookmark(id)
+}
+
+func (s *bookmarkService) RemoveBookmarkByTarget(userID uint, targetType models.BookmarkTargetType, targetID uint) error {
+	bookmark, err := s.bookmarkRepo.GetBookmarkByTarget(userID, targetType, targetID)
+	if err != nil {
+		return errors.New("bookmark not found")
+	}
+	return s.bookmarkRepo.DeleteBookmark(bookmark.ID)
+}
+
+func (s *bookmarkService) ListBookmarks(userID uint, filters map[string]interface{}) ([]BookmarkItem, int, error) {
+	if collectionID, ok := filters["collection_id"].(uint); ok && collectionID != 0 {
+		if _, err := s.ownedCollection(&collectionID, userID); err != nil {
+			return nil, 0, err
+		}
+	}
+	bookmarks, total, err := s.bookmarkRepo.ListBookmarks(userID, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.loadTargets(bookmarks), total, nil
+}
+
+// ReorderBookmarks nhận toàn bộ ID bookmark của một bộ sưu tập (0 là mục mặc định) theo thứ tự mới
+func (s *bookmarkService) ReorderBookmarks(userID uint, collectionID uint, ids []uint) error {
+	if len(ids) == 0 {
+		return errors.New("bookmark ids are required")
+	}
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return errors.New("duplicate bookmark id")
+		}
+		seen[id] = true
+	}
+	target, err := s.ownedCollection(&collectionID, userID)
+	if err != nil {
+		return err
+	}
+	return s.bookmarkRepo.ReorderBookmarks(userID, target, ids)
+}
+
+// BookmarkedIDs trả về tập ID nội dung mà người dùng đã lưu, dùng để gắn cờ bookmarked vào response
+func (s *bookmarkService) BookmarkedIDs(userID uint, targetType models.BookmarkTargetType, targetIDs []uint) map[uint]bool {
+	marked := make(map[uint]bool)
+	if userID == 0 || len(targetIDs) == 0 {
+		return marked
+	}
+	ids, err := s.bookmarkRepo.BookmarkedTargetIDs(userID, targetType, targetIDs)
+	if err != nil {
+		log.Printf("Failed to load bookmarks of user %d: %v", userID, err)
+		return marked
+	}
+	for _, id := range ids {
+		marked[id] = true
+	}
+	return marked
+}
+
+func (s *bookmarkService) CreateCollection(userID uint, input CollectionInput) (*models.BookmarkCollection, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("collection name is required")
+	}
+	existing, err := s.bookmarkRepo.ListCollections(userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxBookmarkCollections {
+		return nil, errors.New("collection limit reached")
+	}
+
+	collection := &models.BookmarkCollection{
+		UserID:      userID,
+		Name:        name,
+		Description: strings.TrimSpace(input.Description),
+		IsPublic:    input.IsPublic,
+	}
+	if err := s.bookmarkRepo.CreateCollection(collection); err != nil {
+		log.Printf("Failed to create bookmark collection for user %d: %v", userID, err)
+		return nil, err
+	}
+	return collection, nil
+}
+
+func (s *bookmarkService) UpdateCollection(id, userID uint, input CollectionInput) (*models.BookmarkCollection, error) {
+	collection, err := s.bookmarkRepo.GetCollectionByID(id)
+	if err != nil || collection.UserID != userID {
+		return nil, errors.New("collection not found")
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("collection name is required")
+	}
+	collection.Name = name
+	collection.Description = strings.TrimSpace(input.Description)
+	collection.IsPublic = input.IsPublic
+	if err := s.bookmarkRepo.UpdateCollection(collection); err != nil {
+		log.Printf("Failed to update bookmark collection %d: %v", id, err)
+		return nil, err
+	}
+	return collection, nil
+}
+
+// DeleteCollection xoá bộ sưu tập; các bookmark bên trong được giữ lại ở mục mặc định
+func (s *bookmarkService) DeleteCollection(id, userID uint) error {
+	collection, err := s.bookmarkRepo.GetCollectionByID(id)
+	if err != nil || collection.UserID != userID {
+		return errors.New("collection not found")
+	}
+	return s.bookmarkRepo.DeleteCollection(id)
+}
+
+// ListCollections trả về các bộ sưu tập của ownerID và số bookmark nằm ở mục mặc định.
+// Người xem khác chỉ thấy các bộ sưu tập công khai, không thấy số bookmark ở mục mặc định.
+func (s *bookmarkService) ListCollections(ownerID, viewerID uint) ([]CollectionSummary, int, error) {
+	owner := ownerID == viewerID
+	collections, err := s.bookmarkRepo.ListCollections(ownerID, !owner)
+	if err != nil {
+		return nil, 0, err
+	}
+	counts, err := s.bookmarkRepo.CountByCollection(ownerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !owner {
+		counts[0] = 0
+	}
+	summaries := make([]CollectionSummary, 0, len(collections))
+	for _, collection := range collections {
+		summaries = append(summaries, CollectionSummary{Collection: collection, ItemCount: counts[collection.ID]})
+	}
+	return summaries, counts[0], nil
+}
+
+// GetCollection trả về bộ sưu tập cùng các bookmark. Người khác chỉ xem được bộ sưu tập công khai
+// và không thấy ghi chú riêng của chủ sở hữu.
+func (s *bookmarkService) GetCollection(id, viewerID uint, filters map[string]interface{}) (*models.BookmarkCollection, []BookmarkItem, int, error) {
+	collection, err := s.bookmarkRepo.GetCollectionByID(id)
+	if err != nil {
+		return nil, nil, 0, errors.New("collection not found")
+	}
+	owner := collection.UserID == viewerID
+	if !owner && !collection.IsPublic {
+		return nil, nil, 0, errors.New("collection not found")
+	}
+
+	filters["collection_id"] = collection.ID
+	bookmarks, total, err := s.bookmarkRepo.ListBookmarks(collection.UserID, filters)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if !owner {
+		for i := range bookmarks {
+			bookmarks[i].Note = ""
+		}
+	}
+	return collection, s.loadTargets(bookmarks), total, nil
+}
+
+func (s *bookmarkService) getOwnedBookmark(id, userID uint) (*models.Bookmark, error) {
+	bookmark, err := s.bookmarkRepo.GetBookmarkByID(id)
+	if err != nil || bookmark.UserID != userID {
+		return nil, errors.New("bookmark not found")
+	}
+	return bookmark, nil
+}
+
+// ownedCollection kiểm tra bộ sưu tập thuộc về người dùng; nil hoặc 0 trả về nil (mục mặc định)
+func (s *bookmarkService) ownedCollection(collectionID *uint, userID uint) (*uint, error) {
+	if collectionID == nil || *collectionID == 0 {
+		return nil, nil
+	}
+	collection, err := s.bookmarkRepo.GetCollectionByID(*collectionID)
+	if err != nil || collection.UserID != userID {
+		return nil, errors.New("collection not found")
+	}
+	return &collection.ID, nil
+}
+
+// loadTargets nạp nội dung gốc theo lô cho từng loại; nội dung đã xoá (không còn trong kết quả)
+// hoặc đã bị từ chối được đánh dấu không khả dụng thay vì bị bỏ khỏi danh sách
+func (s *bookmarkService) loadTargets(bookmarks []models.Bookmark) []BookmarkItem {
+	ids := make(map[models.BookmarkTargetType][]uint)
+	for _, bookmark := range bookmarks {
+		ids[bookmark.TargetType] = append(ids[bookmark.TargetType], bookmark.TargetID)
+	}
+
+	questions := make(map[uint]*models.Question)
+	if list, err := s.bookmarkRepo.GetQuestionsByIDs(ids[models.BookmarkQuestion]); err == nil {
+		for i := range list {
+			questions[list[i].ID] = &list[i]
+		}
+	} else {
+		log.Printf("Failed to load bookmarked questions: %v", err)
+	}
+	answers := make(map[uint]*models.Answer)
+	if list, err := s.bookmarkRepo.GetAnswersByIDs(ids[models.BookmarkAnswer]); err == nil {
+		for i := range list {
+			answers[list[i].ID] = &list[i]
+		}
+	} else {
+		log.Printf("Failed to load bookmarked answers: %v", err)
+	}
+	posts := make(map[uint]*models.Post)
+	if list, err := s.bookmarkRepo.GetPostsByIDs(ids[models.BookmarkPost]); err == nil {
+		for i := range list {
+			posts[list[i].ID] = &list[i]
+		}
+	} else {
+		log.Printf("Failed to load bookmarked posts: %v", err)
+	}
+
+	items := make([]BookmarkItem, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		item := BookmarkItem{Bookmark: bookmark}
+		switch bookmark.TargetType {
+		case models.BookmarkQuestion:
+			if question, ok := questions[bookmark.TargetID]; ok && question.Status != models.StatusRejected {
+				item.Question, item.Available = question, true
+			}
+		case models.BookmarkAnswer:
+			if answer, ok := answers[bookmark.TargetID]; ok && answer.Status != "rejected" {
+				item.Answer, item.Available = answer, true
+			}
+		case models.BookmarkPost:
+			if post, ok := posts[bookmark.TargetID]; ok && post.Status != models.Rejected {
+				item.Post, item.Available = post, true
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func sameCollection(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// orZero đổi nil thành con trỏ tới 0 để UpdateBookmark hiểu là chuyển về mục mặc định
+func orZero(id *uint) *uint {
+	if id == nil {
+		zero := uint(0)
+		return &zero
+	}
+	return id
+}
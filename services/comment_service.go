@@ -16,43 +16,68 @@ import (
 )
 
 type CommentService interface {
-	CreateComment(content string, userID uint, postID *uint, answerID *uint, parentID *uint, status string) (*models.Comment, error)
+	CreateComment(content string, userID uint, postID *uint, answerID *uint, questionID *uint, parentID *uint, contentFormat string, anonymous bool) (*models.Comment, error)
 	GetCommentByID(id uint) (*models.Comment, error)
-	UpdateComment(id uint, content string) (*models.Comment, error)
+	UpdateComment(id uint, content string, contentFormat string) (*models.Comment, error)
 	DeleteComment(id uint) error
 	ListComments(filters map[string]interface{}) ([]models.Comment, int, error)
 	ListReplies(parentID uint, filters map[string]interface{}) ([]models.Comment, int, error)
 	GetAllComments(filters map[string]interface{}) ([]models.Comment, int, error)
 	UpdateCommentStatus(id uint, status string) (*models.Comment, error)
+	ApplyReviewedStatus(id uint, status string) (*models.Comment, error)
+	GetThread(filters map[string]interface{}, depth, perParent int) ([]*models.CommentNode, int, error)
+	CountReplies(id uint) (int64, error)
 }
 
+// Giới hạn khi dựng cây bình luận
+const (
+	defaultThreadDepth    = 3
+	maxThreadDepth        = 10
+	defaultThreadChildren = 5
+	maxThreadChildren     = 50
+)
+
 type commentService struct {
-	commentRepo repositories.CommentRepository
-	postRepo    repositories.PostRepository
-	answerRepo  repositories.AnswerRepository
-	userRepo    repositories.UserRepository // Thêm UserRepository
-	redisClient *redis.Client
-	db          *gorm.DB
-	novuClient  *notification.NovuClient // Thêm NovuClient
+	commentRepo  repositories.CommentRepository
+	postRepo     repositories.PostRepository
+	answerRepo   repositories.AnswerRepository
+	questionRepo repositories.QuestionRepository
+	followRepo   repositories.QuestionFollowRepository
+	userRepo     repositories.UserRepository // Thêm UserRepository
+	redisClient  *redis.Client
+	db           *gorm.DB
+	novuClient   *notification.NovuClient // Thêm NovuClient
+	mentions     MentionService
+	autoMod      AutoModerationService
 }
 
-func NewCommentService(cRepo repositories.CommentRepository, pRepo repositories.PostRepository, aRepo repositories.AnswerRepository, userRepo repositories.UserRepository, redisClient *redis.Client, db *gorm.DB, novuClient *notification.NovuClient) CommentService {
+func NewCommentService(cRepo repositories.CommentRepository, pRepo repositories.PostRepository, aRepo repositories.AnswerRepository, qRepo repositories.QuestionRepository, followRepo repositories.QuestionFollowRepository, userRepo repositories.UserRepository, redisClient *redis.Client, db *gorm.DB, novuClient *notification.NovuClient, mentions MentionService, autoMod AutoModerationService) CommentService {
 	return &commentService{
-		commentRepo: cRepo,
-		postRepo:    pRepo,
-		answerRepo:  aRepo,
-		userRepo:    userRepo, // Khởi tạo UserRepository
-		redisClient: redisClient,
-		db:          db,
-		novuClient:  novuClient, // Khởi tạo NovuClient
+		commentRepo:  cRepo,
+		postRepo:     pRepo,
+		answerRepo:   aRepo,
+		questionRepo: qRepo,
+		followRepo:   followRepo,
+		userRepo:     userRepo, // Khởi tạo UserRepository
+		redisClient:  redisClient,
+		db:           db,
+		novuClient:   novuClient, // Khởi tạo NovuClient
+		mentions:     mentions,
+		autoMod:      autoMod,
 	}
 }
 
-func (s *commentService) CreateComment(content string, userID uint, postID *uint, answerID *uint, parentID *uint, status string) (*models.Comment, error) {
+func (s *commentService) CreateComment(content string, userID uint, postID *uint, answerID *uint, questionID *uint, parentID *uint, contentFormat string, anonymous bool) (*models.Comment, error) {
 	if content == "" {
 		return nil, fmt.Errorf("Nội dung là bắt buộc")
 	}
+	format, err := resolveContentFormat(contentFormat, "")
+	if err != nil {
+		return nil, fmt.Errorf("Định dạng nội dung không hợp lệ")
+	}
 
+	// thread là luồng thảo luận chứa bình luận, dùng để tính bí danh khi ẩn danh
+	var thread string
 	if postID != nil {
 		post, err := s.postRepo.GetPostByID(*postID)
 		if err != nil {
@@ -61,13 +86,26 @@ func (s *commentService) CreateComment(content string, userID uint, postID *uint
 		if post.Status != "approved" {
 			return nil, fmt.Errorf("Không thể bình luận trên bài viết chưa được duyệt")
 		}
+		thread = utils.PostThread(post.ID)
 	}
 
 	if answerID != nil {
-		_, err := s.answerRepo.GetAnswerByID(*answerID)
+		answer, err := s.answerRepo.GetAnswerByID(*answerID)
 		if err != nil {
 			return nil, fmt.Errorf("Không tìm thấy câu trả lời: %v", err)
 		}
+		if answer.Question.IsLocked(time.Now()) {
+			return nil, fmt.Errorf("Câu hỏi đã bị khoá, không thể bình luận")
+		}
+		thread = utils.QuestionThread(answer.QuestionID)
+	}
+
+	if questionID != nil {
+		question, err := s.commentableQuestion(*questionID)
+		if err != nil {
+			return nil, err
+		}
+		thread = utils.QuestionThread(question.ID)
 	}
 
 	if parentID != nil {
@@ -78,21 +116,59 @@ func (s *commentService) CreateComment(content string, userID uint, postID *uint
 		if parent.Status != "approved" {
 			return nil, fmt.Errorf("Không thể trả lời bình luận chưa được duyệt")
 		}
+		if parent.Depth+1 > models.MaxCommentDepth {
+			return nil, fmt.Errorf("Nhánh bình luận đã quá sâu, không thể trả lời thêm")
+		}
+		if answerID == nil && parent.AnswerID != nil {
+			if answer, err := s.answerRepo.GetAnswerByID(*parent.AnswerID); err == nil {
+				if answer.Question.IsLocked(time.Now()) {
+					return nil, fmt.Errorf("Câu hỏi đã bị khoá, không thể bình luận")
+				}
+				if thread == "" {
+					thread = utils.QuestionThread(answer.QuestionID)
+				}
+			}
+		}
+		if questionID == nil && parent.QuestionID != nil {
+			question, err := s.commentableQuestion(*parent.QuestionID)
+			if err != nil {
+				return nil, err
+			}
+			if thread == "" {
+				thread = utils.QuestionThread(question.ID)
+			}
+		}
+		if thread == "" && parent.PostID != nil {
+			thread = utils.PostThread(*parent.PostID)
+		}
+	}
+	if anonymous && thread == "" {
+		return nil, fmt.Errorf("Không xác định được nơi bình luận để đăng ẩn danh")
 	}
 
 	comment := &models.Comment{
-		Content:  content,
-		UserID:   userID,
-		PostID:   postID,
-		AnswerID: answerID,
-		ParentID: parentID,
-		Status:   status,
-		Metadata: []byte(`{"has_replies": false}`),
+		ContentFormat: format,
+		UserID:        userID,
+		PostID:        postID,
+		AnswerID:      answerID,
+		QuestionID:    questionID,
+		ParentID:      parentID,
+		Metadata:      []byte(`{"has_replies": false}`),
+		Anonymous:     anonymous,
+	}
+	if anonymous {
+		comment.Pseudonym = utils.Pseudonym(thread, userID)
 	}
+	applyContentBody(format, content, &comment.Content, &comment.ContentSource, &comment.PlainContent)
+
+	subject := AutoModerationSubject{ContentType: models.ModerationComment, UserID: userID, Event: models.AutoModerationEventCreate, Body: comment.Content}
+	verdict := s.autoMod.Evaluate(subject)
+	comment.Status = verdict.Status
 
 	if err := s.commentRepo.CreateComment(comment); err != nil {
 		return nil, fmt.Errorf("Tạo bình luận thất bại: %v", err)
 	}
+	s.autoMod.Record(subject, comment.ID, verdict)
 
 	// Invalidate cache
 	if postID != nil && parentID == nil {
@@ -101,21 +177,29 @@ func (s *commentService) CreateComment(content string, userID uint, postID *uint
 	if answerID != nil && parentID == nil {
 		s.invalidateCache(fmt.Sprintf("comments:answer:%d:*", *answerID))
 	}
+	if questionID != nil && parentID == nil {
+		s.invalidateCache(fmt.Sprintf("comments:question:%d:*", *questionID))
+	}
 	if parentID != nil {
 		s.invalidateCache(fmt.Sprintf("comments:comment:%d:*", *parentID))
 		s.updateReplyCacheAfterCreate(comment, *parentID)
 	}
+	s.syncPublishedMentions(comment)
 
 	// Send notification
 	commenter, err := s.userRepo.GetUserByID(userID)
 	if err != nil {
 		log.Printf("Không lấy được thông tin người bình luận: %v", err)
 	} else {
+		commenterName := commenter.FullName
+		if comment.Anonymous {
+			commenterName = comment.Pseudonym
+		}
 		if postID != nil {
 			post, err := s.postRepo.GetPostByID(*postID)
 			if err == nil && post.UserID != userID {
 				workflowID := "new-post-comment-notification"
-				message := fmt.Sprintf("%s đã bình luận trên bài viết của bạn: %s", commenter.FullName, post.Title)
+				message := fmt.Sprintf("%s đã bình luận trên bài viết của bạn: %s", commenterName, post.Title)
 				if err := s.novuClient.SendNotification(post.UserID, workflowID, message); err != nil {
 					log.Printf("Gửi thông báo bình luận bài viết thất bại: %v", err)
 				}
@@ -125,7 +209,7 @@ func (s *commentService) CreateComment(content string, userID uint, postID *uint
 			if err == nil && parent.UserID != userID {
 				workflowID := "new-comment-reply-notification"
 
-				message := fmt.Sprintf("%s đã trả lời bình luận của bạn: %s", commenter.FullName, utils.StripHTML(parent.Content))
+				message := fmt.Sprintf("%s đã trả lời bình luận của bạn: %s", commenterName, utils.StripHTML(parent.Content))
 				log.Printf("Gửi thông báo trả lời bình luận thất bại: %v", message)
 				if err := s.novuClient.SendNotification(parent.UserID, workflowID, message); err != nil {
 					log.Printf("Gửi thông báo trả lời bình luận thất bại: %v", err)
@@ -135,17 +219,65 @@ func (s *commentService) CreateComment(content string, userID uint, postID *uint
 			answer, err := s.answerRepo.GetAnswerByID(*answerID)
 			if err == nil && answer.UserID != userID {
 				workflowID := "new-answer-comment-notification"
-				message := fmt.Sprintf("%s đã bình luận trên câu trả lời của bạn: %s", commenter.FullName, answer.Title)
+				message := fmt.Sprintf("%s đã bình luận trên câu trả lời của bạn: %s", commenterName, answer.Title)
 				if err := s.novuClient.SendNotification(answer.UserID, workflowID, message); err != nil {
 					log.Printf("Gửi thông báo trả lời bình luận thất bại: %v", err)
 				}
 			}
+		} else if questionID != nil {
+			s.notifyQuestionComment(*questionID, userID, commenterName)
 		}
 	}
 
 	return comment, nil
 }
 
+// commentableQuestion trả về câu hỏi nếu có thể bình luận: đã duyệt, đã đăng và không bị khoá.
+// Câu hỏi đã đóng vẫn nhận bình luận làm rõ.
+func (s *commentService) commentableQuestion(questionID uint) (*models.Question, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("Không tìm thấy câu hỏi: %v", err)
+	}
+	if question.Status != models.StatusApproved || question.PublishAt != nil {
+		return nil, fmt.Errorf("Không thể bình luận trên câu hỏi chưa được duyệt")
+	}
+	if question.IsLocked(time.Now()) {
+		return nil, fmt.Errorf("Câu hỏi đã bị khoá, không thể bình luận")
+	}
+	return question, nil
+}
+
+// notifyQuestionComment báo cho chủ câu hỏi và những người theo dõi câu hỏi (trừ người bình luận)
+func (s *commentService) notifyQuestionComment(questionID, commenterID uint, commenterName string) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		log.Printf("Không lấy được câu hỏi %d để gửi thông báo bình luận: %v", questionID, err)
+		return
+	}
+	if question.UserID != commenterID {
+		message := fmt.Sprintf("%s đã bình luận trên câu hỏi của bạn: %s", commenterName, question.Title)
+		if err := s.novuClient.SendNotification(question.UserID, "new-question-comment-notification", message); err != nil {
+			log.Printf("Gửi thông báo bình luận câu hỏi thất bại: %v", err)
+		}
+	}
+
+	follows, err := s.followRepo.GetFollowsByQuestion(questionID)
+	if err != nil {
+		log.Printf("Không lấy được người theo dõi câu hỏi %d: %v", questionID, err)
+		return
+	}
+	message := fmt.Sprintf("%s đã bình luận trên câu hỏi bạn theo dõi: %s", commenterName, question.Title)
+	for _, follow := range follows {
+		if follow.UserID == commenterID || follow.UserID == question.UserID {
+			continue
+		}
+		if err := s.novuClient.SendNotification(follow.UserID, "followed-question-comment-notification", message); err != nil {
+			log.Printf("Gửi thông báo bình luận cho người theo dõi câu hỏi thất bại: %v", err)
+		}
+	}
+}
+
 func (s *commentService) GetCommentByID(id uint) (*models.Comment, error) {
 	cacheKey := fmt.Sprintf("comment:%d", id)
 	ctx := context.Background()
@@ -180,7 +312,7 @@ func (s *commentService) GetCommentByID(id uint) (*models.Comment, error) {
 	return comment, nil
 }
 
-func (s *commentService) UpdateComment(id uint, content string) (*models.Comment, error) {
+func (s *commentService) UpdateComment(id uint, content string, contentFormat string) (*models.Comment, error) {
 	comment, err := s.commentRepo.GetCommentByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("Lấy bình luận thất bại: %v", err)
@@ -188,14 +320,30 @@ func (s *commentService) UpdateComment(id uint, content string) (*models.Comment
 	if comment.DeletedAt.Valid {
 		return nil, fmt.Errorf("Không tìm thấy bình luận")
 	}
+	format, err := resolveContentFormat(contentFormat, comment.ContentFormat)
+	if err != nil {
+		return nil, fmt.Errorf("Định dạng nội dung không hợp lệ")
+	}
+	if format != comment.ContentFormat && content == "" {
+		return nil, fmt.Errorf("Cần nhập nội dung khi đổi định dạng")
+	}
 
+	var subject AutoModerationSubject
+	var verdict *AutoModerationVerdict
 	if content != "" {
-		comment.Content = content
+		comment.ContentFormat = format
+		applyContentBody(format, content, &comment.Content, &comment.ContentSource, &comment.PlainContent)
+		subject = AutoModerationSubject{ContentType: models.ModerationComment, ContentID: id, UserID: comment.UserID, Event: models.AutoModerationEventUpdate, Body: comment.Content}
+		verdict = s.autoMod.Evaluate(subject)
+		if verdict.Status != "" {
+			comment.Status = verdict.Status
+		}
 	}
 
 	if err := s.commentRepo.UpdateComment(comment); err != nil {
 		return nil, fmt.Errorf("Cập nhật bình luận thất bại: %v", err)
 	}
+	s.autoMod.Record(subject, id, verdict)
 
 	s.invalidateCache(fmt.Sprintf("comment:%d", id))
 	if comment.PostID != nil {
@@ -204,9 +352,15 @@ func (s *commentService) UpdateComment(id uint, content string) (*models.Comment
 	if comment.AnswerID != nil {
 		s.invalidateCache(fmt.Sprintf("comments:answer:%d:*", *comment.AnswerID))
 	}
+	if comment.QuestionID != nil {
+		s.invalidateCache(fmt.Sprintf("comments:question:%d:*", *comment.QuestionID))
+	}
 	if comment.ParentID != nil {
 		s.invalidateCache(fmt.Sprintf("replies:comment:%d:*", *comment.ParentID))
 	}
+	if content != "" {
+		s.syncPublishedMentions(comment)
+	}
 
 	return comment, nil
 }
@@ -242,6 +396,9 @@ func (s *commentService) DeleteComment(id uint) error {
 	if comment.AnswerID != nil {
 		s.invalidateCache(fmt.Sprintf("comments:answer:%d:*", *comment.AnswerID))
 	}
+	if comment.QuestionID != nil {
+		s.invalidateCache(fmt.Sprintf("comments:question:%d:*", *comment.QuestionID))
+	}
 	if comment.ParentID != nil {
 		s.invalidateCache(fmt.Sprintf("replies:comment:%d:*", *comment.ParentID))
 	}
@@ -264,8 +421,10 @@ func (s *commentService) ListComments(filters map[string]interface{}) ([]models.
 		cacheKey = utils.GenerateCacheKey("comments:post", postID, filters)
 	} else if answerID, ok := filters["answer_id"].(uint); ok {
 		cacheKey = utils.GenerateCacheKey("comments:answer", answerID, filters)
+	} else if questionID, ok := filters["question_id"].(uint); ok {
+		cacheKey = utils.GenerateCacheKey("comments:question", questionID, filters)
 	} else {
-		return nil, 0, fmt.Errorf("post_id hoặc answer_id là bắt buộc")
+		return nil, 0, fmt.Errorf("post_id, answer_id hoặc question_id là bắt buộc")
 	}
 
 	ctx := context.Background()
@@ -389,6 +548,11 @@ func (s *commentService) UpdateCommentStatus(id uint, status string) (*models.Co
 		log.Printf("Cập nhật trạng thái bình luận thất bại: %v", err)
 		return nil, err
 	}
+	return s.ApplyReviewedStatus(id, status)
+}
+
+// ApplyReviewedStatus chỉ xoá cache, nhắc tên và thông báo; trạng thái bình luận phải được ghi từ trước
+func (s *commentService) ApplyReviewedStatus(id uint, status string) (*models.Comment, error) {
 	comment, err := s.commentRepo.GetCommentByID(id)
 	if err != nil {
 		log.Printf("Lấy bình luận đã cập nhật %d thất bại: %v", id, err)
@@ -400,10 +564,15 @@ func (s *commentService) UpdateCommentStatus(id uint, status string) (*models.Co
 	if comment.AnswerID != nil {
 		s.invalidateCache(fmt.Sprintf("comments:answer:%d:*", *comment.AnswerID))
 	}
+	if comment.QuestionID != nil {
+		s.invalidateCache(fmt.Sprintf("comments:question:%d:*", *comment.QuestionID))
+	}
 	if comment.ParentID != nil {
 		s.invalidateCache(fmt.Sprintf("replies:comment:%d:*", *comment.ParentID))
 	}
 	s.invalidateCache("comments:all:*")
+	s.autoMod.Feedback(models.ModerationComment, id, status)
+	s.syncPublishedMentions(comment)
 	commentOwner, err := s.userRepo.GetUserByID(comment.UserID)
 	if err != nil {
 		log.Printf("Không lấy được thông tin chủ bình luận: %v", err)
@@ -424,6 +593,68 @@ func (s *commentService) UpdateCommentStatus(id uint, status string) (*models.Co
 	return comment, nil
 }
 
+// GetThread dựng cây bình luận: filters chọn trang các nút đầu (bình luận gốc của post_id/answer_id,
+// hoặc trả lời trực tiếp của parent_id, phân trang bằng cursor như các danh sách khác), sau đó tải
+// mỗi tầng con bằng một truy vấn, tối đa perParent trả lời cho mỗi nút và depth tầng bên dưới trang.
+// Nút có ReplyCount lớn hơn số trả lời đã tải được tải tiếp qua parent_id và cursor của trả lời cuối.
+func (s *commentService) GetThread(filters map[string]interface{}, depth, perParent int) ([]*models.CommentNode, int, error) {
+	if depth < 0 || depth > maxThreadDepth {
+		depth = defaultThreadDepth
+	}
+	if perParent < 1 || perParent > maxThreadChildren {
+		perParent = defaultThreadChildren
+	}
+	status, _ := filters["status"].(string)
+
+	comments, total, err := s.commentRepo.ListThreadPage(filters)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Lấy cây bình luận thất bại: %v", err)
+	}
+	roots := make([]*models.CommentNode, 0, len(comments))
+	level := make(map[uint]*models.CommentNode, len(comments))
+	for _, comment := range comments {
+		node := &models.CommentNode{Comment: comment}
+		roots = append(roots, node)
+		level[comment.ID] = node
+	}
+
+	for d := 0; len(level) > 0; d++ {
+		parentIDs := make([]uint, 0, len(level))
+		for id := range level {
+			parentIDs = append(parentIDs, id)
+		}
+		counts, err := s.commentRepo.CountChildren(parentIDs, status)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Đếm trả lời thất bại: %v", err)
+		}
+		for id, node := range level {
+			node.ReplyCount = counts[id]
+		}
+		if d == depth {
+			break
+		}
+
+		children, err := s.commentRepo.ListChildren(parentIDs, perParent, status)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Lấy trả lời thất bại: %v", err)
+		}
+		next := make(map[uint]*models.CommentNode, len(children))
+		for _, child := range children {
+			parent := level[*child.ParentID]
+			node := &models.CommentNode{Comment: child}
+			parent.Replies = append(parent.Replies, node)
+			next[child.ID] = node
+		}
+		level = next
+	}
+	return roots, int(total), nil
+}
+
+// CountReplies đếm toàn bộ hậu duệ của một bình luận (mọi tầng)
+func (s *commentService) CountReplies(id uint) (int64, error) {
+	return s.commentRepo.CountDescendants(id)
+}
+
 func IsValidCommentStatus(status string) bool {
 	validStatuses := []string{"approved", "pending", "spam"}
 	for _, s := range validStatuses {
@@ -550,3 +781,11 @@ func (s *commentService) invalidateCache(pattern string) {
 		}
 	}
 }
+
+// syncPublishedMentions chỉ đồng bộ và gửi thông báo nhắc tên khi bình luận đã được duyệt
+func (s *commentService) syncPublishedMentions(comment *models.Comment) {
+	if comment.Status != "approved" {
+		return
+	}
+	s.mentions.SyncMentions(models.MentionTargetComment, comment.ID, comment.UserID, comment.Pseudonym, comment.Content, mentionSummary("", comment.PlainContent))
+}
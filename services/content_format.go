@@ -0,0 +1,42 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/utils"
+	"errors"
+)
+
+// resolveContentFormat chọn định dạng nội dung; giá trị rỗng giữ nguyên định dạng hiện tại (mặc định html)
+func resolveContentFormat(requested string, current models.ContentFormat) (models.ContentFormat, error) {
+	if requested == "" {
+		if current == "" {
+			return models.ContentFormatHTML, nil
+		}
+		return current, nil
+	}
+	format := models.ContentFormat(requested)
+	if !models.IsValidContentFormat(format) {
+		return "", errors.New("invalid content format, must be 'html' or 'markdown'")
+	}
+	return format, nil
+}
+
+// applyContentBody gán nội dung theo định dạng rồi tính HTML sẽ lưu và bản văn bản thuần dùng cho tìm kiếm:
+// markdown được lưu nguyên văn vào source và render ra content, html thì được lọc rồi gán vào content
+func applyContentBody(format models.ContentFormat, body string, content, source, plain *string) {
+	if format == models.ContentFormatMarkdown {
+		*source = body
+	} else {
+		*source = ""
+	}
+	*content = utils.RenderContent(string(format), *source, body)
+	*plain = utils.StripHTML(*content)
+}
+
+// editableBody trả về nội dung để người dùng chỉnh sửa: nguồn markdown nếu có, ngược lại là HTML đã lưu
+func editableBody(format models.ContentFormat, content, source string) string {
+	if format == models.ContentFormatMarkdown {
+		return source
+	}
+	return content
+}
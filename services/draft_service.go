@@ -0,0 +1,216 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Số ngày giữ bản nháp không được cập nhật trước khi bị xoá (ghi đè bằng DRAFT_RETENTION_DAYS)
+const defaultDraftRetentionDays = 30
+
+type DraftInput struct {
+	TargetType  models.DraftTargetType
+	TargetID    uint
+	Title       string
+	Content     string
+	Format      models.ContentFormat
+	TopicID     uint
+	TagIDs      []uint
+	BaseVersion uint // version client đang giữ; 0 nghĩa là không kiểm tra
+}
+
+// DraftPublishResult chứa đúng một trong ba đối tượng được tạo/cập nhật từ bản nháp
+type DraftPublishResult struct {
+	Question *models.Question
+	Answer   *models.Answer
+	Post     *models.Post
+}
+
+// DraftService lưu bản nháp phía server (tự động lưu, last-write-wins) và
+// chuyển bản nháp thành câu hỏi, câu trả lời hoặc bài viết qua các service sẵn có.
+// Không cache vì bản nháp thay đổi liên tục và chỉ chủ sở hữu đọc.
+type DraftService interface {
+	SaveDraft(userID uint, input DraftInput) (*models.Draft, bool, error)
+	GetDraft(id, userID uint) (*models.Draft, error)
+	GetDraftByTarget(userID uint, targetType models.DraftTargetType, targetID uint) (*models.Draft, error)
+	ListDrafts(userID uint, filters map[string]interface{}) ([]models.Draft, int, error)
+	DeleteDraft(id, userID uint) error
+	PublishDraft(id, userID uint) (*DraftPublishResult, error)
+	PruneDrafts() (int64, error)
+}
+
+type draftService struct {
+	draftRepo       repositories.DraftRepository
+	questionService QuestionService
+	answerService   AnswerService
+	postService     PostService
+	retention       time.Duration
+}
+
+func NewDraftService(draftRepo repositories.DraftRepository, qService QuestionService, aService AnswerService, pService PostService) DraftService {
+	days := defaultDraftRetentionDays
+	if value, err := strconv.Atoi(os.Getenv("DRAFT_RETENTION_DAYS")); err == nil && value > 0 {
+		days = value
+	}
+	return &draftService{
+		draftRepo:       draftRepo,
+		questionService: qService,
+		answerService:   aService,
+		postService:     pService,
+		retention:       time.Duration(days) * 24 * time.Hour,
+	}
+}
+
+// SaveDraft ghi đè bản nháp của người dùng cho đích tương ứng. Giá trị bool trả về là true
+// khi BaseVersion cũ hơn bản trên server (ví dụ đang mở ở tab khác); bản mới vẫn được ghi.
+func (s *draftService) SaveDraft(userID uint, input DraftInput) (*models.Draft, bool, error) {
+	if !models.IsValidDraftTargetType(input.TargetType) {
+		return nil, false, errors.New("invalid draft target type")
+	}
+	switch input.TargetType {
+	case models.DraftNewQuestion, models.DraftNewPost:
+		input.TargetID = 0
+	default:
+		if input.TargetID == 0 {
+			return nil, false, errors.New("target id is required")
+		}
+	}
+
+	if input.Format != "" && !models.IsValidContentFormat(input.Format) {
+		return nil, false, errors.New("invalid content format, must be 'html' or 'markdown'")
+	}
+
+	conflict := false
+	if input.BaseVersion > 0 {
+		if existing, err := s.draftRepo.GetDraftByTarget(userID, input.TargetType, input.TargetID); err == nil && existing.Version > input.BaseVersion {
+			conflict = true
+		}
+	}
+
+	var tagIDs json.RawMessage
+	if len(input.TagIDs) > 0 {
+		encoded, err := json.Marshal(input.TagIDs)
+		if err != nil {
+			return nil, false, err
+		}
+		tagIDs = encoded
+	}
+
+	draft := &models.Draft{
+		UserID:        userID,
+		TargetType:    input.TargetType,
+		TargetID:      input.TargetID,
+		Title:         input.Title,
+		Content:       input.Content,
+		ContentFormat: input.Format,
+		TopicID:       input.TopicID,
+		TagIDs:        tagIDs,
+	}
+	if err := s.draftRepo.SaveDraft(draft); err != nil {
+		log.Printf("Failed to save draft for user %d: %v", userID, err)
+		return nil, false, err
+	}
+	return draft, conflict, nil
+}
+
+func (s *draftService) GetDraft(id, userID uint) (*models.Draft, error) {
+	draft, err := s.draftRepo.GetDraftByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.UserID != userID {
+		return nil, errors.New("draft not found")
+	}
+	return draft, nil
+}
+
+func (s *draftService) GetDraftByTarget(userID uint, targetType models.DraftTargetType, targetID uint) (*models.Draft, error) {
+	if !models.IsValidDraftTargetType(targetType) {
+		return nil, errors.New("invalid draft target type")
+	}
+	return s.draftRepo.GetDraftByTarget(userID, targetType, targetID)
+}
+
+func (s *draftService) ListDrafts(userID uint, filters map[string]interface{}) ([]models.Draft, int, error) {
+	return s.draftRepo.ListDrafts(userID, filters)
+}
+
+func (s *draftService) DeleteDraft(id, userID uint) error {
+	if _, err := s.GetDraft(id, userID); err != nil {
+		return err
+	}
+	return s.draftRepo.DeleteDraft(id)
+}
+
+// PublishDraft tạo mới hoặc cập nhật nội dung từ bản nháp rồi xoá bản nháp.
+// Trạng thái của nội dung mới do kiểm duyệt tự động quyết định như khi đăng trực tiếp.
+func (s *draftService) PublishDraft(id, userID uint) (*DraftPublishResult, error) {
+	draft, err := s.GetDraft(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagIDs []uint
+	if len(draft.TagIDs) > 0 {
+		if err := json.Unmarshal(draft.TagIDs, &tagIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	format := string(draft.ContentFormat)
+	result := &DraftPublishResult{}
+	switch draft.TargetType {
+	case models.DraftNewQuestion:
+		result.Question, err = s.questionService.CreateQuestion(draft.Title, draft.Content, userID, draft.TopicID, nil, format, false)
+	case models.DraftNewPost:
+		result.Post, err = s.postService.CreatePost(draft.Content, userID, draft.Title, tagIDs, nil, format)
+	case models.DraftAnswer:
+		result.Answer, err = s.answerService.CreateAnswer(draft.Content, userID, draft.TargetID, tagIDs, draft.Title, format, false)
+	case models.DraftEditQuestion:
+		var question *models.Question
+		if question, err = s.questionService.GetQuestionByID(draft.TargetID); err == nil {
+			if question.UserID != userID {
+				return nil, errors.New("only the author can edit this question")
+			}
+			result.Question, err = s.questionService.UpdateQuestion(draft.TargetID, draft.Title, draft.Content, draft.TopicID, format)
+		}
+	case models.DraftEditAnswer:
+		var answer *models.Answer
+		if answer, err = s.answerService.GetAnswerByID(draft.TargetID); err == nil {
+			if answer.UserID != userID {
+				return nil, errors.New("only the author can edit this answer")
+			}
+			result.Answer, err = s.answerService.UpdateAnswer(draft.TargetID, draft.Title, draft.Content, tagIDs, format)
+		}
+	case models.DraftEditPost:
+		var post *models.Post
+		if post, err = s.postService.GetPostByIDSimple(draft.TargetID); err == nil {
+			if post.UserID != userID {
+				return nil, errors.New("only the author can edit this post")
+			}
+			result.Post, err = s.postService.UpdatePost(draft.TargetID, draft.Title, draft.Content, tagIDs, format)
+		}
+	default:
+		return nil, errors.New("invalid draft target type")
+	}
+	if err != nil {
+		log.Printf("Failed to publish draft %d: %v", id, err)
+		return nil, err
+	}
+
+	if err := s.draftRepo.DeleteDraft(draft.ID); err != nil {
+		log.Printf("Failed to delete published draft %d: %v", draft.ID, err)
+	}
+	return result, nil
+}
+
+// PruneDrafts xoá các bản nháp không được cập nhật trong khoảng thời gian lưu giữ
+func (s *draftService) PruneDrafts() (int64, error) {
+	return s.draftRepo.DeleteDraftsBefore(time.Now().Add(-s.retention))
+}
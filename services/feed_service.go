@@ -0,0 +1,305 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"Forum_BE/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Số mục tối đa trong một feed
+const feedEntryLimit = 30
+
+// FeedService sinh feed Atom/RSS cho nội dung đã duyệt và quản lý feed token riêng của từng người dùng.
+// Link trong feed trỏ về giao diện web (FRONTEND_URL).
+type FeedService interface {
+	GetToken(userID uint) (*models.FeedToken, error)
+	RotateToken(userID uint) (*models.FeedToken, error)
+	Authenticate(token string) (uint, error)
+
+	QuestionsFeed(topicID uint) (*utils.Feed, error)
+	UserFeed(userID uint) (*utils.Feed, error)
+	TagFeed(tagID uint) (*utils.Feed, error)
+	AnswersFeed(questionID uint) (*utils.Feed, error)
+}
+
+type feedService struct {
+	feedRepo     repositories.FeedRepository
+	userRepo     repositories.UserRepository
+	topicRepo    repositories.TopicRepository
+	tagRepo      repositories.TagRepository
+	questionRepo repositories.QuestionRepository
+	siteURL      string
+}
+
+func NewFeedService(feedRepo repositories.FeedRepository, userRepo repositories.UserRepository, topicRepo repositories.TopicRepository, tagRepo repositories.TagRepository, questionRepo repositories.QuestionRepository) FeedService {
+	siteURL := strings.TrimRight(os.Getenv("FRONTEND_URL"), "/")
+	if siteURL == "" {
+		siteURL = "http://localhost:3000"
+	}
+	return &feedService{
+		feedRepo:     feedRepo,
+		userRepo:     userRepo,
+		topicRepo:    topicRepo,
+		tagRepo:      tagRepo,
+		questionRepo: questionRepo,
+		siteURL:      siteURL,
+	}
+}
+
+// GetToken trả về feed token hiện tại của người dùng, tạo mới nếu chưa có
+func (s *feedService) GetToken(userID uint) (*models.FeedToken, error) {
+	if token, err := s.feedRepo.GetTokenByUser(userID); err == nil {
+		return token, nil
+	}
+	return s.RotateToken(userID)
+}
+
+// RotateToken cấp token mới; mọi URL feed dùng token cũ sẽ không còn truy cập được
+func (s *feedService) RotateToken(userID uint) (*models.FeedToken, error) {
+	value, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.feedRepo.GetTokenByUser(userID)
+	if err != nil {
+		token = &models.FeedToken{UserID: userID}
+	}
+	token.Token = value
+	token.LastUsedAt = nil
+	if err := s.feedRepo.SaveToken(token); err != nil {
+		log.Printf("Failed to save feed token for user %d: %v", userID, err)
+		return nil, err
+	}
+	return token, nil
+}
+
+// Authenticate trả về người dùng sở hữu token; tài khoản bị khoá không đọc được feed
+func (s *feedService) Authenticate(value string) (uint, error) {
+	if value == "" {
+		return 0, errors.New("feed token is required")
+	}
+	token, err := s.feedRepo.GetTokenByValue(value)
+	if err != nil {
+		return 0, errors.New("invalid feed token")
+	}
+	user, err := s.userRepo.GetUserByID(token.UserID)
+	if err != nil || user.Status == models.StatusBanned {
+		return 0, errors.New("invalid feed token")
+	}
+	if err := s.feedRepo.TouchToken(token.ID, time.Now()); err != nil {
+		log.Printf("Failed to update feed token usage %d: %v", token.ID, err)
+	}
+	return user.ID, nil
+}
+
+// QuestionsFeed trả về câu hỏi mới nhất; topicID = 0 là toàn bộ diễn đàn
+func (s *feedService) QuestionsFeed(topicID uint) (*utils.Feed, error) {
+	feed := &utils.Feed{
+		ID:    s.siteURL + "/feeds/questions",
+		Title: "Câu hỏi mới nhất",
+		Link:  s.siteURL + "/questions",
+	}
+	if topicID != 0 {
+		topic, err := s.topicRepo.GetTopicByID(topicID)
+		if err != nil {
+			return nil, errors.New("topic not found")
+		}
+		feed.ID = fmt.Sprintf("%s/feeds/topics/%d", s.siteURL, topic.ID)
+		feed.Title = "Câu hỏi mới trong chủ đề " + topic.Name
+		feed.Subtitle = topic.Description
+		feed.Link = fmt.Sprintf("%s/topics/%d", s.siteURL, topic.ID)
+	}
+
+	questions, err := s.feedRepo.ListQuestions(topicID, 0, feedEntryLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range questions {
+		feed.Entries = append(feed.Entries, s.questionEntry(&questions[i]))
+	}
+	return finishFeed(feed), nil
+}
+
+// UserFeed trả về câu hỏi mới nhất của một người dùng (không gồm câu hỏi đăng ẩn danh)
+func (s *feedService) UserFeed(userID uint) (*utils.Feed, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	feed := &utils.Feed{
+		ID:    fmt.Sprintf("%s/feeds/users/%d", s.siteURL, user.ID),
+		Title: "Câu hỏi của " + displayName(user),
+		Link:  fmt.Sprintf("%s/users/%d", s.siteURL, user.ID),
+	}
+
+	questions, err := s.feedRepo.ListQuestions(0, user.ID, feedEntryLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range questions {
+		feed.Entries = append(feed.Entries, s.questionEntry(&questions[i]))
+	}
+	return finishFeed(feed), nil
+}
+
+// TagFeed trả về câu trả lời và bài viết mới nhất gắn thẻ (câu hỏi không có thẻ, chỉ có chủ đề)
+func (s *feedService) TagFeed(tagID uint) (*utils.Feed, error) {
+	tag, err := s.tagRepo.GetTagByID(tagID)
+	if err != nil {
+		return nil, errors.New("tag not found")
+	}
+	feed := &utils.Feed{
+		ID:       fmt.Sprintf("%s/feeds/tags/%d", s.siteURL, tag.ID),
+		Title:    "Nội dung mới với thẻ " + tag.Name,
+		Subtitle: tag.Description,
+		Link:     fmt.Sprintf("%s/tags/%d", s.siteURL, tag.ID),
+	}
+
+	answers, err := s.feedRepo.ListTaggedAnswers(tag.ID, feedEntryLimit)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.feedRepo.ListTaggedPosts(tag.ID, feedEntryLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range answers {
+		feed.Entries = append(feed.Entries, s.answerEntry(&answers[i]))
+	}
+	for i := range posts {
+		feed.Entries = append(feed.Entries, s.postEntry(&posts[i]))
+	}
+	sort.SliceStable(feed.Entries, func(i, j int) bool {
+		return feed.Entries[i].Published.After(feed.Entries[j].Published)
+	})
+	if len(feed.Entries) > feedEntryLimit {
+		feed.Entries = feed.Entries[:feedEntryLimit]
+	}
+	return finishFeed(feed), nil
+}
+
+// AnswersFeed trả về câu trả lời mới của một câu hỏi đã duyệt
+func (s *feedService) AnswersFeed(questionID uint) (*utils.Feed, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil || question.Status != models.StatusApproved || question.PublishAt != nil {
+		return nil, errors.New("question not found")
+	}
+	feed := &utils.Feed{
+		ID:    fmt.Sprintf("%s/feeds/questions/%d/answers", s.siteURL, question.ID),
+		Title: "Câu trả lời cho: " + question.Title,
+		Link:  fmt.Sprintf("%s/questions/%d", s.siteURL, question.ID),
+	}
+
+	answers, err := s.feedRepo.ListAnswers(question.ID, feedEntryLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range answers {
+		feed.Entries = append(feed.Entries, s.answerEntry(&answers[i]))
+	}
+	// Câu hỏi được sửa cũng làm feed thay đổi
+	if question.UpdatedAt.After(feed.Updated) {
+		feed.Updated = question.UpdatedAt
+	}
+	return finishFeed(feed), nil
+}
+
+func (s *feedService) questionEntry(question *models.Question) utils.FeedEntry {
+	link := fmt.Sprintf("%s/questions/%d", s.siteURL, question.ID)
+	entry := utils.FeedEntry{
+		ID:        link,
+		Title:     question.Title,
+		Link:      link,
+		Content:   utils.SanitizeHTML(question.Description),
+		Author:    authorName(&question.User, question.Anonymous, question.Pseudonym),
+		Published: question.CreatedAt,
+		Updated:   question.UpdatedAt,
+	}
+	if question.Topic.Name != "" {
+		entry.Categories = []string{question.Topic.Name}
+	}
+	return entry
+}
+
+func (s *feedService) answerEntry(answer *models.Answer) utils.FeedEntry {
+	link := fmt.Sprintf("%s/questions/%d#answer-%d", s.siteURL, answer.QuestionID, answer.ID)
+	title := answer.Title
+	if title == "" {
+		title = "Trả lời: " + answer.Question.Title
+	}
+	entry := utils.FeedEntry{
+		ID:        fmt.Sprintf("%s/answers/%d", s.siteURL, answer.ID),
+		Title:     title,
+		Link:      link,
+		Content:   utils.SanitizeHTML(answer.Content),
+		Author:    authorName(&answer.User, answer.Anonymous, answer.Pseudonym),
+		Published: answer.CreatedAt,
+		Updated:   answer.UpdatedAt,
+	}
+	for _, tag := range answer.Tags {
+		entry.Categories = append(entry.Categories, tag.Name)
+	}
+	return entry
+}
+
+func (s *feedService) postEntry(post *models.Post) utils.FeedEntry {
+	link := fmt.Sprintf("%s/posts/%d", s.siteURL, post.ID)
+	entry := utils.FeedEntry{
+		ID:        link,
+		Title:     post.Title,
+		Link:      link,
+		Content:   utils.SanitizeHTML(post.Content),
+		Author:    displayName(&post.User),
+		Published: post.CreatedAt,
+		Updated:   post.UpdatedAt,
+	}
+	for _, tag := range post.Tags {
+		entry.Categories = append(entry.Categories, tag.Name)
+	}
+	return entry
+}
+
+// finishFeed lấy thời điểm cập nhật của feed là lần sửa mới nhất trong các mục (dùng cho Last-Modified)
+func finishFeed(feed *utils.Feed) *utils.Feed {
+	for _, entry := range feed.Entries {
+		if entry.Updated.After(feed.Updated) {
+			feed.Updated = entry.Updated
+		}
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = time.Unix(0, 0)
+	}
+	return feed
+}
+
+func displayName(user *models.User) string {
+	if user.FullName != "" {
+		return user.FullName
+	}
+	return user.Username
+}
+
+// authorName trả về bí danh thay cho tên thật khi nội dung được đăng ẩn danh
+func authorName(user *models.User, anonymous bool, pseudonym string) string {
+	if anonymous {
+		return pseudonym
+	}
+	return displayName(user)
+}
+
+func newFeedToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
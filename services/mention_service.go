@@ -0,0 +1,116 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"Forum_BE/utils"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const maxAutocompleteLimit = 20
+
+// MentionService xử lý @username trong nội dung: lưu người được nhắc và chỉ thông báo cho người mới được nhắc
+type MentionService interface {
+	SyncMentions(targetType string, targetID, authorID uint, authorAlias, content, title string)
+	Autocomplete(prefix string, limit int) ([]models.User, error)
+	ListMentions(userID uint, filters map[string]interface{}) ([]models.Mention, int, error)
+}
+
+type mentionService struct {
+	mentionRepo repositories.MentionRepository
+	userRepo    repositories.UserRepository
+	novuClient  *notification.NovuClient
+}
+
+func NewMentionService(mentionRepo repositories.MentionRepository, userRepo repositories.UserRepository, novuClient *notification.NovuClient) MentionService {
+	return &mentionService{mentionRepo: mentionRepo, userRepo: userRepo, novuClient: novuClient}
+}
+
+// SyncMentions phân tích HTML đã lưu của nội dung và đồng bộ danh sách người được nhắc.
+// authorAlias là bí danh của tác giả khi nội dung ẩn danh (rỗng nếu không), được dùng thay tên thật trong thông báo.
+// Lỗi chỉ được ghi log để không làm hỏng thao tác tạo/sửa nội dung.
+func (s *mentionService) SyncMentions(targetType string, targetID, authorID uint, authorAlias, content, title string) {
+	var userIDs []uint
+	for _, username := range utils.ExtractMentions(content) {
+		user, err := s.userRepo.GetUserByUsername(username)
+		if err != nil {
+			if !errors.Is(err, repositories.ErrNotFound) {
+				log.Printf("Failed to resolve mention @%s: %v", username, err)
+			}
+			continue
+		}
+		if user.ID == authorID || user.Status == models.StatusBanned {
+			continue
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+
+	created, err := s.mentionRepo.SyncMentions(targetType, targetID, authorID, authorAlias, userIDs)
+	if err != nil {
+		log.Printf("Failed to sync mentions for %s %d: %v", targetType, targetID, err)
+		return
+	}
+	if len(created) == 0 {
+		return
+	}
+
+	authorName := authorAlias
+	if authorName == "" {
+		author, err := s.userRepo.GetUserByID(authorID)
+		if err != nil {
+			log.Printf("Không lấy được thông tin người nhắc: %v", err)
+			return
+		}
+		authorName = author.FullName
+	}
+	message := fmt.Sprintf("%s đã nhắc đến bạn trong %s: %s", authorName, mentionTargetLabel(targetType), title)
+	for _, userID := range created {
+		if err := s.novuClient.SendNotification(userID, "mention-notification", message); err != nil {
+			log.Printf("Gửi thông báo nhắc tên thất bại: %v", err)
+		}
+	}
+}
+
+func (s *mentionService) Autocomplete(prefix string, limit int) ([]models.User, error) {
+	prefix = strings.TrimPrefix(strings.TrimSpace(prefix), "@")
+	if prefix == "" {
+		return []models.User{}, nil
+	}
+	if limit <= 0 || limit > maxAutocompleteLimit {
+		limit = 10
+	}
+	return s.userRepo.SearchUsersByPrefix(prefix, limit)
+}
+
+func (s *mentionService) ListMentions(userID uint, filters map[string]interface{}) ([]models.Mention, int, error) {
+	return s.mentionRepo.ListMentionsForUser(userID, filters)
+}
+
+// mentionSummary dùng tiêu đề nếu có, ngược lại lấy đoạn đầu nội dung để hiển thị trong thông báo
+func mentionSummary(title, plain string) string {
+	if title != "" {
+		return title
+	}
+	runes := []rune(plain)
+	if len(runes) > 80 {
+		return string(runes[:80]) + "..."
+	}
+	return plain
+}
+
+func mentionTargetLabel(targetType string) string {
+	switch targetType {
+	case models.MentionTargetQuestion:
+		return "câu hỏi"
+	case models.MentionTargetAnswer:
+		return "câu trả lời"
+	case models.MentionTargetPost:
+		return "bài viết"
+	default:
+		return "bình luận"
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// Số mục tối đa trong một lần duyệt hàng loạt
+const maxModerationBatch = 100
+
+var (
+	ErrModerationNotPending = repositories.ErrModerationNotPending
+	ErrModerationBatchSize  = fmt.Errorf("a review batch must contain between 1 and %d items", maxModerationBatch)
+)
+
+// ModerationTarget là một mục người kiểm duyệt chọn để xử lý; Reason ghi đè lý do chung của cả lô
+type ModerationTarget struct {
+	ContentType string `json:"contentType" binding:"required"`
+	ContentID   uint   `json:"contentId" binding:"required"`
+	Reason      string `json:"reason"`
+}
+
+// ModerationResult là kết quả xử lý từng mục trong một lô; Error rỗng nghĩa là thành công
+type ModerationResult struct {
+	ContentType string `json:"contentType"`
+	ContentID   uint   `json:"contentId"`
+	Status      string `json:"status,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type ModerationService interface {
+	ListQueue(filters map[string]interface{}) ([]models.ModerationItem, int, error)
+	Claim(moderatorID uint, contentType string, contentID uint) (*models.ModerationClaim, error)
+	Release(moderatorID uint, contentType string, contentID uint) error
+	Review(moderatorID uint, action models.ModerationAction, targets []ModerationTarget, reason string) ([]ModerationResult, error)
+	Stats(filters map[string]interface{}) ([]models.ModerationStat, error)
+}
+
+type moderationService struct {
+	moderationRepo  repositories.ModerationRepository
+	questionService QuestionService
+	answerService   AnswerService
+	postService     PostService
+	commentService  CommentService
+	novuClient      *notification.NovuClient
+}
+
+func NewModerationService(repo repositories.ModerationRepository, qService QuestionService, aService AnswerService, pService PostService, cService CommentService, novuClient *notification.NovuClient) ModerationService {
+	return &moderationService{
+		moderationRepo:  repo,
+		questionService: qService,
+		answerService:   aService,
+		postService:     pService,
+		commentService:  cService,
+		novuClient:      novuClient,
+	}
+}
+
+// moderationClaimTTL là thời gian một người kiểm duyệt được giữ mục (MODERATION_CLAIM_MINUTES, mặc định 15 phút)
+func moderationClaimTTL() time.Duration {
+	return time.Duration(envThreshold("MODERATION_CLAIM_MINUTES", 15)) * time.Minute
+}
+
+func (s *moderationService) ListQueue(filters map[string]interface{}) ([]models.ModerationItem, int, error) {
+	return s.moderationRepo.ListQueue(filters)
+}
+
+func (s *moderationService) Claim(moderatorID uint, contentType string, contentID uint) (*models.ModerationClaim, error) {
+	if !models.IsValidModerationContentType(contentType) {
+		return nil, errors.New("invalid content type")
+	}
+	item, err := s.moderationRepo.GetQueueItem(contentType, contentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("content not found")
+		}
+		return nil, err
+	}
+	if !isAwaitingModeration(item.Status) {
+		return nil, ErrModerationNotPending
+	}
+
+	claim := &models.ModerationClaim{
+		ContentType: contentType,
+		ContentID:   contentID,
+		ModeratorID: moderatorID,
+		ExpiresAt:   time.Now().Add(moderationClaimTTL()),
+	}
+	if err := s.moderationRepo.Claim(claim); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+func (s *moderationService) Release(moderatorID uint, contentType string, contentID uint) error {
+	if err := s.moderationRepo.ReleaseClaim(contentType, contentID, moderatorID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("claim not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// Review duyệt hoặc từ chối từng mục qua service của loại nội dung đó để giữ nguyên việc xoá cache,
+// cập nhật chỉ mục tìm kiếm và thông báo cho tác giả. Mục lỗi không làm dừng cả lô.
+func (s *moderationService) Review(moderatorID uint, action models.ModerationAction, targets []ModerationTarget, reason string) ([]ModerationResult, error) {
+	if action != models.ModerationApprove && action != models.ModerationReject {
+		return nil, errors.New("invalid action")
+	}
+	if len(targets) == 0 || len(targets) > maxModerationBatch {
+		return nil, ErrModerationBatchSize
+	}
+
+	results := make([]ModerationResult, 0, len(targets))
+	for _, target := range targets {
+		result := ModerationResult{ContentType: target.ContentType, ContentID: target.ContentID}
+		itemReason := target.Reason
+		if itemReason == "" {
+			itemReason = reason
+		}
+		status, err := s.reviewOne(moderatorID, action, target.ContentType, target.ContentID, itemReason)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Status = status
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *moderationService) reviewOne(moderatorID uint, action models.ModerationAction, contentType string, contentID uint, reason string) (string, error) {
+	if !models.IsValidModerationContentType(contentType) {
+		return "", errors.New("invalid content type")
+	}
+	if len([]rune(reason)) > 500 {
+		return "", errors.New("reason must not exceed 500 characters")
+	}
+	item, err := s.moderationRepo.GetQueueItem(contentType, contentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("content not found")
+		}
+		return "", err
+	}
+	if !isAwaitingModeration(item.Status) {
+		return "", ErrModerationNotPending
+	}
+	if item.ClaimedBy != nil && *item.ClaimedBy != moderatorID {
+		return "", repositories.ErrModerationClaimed
+	}
+
+	// Kiểm tra ở trên chỉ để trả lỗi sớm; quyết định thật sự được chốt dưới khoá dòng trong ReviewItem
+	status := moderationStatus(contentType, action)
+	if err := s.moderationRepo.ReviewItem(&models.ModerationLog{
+		ModeratorID: moderatorID,
+		ContentType: contentType,
+		ContentID:   contentID,
+		Action:      action,
+		ToStatus:    status,
+		Reason:      reason,
+	}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("content not found")
+		}
+		return "", err
+	}
+
+	// Chỉ người thắng mới tới đây; trạng thái đã được ghi nên service của loại nội dung chỉ còn xoá cache,
+	// cập nhật chỉ mục tìm kiếm, nhắc tên và thông báo cho tác giả
+	if err := s.afterReview(contentType, contentID, status); err != nil {
+		log.Printf("Failed to apply moderation side effects for %s %d: %v", contentType, contentID, err)
+	}
+
+	if action == models.ModerationReject && reason != "" {
+		message := fmt.Sprintf("%s của bạn đã bị từ chối. Lý do: %s", moderationLabel(contentType), reason)
+		if err := s.novuClient.SendNotification(item.UserID, "moderation-reason-notification", message); err != nil {
+			log.Printf("Gửi thông báo moderation-reason-notification thất bại: %v", err)
+		}
+	}
+	return status, nil
+}
+
+// afterReview chạy phần việc sau duyệt qua đúng service của loại nội dung
+func (s *moderationService) afterReview(contentType string, contentID uint, status string) error {
+	var err error
+	switch contentType {
+	case models.ModerationQuestion:
+		_, err = s.questionService.ApplyReviewedStatus(contentID, status)
+	case models.ModerationAnswer:
+		_, err = s.answerService.ApplyReviewedStatus(contentID, status)
+	case models.ModerationPost:
+		_, err = s.postService.ApplyReviewedStatus(contentID, status)
+	case models.ModerationComment:
+		_, err = s.commentService.ApplyReviewedStatus(contentID, status)
+	}
+	return err
+}
+
+func (s *moderationService) Stats(filters map[string]interface{}) ([]models.ModerationStat, error) {
+	return s.moderationRepo.Stats(filters)
+}
+
+func isAwaitingModeration(status string) bool {
+	return status == "pending" || status == "spam"
+}
+
+// moderationStatus trả về trạng thái đích; bình luận không có trạng thái "rejected" nên bị đánh dấu spam
+func moderationStatus(contentType string, action models.ModerationAction) string {
+	if action == models.ModerationApprove {
+		return "approved"
+	}
+	if contentType == models.ModerationComment {
+		return "spam"
+	}
+	return "rejected"
+}
+
+func moderationLabel(contentType string) string {
+	switch contentType {
+	case models.ModerationQuestion:
+		return "Câu hỏi"
+	case models.ModerationAnswer:
+		return "Câu trả lời"
+	case models.ModerationPost:
+		return "Bài viết"
+	default:
+		return "Bình luận"
+	}
+}
@@ -1,62 +1,109 @@
 package services
 
 import (
+	"Forum_BE/models"
 	"Forum_BE/repositories"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"log"
 	"time"
 )
 
+const (
+	// Câu hỏi bị bỏ qua lâu hơn ngần này mà vẫn chưa có câu trả lời sẽ được đưa trở lại feed
+	passResurfaceAfter = 30 * 24 * time.Hour
+	// Thời gian hoãn tối đa
+	maxSnoozeDuration = 365 * 24 * time.Hour
+)
+
+// PassItem là một lần bỏ qua kèm câu hỏi tương ứng (nil nếu câu hỏi đã bị xoá)
+type PassItem struct {
+	Pass     models.PassedQuestion
+	Question *models.Question
+}
+
 type PassService interface {
-	PassQuestion(userID, questionID uint) error
+	PassQuestion(userID, questionID uint, kind models.PassKind) (*models.PassedQuestion, error)
+	SnoozeQuestion(userID, questionID uint, until time.Time) (*models.PassedQuestion, error)
+	UndoPass(userID, questionID uint) error
 	IsQuestionPassed(userID, questionID uint) (bool, error)
 	GetPassedIDs(userID uint) ([]uint, error)
+	ListPasses(userID uint, filters map[string]interface{}) ([]PassItem, int, error)
+	ResurfaceQuestion(questionID uint, reason string) (int, error)
+	ResurfaceStale() (int, error)
 }
 
 type passService struct {
-	repo        repositories.PassRepository
-	redisClient *redis.Client
+	repo         repositories.PassRepository
+	questionRepo repositories.QuestionRepository
+	redisClient  *redis.Client
 }
 
-func NewPassService(r repositories.PassRepository, redisClient *redis.Client) PassService {
-	return &passService{repo: r, redisClient: redisClient}
+func NewPassService(r repositories.PassRepository, questionRepo repositories.QuestionRepository, redisClient *redis.Client) PassService {
+	return &passService{repo: r, questionRepo: questionRepo, redisClient: redisClient}
 }
 
-func (s *passService) PassQuestion(userID, questionID uint) error {
-	// Kiểm tra xem câu hỏi đã được bỏ qua chưa
-	isPassed, err := s.IsQuestionPassed(userID, questionID)
-	if err != nil {
-		log.Printf("Failed to check if question %d is passed for user %d: %v", questionID, userID, err)
-		return err
+// PassQuestion ẩn câu hỏi khỏi feed của người dùng. Bỏ qua lại một câu hỏi sẽ thay lần bỏ qua cũ
+// (ví dụ đổi từ "pass" sang "not_expertise") thay vì báo lỗi.
+func (s *passService) PassQuestion(userID, questionID uint, kind models.PassKind) (*models.PassedQuestion, error) {
+	if kind == "" {
+		kind = models.PassKindPass
 	}
-	if isPassed {
-		log.Printf("Question %d already passed by user %d", questionID, userID)
-		return fmt.Errorf("question already passed")
+	if kind == models.PassKindSnooze || !models.IsValidPassKind(kind) {
+		return nil, errors.New("invalid pass kind")
 	}
+	return s.savePass(userID, questionID, kind, nil)
+}
 
-	err = s.repo.PassQuestion(userID, questionID)
+// SnoozeQuestion ẩn câu hỏi đến thời điểm until, sau đó câu hỏi tự xuất hiện lại trong feed
+func (s *passService) SnoozeQuestion(userID, questionID uint, until time.Time) (*models.PassedQuestion, error) {
+	now := time.Now()
+	if !until.After(now) {
+		return nil, errors.New("snooze time must be in the future")
+	}
+	if until.Sub(now) > maxSnoozeDuration {
+		return nil, errors.New("snooze time is too far in the future")
+	}
+	return s.savePass(userID, questionID, models.PassKindSnooze, &until)
+}
+
+func (s *passService) savePass(userID, questionID uint, kind models.PassKind, until *time.Time) (*models.PassedQuestion, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
 	if err != nil {
-		log.Printf("Failed to pass question %d for user %d: %v", questionID, userID, err)
-		return err
+		return nil, errors.New("question not found")
 	}
 
-	// Cập nhật cache
-	for attempt := 1; attempt <= 3; attempt++ {
-		if err := s.updateCacheAfterPass(userID, questionID); err == nil {
-			log.Printf("Cache updated for passed:user:%d", userID)
-			break
-		}
-		log.Printf("Failed to update cache for user %d, question %d (attempt %d): %v", userID, questionID, attempt, err)
-		time.Sleep(100 * time.Millisecond)
+	pass := &models.PassedQuestion{
+		UserID:      userID,
+		QuestionID:  questionID,
+		Kind:        kind,
+		SnoozeUntil: until,
+		TopicID:     question.TopicID,
+	}
+	if err := s.repo.PassQuestion(pass); err != nil {
+		log.Printf("Failed to pass question %d for user %d: %v", questionID, userID, err)
+		return nil, err
 	}
 
-	// Xóa cache
-	s.invalidateCache(fmt.Sprintf("passed:user:%d", userID))
-	s.invalidateCache("questions:*")
+	s.invalidateUserCache(userID)
+	return pass, nil
+}
+
+// UndoPass huỷ bỏ qua/hoãn, câu hỏi xuất hiện lại ngay
+func (s *passService) UndoPass(userID, questionID uint) error {
+	affected, err := s.repo.UndoPass(userID, questionID)
+	if err != nil {
+		log.Printf("Failed to undo pass of question %d for user %d: %v", questionID, userID, err)
+		return err
+	}
+	if affected == 0 {
+		return errors.New("pass not found")
+	}
 
+	s.invalidateUserCache(userID)
 	return nil
 }
 
@@ -68,6 +115,7 @@ func (s *passService) IsQuestionPassed(userID, questionID uint) (bool, error) {
 	return isPassed, err
 }
 
+// GetPassedIDs trả về các câu hỏi đang bị ẩn (không gồm câu hỏi đã hết hạn hoãn hoặc đã được đưa trở lại)
 func (s *passService) GetPassedIDs(userID uint) ([]uint, error) {
 	cacheKey := fmt.Sprintf("passed:user:%d", userID)
 	ctx := context.Background()
@@ -106,38 +154,69 @@ func (s *passService) GetPassedIDs(userID uint) ([]uint, error) {
 	return passedIDs, nil
 }
 
-func (s *passService) updateCacheAfterPass(userID, questionID uint) error {
-	cacheKey := fmt.Sprintf("passed:user:%d", userID)
-	ctx := context.Background()
+func (s *passService) ListPasses(userID uint, filters map[string]interface{}) ([]PassItem, int, error) {
+	if kind, ok := filters["kind"].(string); ok && kind != "" && !models.IsValidPassKind(models.PassKind(kind)) {
+		return nil, 0, errors.New("invalid pass kind")
+	}
+	passes, total, err := s.repo.ListPasses(userID, filters)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	var passedIDs []uint
-	cached, err := s.redisClient.Get(ctx, cacheKey).Result()
-	if err == nil {
-		if err := json.Unmarshal([]byte(cached), &passedIDs); err != nil {
-			log.Printf("Failed to unmarshal cache for user %d: %v", userID, err)
-			passedIDs = []uint{questionID}
-		} else {
-			passedIDs = append([]uint{questionID}, passedIDs...)
-		}
-	} else if err == redis.Nil {
-		passedIDs = []uint{questionID}
-	} else {
-		log.Printf("Redis error for user %d: %v", userID, err)
-		return err
+	ids := make([]uint, 0, len(passes))
+	for _, pass := range passes {
+		ids = append(ids, pass.QuestionID)
+	}
+	questions, err := s.repo.GetQuestionsByIDs(ids)
+	if err != nil {
+		log.Printf("Failed to load passed questions for user %d: %v", userID, err)
+		return nil, 0, err
+	}
+	byID := make(map[uint]*models.Question, len(questions))
+	for i := range questions {
+		byID[questions[i].ID] = &questions[i]
 	}
 
-	data, err := json.Marshal(passedIDs)
+	items := make([]PassItem, 0, len(passes))
+	for _, pass := range passes {
+		items = append(items, PassItem{Pass: pass, Question: byID[pass.QuestionID]})
+	}
+	return items, total, nil
+}
+
+// ResurfaceQuestion đưa câu hỏi trở lại feed của những người đã ẩn nó,
+// dùng khi câu hỏi có thay đổi đáng chú ý (ví dụ được treo thưởng - models.ResurfaceBounty).
+// Hiện chưa có tính năng treo thưởng; nơi tạo thưởng sau này chỉ cần gọi hàm này với lý do tương ứng.
+func (s *passService) ResurfaceQuestion(questionID uint, reason string) (int, error) {
+	affected, err := s.repo.ResurfaceQuestion(questionID, reason, time.Now())
 	if err != nil {
-		log.Printf("Failed to marshal cache for user %d: %v", userID, err)
-		return err
+		log.Printf("Failed to resurface question %d: %v", questionID, err)
+		return 0, err
 	}
+	if affected > 0 {
+		s.invalidateCache("passed:user:*")
+		s.invalidateCache("questions:*")
+	}
+	return int(affected), nil
+}
 
-	if err := s.redisClient.Set(ctx, cacheKey, data, 2*time.Minute).Err(); err != nil {
-		log.Printf("Failed to set cache for user %d: %v", userID, err)
-		return err
+// ResurfaceStale đưa trở lại các câu hỏi đã bị bỏ qua quá passResurfaceAfter mà vẫn chưa có ai trả lời
+func (s *passService) ResurfaceStale() (int, error) {
+	now := time.Now()
+	affected, err := s.repo.ResurfaceUnanswered(now.Add(-passResurfaceAfter), now)
+	if err != nil {
+		return 0, err
+	}
+	if affected > 0 {
+		s.invalidateCache("passed:user:*")
+		s.invalidateCache("questions:*")
 	}
+	return int(affected), nil
+}
 
-	return nil
+func (s *passService) invalidateUserCache(userID uint) {
+	s.invalidateCache(fmt.Sprintf("passed:user:%d", userID))
+	s.invalidateCache("questions:*")
 }
 
 func (s *passService) invalidateCache(pattern string) {
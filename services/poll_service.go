@@ -0,0 +1,404 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minPollOptions = 2
+	maxPollOptions = 20
+	// Thời gian giữ bộ đếm kết quả trong Redis; hết hạn sẽ được dựng lại từ DB
+	pollCountsTTL = 10 * time.Minute
+	// Trường trong hash Redis lưu tổng số người đã bầu
+	pollVotersField = "voters"
+)
+
+type PollInput struct {
+	Question               string
+	Options                []string
+	MultipleChoice         bool
+	MaxChoices             int
+	Anonymous              bool
+	HideResultsUntilClosed bool
+	ClosesAt               *time.Time
+}
+
+// PollResult là bình chọn kèm kết quả theo góc nhìn của người xem.
+// Khi ResultsHidden = true thì Counts rỗng và TotalVoters = 0.
+type PollResult struct {
+	Poll          *models.Poll
+	Closed        bool
+	ResultsHidden bool
+	Counts        map[uint]int
+	TotalVoters   int
+	MyChoices     []uint
+}
+
+type PollService interface {
+	CreatePoll(postID, userID uint, input PollInput) (*PollResult, error)
+	GetPoll(pollID, viewerID uint) (*PollResult, error)
+	GetPollByPost(postID, viewerID uint) (*PollResult, error)
+	Vote(pollID, userID uint, optionIDs []uint) (*PollResult, error)
+	RetractVote(pollID, userID uint) (*PollResult, error)
+	ClosePoll(pollID, userID uint, canManage bool) (*PollResult, error)
+	DeletePoll(pollID, userID uint, canManage bool) error
+	ListVoters(pollID, viewerID uint, filters map[string]interface{}) ([]models.PollBallot, int, error)
+}
+
+type pollService struct {
+	pollRepo    repositories.PollRepository
+	postRepo    repositories.PostRepository
+	redisClient *redis.Client
+}
+
+func NewPollService(pollRepo repositories.PollRepository, postRepo repositories.PostRepository, redisClient *redis.Client) PollService {
+	return &pollService{pollRepo: pollRepo, postRepo: postRepo, redisClient: redisClient}
+}
+
+func (s *pollService) CreatePoll(postID, userID uint, input PollInput) (*PollResult, error) {
+	post, err := s.postRepo.GetPostByIDSimple(postID)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+	if post.UserID != userID {
+		return nil, errors.New("only the author of the post can attach a poll")
+	}
+	if _, err := s.pollRepo.GetPollByPostID(postID); err == nil {
+		return nil, errors.New("post already has a poll")
+	}
+
+	question := strings.TrimSpace(input.Question)
+	if question == "" {
+		return nil, errors.New("poll question is required")
+	}
+	options := make([]models.PollOption, 0, len(input.Options))
+	seen := make(map[string]bool)
+	for _, text := range input.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		key := strings.ToLower(text)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate poll option: %s", text)
+		}
+		seen[key] = true
+		options = append(options, models.PollOption{Text: text, Position: len(options)})
+	}
+	if len(options) < minPollOptions || len(options) > maxPollOptions {
+		return nil, fmt.Errorf("a poll needs between %d and %d options", minPollOptions, maxPollOptions)
+	}
+	if input.ClosesAt != nil && !input.ClosesAt.After(time.Now()) {
+		return nil, errors.New("close time must be in the future")
+	}
+
+	maxChoices := 1
+	if input.MultipleChoice {
+		if input.MaxChoices < 0 || input.MaxChoices > len(options) {
+			return nil, errors.New("invalid maximum number of choices")
+		}
+		maxChoices = input.MaxChoices
+	}
+
+	poll := &models.Poll{
+		PostID:                 postID,
+		Question:               question,
+		MultipleChoice:         input.MultipleChoice,
+		MaxChoices:             maxChoices,
+		Anonymous:              input.Anonymous,
+		HideResultsUntilClosed: input.HideResultsUntilClosed,
+		ClosesAt:               input.ClosesAt,
+		Options:                options,
+	}
+	if err := s.pollRepo.CreatePoll(poll); err != nil {
+		log.Printf("Failed to create poll for post %d: %v", postID, err)
+		return nil, err
+	}
+	return s.GetPoll(poll.ID, userID)
+}
+
+func (s *pollService) GetPoll(pollID, viewerID uint) (*PollResult, error) {
+	poll, err := s.pollRepo.GetPollByID(pollID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+	return s.buildResult(poll, viewerID)
+}
+
+func (s *pollService) GetPollByPost(postID, viewerID uint) (*PollResult, error) {
+	poll, err := s.pollRepo.GetPollByPostID(postID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+	return s.buildResult(poll, viewerID)
+}
+
+func (s *pollService) Vote(pollID, userID uint, optionIDs []uint) (*PollResult, error) {
+	poll, err := s.pollRepo.GetPollByID(pollID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+	if poll.IsClosed(time.Now()) {
+		return nil, errors.New("poll is closed")
+	}
+	post, err := s.postRepo.GetPostByIDSimple(poll.PostID)
+	if err != nil || post.Status != models.Approved || post.PublishAt != nil {
+		return nil, errors.New("cannot vote on a post that is not published")
+	}
+
+	valid := make(map[uint]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		valid[option.ID] = true
+	}
+	chosen := make([]uint, 0, len(optionIDs))
+	seen := make(map[uint]bool)
+	for _, id := range optionIDs {
+		if !valid[id] {
+			return nil, fmt.Errorf("option %d does not belong to this poll", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			chosen = append(chosen, id)
+		}
+	}
+	if len(chosen) == 0 {
+		return nil, errors.New("at least one option must be chosen")
+	}
+	if !poll.MultipleChoice && len(chosen) > 1 {
+		return nil, errors.New("this poll allows only one choice")
+	}
+	if poll.MaxChoices > 0 && len(chosen) > poll.MaxChoices {
+		return nil, fmt.Errorf("this poll allows at most %d choices", poll.MaxChoices)
+	}
+
+	if _, err := s.pollRepo.GetBallot(pollID, userID); err == nil {
+		return nil, errors.New("you have already voted in this poll")
+	}
+	ballot := &models.PollBallot{PollID: pollID, UserID: userID}
+	if err := s.pollRepo.CreateBallot(ballot, chosen); err != nil {
+		// Unique index (poll_id, user_id) chặn trường hợp bầu đồng thời hai lần
+		log.Printf("Failed to record ballot for poll %d by user %d: %v", pollID, userID, err)
+		return nil, errors.New("could not record your vote")
+	}
+
+	s.adjustCounts(pollID, chosen, 1)
+	return s.buildResult(poll, userID)
+}
+
+// RetractVote rút lá phiếu khi bình chọn còn mở để người dùng có thể bầu lại
+func (s *pollService) RetractVote(pollID, userID uint) (*PollResult, error) {
+	poll, err := s.pollRepo.GetPollByID(pollID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+	if poll.IsClosed(time.Now()) {
+		return nil, errors.New("poll is closed")
+	}
+	ballot, err := s.pollRepo.GetBallot(pollID, userID)
+	if err != nil {
+		return nil, errors.New("you have not voted in this poll")
+	}
+	if err := s.pollRepo.DeleteBallot(ballot); err != nil {
+		log.Printf("Failed to retract ballot %d: %v", ballot.ID, err)
+		return nil, err
+	}
+
+	optionIDs := make([]uint, 0, len(ballot.Choices))
+	for _, choice := range ballot.Choices {
+		optionIDs = append(optionIDs, choice.OptionID)
+	}
+	s.adjustCounts(pollID, optionIDs, -1)
+	return s.buildResult(poll, userID)
+}
+
+func (s *pollService) ClosePoll(pollID, userID uint, canManage bool) (*PollResult, error) {
+	poll, err := s.pollRepo.GetPollByID(pollID)
+	if err != nil {
+		return nil, errors.New("poll not found")
+	}
+	if err := s.checkOwner(poll, userID, canManage); err != nil {
+		return nil, err
+	}
+	if poll.IsClosed(time.Now()) {
+		return nil, errors.New("poll is already closed")
+	}
+
+	now := time.Now()
+	poll.ClosedAt = &now
+	if err := s.pollRepo.ClosePoll(poll); err != nil {
+		log.Printf("Failed to close poll %d: %v", pollID, err)
+		return nil, err
+	}
+	return s.buildResult(poll, userID)
+}
+
+func (s *pollService) DeletePoll(pollID, userID uint, canManage bool) error {
+	poll, err := s.pollRepo.GetPollByID(pollID)
+	if err != nil {
+		return errors.New("poll not found")
+	}
+	if err := s.checkOwner(poll, userID, canManage); err != nil {
+		return err
+	}
+	if err := s.pollRepo.DeletePoll(pollID); err != nil {
+		log.Printf("Failed to delete poll %d: %v", pollID, err)
+		return err
+	}
+	if err := s.redisClient.Del(context.Background(), pollCountsKey(pollID)).Err(); err != nil {
+		log.Printf("Failed to delete poll counts cache %d: %v", pollID, err)
+	}
+	return nil
+}
+
+// ListVoters liệt kê ai đã bầu gì; không khả dụng với bình chọn ẩn danh hoặc khi kết quả đang bị ẩn
+func (s *pollService) ListVoters(pollID, viewerID uint, filters map[string]interface{}) ([]models.PollBallot, int, error) {
+	poll, err := s.pollRepo.GetPollByID(pollID)
+	if err != nil {
+		return nil, 0, errors.New("poll not found")
+	}
+	if poll.Anonymous {
+		return nil, 0, errors.New("voters of an anonymous poll are not visible")
+	}
+	hidden, err := s.resultsHidden(poll, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if hidden {
+		return nil, 0, errors.New("results are hidden until the poll closes")
+	}
+	return s.pollRepo.ListBallots(pollID, filters)
+}
+
+func (s *pollService) checkOwner(poll *models.Poll, userID uint, canManage bool) error {
+	if canManage {
+		return nil
+	}
+	post, err := s.postRepo.GetPostByIDSimple(poll.PostID)
+	if err != nil {
+		return errors.New("post not found")
+	}
+	if post.UserID != userID {
+		return errors.New("only the author of the post can manage this poll")
+	}
+	return nil
+}
+
+// resultsHidden: tác giả bài viết luôn xem được kết quả, người khác phải chờ bình chọn đóng nếu tác giả chọn ẩn
+func (s *pollService) resultsHidden(poll *models.Poll, viewerID uint) (bool, error) {
+	if !poll.HideResultsUntilClosed || poll.IsClosed(time.Now()) {
+		return false, nil
+	}
+	post, err := s.postRepo.GetPostByIDSimple(poll.PostID)
+	if err != nil {
+		return false, errors.New("post not found")
+	}
+	return post.UserID != viewerID, nil
+}
+
+func (s *pollService) buildResult(poll *models.Poll, viewerID uint) (*PollResult, error) {
+	result := &PollResult{
+		Poll:   poll,
+		Closed: poll.IsClosed(time.Now()),
+		Counts: make(map[uint]int),
+	}
+
+	if ballot, err := s.pollRepo.GetBallot(poll.ID, viewerID); err == nil {
+		for _, choice := range ballot.Choices {
+			result.MyChoices = append(result.MyChoices, choice.OptionID)
+		}
+	}
+
+	hidden, err := s.resultsHidden(poll, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if hidden {
+		result.ResultsHidden = true
+		return result, nil
+	}
+
+	counts, voters, err := s.loadCounts(poll.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.Counts = counts
+	result.TotalVoters = voters
+	return result, nil
+}
+
+func pollCountsKey(pollID uint) string {
+	return fmt.Sprintf("poll:%d:counts", pollID)
+}
+
+// loadCounts đọc bộ đếm từ Redis; nếu chưa có thì đếm lại từ DB và lưu vào cache
+func (s *pollService) loadCounts(pollID uint) (map[uint]int, int, error) {
+	ctx := context.Background()
+	key := pollCountsKey(pollID)
+
+	cached, err := s.redisClient.HGetAll(ctx, key).Result()
+	if err == nil && len(cached) > 0 {
+		counts := make(map[uint]int, len(cached))
+		voters := 0
+		for field, value := range cached {
+			n, _ := strconv.Atoi(value)
+			if field == pollVotersField {
+				voters = n
+				continue
+			}
+			if optionID, err := strconv.ParseUint(field, 10, 64); err == nil {
+				counts[uint(optionID)] = n
+			}
+		}
+		return counts, voters, nil
+	}
+
+	rows, voters, err := s.pollRepo.CountVotes(pollID)
+	if err != nil {
+		log.Printf("Failed to count votes for poll %d: %v", pollID, err)
+		return nil, 0, err
+	}
+	counts := make(map[uint]int, len(rows))
+	fields := map[string]interface{}{pollVotersField: voters}
+	for _, row := range rows {
+		counts[row.OptionID] = row.Count
+		fields[strconv.FormatUint(uint64(row.OptionID), 10)] = row.Count
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.HSet(ctx, key, fields)
+	pipe.Expire(ctx, key, pollCountsTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to cache poll counts %d: %v", pollID, err)
+	}
+	return counts, voters, nil
+}
+
+// adjustCounts cập nhật trực tiếp bộ đếm trong Redis khi đã có cache; nếu chưa có thì lần đọc sau sẽ đếm lại từ DB
+func (s *pollService) adjustCounts(pollID uint, optionIDs []uint, delta int64) {
+	ctx := context.Background()
+	key := pollCountsKey(pollID)
+
+	exists, err := s.redisClient.Exists(ctx, key).Result()
+	if err != nil || exists == 0 {
+		return
+	}
+	pipe := s.redisClient.TxPipeline()
+	for _, optionID := range optionIDs {
+		pipe.HIncrBy(ctx, key, strconv.FormatUint(uint64(optionID), 10), delta)
+	}
+	pipe.HIncrBy(ctx, key, pollVotersField, delta)
+	pipe.Expire(ctx, key, pollCountsTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to update poll counts %d, dropping cache: %v", pollID, err)
+		s.redisClient.Del(ctx, key)
+	}
+}
@@ -11,18 +11,25 @@ import (
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"log"
+	"strconv"
 	"time"
 )
 
 type PostService interface {
-	CreatePost(content string, userID uint, title string, tagId []uint, status models.PostStatus) (*models.Post, error)
+	CreatePost(content string, userID uint, title string, tagId []uint, publishAt *time.Time, contentFormat string) (*models.Post, error)
 	GetPostByID(id uint) (*models.Post, error)
 	GetPostByIDSimple(id uint) (*models.Post, error)
+	ResolvePostID(ref string) (uint, error)
 	DeletePost(id uint) error
-	UpdatePost(id uint, title, content string, status models.PostStatus, tagId []uint) (*models.Post, error)
+	UpdatePost(id uint, title, content string, tagId []uint, contentFormat string) (*models.Post, error)
 	UpdatePostStatus(id uint, status string) (*models.Post, error)
+	ApplyReviewedStatus(id uint, status string) (*models.Post, error)
 	ListPosts(filters map[string]interface{}) ([]models.Post, int, error)
 	GetAllPosts(filters map[string]interface{}) ([]models.Post, int, error)
+	ListScheduledPosts(userID uint) ([]models.Post, error)
+	ReschedulePost(id, userID uint, publishAt time.Time) (*models.Post, error)
+	CancelScheduledPost(id, userID uint) error
+	PublishDuePosts() (int, error)
 }
 
 type postService struct {
@@ -30,32 +37,51 @@ type postService struct {
 	redisClient *redis.Client
 	userRepo    repositories.UserRepository
 	novuClient  *notification.NovuClient
+	searchIndex SearchIndexService
+	mentions    MentionService
+	autoMod     AutoModerationService
 }
 
-func NewPostService(postRepo repositories.PostRepository, redisClient *redis.Client, userRepo repositories.UserRepository, novuClient *notification.NovuClient) PostService {
-	return &postService{postRepo: postRepo, redisClient: redisClient, userRepo: userRepo, novuClient: novuClient}
+func NewPostService(postRepo repositories.PostRepository, redisClient *redis.Client, userRepo repositories.UserRepository, novuClient *notification.NovuClient, searchIndex SearchIndexService, mentions MentionService, autoMod AutoModerationService) PostService {
+	return &postService{postRepo: postRepo, redisClient: redisClient, userRepo: userRepo, novuClient: novuClient, searchIndex: searchIndex, mentions: mentions, autoMod: autoMod}
 }
 
-func (s *postService) CreatePost(content string, userID uint, title string, tagId []uint, status models.PostStatus) (*models.Post, error) {
+func (s *postService) CreatePost(content string, userID uint, title string, tagId []uint, publishAt *time.Time, contentFormat string) (*models.Post, error) {
 	if content == "" {
 		return nil, errors.New("content is required")
 	}
+	format, err := resolveContentFormat(contentFormat, "")
+	if err != nil {
+		return nil, err
+	}
+	// Thời điểm đã qua được coi như đăng ngay
+	if publishAt != nil && !publishAt.After(time.Now()) {
+		publishAt = nil
+	}
 
 	post := &models.Post{
-		Content: content,
-		Title:   title,
-		UserID:  userID,
-		Status:  models.PostStatus(status),
+		ContentFormat: format,
+		Title:         title,
+		UserID:        userID,
+		PublishAt:     publishAt,
 	}
+	applyContentBody(format, content, &post.Content, &post.ContentSource, &post.PlainContent)
+
+	subject := AutoModerationSubject{ContentType: models.ModerationPost, UserID: userID, Event: models.AutoModerationEventCreate, Title: title, Body: post.Content}
+	verdict := s.autoMod.Evaluate(subject)
+	post.Status = models.PostStatus(verdict.Status)
 
 	if err := s.postRepo.CreatePost(post, tagId); err != nil {
 		log.Printf("Failed to create post: %v", err)
 		return nil, err
 	}
+	s.autoMod.Record(subject, post.ID, verdict)
 
 	s.invalidateCache("posts:*")
 	s.invalidateCache("tags:*") // Thêm invalidation cho tag cache
 	log.Printf("Cache invalidated for posts:* and tags:* due to new post %d", post.ID)
+	s.searchIndex.SyncPost(post.ID)
+	s.syncPublishedMentions(post)
 
 	return post, nil
 }
@@ -136,6 +162,18 @@ func (s *postService) GetPostByIDSimple(id uint) (*models.Post, error) {
 	return post, nil
 }
 
+// ResolvePostID nhận ID hoặc slug (kể cả slug cũ) và trả về ID bài đăng
+func (s *postService) ResolvePostID(ref string) (uint, error) {
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		return uint(id), nil
+	}
+	id, err := s.postRepo.GetPostIDBySlug(ref)
+	if err != nil {
+		return 0, errors.New("post not found")
+	}
+	return id, nil
+}
+
 func (s *postService) DeletePost(id uint) error {
 	_, err := s.postRepo.GetPostByIDSimple(id)
 	if err != nil {
@@ -153,38 +191,55 @@ func (s *postService) DeletePost(id uint) error {
 	s.invalidateCache("posts:*")
 	s.invalidateCache("tags:*") // Thêm invalidation cho tag cache
 	log.Printf("Cache invalidated for posts:* and tags:* due to deleted post %d", id)
+	s.searchIndex.SyncPost(id)
 
 	return nil
 }
 
-func (s *postService) UpdatePost(id uint, title, content string, status models.PostStatus, tagId []uint) (*models.Post, error) {
+func (s *postService) UpdatePost(id uint, title, content string, tagId []uint, contentFormat string) (*models.Post, error) {
 	post, err := s.postRepo.GetPostByID(id)
 	if err != nil {
 		log.Printf("Failed to get post %d: %v", id, err)
 		return nil, err
 	}
+	format, err := resolveContentFormat(contentFormat, post.ContentFormat)
+	if err != nil {
+		return nil, err
+	}
+	if format != post.ContentFormat && content == "" {
+		return nil, errors.New("content is required when changing content format")
+	}
 
 	if content != "" {
-		post.Content = content
+		post.ContentFormat = format
+		applyContentBody(format, content, &post.Content, &post.ContentSource, &post.PlainContent)
 	}
 	if title != "" {
 		post.Title = title
 	}
-	if status != "" {
-		if !IsValidStatus(string(status)) {
-			return nil, errors.New("invalid status")
+	var subject AutoModerationSubject
+	var verdict *AutoModerationVerdict
+	if title != "" || content != "" {
+		subject = AutoModerationSubject{ContentType: models.ModerationPost, ContentID: id, UserID: post.UserID, Event: models.AutoModerationEventUpdate, Title: post.Title, Body: post.Content}
+		verdict = s.autoMod.Evaluate(subject)
+		if verdict.Status != "" {
+			post.Status = models.PostStatus(verdict.Status)
 		}
-		post.Status = status
 	}
 
 	if err := s.postRepo.UpdatePost(post, tagId); err != nil {
 		log.Printf("Failed to update post %d: %v", id, err)
 		return nil, err
 	}
+	s.autoMod.Record(subject, id, verdict)
 
 	s.invalidateCache(fmt.Sprintf("post:%d", id))
 	s.invalidateCache("posts:*")
 	s.invalidateCache("tags:*") // Thêm invalidation cho tag cache
+	s.searchIndex.SyncPost(id)
+	if content != "" {
+		s.syncPublishedMentions(post)
+	}
 
 	return post, nil
 }
@@ -199,7 +254,11 @@ func (s *postService) UpdatePostStatus(id uint, status string) (*models.Post, er
 		log.Printf("Failed to update post status %d: %v", id, err)
 		return nil, err
 	}
+	return s.ApplyReviewedStatus(id, status)
+}
 
+// ApplyReviewedStatus giống UpdatePostStatus nhưng không ghi trạng thái, dùng khi trạng thái đã được ghi ở nơi khác
+func (s *postService) ApplyReviewedStatus(id uint, status string) (*models.Post, error) {
 	post, err := s.postRepo.GetPostByIDSimple(id)
 	if err != nil {
 		log.Printf("Failed to get updated post %d: %v", id, err)
@@ -210,6 +269,9 @@ func (s *postService) UpdatePostStatus(id uint, status string) (*models.Post, er
 	s.invalidateCache(fmt.Sprintf("post:%d", id))
 	s.invalidateCache("posts:*")
 	s.invalidateCache("tags:*")
+	s.searchIndex.SyncPost(id)
+	s.autoMod.Feedback(models.ModerationPost, id, status)
+	s.syncPublishedMentions(post)
 
 	// Gửi notification cho chủ post
 	user, err := s.userRepo.GetUserByID(post.UserID)
@@ -357,3 +419,92 @@ func (s *postService) invalidateCache(pattern string) {
 		log.Printf("Deleted cache keys %v", keys)
 	}
 }
+
+// ListScheduledPosts liệt kê bài viết hẹn giờ của userID; userID = 0 để lấy tất cả (dành cho quản trị)
+func (s *postService) ListScheduledPosts(userID uint) ([]models.Post, error) {
+	return s.postRepo.ListScheduledPosts(userID)
+}
+
+// getScheduledPost kiểm tra bài viết còn đang hẹn giờ và thuộc về userID (0 = bỏ qua kiểm tra chủ sở hữu)
+func (s *postService) getScheduledPost(id, userID uint) (*models.Post, error) {
+	post, err := s.postRepo.GetPostByIDSimple(id)
+	if err != nil {
+		return nil, errors.New("post not found")
+	}
+	if userID != 0 && post.UserID != userID {
+		return nil, errors.New("you do not have permission to manage this post")
+	}
+	if post.PublishAt == nil {
+		return nil, errors.New("post is not scheduled")
+	}
+	return post, nil
+}
+
+func (s *postService) ReschedulePost(id, userID uint, publishAt time.Time) (*models.Post, error) {
+	if !publishAt.After(time.Now()) {
+		return nil, errors.New("publish time must be in the future")
+	}
+	post, err := s.getScheduledPost(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.postRepo.UpdatePostPublishAt(id, publishAt); err != nil {
+		log.Printf("Failed to reschedule post %d: %v", id, err)
+		return nil, err
+	}
+	post.PublishAt = &publishAt
+
+	s.invalidateCache(fmt.Sprintf("post:%d", id))
+	return post, nil
+}
+
+// CancelScheduledPost huỷ lịch đăng bằng cách xoá bài viết chưa được hiển thị
+func (s *postService) CancelScheduledPost(id, userID uint) error {
+	if _, err := s.getScheduledPost(id, userID); err != nil {
+		return err
+	}
+	return s.DeletePost(id)
+}
+
+// PublishDuePosts hiển thị các bài viết đã đến giờ đăng, được gọi định kỳ từ cron
+func (s *postService) PublishDuePosts() (int, error) {
+	now := time.Now()
+	posts, err := s.postRepo.ListDueScheduledPosts(now)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, post := range posts {
+		if err := s.postRepo.MarkPostPublished(post.ID, *post.PublishAt); err != nil {
+			log.Printf("Failed to publish scheduled post %d: %v", post.ID, err)
+			continue
+		}
+		published++
+		post.PublishAt = nil
+
+		s.invalidateCache(fmt.Sprintf("post:%d", post.ID))
+		s.searchIndex.SyncPost(post.ID)
+		s.syncPublishedMentions(&post)
+
+		workflowID := "post-published"
+		message := fmt.Sprintf("Bài viết hẹn giờ của bạn đã được đăng: %s", post.Title)
+		if err := s.novuClient.SendNotification(post.UserID, workflowID, message); err != nil {
+			log.Printf("Gửi notification đăng bài viết hẹn giờ thất bại: %v", err)
+		}
+	}
+	if published > 0 {
+		s.invalidateCache("posts:*")
+		s.invalidateCache("tags:*")
+	}
+	return published, nil
+}
+
+// syncPublishedMentions chỉ đồng bộ và gửi thông báo nhắc tên khi bài viết đã hiển thị (đã duyệt, không còn hẹn giờ);
+// bài viết chờ duyệt hoặc hẹn giờ được đồng bộ lại lúc duyệt hoặc lúc đến giờ đăng
+func (s *postService) syncPublishedMentions(post *models.Post) {
+	if post.Status != models.Approved || post.PublishAt != nil {
+		return
+	}
+	s.mentions.SyncMentions(models.MentionTargetPost, post.ID, post.UserID, "", post.Content, mentionSummary(post.Title, post.PlainContent))
+}
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Số phiếu cộng đồng cần để đóng/mở lại câu hỏi (ghi đè bằng QUESTION_CLOSE_VOTES, QUESTION_REOPEN_VOTES)
+const (
+	defaultCloseVoteThreshold  = 3
+	defaultReopenVoteThreshold = 3
+)
+
+type CloseVoteStatus struct {
+	QuestionID      uint
+	Closed          bool
+	Locked          bool
+	CloseVotes      int
+	ReopenVotes     int
+	CloseThreshold  int
+	ReopenThreshold int
+	HasVotedClose   bool
+	HasVotedReopen  bool
+	Reasons         map[models.CloseReason]int
+}
+
+// QuestionCloseService quản lý việc đóng, mở lại và khoá câu hỏi: người kiểm duyệt thao tác trực tiếp,
+// cộng đồng bỏ phiếu và câu hỏi đổi trạng thái khi đủ ngưỡng. Mọi thay đổi đều được ghi vào dòng thời gian.
+type QuestionCloseService interface {
+	CloseQuestion(questionID, actorID uint, reason models.CloseReason, duplicateOfID *uint, note string) (*models.Question, error)
+	ReopenQuestion(questionID, actorID uint, note string) (*models.Question, error)
+	LockQuestion(questionID, actorID uint, duration time.Duration, note string) (*models.Question, error)
+	UnlockQuestion(questionID, actorID uint, note string) (*models.Question, error)
+	VoteToClose(questionID, userID uint, reason models.CloseReason, duplicateOfID *uint) (*CloseVoteStatus, error)
+	VoteToReopen(questionID, userID uint) (*CloseVoteStatus, error)
+	RetractVote(questionID, userID uint) (*CloseVoteStatus, error)
+	GetVoteStatus(questionID, userID uint) (*CloseVoteStatus, error)
+	GetTimeline(questionID uint) ([]models.QuestionEvent, error)
+	ExpireLocks() (int, error)
+}
+
+type questionCloseService struct {
+	closeRepo       repositories.QuestionCloseRepository
+	questionRepo    repositories.QuestionRepository
+	redisClient     *redis.Client
+	novuClient      *notification.NovuClient
+	closeThreshold  int
+	reopenThreshold int
+}
+
+func NewQuestionCloseService(closeRepo repositories.QuestionCloseRepository, questionRepo repositories.QuestionRepository, redisClient *redis.Client, novuClient *notification.NovuClient) QuestionCloseService {
+	return &questionCloseService{
+		closeRepo:       closeRepo,
+		questionRepo:    questionRepo,
+		redisClient:     redisClient,
+		novuClient:      novuClient,
+		closeThreshold:  envThreshold("QUESTION_CLOSE_VOTES", defaultCloseVoteThreshold),
+		reopenThreshold: envThreshold("QUESTION_REOPEN_VOTES", defaultReopenVoteThreshold),
+	}
+}
+
+func envThreshold(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func (s *questionCloseService) CloseQuestion(questionID, actorID uint, reason models.CloseReason, duplicateOfID *uint, note string) (*models.Question, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, errors.New("question not found")
+	}
+	if question.IsClosed() {
+		return nil, errors.New("question is already closed")
+	}
+	if err := s.validateReason(questionID, reason, duplicateOfID); err != nil {
+		return nil, err
+	}
+	closed, err := s.applyClose(question, actorID, reason, duplicateOfID, note, false, 0)
+	if errors.Is(err, repositories.ErrQuestionStateCurrent) {
+		return nil, errors.New("question is already closed")
+	}
+	return closed, err
+}
+
+func (s *questionCloseService) ReopenQuestion(questionID, actorID uint, note string) (*models.Question, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, errors.New("question not found")
+	}
+	if !question.IsClosed() {
+		return nil, errors.New("question is not closed")
+	}
+	reopened, err := s.applyReopen(question, actorID, note, false, 0)
+	if errors.Is(err, repositories.ErrQuestionStateCurrent) {
+		return nil, errors.New("question is not closed")
+	}
+	return reopened, err
+}
+
+// LockQuestion chặn câu trả lời và bình luận mới; duration bằng 0 nghĩa là khoá vô thời hạn
+func (s *questionCloseService) LockQuestion(questionID, actorID uint, duration time.Duration, note string) (*models.Question, error) {
+	if duration < 0 {
+		return nil, errors.New("lock duration must not be negative")
+	}
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, errors.New("question not found")
+	}
+
+	now := time.Now()
+	var lockedUntil *time.Time
+	if duration > 0 {
+		until := now.Add(duration)
+		lockedUntil = &until
+	}
+
+	updates := map[string]interface{}{
+		"locked_at":    now,
+		"locked_until": lockedUntil,
+	}
+	event := &models.QuestionEvent{
+		ActorID:     &actorID,
+		Action:      models.QuestionEventLocked,
+		Note:        note,
+		LockedUntil: lockedUntil,
+	}
+	if err := s.closeRepo.ApplyStateChange(question.ID, updates, "", event); err != nil {
+		log.Printf("Failed to lock question %d: %v", questionID, err)
+		return nil, err
+	}
+	return s.reload(questionID)
+}
+
+func (s *questionCloseService) UnlockQuestion(questionID, actorID uint, note string) (*models.Question, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, errors.New("question not found")
+	}
+	if !question.IsLocked(time.Now()) {
+		return nil, errors.New("question is not locked")
+	}
+	if err := s.unlock(question.ID, &actorID, note); err != nil {
+		log.Printf("Failed to unlock question %d: %v", questionID, err)
+		return nil, err
+	}
+	return s.reload(questionID)
+}
+
+func (s *questionCloseService) VoteToClose(questionID, userID uint, reason models.CloseReason, duplicateOfID *uint) (*CloseVoteStatus, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, errors.New("question not found")
+	}
+	if question.IsClosed() {
+		return nil, errors.New("question is already closed")
+	}
+	if question.IsLocked(time.Now()) {
+		return nil, errors.New("question is locked")
+	}
+	if err := s.validateReason(questionID, reason, duplicateOfID); err != nil {
+		return nil, err
+	}
+	if reason != models.CloseReasonDuplicate {
+		duplicateOfID = nil
+	}
+	if voted, err := s.closeRepo.HasVoted(questionID, userID, models.CloseVoteClose); err != nil {
+		return nil, err
+	} else if voted {
+		return nil, errors.New("you have already voted to close this question")
+	}
+
+	vote := &models.QuestionCloseVote{
+		QuestionID:    questionID,
+		UserID:        userID,
+		VoteType:      models.CloseVoteClose,
+		Reason:        reason,
+		DuplicateOfID: duplicateOfID,
+	}
+	if err := s.closeRepo.AddVote(vote); err != nil {
+		if errors.Is(err, repositories.ErrCloseVoteExists) {
+			return nil, errors.New("you have already voted to close this question")
+		}
+		log.Printf("Failed to add close vote on question %d: %v", questionID, err)
+		return nil, err
+	}
+
+	votes, err := s.closeRepo.ListVotes(questionID, models.CloseVoteClose)
+	if err != nil {
+		return nil, err
+	}
+	if len(votes) >= s.closeThreshold {
+		winningReason, duplicateTarget := tallyCloseVotes(votes)
+		// Phiếu đồng thời khác có thể đã đóng câu hỏi trước; khi đó không đóng lại lần nữa
+		if _, err := s.applyClose(question, userID, winningReason, duplicateTarget, "", true, len(votes)); err != nil && !errors.Is(err, repositories.ErrQuestionStateCurrent) {
+			return nil, err
+		}
+	}
+	return s.GetVoteStatus(questionID, userID)
+}
+
+func (s *questionCloseService) VoteToReopen(questionID, userID uint) (*CloseVoteStatus, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, errors.New("question not found")
+	}
+	if !question.IsClosed() {
+		return nil, errors.New("question is not closed")
+	}
+	if question.IsLocked(time.Now()) {
+		return nil, errors.New("question is locked")
+	}
+	if voted, err := s.closeRepo.HasVoted(questionID, userID, models.CloseVoteReopen); err != nil {
+		return nil, err
+	} else if voted {
+		return nil, errors.New("you have already voted to reopen this question")
+	}
+
+	vote := &models.QuestionCloseVote{
+		QuestionID: questionID,
+		UserID:     userID,
+		VoteType:   models.CloseVoteReopen,
+	}
+	if err := s.closeRepo.AddVote(vote); err != nil {
+		if errors.Is(err, repositories.ErrCloseVoteExists) {
+			return nil, errors.New("you have already voted to reopen this question")
+		}
+		log.Printf("Failed to add reopen vote on question %d: %v", questionID, err)
+		return nil, err
+	}
+
+	votes, err := s.closeRepo.ListVotes(questionID, models.CloseVoteReopen)
+	if err != nil {
+		return nil, err
+	}
+	if len(votes) >= s.reopenThreshold {
+		if _, err := s.applyReopen(question, userID, "", true, len(votes)); err != nil && !errors.Is(err, repositories.ErrQuestionStateCurrent) {
+			return nil, err
+		}
+	}
+	return s.GetVoteStatus(questionID, userID)
+}
+
+// RetractVote rút phiếu đang chờ của người dùng (phiếu đóng khi câu hỏi mở, phiếu mở lại khi câu hỏi đóng)
+func (s *questionCloseService) RetractVote(questionID, userID uint) (*CloseVoteStatus, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, errors.New("question not found")
+	}
+	voteType := models.CloseVoteClose
+	if question.IsClosed() {
+		voteType = models.CloseVoteReopen
+	}
+	removed, err := s.closeRepo.DeleteVote(questionID, userID, voteType)
+	if err != nil {
+		return nil, err
+	}
+	if removed == 0 {
+		return nil, errors.New("you have no pending vote on this question")
+	}
+	return s.GetVoteStatus(questionID, userID)
+}
+
+func (s *questionCloseService) GetVoteStatus(questionID, userID uint) (*CloseVoteStatus, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(questionID)
+	if err != nil {
+		return nil, errors.New("question not found")
+	}
+	closeVotes, err := s.closeRepo.ListVotes(questionID, models.CloseVoteClose)
+	if err != nil {
+		return nil, err
+	}
+	reopenVotes, err := s.closeRepo.ListVotes(questionID, models.CloseVoteReopen)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &CloseVoteStatus{
+		QuestionID:      questionID,
+		Closed:          question.IsClosed(),
+		Locked:          question.IsLocked(time.Now()),
+		CloseVotes:      len(closeVotes),
+		ReopenVotes:     len(reopenVotes),
+		CloseThreshold:  s.closeThreshold,
+		ReopenThreshold: s.reopenThreshold,
+		Reasons:         make(map[models.CloseReason]int),
+	}
+	for _, vote := range closeVotes {
+		status.Reasons[vote.Reason]++
+		if vote.UserID == userID {
+			status.HasVotedClose = true
+		}
+	}
+	for _, vote := range reopenVotes {
+		if vote.UserID == userID {
+			status.HasVotedReopen = true
+		}
+	}
+	return status, nil
+}
+
+func (s *questionCloseService) GetTimeline(questionID uint) ([]models.QuestionEvent, error) {
+	if _, err := s.questionRepo.GetQuestionByIDMinimal(questionID); err != nil {
+		return nil, errors.New("question not found")
+	}
+	return s.closeRepo.ListEvents(questionID)
+}
+
+// ExpireLocks mở khoá các câu hỏi đã hết thời hạn khoá và ghi sự kiện do hệ thống thực hiện
+func (s *questionCloseService) ExpireLocks() (int, error) {
+	questions, err := s.closeRepo.ListExpiredLocks(time.Now())
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, question := range questions {
+		if err := s.unlock(question.ID, nil, "lock expired"); err != nil {
+			log.Printf("Failed to expire lock on question %d: %v", question.ID, err)
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (s *questionCloseService) validateReason(questionID uint, reason models.CloseReason, duplicateOfID *uint) error {
+	if !models.IsValidCloseReason(reason) {
+		return errors.New("invalid close reason")
+	}
+	if reason != models.CloseReasonDuplicate {
+		return nil
+	}
+	if duplicateOfID == nil || *duplicateOfID == 0 {
+		return errors.New("duplicate questions must reference the original question")
+	}
+	if *duplicateOfID == questionID {
+		return errors.New("a question cannot be a duplicate of itself")
+	}
+	if _, err := s.questionRepo.GetQuestionByIDMinimal(*duplicateOfID); err != nil {
+		return errors.New("original question not found")
+	}
+	return nil
+}
+
+func (s *questionCloseService) applyClose(question *models.Question, actorID uint, reason models.CloseReason, duplicateOfID *uint, note string, byCommunity bool, votes int) (*models.Question, error) {
+	if reason != models.CloseReasonDuplicate {
+		duplicateOfID = nil
+	}
+	updates := map[string]interface{}{
+		"interaction_status": models.InteractionClosed,
+		"close_reason":       reason,
+		"duplicate_of_id":    duplicateOfID,
+		"closed_at":          time.Now(),
+	}
+	event := &models.QuestionEvent{
+		ActorID:       &actorID,
+		Action:        models.QuestionEventClosed,
+		Reason:        reason,
+		DuplicateOfID: duplicateOfID,
+		Note:          note,
+		ByCommunity:   byCommunity,
+		VoteCount:     votes,
+	}
+	if err := s.closeRepo.ApplyStateChange(question.ID, updates, models.CloseVoteClose, event); err != nil {
+		if !errors.Is(err, repositories.ErrQuestionStateCurrent) {
+			log.Printf("Failed to close question %d: %v", question.ID, err)
+		}
+		return nil, err
+	}
+
+	if question.UserID != actorID {
+		message := fmt.Sprintf("Câu hỏi của bạn đã bị đóng: %s", question.Title)
+		if err := s.novuClient.SendNotification(question.UserID, "question-closed", message); err != nil {
+			log.Printf("Gửi thông báo đóng câu hỏi thất bại: %v", err)
+		}
+	}
+	return s.reload(question.ID)
+}
+
+func (s *questionCloseService) applyReopen(question *models.Question, actorID uint, note string, byCommunity bool, votes int) (*models.Question, error) {
+	updates := map[string]interface{}{
+		"interaction_status": models.InteractionOpened,
+		"close_reason":       "",
+		"duplicate_of_id":    nil,
+		"closed_at":          nil,
+	}
+	event := &models.QuestionEvent{
+		ActorID:     &actorID,
+		Action:      models.QuestionEventReopened,
+		Note:        note,
+		ByCommunity: byCommunity,
+		VoteCount:   votes,
+	}
+	if err := s.closeRepo.ApplyStateChange(question.ID, updates, models.CloseVoteReopen, event); err != nil {
+		if !errors.Is(err, repositories.ErrQuestionStateCurrent) {
+			log.Printf("Failed to reopen question %d: %v", question.ID, err)
+		}
+		return nil, err
+	}
+
+	if question.UserID != actorID {
+		message := fmt.Sprintf("Câu hỏi của bạn đã được mở lại: %s", question.Title)
+		if err := s.novuClient.SendNotification(question.UserID, "question-reopened", message); err != nil {
+			log.Printf("Gửi thông báo mở lại câu hỏi thất bại: %v", err)
+		}
+	}
+	return s.reload(question.ID)
+}
+
+func (s *questionCloseService) unlock(questionID uint, actorID *uint, note string) error {
+	updates := map[string]interface{}{
+		"locked_at":    nil,
+		"locked_until": nil,
+	}
+	event := &models.QuestionEvent{
+		ActorID: actorID,
+		Action:  models.QuestionEventUnlocked,
+		Note:    note,
+	}
+	if err := s.closeRepo.ApplyStateChange(questionID, updates, "", event); err != nil {
+		return err
+	}
+	s.invalidateQuestionCache(questionID)
+	return nil
+}
+
+func (s *questionCloseService) reload(questionID uint) (*models.Question, error) {
+	s.invalidateQuestionCache(questionID)
+	return s.questionRepo.GetQuestionByIDMinimal(questionID)
+}
+
+func (s *questionCloseService) invalidateQuestionCache(questionID uint) {
+	s.invalidateCache(fmt.Sprintf("question:%d", questionID))
+	s.invalidateCache("questions:*")
+}
+
+func (s *questionCloseService) invalidateCache(pattern string) {
+	ctx := context.Background()
+	keys, err := s.redisClient.Keys(ctx, pattern).Result()
+	if err != nil {
+		log.Printf("Failed to invalidate cache for pattern %s: %v", pattern, err)
+		return
+	}
+
+	if len(keys) > 0 {
+		if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+			log.Printf("Failed to delete cache keys %v: %v", keys, err)
+		}
+	}
+}
+
+// tallyCloseVotes chọn lý do được bầu nhiều nhất (hoà thì lấy lý do được bầu sớm nhất)
+// và câu hỏi gốc được nhiều phiếu "trùng lặp" trỏ tới nhất
+func tallyCloseVotes(votes []models.QuestionCloseVote) (models.CloseReason, *uint) {
+	reasonCounts := make(map[models.CloseReason]int)
+	targetCounts := make(map[uint]int)
+	var winner models.CloseReason
+	var target *uint
+	for _, vote := range votes {
+		reasonCounts[vote.Reason]++
+		if reasonCounts[vote.Reason] > reasonCounts[winner] {
+			winner = vote.Reason
+		}
+		if vote.Reason == models.CloseReasonDuplicate && vote.DuplicateOfID != nil {
+			id := *vote.DuplicateOfID
+			targetCounts[id]++
+			if target == nil || targetCounts[id] > targetCounts[*target] {
+				target = &id
+			}
+		}
+	}
+	return winner, target
+}
@@ -8,24 +8,31 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"log"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 )
 
 type QuestionService interface {
-	CreateQuestion(title string, description string, userID, topicID uint, status string) (*models.Question, error)
+	CreateQuestion(title string, description string, userID, topicID uint, publishAt *time.Time, contentFormat string, anonymous bool) (*models.Question, error)
 	GetQuestionByID(id uint) (*models.Question, error)
-	UpdateQuestion(id uint, title string, description string, topicID uint) (*models.Question, error)
+	ResolveQuestionID(ref string) (uint, error)
+	UpdateQuestion(id uint, title string, description string, topicID uint, contentFormat string) (*models.Question, error)
 	DeleteQuestion(id uint) error
 	ListQuestions(filters map[string]interface{}) ([]models.Question, int, error)
 	UpdateQuestionStatus(id uint, status string) (*models.Question, error)
+	ApplyReviewedStatus(id uint, status string) (*models.Question, error)
 	UpdateInteractionStatus(id uint, status models.InteractionStatus, userID uint) (*models.Question, error)
 	GetAllQuestion(filters map[string]interface{}) ([]models.Question, int, error)
 	SyncQuestionsToRAG() ([]models.Question, error)
+	ListScheduledQuestions(userID uint) ([]models.Question, error)
+	RescheduleQuestion(id, userID uint, publishAt time.Time) (*models.Question, error)
+	CancelScheduledQuestion(id, userID uint) error
+	PublishDueQuestions() (int, error)
 }
 
 type questionService struct {
@@ -34,59 +41,76 @@ type questionService struct {
 	redisClient  *redis.Client
 	userRepo     repositories.UserRepository
 	novuClient   *notification.NovuClient
+	searchIndex  SearchIndexService
+	classifier   TopicClassifierService
+	mentions     MentionService
+	autoMod      AutoModerationService
 }
 
-func NewQuestionService(qRepo repositories.QuestionRepository, tService TopicService, redisClient *redis.Client, uRepo repositories.UserRepository, novuClient *notification.NovuClient) QuestionService {
-	return &questionService{questionRepo: qRepo, topicService: tService, redisClient: redisClient, userRepo: uRepo, novuClient: novuClient}
+func NewQuestionService(qRepo repositories.QuestionRepository, tService TopicService, redisClient *redis.Client, uRepo repositories.UserRepository, novuClient *notification.NovuClient, searchIndex SearchIndexService, classifier TopicClassifierService, mentions MentionService, autoMod AutoModerationService) QuestionService {
+	return &questionService{questionRepo: qRepo, topicService: tService, redisClient: redisClient, userRepo: uRepo, novuClient: novuClient, searchIndex: searchIndex, classifier: classifier, mentions: mentions, autoMod: autoMod}
 }
 
-func (s *questionService) CreateQuestion(title string, description string, userID, topicID uint, status string) (*models.Question, error) {
+func (s *questionService) CreateQuestion(title string, description string, userID, topicID uint, publishAt *time.Time, contentFormat string, anonymous bool) (*models.Question, error) {
 	if title == "" {
 		return nil, fmt.Errorf("title is required")
 	}
+	format, err := resolveContentFormat(contentFormat, "")
+	if err != nil {
+		return nil, err
+	}
+	// Thời điểm đã qua được coi như đăng ngay
+	if publishAt != nil && !publishAt.After(time.Now()) {
+		publishAt = nil
+	}
 
 	question := &models.Question{
 		Title:             title,
-		Description:       description,
+		ContentFormat:     format,
 		UserID:            userID,
 		TopicID:           topicID,
-		Status:            models.QuestionStatus(status),
 		InteractionStatus: models.InteractionOpened,
+		PublishAt:         publishAt,
+		Anonymous:         anonymous,
 	}
+	applyContentBody(format, description, &question.Description, &question.ContentSource, &question.PlainContent)
+
+	subject := AutoModerationSubject{ContentType: models.ModerationQuestion, UserID: userID, Event: models.AutoModerationEventCreate, Title: title, Body: question.Description}
+	verdict := s.autoMod.Evaluate(subject)
+	question.Status = models.QuestionStatus(verdict.Status)
 
 	if err := s.questionRepo.CreateQuestion(question); err != nil {
 		log.Printf("Failed to create question: %v", err)
 		return nil, err
 	}
+	s.autoMod.Record(subject, question.ID, verdict)
 
 	if topicID == 0 {
 		s.suggestTopicForQuestion(question)
 	}
 
 	s.invalidateCache("questions:*")
+	s.searchIndex.SyncQuestion(question.ID)
+	s.syncPublishedMentions(question)
 
 	return question, nil
 }
 
+// suggestTopicForQuestion gán chủ đề có sẵn được bộ phân loại tin cậy nhất.
+// Không tạo chủ đề mới; nếu độ tin cậy thấp thì để trống cho người dùng tự chọn.
 func (s *questionService) suggestTopicForQuestion(question *models.Question) {
-	keywords := strings.Split(strings.ToLower(question.Title), " ")
-	for _, keyword := range keywords {
-		if len(keyword) < 3 {
-			continue
-		}
-		topic, err := s.topicService.GetTopicByName(keyword)
-		if err != nil && err.Error() == "topic not found" {
-			topic, err = s.topicService.CreateTopic(keyword, "Auto-generated topic from question")
-			if err != nil {
-				log.Printf("Failed to suggest topic %s for question %d: %v", keyword, question.ID, err)
-				continue
-			}
-			question.TopicID = topic.ID
-			if err := s.questionRepo.UpdateQuestion(question); err != nil {
-				log.Printf("Failed to update question %d with topic %d: %v", question.ID, topic.ID, err)
-			}
-			break
-		}
+	suggestions, err := s.classifier.Suggest(question.Title, question.PlainContent, 1)
+	if err != nil {
+		log.Printf("Failed to suggest topic for question %d: %v", question.ID, err)
+		return
+	}
+	if len(suggestions) == 0 || suggestions[0].Confidence < autoAssignConfidence {
+		return
+	}
+
+	question.TopicID = suggestions[0].TopicID
+	if err := s.questionRepo.UpdateQuestion(question); err != nil {
+		log.Printf("Failed to update question %d with topic %d: %v", question.ID, question.TopicID, err)
 	}
 }
 
@@ -121,27 +145,59 @@ func (s *questionService) GetQuestionByID(id uint) (*models.Question, error) {
 	return question, nil
 }
 
-func (s *questionService) UpdateQuestion(id uint, title string, description string, topicID uint) (*models.Question, error) {
+// ResolveQuestionID nhận ID hoặc slug (kể cả slug cũ) và trả về ID câu hỏi
+func (s *questionService) ResolveQuestionID(ref string) (uint, error) {
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		return uint(id), nil
+	}
+	id, err := s.questionRepo.GetQuestionIDBySlug(ref)
+	if err != nil {
+		return 0, errors.New("question not found")
+	}
+	return id, nil
+}
+
+func (s *questionService) UpdateQuestion(id uint, title string, description string, topicID uint, contentFormat string) (*models.Question, error) {
 	question, err := s.questionRepo.GetQuestionByID(id)
 	if err != nil {
 		return nil, err
 	}
+	format, err := resolveContentFormat(contentFormat, question.ContentFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if format != question.ContentFormat && description == "" {
+		return nil, errors.New("description is required when changing content format")
+	}
 
 	if title != "" {
 		question.Title = title
 	}
-	question.Description = description
+	if description != "" {
+		question.ContentFormat = format
+		applyContentBody(format, description, &question.Description, &question.ContentSource, &question.PlainContent)
+	}
 	if topicID != 0 {
 		question.TopicID = topicID
 	}
 
+	subject := AutoModerationSubject{ContentType: models.ModerationQuestion, ContentID: id, UserID: question.UserID, Event: models.AutoModerationEventUpdate, Title: question.Title, Body: question.Description}
+	verdict := s.autoMod.Evaluate(subject)
+	if verdict.Status != "" {
+		question.Status = models.QuestionStatus(verdict.Status)
+	}
+
 	if err := s.questionRepo.UpdateQuestion(question); err != nil {
 		log.Printf("Failed to update question %d: %v", id, err)
 		return nil, err
 	}
+	s.autoMod.Record(subject, id, verdict)
 
 	s.invalidateCache(fmt.Sprintf("question:%d", id))
 	s.invalidateCache("questions:*")
+	s.searchIndex.SyncQuestion(id)
+	s.syncPublishedMentions(question)
 
 	return question, nil
 }
@@ -155,6 +211,7 @@ func (s *questionService) DeleteQuestion(id uint) error {
 
 	s.invalidateCache(fmt.Sprintf("question:%d", id))
 	s.invalidateCache("questions:*")
+	s.searchIndex.SyncQuestion(id)
 
 	return nil
 }
@@ -258,7 +315,12 @@ func (s *questionService) UpdateQuestionStatus(id uint, status string) (*models.
 		log.Printf("Failed to update question status %d: %v", id, err)
 		return nil, err
 	}
+	return s.ApplyReviewedStatus(id, status)
+}
 
+// ApplyReviewedStatus chạy các việc đi kèm khi trạng thái câu hỏi đã được ghi: xoá cache, đồng bộ chỉ mục,
+// nhắc tên và thông báo cho tác giả. Kiểm duyệt gọi trực tiếp sau khi đã ghi trạng thái dưới khoá dòng.
+func (s *questionService) ApplyReviewedStatus(id uint, status string) (*models.Question, error) {
 	updatedQuestion, err := s.questionRepo.GetQuestionByIDMinimal(id)
 	if err != nil {
 		log.Printf("Failed to get updated question %d: %v", id, err)
@@ -268,6 +330,9 @@ func (s *questionService) UpdateQuestionStatus(id uint, status string) (*models.
 	// Invalidate cache
 	s.invalidateCache(fmt.Sprintf("question:%d", id))
 	s.invalidateCache("questions:*")
+	s.searchIndex.SyncQuestion(id)
+	s.autoMod.Feedback(models.ModerationQuestion, id, status)
+	s.syncPublishedMentions(updatedQuestion)
 
 	// Gửi notification cho chủ câu hỏi
 	user, err := s.userRepo.GetUserByID(updatedQuestion.UserID)
@@ -296,6 +361,15 @@ func (s *questionService) UpdateInteractionStatus(id uint, status models.Interac
 		return nil, fmt.Errorf("trạng thái tương tác không hợp lệ")
 	}
 
+	// Đóng và mở lại câu hỏi phải đi qua quy trình đóng/mở để có lý do và được ghi vào dòng thời gian
+	question, err := s.questionRepo.GetQuestionByIDMinimal(id)
+	if err != nil {
+		return nil, err
+	}
+	if status == models.InteractionClosed || question.IsClosed() {
+		return nil, fmt.Errorf("hãy dùng chức năng đóng/mở lại câu hỏi để thay đổi trạng thái đóng")
+	}
+
 	if err := s.questionRepo.UpdateInteractionStatus(id, string(status)); err != nil {
 		log.Printf("Failed to update interaction status for question %d: %v", id, err)
 		return nil, err
@@ -364,3 +438,91 @@ func (s *questionService) SyncQuestionsToRAG() ([]models.Question, error) {
 
 	return questions, nil
 }
+
+// ListScheduledQuestions liệt kê câu hỏi hẹn giờ của userID; userID = 0 để lấy tất cả (dành cho quản trị)
+func (s *questionService) ListScheduledQuestions(userID uint) ([]models.Question, error) {
+	return s.questionRepo.ListScheduledQuestions(userID)
+}
+
+// getScheduledQuestion kiểm tra câu hỏi còn đang hẹn giờ và thuộc về userID (0 = bỏ qua kiểm tra chủ sở hữu)
+func (s *questionService) getScheduledQuestion(id, userID uint) (*models.Question, error) {
+	question, err := s.questionRepo.GetQuestionByIDMinimal(id)
+	if err != nil {
+		return nil, fmt.Errorf("question not found")
+	}
+	if userID != 0 && question.UserID != userID {
+		return nil, fmt.Errorf("you do not have permission to manage this question")
+	}
+	if question.PublishAt == nil {
+		return nil, fmt.Errorf("question is not scheduled")
+	}
+	return question, nil
+}
+
+func (s *questionService) RescheduleQuestion(id, userID uint, publishAt time.Time) (*models.Question, error) {
+	if !publishAt.After(time.Now()) {
+		return nil, fmt.Errorf("publish time must be in the future")
+	}
+	question, err := s.getScheduledQuestion(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.questionRepo.UpdateQuestionPublishAt(id, publishAt); err != nil {
+		log.Printf("Failed to reschedule question %d: %v", id, err)
+		return nil, err
+	}
+	question.PublishAt = &publishAt
+
+	s.invalidateCache(fmt.Sprintf("question:%d", id))
+	return question, nil
+}
+
+// CancelScheduledQuestion huỷ lịch đăng bằng cách xoá câu hỏi chưa được hiển thị
+func (s *questionService) CancelScheduledQuestion(id, userID uint) error {
+	if _, err := s.getScheduledQuestion(id, userID); err != nil {
+		return err
+	}
+	return s.DeleteQuestion(id)
+}
+
+// PublishDueQuestions hiển thị các câu hỏi đã đến giờ đăng, được gọi định kỳ từ cron
+func (s *questionService) PublishDueQuestions() (int, error) {
+	now := time.Now()
+	questions, err := s.questionRepo.ListDueScheduledQuestions(now)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, question := range questions {
+		if err := s.questionRepo.MarkQuestionPublished(question.ID, *question.PublishAt); err != nil {
+			log.Printf("Failed to publish scheduled question %d: %v", question.ID, err)
+			continue
+		}
+		published++
+		question.PublishAt = nil
+
+		s.invalidateCache(fmt.Sprintf("question:%d", question.ID))
+		s.searchIndex.SyncQuestion(question.ID)
+		s.syncPublishedMentions(&question)
+
+		workflowID := "question-published"
+		message := fmt.Sprintf("Câu hỏi hẹn giờ của bạn đã được đăng: %s", question.Title)
+		if err := s.novuClient.SendNotification(question.UserID, workflowID, message); err != nil {
+			log.Printf("Gửi notification đăng câu hỏi hẹn giờ thất bại: %v", err)
+		}
+	}
+	if published > 0 {
+		s.invalidateCache("questions:*")
+	}
+	return published, nil
+}
+
+// syncPublishedMentions chỉ đồng bộ và gửi thông báo nhắc tên khi câu hỏi đã hiển thị (đã duyệt, không còn hẹn giờ);
+// câu hỏi chờ duyệt hoặc hẹn giờ được đồng bộ lại lúc duyệt hoặc lúc đến giờ đăng
+func (s *questionService) syncPublishedMentions(question *models.Question) {
+	if question.Status != models.StatusApproved || question.PublishAt != nil {
+		return
+	}
+	s.mentions.SyncMentions(models.MentionTargetQuestion, question.ID, question.UserID, question.Pseudonym, question.Description, question.Title)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Thời gian giữ bộ đếm tương tác trong Redis; hết hạn sẽ được dựng lại từ DB
+	reactionCountsTTL = 10 * time.Minute
+	// Trường luôn có trong hash Redis để phân biệt "chưa có tương tác" với "chưa có cache"
+	reactionTotalField = "total"
+)
+
+// ReactionType là một loại tương tác người dùng có thể chọn
+type ReactionType struct {
+	Name  string `json:"name"`
+	Emoji string `json:"emoji"`
+	Label string `json:"label"`
+}
+
+var defaultReactionTypes = []ReactionType{
+	{Name: models.ReactionLike, Emoji: "👍", Label: "Thích"},
+	{Name: "love", Emoji: "❤️", Label: "Yêu thích"},
+	{Name: "insightful", Emoji: "💡", Label: "Sâu sắc"},
+	{Name: "funny", Emoji: "😄", Label: "Hài hước"},
+	{Name: "celebrate", Emoji: "🎉", Label: "Chúc mừng"},
+}
+
+// ReactionTypes trả về tập loại tương tác đang bật. REACTION_TYPES liệt kê các loại cách nhau bởi dấu phẩy,
+// mỗi loại có thể kèm emoji sau dấu hai chấm (vd "like,love,sad:😢"); loại mặc định giữ emoji và nhãn sẵn có.
+func ReactionTypes() []ReactionType {
+	value := strings.TrimSpace(os.Getenv("REACTION_TYPES"))
+	if value == "" {
+		return defaultReactionTypes
+	}
+	known := make(map[string]ReactionType, len(defaultReactionTypes))
+	for _, t := range defaultReactionTypes {
+		known[t.Name] = t
+	}
+	var types []ReactionType
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		name, emoji, _ := strings.Cut(strings.TrimSpace(item), ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == reactionTotalField || len(name) > 32 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		t, ok := known[name]
+		if !ok {
+			t = ReactionType{Name: name, Label: name}
+		}
+		if emoji = strings.TrimSpace(emoji); emoji != "" {
+			t.Emoji = emoji
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return defaultReactionTypes
+	}
+	return types
+}
+
+func findReactionType(name string) (ReactionType, bool) {
+	for _, t := range ReactionTypes() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return ReactionType{}, false
+}
+
+// ReactionCounter giữ số tương tác theo loại của từng nội dung trong hash Redis, dùng chung cho
+// các controller cần hiển thị số tương tác trên danh sách mà không truy vấn từng phần tử
+type ReactionCounter interface {
+	Counts(targetType models.ReactionTargetType, targetIDs []uint) map[uint]models.ReactionCounts
+	Adjust(targetType models.ReactionTargetType, targetID uint, reactionType string, delta int64)
+}
+
+type reactionCounter struct {
+	reactionRepo repositories.ReactionRepository
+	redisClient  *redis.Client
+}
+
+func NewReactionCounter(repo repositories.ReactionRepository, redisClient *redis.Client) ReactionCounter {
+	return &reactionCounter{reactionRepo: repo, redisClient: redisClient}
+}
+
+func reactionCountsKey(targetType models.ReactionTargetType, targetID uint) string {
+	return fmt.Sprintf("reaction_counts:%s:%d", targetType, targetID)
+}
+
+// reactionCountsGenKey đếm số lần bộ đếm của nội dung bị thay đổi; Counts chỉ ghi kết quả đếm từ DB
+// khi không có thay đổi nào xen vào giữa lúc đọc DB và lúc ghi cache
+func reactionCountsGenKey(targetType models.ReactionTargetType, targetID uint) string {
+	return reactionCountsKey(targetType, targetID) + ":gen"
+}
+
+// adjustReactionCountsScript tăng thế hệ rồi chỉ cộng dồn khi hash đã có, để hash hết hạn giữa chừng
+// không bị tạo lại chỉ với vài trường. KEYS: hash, thế hệ; ARGV: loại, delta, TTL (giây)
+var adjustReactionCountsScript = redis.NewScript(`
+redis.call('INCR', KEYS[2])
+redis.call('EXPIRE', KEYS[2], ARGV[3] * 2)
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+	redis.call('HINCRBY', KEYS[1], 'total', ARGV[2])
+	redis.call('EXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// storeReactionCountsScript ghi hash đếm lại từ DB nếu chưa có và thế hệ chưa đổi từ lúc đọc DB.
+// KEYS: hash, thế hệ; ARGV: thế hệ đã đọc, TTL (giây), rồi các cặp trường/giá trị
+var storeReactionCountsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 or (redis.call('GET', KEYS[2]) or '') ~= ARGV[1] then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 3))
+redis.call('EXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// Counts đọc bộ đếm từ Redis; các nội dung chưa có cache được đếm lại bằng một truy vấn rồi lưu vào cache.
+// Nội dung không có tương tác nào vẫn có mặt trong kết quả với bộ đếm rỗng.
+func (s *reactionCounter) Counts(targetType models.ReactionTargetType, targetIDs []uint) map[uint]models.ReactionCounts {
+	result := make(map[uint]models.ReactionCounts, len(targetIDs))
+	if len(targetIDs) == 0 {
+		return result
+	}
+	ctx := context.Background()
+
+	missing := targetIDs
+	if s.redisClient != nil {
+		pipe := s.redisClient.Pipeline()
+		cmds := make([]*redis.StringStringMapCmd, len(targetIDs))
+		for i, id := range targetIDs {
+			cmds[i] = pipe.HGetAll(ctx, reactionCountsKey(targetType, id))
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			log.Printf("Failed to read reaction counts for %s: %v", targetType, err)
+		}
+		missing = nil
+		for i, id := range targetIDs {
+			cached, err := cmds[i].Result()
+			if err != nil || len(cached) == 0 {
+				missing = append(missing, id)
+				continue
+			}
+			counts := models.ReactionCounts{}
+			for field, value := range cached {
+				if field == reactionTotalField {
+					continue
+				}
+				if n, err := strconv.ParseInt(value, 10, 64); err == nil && n > 0 {
+					counts[field] = n
+				}
+			}
+			result[id] = counts
+		}
+	}
+	if len(missing) == 0 {
+		return result
+	}
+
+	for _, id := range missing {
+		result[id] = models.ReactionCounts{}
+	}
+	// Thế hệ phải được đọc trước khi đếm trong DB để nhận ra Adjust xảy ra trong lúc đếm
+	var gens []*redis.StringCmd
+	if s.redisClient != nil {
+		pipe := s.redisClient.Pipeline()
+		for _, id := range missing {
+			gens = append(gens, pipe.Get(ctx, reactionCountsGenKey(targetType, id)))
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			log.Printf("Failed to read reaction count generations for %s: %v", targetType, err)
+		}
+	}
+	rows, err := s.reactionRepo.CountByTargets(targetType, missing)
+	if err != nil {
+		log.Printf("Failed to count reactions for %s: %v", targetType, err)
+		return result
+	}
+	for _, row := range rows {
+		result[row.TargetID][row.Type] = row.Count
+	}
+	if s.redisClient == nil {
+		return result
+	}
+
+	pipe := s.redisClient.Pipeline()
+	for i, id := range missing {
+		gen, err := gens[i].Result()
+		if err != nil && err != redis.Nil {
+			continue
+		}
+		counts := result[id]
+		args := []interface{}{gen, int(reactionCountsTTL.Seconds()), reactionTotalField, counts.Total()}
+		for reactionType, count := range counts {
+			args = append(args, reactionType, count)
+		}
+		// Eval thay cho Run: trong pipeline không biết được lỗi NOSCRIPT để gửi lại cả script
+		storeReactionCountsScript.Eval(ctx, pipe, []string{reactionCountsKey(targetType, id), reactionCountsGenKey(targetType, id)}, args...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		log.Printf("Failed to cache reaction counts for %s: %v", targetType, err)
+	}
+	return result
+}
+
+// Adjust cập nhật nguyên tử bộ đếm trong Redis khi đã có cache; nếu chưa có thì lần đọc sau sẽ đếm lại từ DB
+func (s *reactionCounter) Adjust(targetType models.ReactionTargetType, targetID uint, reactionType string, delta int64) {
+	if s.redisClient == nil {
+		return
+	}
+	ctx := context.Background()
+	key := reactionCountsKey(targetType, targetID)
+
+	err := adjustReactionCountsScript.Run(ctx, s.redisClient, []string{key, reactionCountsGenKey(targetType, targetID)},
+		reactionType, delta, int(reactionCountsTTL.Seconds())).Err()
+	if err != nil {
+		log.Printf("Failed to update reaction counts %s, dropping cache: %v", key, err)
+		s.redisClient.Del(ctx, key)
+	}
+}
@@ -11,20 +11,20 @@ import (
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"log"
-	"strconv"
 	"strings"
 	"time"
 )
 
 type ReactionService interface {
-	CreateReaction(userID uint, postID, commentID, answerID *uint) (*models.Reaction, error)
+	CreateReaction(userID uint, reactionType string, postID, commentID, answerID, questionID *uint) (*models.Reaction, error)
 	GetReactionByID(id uint) (*models.Reaction, error)
-	UpdateReaction(id, userID uint, postID, commentID, answerID *uint) (*models.Reaction, error)
+	UpdateReaction(id, userID uint, reactionType string, postID, commentID, answerID, questionID *uint) (*models.Reaction, error)
 	DeleteReaction(id, userID uint) error
 	ListReactions(filters map[string]interface{}) ([]models.Reaction, int, error)
-	GetReactionCount(postID, commentID, answerID *uint) (int64, error)
-	CheckUserReaction(userID uint, postID, commentID, answerID *uint) (bool, *models.Reaction, error)
-	ValidateReactionID(postID, commentID, answerID *uint) error
+	GetReactionCount(postID, commentID, answerID, questionID *uint) (models.ReactionCounts, error)
+	CheckUserReaction(userID uint, postID, commentID, answerID, questionID *uint) (bool, *models.Reaction, error)
+	ValidateReactionID(postID, commentID, answerID, questionID *uint) error
+	ReactionTypes() []ReactionType
 }
 
 type reactionService struct {
@@ -32,12 +32,14 @@ type reactionService struct {
 	answerRepo   repositories.AnswerRepository
 	postRepo     repositories.PostRepository
 	commentRepo  repositories.CommentRepository
+	questionRepo repositories.QuestionRepository
 	userRepo     repositories.UserRepository // Thêm UserRepository
+	counter      ReactionCounter
 	redisClient  *redis.Client
 	novuClient   *notification.NovuClient // Thêm NovuClient
 }
 
-func NewReactionService(repo repositories.ReactionRepository, userRepo repositories.UserRepository, answerRepo repositories.AnswerRepository, postRepo repositories.PostRepository, commentRepo repositories.CommentRepository, redisClient *redis.Client, novuClient *notification.NovuClient) ReactionService {
+func NewReactionService(repo repositories.ReactionRepository, userRepo repositories.UserRepository, answerRepo repositories.AnswerRepository, postRepo repositories.PostRepository, commentRepo repositories.CommentRepository, questionRepo repositories.QuestionRepository, counter ReactionCounter, redisClient *redis.Client, novuClient *notification.NovuClient) ReactionService {
 	if repo == nil {
 		log.Fatal("reaction repository is nil")
 	}
@@ -56,11 +58,17 @@ func NewReactionService(repo repositories.ReactionRepository, userRepo repositor
 	if commentRepo == nil {
 		log.Fatal("user repository is nil")
 	}
+	if questionRepo == nil {
+		log.Fatal("question repository is nil")
+	}
+	if counter == nil {
+		log.Fatal("reaction counter is nil")
+	}
 	if novuClient == nil {
 		log.Fatal("novu client is nil")
 	}
 	log.Printf("Initialized ReactionService with repo: %v, userRepo: %v, redis: %v, novu: %v", repo != nil, userRepo != nil, redisClient != nil, novuClient != nil)
-	return &reactionService{reactionRepo: repo, userRepo: userRepo, answerRepo: answerRepo, postRepo: postRepo, commentRepo: commentRepo, redisClient: redisClient, novuClient: novuClient}
+	return &reactionService{reactionRepo: repo, userRepo: userRepo, answerRepo: answerRepo, postRepo: postRepo, commentRepo: commentRepo, questionRepo: questionRepo, counter: counter, redisClient: redisClient, novuClient: novuClient}
 }
 
 type ReactionListResponse struct {
@@ -68,19 +76,44 @@ type ReactionListResponse struct {
 	Total     int               `json:"total"`
 }
 
-func (s *reactionService) CreateReaction(userID uint, postID, commentID, answerID *uint) (*models.Reaction, error) {
-	if err := s.reactionRepo.ValidateReactionID(postID, commentID, answerID); err != nil {
+// normalizeReactionType trả về loại tương tác hợp lệ; để trống nghĩa là "like"
+func normalizeReactionType(reactionType string) (string, error) {
+	reactionType = strings.ToLower(strings.TrimSpace(reactionType))
+	if reactionType == "" {
+		return models.ReactionLike, nil
+	}
+	if _, ok := findReactionType(reactionType); !ok {
+		return "", fmt.Errorf("unsupported reaction type: %s", reactionType)
+	}
+	return reactionType, nil
+}
+
+func userReactionCacheKey(userID uint, targetType models.ReactionTargetType, targetID uint) string {
+	return fmt.Sprintf("user_reaction:%d:%s:%d", userID, targetType, targetID)
+}
+
+func (s *reactionService) CreateReaction(userID uint, reactionType string, postID, commentID, answerID, questionID *uint) (*models.Reaction, error) {
+	reactionType, err := normalizeReactionType(reactionType)
+	if err != nil {
 		return nil, err
 	}
-	filters := map[string]interface{}{
-		"user_id": userID,
+	if err := s.reactionRepo.ValidateReactionID(postID, commentID, answerID, questionID); err != nil {
+		return nil, err
 	}
-	if postID != nil {
-		filters["post_id"] = *postID
-	} else if commentID != nil {
-		filters["comment_id"] = *commentID
-	} else if answerID != nil {
-		filters["answer_id"] = *answerID
+	reaction := &models.Reaction{
+		UserID:     userID,
+		Type:       reactionType,
+		PostID:     postID,
+		CommentID:  commentID,
+		AnswerID:   answerID,
+		QuestionID: questionID,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	targetType, targetID := reaction.Target()
+	filters := map[string]interface{}{
+		"user_id":           userID,
+		targetType.Column(): targetID,
 	}
 	existingReactions, _, err := s.reactionRepo.ListReactions(filters)
 	if err != nil {
@@ -88,73 +121,84 @@ func (s *reactionService) CreateReaction(userID uint, postID, commentID, answerI
 		return nil, err
 	}
 	if len(existingReactions) > 0 {
-		return nil, errors.New("reaction already exists for this user and entity")
-	}
-	reaction := &models.Reaction{
-		UserID:    userID,
-		PostID:    postID,
-		CommentID: commentID,
-		AnswerID:  answerID,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		existing := &existingReactions[0]
+		if existing.Type == reactionType {
+			return nil, errors.New("reaction already exists for this user and entity")
+		}
+		// Mỗi người chỉ có một tương tác trên một nội dung: chọn loại khác là đổi loại
+		return s.changeReaction(existing, reactionType, nil)
 	}
 	if err := s.reactionRepo.CreateReaction(reaction); err != nil {
 		log.Printf("Failed to create reaction: %v", err)
 		return nil, err
 	}
-	s.updateCacheAfterCreate(reaction, postID, commentID, answerID)
-	cacheKey := fmt.Sprintf("user_reaction:%d:%s", userID, generateCacheKey(filters))
-	s.invalidateCache(cacheKey)
-	s.invalidateCache(fmt.Sprintf("reaction:%d", reaction.ID))
-	if postID != nil {
-		s.invalidateCache(fmt.Sprintf("reactions:post:%d:*", *postID))
-		s.invalidateCache(fmt.Sprintf("reaction_count:post:%d", *postID))
-	} else if commentID != nil {
-		s.invalidateCache(fmt.Sprintf("reactions:comment:%d:*", *commentID))
-		s.invalidateCache(fmt.Sprintf("reaction_count:comment:%d", *commentID))
-	} else if answerID != nil {
-		s.invalidateCache(fmt.Sprintf("reactions:answer:%d:*", *answerID))
-		s.invalidateCache(fmt.Sprintf("reaction_count:answer:%d", *answerID))
-	}
-	s.invalidateCache("reactions:all:*")
+	s.counter.Adjust(targetType, targetID, reaction.Type, 1)
+	s.invalidateReactionCaches(reaction)
 
 	// Send notification
 	reactor, err := s.userRepo.GetUserByID(userID)
 	if err != nil {
 		log.Printf("Không lấy được thông tin người phản ứng: %v", err)
 	} else {
-		if postID != nil {
-			// Lấy thông tin post để gửi thông báo cho chủ sở hữu
-			post, err := s.postRepo.GetPostByID(*postID) // Giả sử có method này trong repository
-			if err == nil && post.UserID != userID {
-				workflowID := "new-post-reaction-notification"
-				message := fmt.Sprintf("%s đã thích bài viết của bạn: %s", reactor.FullName, post.Title)
-				if err := s.novuClient.SendNotification(post.UserID, workflowID, message); err != nil {
-					log.Printf("Gửi thông báo phản ứng bài viết thất bại: %v", err)
-				}
+		s.notifyReaction(reaction, reactor.FullName)
+	}
+
+	return reaction, nil
+}
+
+// reactionVerb là cụm động từ dùng trong thông báo, giữ nguyên "đã thích" cho loại mặc định
+func reactionVerb(reactionType string) string {
+	if reactionType == models.ReactionLike {
+		return "đã thích"
+	}
+	label := reactionType
+	if t, ok := findReactionType(reactionType); ok {
+		label = strings.TrimSpace(t.Emoji + " " + t.Label)
+	}
+	return fmt.Sprintf("đã bày tỏ cảm xúc \"%s\" với", label)
+}
+
+func (s *reactionService) notifyReaction(reaction *models.Reaction, reactorName string) {
+	verb := reactionVerb(reaction.Type)
+	userID := reaction.UserID
+	if reaction.PostID != nil {
+		// Lấy thông tin post để gửi thông báo cho chủ sở hữu
+		post, err := s.postRepo.GetPostByID(*reaction.PostID)
+		if err == nil && post.UserID != userID {
+			workflowID := "new-post-reaction-notification"
+			message := fmt.Sprintf("%s %s bài viết của bạn: %s", reactorName, verb, post.Title)
+			if err := s.novuClient.SendNotification(post.UserID, workflowID, message); err != nil {
+				log.Printf("Gửi thông báo phản ứng bài viết thất bại: %v", err)
 			}
-		} else if commentID != nil {
-			comment, err := s.commentRepo.GetCommentByID(*commentID)
-			if err == nil && comment.UserID != userID {
-				workflowID := "new-comment-reaction-notification"
-				message := fmt.Sprintf("%s đã thích bình luận của bạn: %s", reactor.FullName, utils.StripHTML(comment.Content))
-				if err := s.novuClient.SendNotification(comment.UserID, workflowID, message); err != nil {
-					log.Printf("Gửi thông báo thích bình luận thất bại: %v", err)
-				}
+		}
+	} else if reaction.CommentID != nil {
+		comment, err := s.commentRepo.GetCommentByID(*reaction.CommentID)
+		if err == nil && comment.UserID != userID {
+			workflowID := "new-comment-reaction-notification"
+			message := fmt.Sprintf("%s %s bình luận của bạn: %s", reactorName, verb, utils.StripHTML(comment.Content))
+			if err := s.novuClient.SendNotification(comment.UserID, workflowID, message); err != nil {
+				log.Printf("Gửi thông báo thích bình luận thất bại: %v", err)
+			}
+		}
+	} else if reaction.AnswerID != nil {
+		answer, err := s.answerRepo.GetAnswerByID(*reaction.AnswerID)
+		if err == nil && answer.UserID != userID {
+			workflowID := "new-answer-reaction-notification"
+			message := fmt.Sprintf("%s %s câu trả lời của bạn: %s", reactorName, verb, answer.Title)
+			if err := s.novuClient.SendNotification(answer.UserID, workflowID, message); err != nil {
+				log.Printf("Gửi thông báo thích câu trả lời thất bại: %v", err)
 			}
-		} else if answerID != nil {
-			answer, err := s.answerRepo.GetAnswerByID(*answerID)
-			if err == nil && answer.UserID != userID {
-				workflowID := "new-answer-reaction-notification"
-				message := fmt.Sprintf("%s đã thích câu trả lời của bạn: %s", reactor.FullName, answer.Title)
-				if err := s.novuClient.SendNotification(answer.UserID, workflowID, message); err != nil {
-					log.Printf("Gửi thông báo thích câu trả lời thất bại: %v", err)
-				}
+		}
+	} else if reaction.QuestionID != nil {
+		question, err := s.questionRepo.GetQuestionByIDMinimal(*reaction.QuestionID)
+		if err == nil && question.UserID != userID {
+			workflowID := "new-question-reaction-notification"
+			message := fmt.Sprintf("%s %s câu hỏi của bạn: %s", reactorName, verb, question.Title)
+			if err := s.novuClient.SendNotification(question.UserID, workflowID, message); err != nil {
+				log.Printf("Gửi thông báo tương tác câu hỏi thất bại: %v", err)
 			}
 		}
 	}
-
-	return reaction, nil
 }
 
 func (s *reactionService) GetReactionByID(id uint) (*models.Reaction, error) {
@@ -188,7 +232,8 @@ func (s *reactionService) GetReactionByID(id uint) (*models.Reaction, error) {
 	return reaction, nil
 }
 
-func (s *reactionService) UpdateReaction(id, userID uint, postID, commentID, answerID *uint) (*models.Reaction, error) {
+// UpdateReaction đổi loại và/hoặc nội dung của tương tác; để trống loại hoặc không truyền ID nào thì giữ nguyên
+func (s *reactionService) UpdateReaction(id, userID uint, reactionType string, postID, commentID, answerID, questionID *uint) (*models.Reaction, error) {
 	reaction, err := s.reactionRepo.GetReactionByID(id)
 	if err != nil {
 		log.Printf("Failed to get reaction %d: %v", id, err)
@@ -200,29 +245,50 @@ func (s *reactionService) UpdateReaction(id, userID uint, postID, commentID, ans
 	if reaction.UserID != userID {
 		return nil, errors.New("unauthorized: cannot update another user's reaction")
 	}
-	if err := s.reactionRepo.ValidateReactionID(postID, commentID, answerID); err != nil {
+	if strings.TrimSpace(reactionType) == "" {
+		reactionType = reaction.Type
+	}
+	reactionType, err = normalizeReactionType(reactionType)
+	if err != nil {
+		return nil, err
+	}
+	target := &models.Reaction{PostID: postID, CommentID: commentID, AnswerID: answerID, QuestionID: questionID}
+	if targetType, _ := target.Target(); targetType == "" {
+		target = nil
+	} else if err := s.reactionRepo.ValidateReactionID(postID, commentID, answerID, questionID); err != nil {
 		return nil, err
 	}
-	reaction.PostID = postID
-	reaction.CommentID = commentID
-	reaction.AnswerID = answerID
+	return s.changeReaction(reaction, reactionType, target)
+}
+
+// changeReaction lưu loại mới (và nội dung mới nếu target khác nil) rồi chuyển bộ đếm từ giá trị cũ sang giá trị mới
+func (s *reactionService) changeReaction(reaction *models.Reaction, reactionType string, target *models.Reaction) (*models.Reaction, error) {
+	oldType := reaction.Type
+	oldTargetType, oldTargetID := reaction.Target()
+	old := *reaction
+
+	reaction.Type = reactionType
+	if target != nil {
+		reaction.PostID = target.PostID
+		reaction.CommentID = target.CommentID
+		reaction.AnswerID = target.AnswerID
+		reaction.QuestionID = target.QuestionID
+		// Bỏ các quan hệ đã preload để Save không ghi đè lại khoá ngoại cũ
+		reaction.Post, reaction.Comment, reaction.Answer, reaction.Question = nil, nil, nil, nil
+	}
 	reaction.UpdatedAt = time.Now()
 	if err := s.reactionRepo.UpdateReaction(reaction); err != nil {
-		log.Printf("Failed to update reaction %d: %v", id, err)
+		log.Printf("Failed to update reaction %d: %v", reaction.ID, err)
 		return nil, err
 	}
-	s.invalidateCache(fmt.Sprintf("reaction:%d", id))
-	if postID != nil {
-		s.invalidateCache(fmt.Sprintf("reactions:post:%d:*", *postID))
-		s.invalidateCache(fmt.Sprintf("reaction_count:post:%d", *postID))
-	} else if commentID != nil {
-		s.invalidateCache(fmt.Sprintf("reactions:comment:%d:*", *commentID))
-		s.invalidateCache(fmt.Sprintf("reaction_count:comment:%d", *commentID))
-	} else if answerID != nil {
-		s.invalidateCache(fmt.Sprintf("reactions:answer:%d:*", *answerID))
-		s.invalidateCache(fmt.Sprintf("reaction_count:answer:%d", *answerID))
+
+	targetType, targetID := reaction.Target()
+	if oldType != reaction.Type || oldTargetType != targetType || oldTargetID != targetID {
+		s.counter.Adjust(oldTargetType, oldTargetID, oldType, -1)
+		s.counter.Adjust(targetType, targetID, reaction.Type, 1)
 	}
-	s.invalidateCache("reactions:all:*")
+	s.invalidateReactionCaches(&old)
+	s.invalidateReactionCaches(reaction)
 	return reaction, nil
 }
 
@@ -242,34 +308,9 @@ func (s *reactionService) DeleteReaction(id, userID uint) error {
 		log.Printf("Failed to delete reaction %d: %v", id, err)
 		return err
 	}
-	filters := map[string]interface{}{
-		"user_id": userID,
-	}
-	if reaction.PostID != nil {
-		filters["post_id"] = *reaction.PostID
-		cacheKey := fmt.Sprintf("user_reaction:%d:%s", userID, generateCacheKey(filters))
-		s.invalidateCache(cacheKey)
-		s.invalidateCache(fmt.Sprintf("reaction_count:post:%d", *reaction.PostID))
-	} else if reaction.CommentID != nil {
-		filters["comment_id"] = *reaction.CommentID
-		cacheKey := fmt.Sprintf("user_reaction:%d:%s", userID, generateCacheKey(filters))
-		s.invalidateCache(cacheKey)
-		s.invalidateCache(fmt.Sprintf("reaction_count:comment:%d", *reaction.CommentID))
-	} else if reaction.AnswerID != nil {
-		filters["answer_id"] = *reaction.AnswerID
-		cacheKey := fmt.Sprintf("user_reaction:%d:%s", userID, generateCacheKey(filters))
-		s.invalidateCache(cacheKey)
-		s.invalidateCache(fmt.Sprintf("reaction_count:answer:%d", *reaction.AnswerID))
-	}
-	s.invalidateCache(fmt.Sprintf("reaction:%d", id))
-	if reaction.PostID != nil {
-		s.invalidateCache(fmt.Sprintf("reactions:post:%d:*", *reaction.PostID))
-	} else if reaction.CommentID != nil {
-		s.invalidateCache(fmt.Sprintf("reactions:comment:%d:*", *reaction.CommentID))
-	} else if reaction.AnswerID != nil {
-		s.invalidateCache(fmt.Sprintf("reactions:answer:%d:*", *reaction.AnswerID))
-	}
-	s.invalidateCache("reactions:all:*")
+	targetType, targetID := reaction.Target()
+	s.counter.Adjust(targetType, targetID, reaction.Type, -1)
+	s.invalidateReactionCaches(reaction)
 	return nil
 }
 
@@ -307,69 +348,24 @@ func (s *reactionService) ListReactions(filters map[string]interface{}) ([]model
 	return reactions, total, nil
 }
 
-func (s *reactionService) GetReactionCount(postID, commentID, answerID *uint) (int64, error) {
-	count := 0
-	if postID != nil {
-		count++
-	}
-	if commentID != nil {
-		count++
-	}
-	if answerID != nil {
-		count++
-	}
-	if count != 1 {
-		return 0, errors.New("exactly one of post_id, comment_id, or answer_id must be provided")
-	}
-	if err := s.reactionRepo.ValidateReactionID(postID, commentID, answerID); err != nil {
-		return 0, err
-	}
-	var cacheKey string
-	if postID != nil {
-		cacheKey = fmt.Sprintf("reaction_count:post:%d", *postID)
-	} else if commentID != nil {
-		cacheKey = fmt.Sprintf("reaction_count:comment:%d", *commentID)
-	} else if answerID != nil {
-		cacheKey = fmt.Sprintf("reaction_count:answer:%d", *answerID)
-	}
-	ctx := context.Background()
-	cached, err := s.redisClient.Get(ctx, cacheKey).Result()
-	if err == nil {
-		countVal, err := strconv.ParseInt(cached, 10, 64)
-		if err == nil {
-			log.Printf("Cache hit for %s", cacheKey)
-			return countVal, nil
-		}
-	}
-	if err != redis.Nil {
-		log.Printf("Redis error for %s: %v", cacheKey, err)
-	}
-	countVal, err := s.reactionRepo.GetReactionCount(postID, commentID, answerID)
-	if err != nil {
-		log.Printf("Failed to get reaction count: %v", err)
-		return 0, err
-	}
-	if err := s.redisClient.Set(ctx, cacheKey, fmt.Sprintf("%d", countVal), 2*time.Minute).Err(); err != nil {
-		log.Printf("Failed to set cache for %s: %v", cacheKey, err)
+func (s *reactionService) GetReactionCount(postID, commentID, answerID, questionID *uint) (models.ReactionCounts, error) {
+	if err := s.reactionRepo.ValidateReactionID(postID, commentID, answerID, questionID); err != nil {
+		return nil, err
 	}
-	return countVal, nil
+	targetType, targetID := (&models.Reaction{PostID: postID, CommentID: commentID, AnswerID: answerID, QuestionID: questionID}).Target()
+	return s.counter.Counts(targetType, []uint{targetID})[targetID], nil
 }
 
-func (s *reactionService) CheckUserReaction(userID uint, postID, commentID, answerID *uint) (bool, *models.Reaction, error) {
-	if err := s.reactionRepo.ValidateReactionID(postID, commentID, answerID); err != nil {
+func (s *reactionService) CheckUserReaction(userID uint, postID, commentID, answerID, questionID *uint) (bool, *models.Reaction, error) {
+	if err := s.reactionRepo.ValidateReactionID(postID, commentID, answerID, questionID); err != nil {
 		return false, nil, err
 	}
+	targetType, targetID := (&models.Reaction{PostID: postID, CommentID: commentID, AnswerID: answerID, QuestionID: questionID}).Target()
 	filters := map[string]interface{}{
-		"user_id": userID,
-	}
-	if postID != nil {
-		filters["post_id"] = *postID
-	} else if commentID != nil {
-		filters["comment_id"] = *commentID
-	} else if answerID != nil {
-		filters["answer_id"] = *answerID
+		"user_id":           userID,
+		targetType.Column(): targetID,
 	}
-	cacheKey := fmt.Sprintf("user_reaction:%d:%s", userID, generateCacheKey(filters))
+	cacheKey := userReactionCacheKey(userID, targetType, targetID)
 	ctx := context.Background()
 	cached, err := s.redisClient.Get(ctx, cacheKey).Result()
 	if err == nil {
@@ -399,6 +395,10 @@ func (s *reactionService) CheckUserReaction(userID uint, postID, commentID, answ
 	return false, nil, nil
 }
 
+func (s *reactionService) ReactionTypes() []ReactionType {
+	return ReactionTypes()
+}
+
 func (s *reactionService) validateReactionsUserData(reactions []models.Reaction) bool {
 	for _, reaction := range reactions {
 		if reaction.User.ID == 0 || reaction.DeletedAt.Valid {
@@ -409,39 +409,13 @@ func (s *reactionService) validateReactionsUserData(reactions []models.Reaction)
 	return true
 }
 
-func (s *reactionService) updateCacheAfterCreate(reaction *models.Reaction, postID, commentID, answerID *uint) {
-	var cachePattern string
-	if postID != nil {
-		cachePattern = fmt.Sprintf("reactions:post:%d:*", *postID)
-	} else if commentID != nil {
-		cachePattern = fmt.Sprintf("reactions:comment:%d:*", *commentID)
-	} else if answerID != nil {
-		cachePattern = fmt.Sprintf("reactions:answer:%d:*", *answerID)
-	}
-	ctx := context.Background()
-	pipe := s.redisClient.Pipeline()
-	keys, err := s.redisClient.Keys(ctx, cachePattern).Result()
-	if err != nil {
-		log.Printf("Failed to get cache keys for pattern %s: %v", cachePattern, err)
-		return
-	}
-	for _, key := range keys {
-		cached, err := s.redisClient.Get(ctx, key).Result()
-		if err == nil {
-			var response ReactionListResponse
-			if err := json.Unmarshal([]byte(cached), &response); err == nil {
-				response.Reactions = append([]models.Reaction{*reaction}, response.Reactions...)
-				response.Total++
-				data, err := json.Marshal(response)
-				if err == nil {
-					pipe.Set(ctx, key, data, 2*time.Minute)
-				}
-			}
-		}
-	}
-	if _, err := pipe.Exec(ctx); err != nil {
-		log.Printf("Failed to update cache for pattern %s: %v", cachePattern, err)
-	}
+// invalidateReactionCaches xoá các cache liên quan tới một tương tác; bộ đếm theo loại được cập nhật riêng qua counter
+func (s *reactionService) invalidateReactionCaches(reaction *models.Reaction) {
+	targetType, targetID := reaction.Target()
+	s.invalidateCache(userReactionCacheKey(reaction.UserID, targetType, targetID))
+	s.invalidateCache(fmt.Sprintf("reaction:%d", reaction.ID))
+	s.invalidateCache(fmt.Sprintf("reactions:%s:%d:*", targetType, targetID))
+	s.invalidateCache("reactions:all:*")
 }
 
 func (s *reactionService) invalidateCache(pattern string) {
@@ -472,6 +446,6 @@ func generateCacheKey(filters map[string]interface{}) string {
 	return strings.Join(keys, ":")
 }
 
-func (s *reactionService) ValidateReactionID(postID, commentID, answerID *uint) error {
-	return s.reactionRepo.ValidateReactionID(postID, commentID, answerID)
+func (s *reactionService) ValidateReactionID(postID, commentID, answerID, questionID *uint) error {
+	return s.reactionRepo.ValidateReactionID(postID, commentID, answerID, questionID)
 }
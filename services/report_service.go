@@ -101,7 +101,8 @@ func (s *reportService) CreateReport(reason string, reporterID uint, contentType
 		ID:             fmt.Sprintf("report-%d-%d", time.Now().Unix(), reporterID),
 		Reason:         reason,
 		Details:        details,
-		ReporterID:     reporterID,
+		ReporterID:     &reporterID,
+		Source:         models.ReportSourceUser,
 		ContentType:    contentType,
 		ContentID:      contentID,
 		ContentPreview: contentPreview,
@@ -127,7 +128,7 @@ func (s *reportService) GetReportByID(id string) (*models.Report, error) {
 		if err == nil {
 			var cachedData struct {
 				Report     models.Report
-				Reporter   models.User
+				Reporter   *models.User
 				ResolvedBy *models.User
 			}
 			if err := json.Unmarshal([]byte(cached), &cachedData); err == nil {
@@ -154,7 +155,7 @@ func (s *reportService) GetReportByID(id string) (*models.Report, error) {
 
 	data, err := json.Marshal(struct {
 		Report     models.Report
-		Reporter   models.User
+		Reporter   *models.User
 		ResolvedBy *models.User
 	}{*report, report.Reporter, report.ResolvedBy})
 	if err == nil {
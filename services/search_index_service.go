@@ -0,0 +1,272 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"Forum_BE/searchindex"
+	"errors"
+	"log"
+	"sync"
+)
+
+// Số bản ghi nạp mỗi lượt khi reindex
+const reindexBatchSize = 500
+
+type SearchIndexService interface {
+	SyncQuestion(id uint)
+	SyncAnswer(id uint)
+	SyncPost(id uint)
+	Search(q searchindex.Query) (*searchindex.Result, error)
+	Reindex() (int, error)
+	Enabled() bool
+	Close() error
+}
+
+type searchIndexService struct {
+	searchRepo repositories.SearchRepository
+	index      searchindex.SearchIndex
+
+	// Trong lúc reindex, pending ghi lại các nội dung được đồng bộ để phát lại sau khi Rebuild
+	// thay toàn bộ chỉ mục; nếu không các thay đổi xảy ra trong lúc quét DB sẽ bị mất
+	mu         sync.Mutex
+	reindexing bool
+	pending    map[string]map[uint]struct{}
+}
+
+// NewSearchIndexService nhận index có thể nil (khi không mở được chỉ mục);
+// lúc đó mọi thao tác đồng bộ đều bị bỏ qua.
+func NewSearchIndexService(searchRepo repositories.SearchRepository, index searchindex.SearchIndex) SearchIndexService {
+	return &searchIndexService{searchRepo: searchRepo, index: index}
+}
+
+func (s *searchIndexService) Enabled() bool {
+	return s.index != nil
+}
+
+// Close ghi chỉ mục xuống đĩa và nhả khoá thư mục; gọi một lần khi server dừng
+func (s *searchIndexService) Close() error {
+	if s.index == nil {
+		return nil
+	}
+	return s.index.Close()
+}
+
+// SyncQuestion đọc lại câu hỏi từ DB: nếu còn tồn tại và đã duyệt thì index, ngược lại xoá khỏi chỉ mục
+func (s *searchIndexService) SyncQuestion(id uint) {
+	if s.index == nil {
+		return
+	}
+	s.track(repositories.SearchTypeQuestion, id)
+	question, err := s.searchRepo.GetQuestionForIndex(id)
+	if err != nil || question.Status != models.StatusApproved || question.PublishAt != nil {
+		s.remove(repositories.SearchTypeQuestion, id)
+	} else {
+		s.put(questionDocument(question))
+	}
+
+	// Câu trả lời chỉ được tìm thấy khi câu hỏi cha hiển thị và mang topic của câu hỏi cha
+	answerIDs, err := s.searchRepo.ListAnswerIDsForQuestion(id)
+	if err != nil {
+		log.Printf("Failed to list answers of question %d for search index: %v", id, err)
+		return
+	}
+	for _, answerID := range answerIDs {
+		s.SyncAnswer(answerID)
+	}
+}
+
+func (s *searchIndexService) SyncAnswer(id uint) {
+	if s.index == nil {
+		return
+	}
+	s.track(repositories.SearchTypeAnswer, id)
+	answer, err := s.searchRepo.GetAnswerForIndex(id)
+	if err != nil || answer.Status != "approved" || !isQuestionVisible(&answer.Question) {
+		s.remove(repositories.SearchTypeAnswer, id)
+		return
+	}
+	s.put(answerDocument(answer))
+}
+
+func (s *searchIndexService) SyncPost(id uint) {
+	if s.index == nil {
+		return
+	}
+	s.track(repositories.SearchTypePost, id)
+	post, err := s.searchRepo.GetPostForIndex(id)
+	if err != nil || post.Status != models.Approved || post.PublishAt != nil {
+		s.remove(repositories.SearchTypePost, id)
+		return
+	}
+	s.put(postDocument(post))
+}
+
+// isQuestionVisible cho biết câu hỏi còn tồn tại, đã duyệt và không còn hẹn giờ đăng
+func isQuestionVisible(question *models.Question) bool {
+	return question.ID != 0 && question.Status == models.StatusApproved && question.PublishAt == nil
+}
+
+// track ghi lại nội dung vừa được đồng bộ nếu đang reindex
+func (s *searchIndexService) track(docType string, id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.reindexing {
+		return
+	}
+	if s.pending[docType] == nil {
+		s.pending[docType] = make(map[uint]struct{})
+	}
+	s.pending[docType][id] = struct{}{}
+}
+
+func (s *searchIndexService) put(doc searchindex.Document) {
+	if err := s.index.Index(doc); err != nil {
+		log.Printf("Failed to index %s %d: %v", doc.Type, doc.ID, err)
+	}
+}
+
+func (s *searchIndexService) remove(docType string, id uint) {
+	if err := s.index.Delete(docType, id); err != nil {
+		log.Printf("Failed to remove %s %d from search index: %v", docType, id, err)
+	}
+}
+
+func (s *searchIndexService) Search(q searchindex.Query) (*searchindex.Result, error) {
+	if s.index == nil {
+		return &searchindex.Result{}, nil
+	}
+	return s.index.Search(q)
+}
+
+// Reindex dựng lại toàn bộ chỉ mục từ câu hỏi, câu trả lời và bài viết đã duyệt.
+// Các nội dung được đồng bộ trong lúc quét DB được đọc lại và ghi vào chỉ mục mới sau khi Rebuild.
+func (s *searchIndexService) Reindex() (int, error) {
+	if s.index == nil {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	if s.reindexing {
+		s.mu.Unlock()
+		return 0, errors.New("reindex is already running")
+	}
+	s.reindexing = true
+	s.pending = make(map[string]map[uint]struct{})
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.reindexing = false
+		s.pending = nil
+		s.mu.Unlock()
+	}()
+
+	var docs []searchindex.Document
+	var afterID uint
+	for {
+		questions, err := s.searchRepo.ListQuestionsForIndex(afterID, reindexBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		for i := range questions {
+			docs = append(docs, questionDocument(&questions[i]))
+			afterID = questions[i].ID
+		}
+		if len(questions) < reindexBatchSize {
+			break
+		}
+	}
+
+	afterID = 0
+	for {
+		answers, err := s.searchRepo.ListAnswersForIndex(afterID, reindexBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		for i := range answers {
+			docs = append(docs, answerDocument(&answers[i]))
+			afterID = answers[i].ID
+		}
+		if len(answers) < reindexBatchSize {
+			break
+		}
+	}
+
+	afterID = 0
+	for {
+		posts, err := s.searchRepo.ListPostsForIndex(afterID, reindexBatchSize)
+		if err != nil {
+			return 0, err
+		}
+		for i := range posts {
+			docs = append(docs, postDocument(&posts[i]))
+			afterID = posts[i].ID
+		}
+		if len(posts) < reindexBatchSize {
+			break
+		}
+	}
+
+	if err := s.index.Rebuild(docs); err != nil {
+		return 0, err
+	}
+
+	// Từ đây các lần đồng bộ ghi thẳng vào chỉ mục mới; chỉ cần phát lại những gì đã ghi nhận
+	s.mu.Lock()
+	pending := s.pending
+	s.reindexing = false
+	s.pending = nil
+	s.mu.Unlock()
+	for id := range pending[repositories.SearchTypeQuestion] {
+		s.SyncQuestion(id)
+	}
+	for id := range pending[repositories.SearchTypeAnswer] {
+		s.SyncAnswer(id)
+	}
+	for id := range pending[repositories.SearchTypePost] {
+		s.SyncPost(id)
+	}
+	log.Printf("Search index rebuilt with %d documents", len(docs))
+	return len(docs), nil
+}
+
+func questionDocument(q *models.Question) searchindex.Document {
+	return searchindex.Document{
+		Type:      repositories.SearchTypeQuestion,
+		ID:        q.ID,
+		Title:     q.Title,
+		Body:      q.PlainContent,
+		TopicID:   q.TopicID,
+		CreatedAt: q.CreatedAt,
+	}
+}
+
+func answerDocument(a *models.Answer) searchindex.Document {
+	return searchindex.Document{
+		Type:      repositories.SearchTypeAnswer,
+		ID:        a.ID,
+		Title:     a.Title,
+		Body:      a.PlainContent,
+		TopicID:   a.Question.TopicID,
+		TagIDs:    tagIDs(a.Tags),
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+func postDocument(p *models.Post) searchindex.Document {
+	return searchindex.Document{
+		Type:      repositories.SearchTypePost,
+		ID:        p.ID,
+		Title:     p.Title,
+		Body:      p.PlainContent,
+		TagIDs:    tagIDs(p.Tags),
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+func tagIDs(tags []models.Tag) []uint {
+	ids := make([]uint, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+	return ids
+}
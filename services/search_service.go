@@ -0,0 +1,215 @@
+package services
+
+import (
+	"Forum_BE/repositories"
+	"Forum_BE/searchindex"
+	"Forum_BE/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Độ dài (số ký tự mỗi bên) của đoạn trích quanh từ khoá
+const searchSnippetRadius = 80
+
+// Backend tìm kiếm: MySQL FULLTEXT (mặc định) hoặc chỉ mục nhúng
+const (
+	SearchBackendMySQL = "mysql"
+	SearchBackendIndex = "index"
+)
+
+type SearchResult struct {
+	Hits        []repositories.SearchHit
+	Total       int
+	TypeCounts  map[string]int64
+	TopicFacets []repositories.SearchFacet
+	TagFacets   []repositories.SearchFacet
+}
+
+type SearchService interface {
+	Search(filters map[string]interface{}) (*SearchResult, error)
+}
+
+type searchService struct {
+	searchRepo  repositories.SearchRepository
+	redisClient *redis.Client
+	searchIndex SearchIndexService
+	backend     string
+}
+
+func NewSearchService(searchRepo repositories.SearchRepository, redisClient *redis.Client, searchIndex SearchIndexService, backend string) SearchService {
+	return &searchService{searchRepo: searchRepo, redisClient: redisClient, searchIndex: searchIndex, backend: backend}
+}
+
+func (s *searchService) Search(filters map[string]interface{}) (*SearchResult, error) {
+	query, _ := filters["search"].(string)
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+
+	useIndex := s.backend == SearchBackendIndex && s.searchIndex.Enabled()
+	prefix := "search"
+	if useIndex {
+		prefix = "search:index"
+	}
+	cacheKey := utils.GenerateCacheKey(prefix, 0, filters)
+	ctx := context.Background()
+
+	cached, err := s.redisClient.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var result SearchResult
+		if err := json.Unmarshal([]byte(cached), &result); err == nil {
+			log.Printf("Cache hit for %s", cacheKey)
+			return &result, nil
+		}
+	}
+	if err != redis.Nil {
+		log.Printf("Redis error for %s: %v", cacheKey, err)
+	}
+
+	var result *SearchResult
+	if useIndex {
+		result, err = s.searchWithIndex(query, filters)
+	} else {
+		result, err = s.searchWithMySQL(query, filters)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Hits {
+		result.Hits[i].Title = utils.Snippet(result.Hits[i].Title, query, searchSnippetRadius)
+		result.Hits[i].Body = utils.Snippet(result.Hits[i].Body, query, searchSnippetRadius)
+	}
+
+	data, err := json.Marshal(result)
+	if err == nil {
+		if err := s.redisClient.Set(ctx, cacheKey, data, 2*time.Minute).Err(); err != nil {
+			log.Printf("Failed to set cache for %s: %v", cacheKey, err)
+		}
+	} else {
+		log.Printf("Failed to marshal search result for cache: %v", err)
+	}
+
+	return result, nil
+}
+
+func (s *searchService) searchWithMySQL(query string, filters map[string]interface{}) (*SearchResult, error) {
+	hits, total, err := s.searchRepo.Search(query, filters)
+	if err != nil {
+		return nil, err
+	}
+	typeCounts, err := s.searchRepo.CountByType(query, filters)
+	if err != nil {
+		return nil, err
+	}
+	topicFacets, err := s.searchRepo.TopicFacets(query, filters)
+	if err != nil {
+		return nil, err
+	}
+	tagFacets, err := s.searchRepo.TagFacets(query, filters)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResult{
+		Hits:        hits,
+		Total:       total,
+		TypeCounts:  typeCounts,
+		TopicFacets: topicFacets,
+		TagFacets:   tagFacets,
+	}, nil
+}
+
+// searchWithIndex chỉ phục vụ question, answer và post vì đó là các loại được đồng bộ vào chỉ mục
+func (s *searchService) searchWithIndex(query string, filters map[string]interface{}) (*SearchResult, error) {
+	page, okPage := filters["page"].(int)
+	limit, okLimit := filters["limit"].(int)
+	if !okPage || page < 1 {
+		page = 1
+	}
+	if !okLimit || limit < 1 {
+		limit = 10
+	}
+
+	q := searchindex.Query{
+		Text:   query,
+		Types:  []string{repositories.SearchTypeQuestion, repositories.SearchTypeAnswer, repositories.SearchTypePost},
+		Offset: (page - 1) * limit,
+		Limit:  limit,
+	}
+	if types, ok := filters["typefilter"].([]string); ok && len(types) > 0 {
+		q.Types = types
+	}
+	if topicID, ok := filters["topic_id"].(uint); ok {
+		q.TopicID = topicID
+	}
+	if tagID, ok := filters["tag_id"].(uint); ok {
+		q.TagID = tagID
+	}
+
+	res, err := s.searchIndex.Search(q)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]repositories.SearchHit, 0, len(res.Hits))
+	for _, h := range res.Hits {
+		hits = append(hits, repositories.SearchHit{
+			Type:      h.Type,
+			ID:        h.ID,
+			Title:     h.Title,
+			Body:      h.Body,
+			Score:     h.Score,
+			CreatedAt: h.CreatedAt,
+		})
+	}
+
+	topicFacets, err := s.namedFacets("topics", res.TopicCounts)
+	if err != nil {
+		return nil, err
+	}
+	tagFacets, err := s.namedFacets("tags", res.TagCounts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchResult{
+		Hits:        hits,
+		Total:       res.Total,
+		TypeCounts:  res.TypeCounts,
+		TopicFacets: topicFacets,
+		TagFacets:   tagFacets,
+	}, nil
+}
+
+// namedFacets gắn tên cho facet từ chỉ mục, bỏ các topic/tag đã bị xoá và giữ 20 mục nhiều nhất
+func (s *searchService) namedFacets(table string, counts map[uint]int64) ([]repositories.SearchFacet, error) {
+	ids := make([]uint, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	names, err := s.searchRepo.GetFacetNames(table, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	facets := make([]repositories.SearchFacet, 0, len(names))
+	for id, name := range names {
+		facets = append(facets, repositories.SearchFacet{ID: id, Name: name, Count: counts[id]})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].ID < facets[j].ID
+	})
+	if len(facets) > 20 {
+		facets = facets[:20]
+	}
+	return facets, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"Forum_BE/utils"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// Số ID mỗi khối sitemap; giới hạn của chuẩn là 50.000 URL mỗi tệp
+	sitemapSegmentSize = 5000
+	// Số câu hỏi/bài viết cũ được gán slug mỗi lần chạy
+	slugBackfillBatch = 500
+)
+
+var sitemapKinds = []string{models.SitemapQuestions, models.SitemapPosts, models.SitemapTopics, models.SitemapTags}
+
+// SitemapService sinh sitemap chia khối cho câu hỏi, bài viết, chủ đề và thẻ.
+// Mỗi lần Regenerate chỉ sinh lại những khối có thay đổi (số URL hoặc lần sửa mới nhất khác đi).
+type SitemapService interface {
+	Regenerate() (int, error)
+	Index(baseURL string) ([]byte, error)
+	Segment(name string) ([]byte, time.Time, error)
+}
+
+type sitemapService struct {
+	sitemapRepo repositories.SitemapRepository
+	siteURL     string
+}
+
+func NewSitemapService(sitemapRepo repositories.SitemapRepository) SitemapService {
+	siteURL := strings.TrimRight(os.Getenv("FRONTEND_URL"), "/")
+	if siteURL == "" {
+		siteURL = "http://localhost:3000"
+	}
+	return &sitemapService{sitemapRepo: sitemapRepo, siteURL: siteURL}
+}
+
+// Regenerate cập nhật các khối sitemap đã thay đổi, xoá khối không còn nội dung và trả về số khối đã sinh lại
+func (s *sitemapService) Regenerate() (int, error) {
+	backfilled, err := s.sitemapRepo.BackfillSlugs(slugBackfillBatch)
+	if err != nil {
+		log.Printf("Failed to backfill slugs: %v", err)
+	}
+
+	segments, err := s.sitemapRepo.ListSegments()
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[string]models.SitemapSegment, len(segments))
+	for _, segment := range segments {
+		existing[segment.Name] = segment
+	}
+
+	generated := 0
+	seen := make(map[string]bool)
+	for _, kind := range sitemapKinds {
+		stats, err := s.sitemapRepo.SegmentStats(kind, sitemapSegmentSize)
+		if err != nil {
+			return generated, err
+		}
+		for _, stat := range stats {
+			name := fmt.Sprintf("%s-%d", kind, stat.Number+1)
+			seen[name] = true
+			fingerprint := fmt.Sprintf("%d:%d", stat.URLCount, stat.LastMod.UnixNano())
+			// Gán slug không đổi updated_at nên khi vừa gán slug phải sinh lại toàn bộ để URL dùng slug mới
+			if segment, ok := existing[name]; ok && segment.Fingerprint == fingerprint && backfilled == 0 {
+				continue
+			}
+			if err := s.generateSegment(kind, name, stat, fingerprint); err != nil {
+				return generated, err
+			}
+			generated++
+		}
+	}
+
+	var stale []string
+	for name := range existing {
+		if !seen[name] {
+			stale = append(stale, name)
+		}
+	}
+	if err := s.sitemapRepo.DeleteSegments(stale); err != nil {
+		return generated, err
+	}
+	return generated, nil
+}
+
+func (s *sitemapService) generateSegment(kind, name string, stat repositories.SitemapStat, fingerprint string) error {
+	entries, err := s.sitemapRepo.ListEntries(kind, sitemapSegmentSize, stat.Number)
+	if err != nil {
+		return err
+	}
+	urls := make([]utils.SitemapURL, 0, len(entries))
+	for _, entry := range entries {
+		urls = append(urls, utils.SitemapURL{Loc: s.entryURL(kind, entry), LastMod: entry.UpdatedAt})
+	}
+	content, err := utils.RenderSitemap(urls)
+	if err != nil {
+		return err
+	}
+	segment := &models.SitemapSegment{
+		Name:        name,
+		Kind:        kind,
+		Number:      stat.Number + 1,
+		URLCount:    len(urls),
+		LastMod:     stat.LastMod,
+		Fingerprint: fingerprint,
+		Content:     string(content),
+		GeneratedAt: time.Now(),
+	}
+	if err := s.sitemapRepo.SaveSegment(segment); err != nil {
+		log.Printf("Failed to save sitemap segment %s: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+func (s *sitemapService) entryURL(kind string, entry repositories.SitemapEntry) string {
+	ref := entry.Slug
+	if ref == "" {
+		ref = fmt.Sprint(entry.ID)
+	}
+	return fmt.Sprintf("%s/%s/%s", s.siteURL, kind, ref)
+}
+
+// Index trả về sitemap index; lần đầu chưa có khối nào thì sinh ngay thay vì chờ cron
+func (s *sitemapService) Index(baseURL string) ([]byte, error) {
+	segments, err := s.sitemapRepo.ListSegments()
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		if _, err := s.Regenerate(); err != nil {
+			return nil, err
+		}
+		if segments, err = s.sitemapRepo.ListSegments(); err != nil {
+			return nil, err
+		}
+	}
+
+	baseURL = strings.TrimRight(baseURL, "/")
+	sitemaps := make([]utils.SitemapURL, 0, len(segments))
+	for _, segment := range segments {
+		sitemaps = append(sitemaps, utils.SitemapURL{
+			Loc:     fmt.Sprintf("%s/sitemaps/%s.xml", baseURL, segment.Name),
+			LastMod: segment.LastMod,
+		})
+	}
+	return utils.RenderSitemapIndex(sitemaps)
+}
+
+// Segment trả về nội dung XML của một khối cùng thời điểm sửa mới nhất (dùng cho Last-Modified)
+func (s *sitemapService) Segment(name string) ([]byte, time.Time, error) {
+	segment, err := s.sitemapRepo.GetSegment(name)
+	if err != nil {
+		return nil, time.Time{}, errors.New("sitemap not found")
+	}
+	return []byte(segment.Content), segment.LastMod, nil
+}
@@ -0,0 +1,557 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/repositories"
+	"Forum_BE/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Mỗi nhãn cần ít nhất ngần này mẫu thì mô hình mới chấm điểm
+	minSpamSamples = 10
+	// Mẫu có ID chia hết cho số này được giữ lại làm tập kiểm tra khi đánh giá
+	spamHoldoutModulo = 5
+	// Chu kỳ kiểm tra phiên bản đang dùng có bị đổi (huấn luyện hoặc quay lui ở tiến trình khác)
+	spamVersionCheckInterval = 10 * time.Minute
+	defaultSpamThreshold     = 0.9
+	spamReportVersions       = 20
+	spamClassifierRuleName   = "spam_classifier"
+)
+
+var errNoSpamSamples = errors.New("no spam training samples yet")
+
+// SpamClassifierStatus mô tả mô hình đang chạy trong tiến trình, kể cả các quyết định đã học thêm sau lần huấn luyện
+type SpamClassifierStatus struct {
+	VersionID            uint    `json:"versionId"`
+	Threshold            float64 `json:"threshold"`
+	Ready                bool    `json:"ready"`
+	SpamCount            int     `json:"spamCount"`
+	HamCount             int     `json:"hamCount"`
+	VocabularySize       int     `json:"vocabularySize"`
+	LearnedSinceTraining int     `json:"learnedSinceTraining"`
+}
+
+// SpamClassifierService chấm xác suất spam của nội dung bằng Naive Bayes (multinomial) trên nội dung
+// và một số đặc trưng (loại nội dung, số liên kết, tuổi tài khoản, điểm uy tín, độ dài).
+// Mô hình học thêm từ từng quyết định của người kiểm duyệt và được huấn luyện lại định kỳ thành phiên bản mới.
+type SpamClassifierService interface {
+	Score(contentType, title, body string, author *models.User) (float64, uint, bool)
+	Threshold() float64
+	Learn(contentType string, contentID uint, status string)
+	Train(actorID *uint) (*models.SpamModelVersion, error)
+	Activate(versionID uint) (*models.SpamModelVersion, error)
+	Report() (*SpamClassifierStatus, []models.SpamModelVersion, error)
+}
+
+// spamWeights là phần được lưu của mô hình; tổng và từ vựng được tính lại khi nạp
+type spamWeights struct {
+	Docs  map[string]int                `json:"docs"`
+	Terms map[string]map[string]float64 `json:"terms"`
+}
+
+type spamModel struct {
+	versionID  uint
+	docs       map[string]int
+	termCounts map[string]map[string]float64
+	totalTerms map[string]float64
+	vocabulary map[string]float64
+	learned    int
+	checkedAt  time.Time
+}
+
+func newSpamModel() *spamModel {
+	return &spamModel{
+		docs:       map[string]int{},
+		termCounts: map[string]map[string]float64{models.SpamLabelSpam: {}, models.SpamLabelHam: {}},
+		totalTerms: map[string]float64{},
+		vocabulary: map[string]float64{},
+		checkedAt:  time.Now(),
+	}
+}
+
+// add cộng (sign = 1) hoặc trừ (sign = -1) một tài liệu khỏi nhãn; dùng trừ khi người kiểm duyệt đổi quyết định
+func (m *spamModel) add(label string, terms map[string]float64, sign float64) {
+	m.docs[label] += int(sign)
+	counts := m.termCounts[label]
+	for term, count := range terms {
+		counts[term] += sign * count
+		m.totalTerms[label] += sign * count
+		m.vocabulary[term] += sign * count
+		if counts[term] <= 0 {
+			delete(counts, term)
+		}
+		if m.vocabulary[term] <= 0 {
+			delete(m.vocabulary, term)
+		}
+	}
+}
+
+func (m *spamModel) ready() bool {
+	return m.docs[models.SpamLabelSpam] >= minSpamSamples && m.docs[models.SpamLabelHam] >= minSpamSamples
+}
+
+// score trả về xác suất hậu nghiệm của nhãn spam; chỉ các term đã có trong từ vựng được tính
+func (m *spamModel) score(terms map[string]float64) (float64, bool) {
+	if !m.ready() {
+		return 0, false
+	}
+	total := float64(m.docs[models.SpamLabelSpam] + m.docs[models.SpamLabelHam])
+	vocabSize := float64(len(m.vocabulary))
+	logScore := func(label string) float64 {
+		score := math.Log(float64(m.docs[label]) / total)
+		denominator := m.totalTerms[label] + vocabSize
+		for term, count := range terms {
+			if _, ok := m.vocabulary[term]; !ok {
+				continue
+			}
+			score += count * math.Log((m.termCounts[label][term]+1)/denominator)
+		}
+		return score
+	}
+	return 1 / (1 + math.Exp(logScore(models.SpamLabelHam)-logScore(models.SpamLabelSpam))), true
+}
+
+func (m *spamModel) weights() spamWeights {
+	return spamWeights{Docs: m.docs, Terms: m.termCounts}
+}
+
+func spamModelFromVersion(version *models.SpamModelVersion) (*spamModel, error) {
+	var weights spamWeights
+	if err := json.Unmarshal(version.Weights, &weights); err != nil {
+		return nil, fmt.Errorf("invalid weights of spam model %d: %v", version.ID, err)
+	}
+	model := newSpamModel()
+	model.versionID = version.ID
+	for label, count := range weights.Docs {
+		model.docs[label] = count
+	}
+	for label, terms := range weights.Terms {
+		if model.termCounts[label] == nil {
+			continue
+		}
+		model.termCounts[label] = terms
+		for term, count := range terms {
+			model.totalTerms[label] += count
+			model.vocabulary[term] += count
+		}
+	}
+	return model, nil
+}
+
+type spamClassifierService struct {
+	repo      repositories.SpamClassifierRepository
+	threshold float64
+
+	mu       sync.RWMutex
+	model    *spamModel
+	training sync.Mutex
+}
+
+func NewSpamClassifierService(repo repositories.SpamClassifierRepository) SpamClassifierService {
+	threshold := defaultSpamThreshold
+	if value, err := strconv.ParseFloat(os.Getenv("SPAM_SCORE_THRESHOLD"), 64); err == nil && value > 0 && value <= 1 {
+		threshold = value
+	}
+	return &spamClassifierService{repo: repo, threshold: threshold}
+}
+
+func (s *spamClassifierService) Threshold() float64 {
+	return s.threshold
+}
+
+// Score chấm nội dung mới tạo; ok = false khi mô hình chưa đủ mẫu của cả hai nhãn
+func (s *spamClassifierService) Score(contentType, title, body string, author *models.User) (float64, uint, bool) {
+	model := s.currentModel()
+	if model == nil {
+		return 0, 0, false
+	}
+	plain := utils.StripHTML(body)
+	var age *time.Duration
+	var reputation *uint
+	if author != nil {
+		accountAge := time.Since(author.CreatedAt)
+		age, reputation = &accountAge, &author.Reputation
+	}
+	terms := spamTerms(title, plain, spamFeatures(contentType, body, plain, age, reputation))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	score, ok := model.score(terms)
+	return math.Round(score*1000) / 1000, model.versionID, ok
+}
+
+// Learn ghi quyết định của người kiểm duyệt thành mẫu huấn luyện và cập nhật ngay mô hình đang chạy.
+// Duyệt là "ham", từ chối hoặc đánh dấu spam là "spam"; các trạng thái khác không phải quyết định nên bị bỏ qua.
+func (s *spamClassifierService) Learn(contentType string, contentID uint, status string) {
+	var label string
+	switch status {
+	case string(models.StatusApproved):
+		label = models.SpamLabelHam
+	case string(models.StatusRejected), "spam":
+		label = models.SpamLabelSpam
+	default:
+		return
+	}
+
+	content, err := s.repo.LoadContent(contentType, contentID)
+	if err != nil {
+		log.Printf("Spam classifier could not load %s %d: %v", contentType, contentID, err)
+		return
+	}
+	previous, err := s.repo.GetSample(contentType, contentID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Spam classifier could not load sample of %s %d: %v", contentType, contentID, err)
+		return
+	}
+
+	// Tuổi tài khoản tính tại thời điểm đăng, không phải lúc được duyệt
+	age := content.CreatedAt.Sub(content.AuthorCreatedAt)
+	features := spamFeatures(contentType, content.RawContent, content.PlainContent, &age, &content.AuthorReputation)
+	sample := &models.SpamTrainingSample{
+		ContentType: contentType,
+		ContentID:   contentID,
+		UserID:      content.UserID,
+		Label:       label,
+		Title:       content.Title,
+		Body:        content.PlainContent,
+		Features:    strings.Join(features, " "),
+	}
+	if err := s.repo.SaveSample(sample); err != nil {
+		log.Printf("Failed to save spam training sample for %s %d: %v", contentType, contentID, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.model == nil {
+		// Mô hình chưa được nạp; mẫu sẽ được tính khi nạp hoặc huấn luyện
+		return
+	}
+	if previous != nil {
+		s.model.add(previous.Label, sampleTerms(previous), -1)
+	}
+	s.model.add(label, sampleTerms(sample), 1)
+	s.model.learned++
+}
+
+// Train huấn luyện lại từ toàn bộ mẫu, đánh giá trên tập kiểm tra rồi lưu và kích hoạt phiên bản mới.
+// Quyết định được ghi trong lúc đang huấn luyện có thể chưa có trong phiên bản này và sẽ được tính ở lần sau.
+func (s *spamClassifierService) Train(actorID *uint) (*models.SpamModelVersion, error) {
+	s.training.Lock()
+	defer s.training.Unlock()
+	return s.train(actorID)
+}
+
+func (s *spamClassifierService) train(actorID *uint) (*models.SpamModelVersion, error) {
+	full := newSpamModel()
+	trainSplit := newSpamModel()
+	type holdoutSample struct {
+		label string
+		terms map[string]float64
+	}
+	var holdout []holdoutSample
+
+	var afterID uint
+	for {
+		batch, err := s.repo.ListSamples(afterID, trainingBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range batch {
+			sample := &batch[i]
+			afterID = sample.ID
+			terms := sampleTerms(sample)
+			full.add(sample.Label, terms, 1)
+			if sample.ID%spamHoldoutModulo == 0 {
+				holdout = append(holdout, holdoutSample{label: sample.Label, terms: terms})
+			} else {
+				trainSplit.add(sample.Label, terms, 1)
+			}
+		}
+		if len(batch) < trainingBatchSize {
+			break
+		}
+	}
+	if full.docs[models.SpamLabelSpam]+full.docs[models.SpamLabelHam] == 0 {
+		return nil, errNoSpamSamples
+	}
+
+	version := &models.SpamModelVersion{
+		SampleCount:    full.docs[models.SpamLabelSpam] + full.docs[models.SpamLabelHam],
+		SpamCount:      full.docs[models.SpamLabelSpam],
+		HamCount:       full.docs[models.SpamLabelHam],
+		VocabularySize: len(full.vocabulary),
+		Threshold:      s.threshold,
+		Active:         true,
+		TrainedByID:    actorID,
+	}
+	for _, sample := range holdout {
+		score, ok := trainSplit.score(sample.terms)
+		if !ok {
+			break
+		}
+		version.TestSize++
+		predictedSpam := score >= s.threshold
+		switch {
+		case predictedSpam && sample.label == models.SpamLabelSpam:
+			version.TruePositives++
+		case predictedSpam:
+			version.FalsePositives++
+		case sample.label == models.SpamLabelSpam:
+			version.FalseNegatives++
+		default:
+			version.TrueNegatives++
+		}
+	}
+	fillSpamMetrics(version)
+
+	data, err := json.Marshal(full.weights())
+	if err != nil {
+		return nil, err
+	}
+	version.Weights = data
+	if err := s.repo.CreateVersion(version); err != nil {
+		log.Printf("Failed to save spam model version: %v", err)
+		return nil, err
+	}
+	full.versionID = version.ID
+
+	s.mu.Lock()
+	s.model = full
+	s.mu.Unlock()
+
+	log.Printf("Spam classifier v%d trained on %d samples (%d spam), F1 %.3f on %d held-out samples",
+		version.ID, version.SampleCount, version.SpamCount, version.F1, version.TestSize)
+	return version, nil
+}
+
+// Activate chuyển sang một phiên bản đã huấn luyện (vd quay lại phiên bản cũ khi phiên bản mới chấm sai nhiều)
+func (s *spamClassifierService) Activate(versionID uint) (*models.SpamModelVersion, error) {
+	s.training.Lock()
+	defer s.training.Unlock()
+
+	version, err := s.repo.GetVersion(versionID)
+	if err != nil {
+		return nil, errors.New("model version not found")
+	}
+	model, err := s.loadVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ActivateVersion(versionID); err != nil {
+		log.Printf("Failed to activate spam model version %d: %v", versionID, err)
+		return nil, err
+	}
+	version.Active = true
+
+	s.mu.Lock()
+	s.model = model
+	s.mu.Unlock()
+	return version, nil
+}
+
+func (s *spamClassifierService) Report() (*SpamClassifierStatus, []models.SpamModelVersion, error) {
+	versions, err := s.repo.ListVersions(spamReportVersions)
+	if err != nil {
+		return nil, nil, err
+	}
+	status := &SpamClassifierStatus{Threshold: s.threshold}
+	if model := s.currentModel(); model != nil {
+		s.mu.RLock()
+		status.VersionID = model.versionID
+		status.Ready = model.ready()
+		status.SpamCount = model.docs[models.SpamLabelSpam]
+		status.HamCount = model.docs[models.SpamLabelHam]
+		status.VocabularySize = len(model.vocabulary)
+		status.LearnedSinceTraining = model.learned
+		s.mu.RUnlock()
+	}
+	return status, versions, nil
+}
+
+// loadVersion dựng mô hình từ trọng số đã lưu rồi cộng thêm các mẫu mới có sau khi phiên bản được huấn luyện.
+// Mẫu cũ bị đổi nhãn sau đó chỉ được cập nhật ở lần huấn luyện kế tiếp.
+func (s *spamClassifierService) loadVersion(version *models.SpamModelVersion) (*spamModel, error) {
+	model, err := spamModelFromVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := s.repo.ListSamplesSince(version.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for i := range samples {
+		model.add(samples[i].Label, sampleTerms(&samples[i]), 1)
+		model.learned++
+	}
+	return model, nil
+}
+
+// currentModel nạp phiên bản đang dùng ở lần gọi đầu (chưa có phiên bản nào thì huấn luyện),
+// sau đó định kỳ kiểm tra ở nền xem phiên bản đang dùng có đổi không.
+func (s *spamClassifierService) currentModel() *spamModel {
+	s.mu.RLock()
+	model := s.model
+	s.mu.RUnlock()
+	if model == nil {
+		s.reload(false)
+		s.mu.RLock()
+		model = s.model
+		s.mu.RUnlock()
+		return model
+	}
+
+	s.mu.Lock()
+	stale := time.Since(model.checkedAt) > spamVersionCheckInterval
+	if stale {
+		model.checkedAt = time.Now()
+	}
+	s.mu.Unlock()
+	if stale {
+		go func() {
+			activeID, err := s.repo.GetActiveVersionID()
+			if err != nil {
+				log.Printf("Failed to check active spam model version: %v", err)
+				return
+			}
+			if activeID != 0 && activeID != model.versionID {
+				s.reload(true)
+			}
+		}()
+	}
+	return model
+}
+
+// reload nạp lại phiên bản đang dùng; force = false thì bỏ qua nếu tiến trình khác đã nạp xong trong lúc chờ khoá
+func (s *spamClassifierService) reload(force bool) {
+	s.training.Lock()
+	defer s.training.Unlock()
+
+	s.mu.RLock()
+	loaded := s.model != nil
+	s.mu.RUnlock()
+	if loaded && !force {
+		return
+	}
+
+	version, err := s.repo.GetActiveVersion()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := s.train(nil); err != nil {
+			if !errors.Is(err, errNoSpamSamples) {
+				log.Printf("Failed to train spam classifier: %v", err)
+			}
+			// Chưa có mẫu nào: dùng mô hình rỗng (chưa chấm điểm, vẫn học thêm) để không huấn luyện lại ở mỗi lần tạo nội dung
+			s.mu.Lock()
+			if s.model == nil {
+				s.model = newSpamModel()
+			}
+			s.mu.Unlock()
+		}
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to load active spam model: %v", err)
+		return
+	}
+
+	model, err := s.loadVersion(version)
+	if err != nil {
+		log.Printf("Failed to load spam model v%d: %v", version.ID, err)
+		return
+	}
+	s.mu.Lock()
+	s.model = model
+	s.mu.Unlock()
+}
+
+func fillSpamMetrics(version *models.SpamModelVersion) {
+	ratio := func(a, b int) float64 {
+		if b == 0 {
+			return 0
+		}
+		return math.Round(float64(a)/float64(b)*1000) / 1000
+	}
+	version.Precision = ratio(version.TruePositives, version.TruePositives+version.FalsePositives)
+	version.Recall = ratio(version.TruePositives, version.TruePositives+version.FalseNegatives)
+	version.Accuracy = ratio(version.TruePositives+version.TrueNegatives, version.TestSize)
+	if version.Precision+version.Recall > 0 {
+		version.F1 = math.Round(2*version.Precision*version.Recall/(version.Precision+version.Recall)*1000) / 1000
+	}
+}
+
+// spamFeatures chuyển thông tin ngoài nội dung thành các term dạng "#tên:nhóm" để dùng chung mô hình với từ ngữ;
+// age và reputation nil khi không biết tác giả
+func spamFeatures(contentType, raw, plain string, age *time.Duration, reputation *uint) []string {
+	features := []string{"#type:" + contentType}
+
+	links := countLinks(raw)
+	if links > 3 {
+		features = append(features, "#links:many")
+	} else {
+		features = append(features, "#links:"+strconv.Itoa(links))
+	}
+
+	length := utf8.RuneCountInString(plain)
+	switch {
+	case length < 50:
+		features = append(features, "#length:tiny")
+	case length < 300:
+		features = append(features, "#length:short")
+	case length < 2000:
+		features = append(features, "#length:medium")
+	default:
+		features = append(features, "#length:long")
+	}
+
+	if age != nil {
+		switch {
+		case *age < time.Hour:
+			features = append(features, "#account_age:hour")
+		case *age < 24*time.Hour:
+			features = append(features, "#account_age:day")
+		case *age < 7*24*time.Hour:
+			features = append(features, "#account_age:week")
+		case *age < 30*24*time.Hour:
+			features = append(features, "#account_age:month")
+		default:
+			features = append(features, "#account_age:old")
+		}
+	}
+	if reputation != nil {
+		switch {
+		case *reputation == 0:
+			features = append(features, "#reputation:0")
+		case *reputation < 10:
+			features = append(features, "#reputation:low")
+		case *reputation < 100:
+			features = append(features, "#reputation:medium")
+		default:
+			features = append(features, "#reputation:high")
+		}
+	}
+	return features
+}
+
+func spamTerms(title, plain string, features []string) map[string]float64 {
+	terms := documentTerms(title, plain)
+	for _, feature := range features {
+		terms[feature]++
+	}
+	return terms
+}
+
+func sampleTerms(sample *models.SpamTrainingSample) map[string]float64 {
+	return spamTerms(sample.Title, sample.Body, strings.Fields(sample.Features))
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func trainedSpamModel(samples int) *spamModel {
+	model := newSpamModel()
+	for i := 0; i < samples; i++ {
+		model.add(models.SpamLabelSpam, spamTerms(fmt.Sprintf("Mua thuốc giảm cân giá rẻ %d", i), "Liên hệ ngay khuyến mãi casino", []string{"#links:many"}), 1)
+		model.add(models.SpamLabelHam, spamTerms(fmt.Sprintf("Cách dùng goroutine trong Golang %d", i), "Mình gặp lỗi deadlock khi dùng channel", []string{"#links:0"}), 1)
+	}
+	return model
+}
+
+func TestSpamModelScore(t *testing.T) {
+	model := trainedSpamModel(minSpamSamples)
+
+	tests := []struct {
+		name  string
+		title string
+		body  string
+		links string
+		spam  bool
+	}{
+		{"spam", "Thuốc giảm cân khuyến mãi", "Liên hệ casino", "#links:many", true},
+		{"ham", "Lỗi deadlock với channel", "goroutine bị treo", "#links:0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, ok := model.score(spamTerms(tt.title, tt.body, []string{tt.links}))
+			if !ok {
+				t.Fatal("trained model did not score")
+			}
+			if tt.spam && score < 0.9 || !tt.spam && score > 0.1 {
+				t.Errorf("score = %v, want spam = %v", score, tt.spam)
+			}
+		})
+	}
+
+	// Không có term nào trong từ vựng thì điểm chỉ còn xác suất tiên nghiệm
+	if score, _ := model.score(spamTerms("Thời tiết", "hôm nay", nil)); math.Abs(score-0.5) > 1e-9 {
+		t.Errorf("score of unknown terms = %v, want the prior 0.5", score)
+	}
+}
+
+func TestSpamModelNeedsSamples(t *testing.T) {
+	model := trainedSpamModel(minSpamSamples - 1)
+	if _, ok := model.score(spamTerms("Mua thuốc", "", nil)); ok {
+		t.Errorf("model with %d samples per label scored, want no score", minSpamSamples-1)
+	}
+}
+
+func TestSpamModelAddIsReversible(t *testing.T) {
+	model := trainedSpamModel(minSpamSamples)
+	before, _ := model.score(spamTerms("Mua thuốc", "casino", nil))
+
+	terms := spamTerms("Quảng cáo mới toanh", "xổ số", []string{"#links:many"})
+	model.add(models.SpamLabelSpam, terms, 1)
+	model.add(models.SpamLabelSpam, terms, -1)
+
+	after, _ := model.score(spamTerms("Mua thuốc", "casino", nil))
+	if math.Abs(before-after) > 1e-12 {
+		t.Errorf("score after add and remove = %v, want %v", after, before)
+	}
+	if _, ok := model.vocabulary["toanh"]; ok {
+		t.Errorf("removed term is still in the vocabulary")
+	}
+}
+
+func TestSpamModelFromVersion(t *testing.T) {
+	model := trainedSpamModel(minSpamSamples)
+	weights, err := json.Marshal(model.weights())
+	if err != nil {
+		t.Fatalf("marshal weights: %v", err)
+	}
+	loaded, err := spamModelFromVersion(&models.SpamModelVersion{ID: 7, Weights: weights})
+	if err != nil {
+		t.Fatalf("spamModelFromVersion: %v", err)
+	}
+	if loaded.versionID != 7 {
+		t.Errorf("versionID = %d, want 7", loaded.versionID)
+	}
+	for label := range model.totalTerms {
+		if math.Abs(loaded.totalTerms[label]-model.totalTerms[label]) > 1e-9 {
+			t.Errorf("totalTerms[%s] = %v, want %v", label, loaded.totalTerms[label], model.totalTerms[label])
+		}
+	}
+	if len(loaded.vocabulary) != len(model.vocabulary) {
+		t.Errorf("vocabulary size = %d, want %d", len(loaded.vocabulary), len(model.vocabulary))
+	}
+	terms := spamTerms("Mua thuốc", "goroutine", nil)
+	want, _ := model.score(terms)
+	if got, _ := loaded.score(terms); math.Abs(got-want) > 1e-9 {
+		t.Errorf("loaded score = %v, want %v", got, want)
+	}
+
+	if _, err := spamModelFromVersion(&models.SpamModelVersion{Weights: []byte("{")}); err == nil {
+		t.Error("spamModelFromVersion with invalid weights succeeded, want an error")
+	}
+}
+
+func TestSpamFeatures(t *testing.T) {
+	hour := 30 * time.Minute
+	month := 10 * 24 * time.Hour
+	zero, low, high := uint(0), uint(5), uint(1000)
+	longText := string(make([]rune, 2500))
+
+	tests := []struct {
+		name       string
+		raw        string
+		plain      string
+		age        *time.Duration
+		reputation *uint
+		want       []string
+	}{
+		{"unknown author", "", "ngắn", nil, nil,
+			[]string{"#type:answer", "#links:0", "#length:tiny"}},
+		{"new account without reputation", `<a href="https://a.example">a</a>`, "ngắn", &hour, &zero,
+			[]string{"#type:answer", "#links:1", "#length:tiny", "#account_age:hour", "#reputation:0"}},
+		{"many links", "https://a.example https://b.example https://c.example https://d.example", "x", &month, &low,
+			[]string{"#type:answer", "#links:many", "#length:tiny", "#account_age:month", "#reputation:low"}},
+		{"long text from a trusted author", "", longText, &month, &high,
+			[]string{"#type:answer", "#links:0", "#length:long", "#account_age:month", "#reputation:high"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := spamFeatures("answer", tt.raw, tt.plain, tt.age, tt.reputation); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("spamFeatures = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Điểm uy tín cộng cho người đề xuất khi bản sửa được duyệt
+const suggestedEditReputation = 2
+
+var ErrSuggestedEditConflict = errors.New("the target has been edited since this suggestion was made")
+
+// SuggestedEditOverride là nội dung người duyệt chỉnh lại trước khi duyệt ("improve")
+type SuggestedEditOverride struct {
+	Title   string
+	Content string
+	TopicID uint
+	TagIDs  []uint
+}
+
+type SuggestedEditService interface {
+	SuggestEdit(editorID uint, targetType string, targetID uint, title, content string, topicID uint, tagIDs []uint, summary string) (*models.SuggestedEdit, error)
+	GetSuggestedEdit(id uint) (*models.SuggestedEdit, error)
+	ListSuggestedEdits(filters map[string]interface{}) ([]models.SuggestedEdit, int, error)
+	ApproveSuggestedEdit(id, reviewerID uint, override *SuggestedEditOverride, note string, force bool) (*models.SuggestedEdit, error)
+	RejectSuggestedEdit(id, reviewerID uint, note string) (*models.SuggestedEdit, error)
+	WithdrawSuggestedEdit(id, editorID uint) error
+}
+
+type suggestedEditService struct {
+	editRepo        repositories.SuggestedEditRepository
+	questionRepo    repositories.QuestionRepository
+	answerRepo      repositories.AnswerRepository
+	questionService QuestionService
+	answerService   AnswerService
+	novuClient      *notification.NovuClient
+}
+
+func NewSuggestedEditService(editRepo repositories.SuggestedEditRepository, qRepo repositories.QuestionRepository, aRepo repositories.AnswerRepository, qService QuestionService, aService AnswerService, novuClient *notification.NovuClient) SuggestedEditService {
+	return &suggestedEditService{
+		editRepo:        editRepo,
+		questionRepo:    qRepo,
+		answerRepo:      aRepo,
+		questionService: qService,
+		answerService:   aService,
+		novuClient:      novuClient,
+	}
+}
+
+// currentContent trả về tiêu đề và nội dung hiện tại của đích để so sánh xung đột
+func (s *suggestedEditService) currentContent(targetType string, targetID uint) (string, string, error) {
+	switch targetType {
+	case models.SuggestedEditTargetQuestion:
+		question, err := s.questionRepo.GetQuestionByIDMinimal(targetID)
+		if err != nil {
+			return "", "", errors.New("question not found")
+		}
+		return question.Title, editableBody(question.ContentFormat, question.Description, question.ContentSource), nil
+	case models.SuggestedEditTargetAnswer:
+		answer, err := s.answerRepo.GetAnswerByIDSimple(targetID)
+		if err != nil {
+			return "", "", errors.New("answer not found")
+		}
+		return answer.Title, editableBody(answer.ContentFormat, answer.Content, answer.ContentSource), nil
+	}
+	return "", "", errors.New("invalid target type, must be 'question' or 'answer'")
+}
+
+// SuggestEdit tạo đề xuất mới; nếu người dùng đã có đề xuất đang chờ cho cùng đích thì ghi đè đề xuất đó
+func (s *suggestedEditService) SuggestEdit(editorID uint, targetType string, targetID uint, title, content string, topicID uint, tagIDs []uint, summary string) (*models.SuggestedEdit, error) {
+	baseTitle, baseContent, err := s.currentContent(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if title == "" {
+		title = baseTitle
+	}
+	if content == "" {
+		return nil, errors.New("content is required")
+	}
+	if title == baseTitle && content == baseContent && topicID == 0 && len(tagIDs) == 0 {
+		return nil, errors.New("suggested edit does not change anything")
+	}
+
+	var encodedTags json.RawMessage
+	if len(tagIDs) > 0 && targetType == models.SuggestedEditTargetAnswer {
+		if encodedTags, err = json.Marshal(tagIDs); err != nil {
+			return nil, err
+		}
+	}
+	if targetType != models.SuggestedEditTargetQuestion {
+		topicID = 0
+	}
+
+	edit, err := s.editRepo.GetPendingEdit(editorID, targetType, targetID)
+	if err != nil {
+		edit = &models.SuggestedEdit{
+			TargetType: targetType,
+			TargetID:   targetID,
+			EditorID:   editorID,
+			Status:     models.SuggestedEditPending,
+		}
+	}
+	edit.Title = title
+	edit.Content = content
+	edit.TopicID = topicID
+	edit.TagIDs = encodedTags
+	edit.Summary = summary
+	edit.BaseTitle = baseTitle
+	edit.BaseContent = baseContent
+
+	if edit.ID == 0 {
+		err = s.editRepo.CreateSuggestedEdit(edit)
+	} else {
+		err = s.editRepo.UpdateSuggestedEdit(edit)
+	}
+	if err != nil {
+		log.Printf("Failed to save suggested edit on %s %d: %v", targetType, targetID, err)
+		return nil, err
+	}
+	return s.editRepo.GetSuggestedEditByID(edit.ID)
+}
+
+func (s *suggestedEditService) GetSuggestedEdit(id uint) (*models.SuggestedEdit, error) {
+	edit, err := s.editRepo.GetSuggestedEditByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.markConflict(edit)
+	return edit, nil
+}
+
+func (s *suggestedEditService) ListSuggestedEdits(filters map[string]interface{}) ([]models.SuggestedEdit, int, error) {
+	edits, total, err := s.editRepo.ListSuggestedEdits(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range edits {
+		s.markConflict(&edits[i])
+	}
+	return edits, total, nil
+}
+
+func (s *suggestedEditService) markConflict(edit *models.SuggestedEdit) {
+	if edit.Status != models.SuggestedEditPending {
+		return
+	}
+	title, content, err := s.currentContent(edit.TargetType, edit.TargetID)
+	edit.Conflict = err != nil || title != edit.BaseTitle || content != edit.BaseContent
+}
+
+// ApproveSuggestedEdit áp dụng bản sửa qua UpdateQuestion/UpdateAnswer và cộng điểm cho người đề xuất.
+// Nếu đích đã bị sửa sau khi đề xuất thì trả về ErrSuggestedEditConflict, trừ khi force = true.
+func (s *suggestedEditService) ApproveSuggestedEdit(id, reviewerID uint, override *SuggestedEditOverride, note string, force bool) (*models.SuggestedEdit, error) {
+	edit, err := s.editRepo.GetSuggestedEditByID(id)
+	if err != nil {
+		return nil, errors.New("suggested edit not found")
+	}
+	if edit.Status != models.SuggestedEditPending {
+		return nil, errors.New("suggested edit has already been reviewed")
+	}
+	if edit.EditorID == reviewerID {
+		return nil, errors.New("you cannot review your own suggested edit")
+	}
+
+	s.markConflict(edit)
+	if edit.Conflict && !force {
+		return edit, ErrSuggestedEditConflict
+	}
+
+	var tagIDs []uint
+	if len(edit.TagIDs) > 0 {
+		if err := json.Unmarshal(edit.TagIDs, &tagIDs); err != nil {
+			return nil, err
+		}
+	}
+	if override != nil {
+		if override.Title != "" {
+			edit.Title = override.Title
+		}
+		if override.Content != "" {
+			edit.Content = override.Content
+		}
+		if override.TopicID != 0 && edit.TargetType == models.SuggestedEditTargetQuestion {
+			edit.TopicID = override.TopicID
+		}
+		if len(override.TagIDs) > 0 && edit.TargetType == models.SuggestedEditTargetAnswer {
+			tagIDs = override.TagIDs
+			if edit.TagIDs, err = json.Marshal(tagIDs); err != nil {
+				return nil, err
+			}
+		}
+		edit.Improved = true
+	}
+
+	var ownerID uint
+	switch edit.TargetType {
+	case models.SuggestedEditTargetQuestion:
+		question, err := s.questionService.UpdateQuestion(edit.TargetID, edit.Title, edit.Content, edit.TopicID, "")
+		if err != nil {
+			return nil, err
+		}
+		ownerID = question.UserID
+	case models.SuggestedEditTargetAnswer:
+		answer, err := s.answerService.UpdateAnswer(edit.TargetID, edit.Title, edit.Content, tagIDs, "")
+		if err != nil {
+			return nil, err
+		}
+		ownerID = answer.UserID
+	default:
+		return nil, errors.New("invalid target type")
+	}
+
+	now := time.Now()
+	edit.Status = models.SuggestedEditApproved
+	edit.ReviewerID = &reviewerID
+	edit.ReviewNote = note
+	edit.ReviewedAt = &now
+	edit.Conflict = false
+	if err := s.editRepo.ApproveSuggestedEdit(edit, suggestedEditReputation); err != nil {
+		log.Printf("Failed to record approval of suggested edit %d: %v", id, err)
+		return nil, err
+	}
+
+	s.notify(edit.EditorID, "suggested-edit-approved", fmt.Sprintf("Đề xuất chỉnh sửa %s của bạn đã được duyệt", targetLabel(edit.TargetType)))
+	if ownerID != edit.EditorID {
+		s.notify(ownerID, "suggested-edit-applied", fmt.Sprintf("Nội dung %s của bạn vừa được chỉnh sửa theo đề xuất của cộng đồng", targetLabel(edit.TargetType)))
+	}
+	return s.editRepo.GetSuggestedEditByID(id)
+}
+
+func (s *suggestedEditService) RejectSuggestedEdit(id, reviewerID uint, note string) (*models.SuggestedEdit, error) {
+	edit, err := s.editRepo.GetSuggestedEditByID(id)
+	if err != nil {
+		return nil, errors.New("suggested edit not found")
+	}
+	if edit.Status != models.SuggestedEditPending {
+		return nil, errors.New("suggested edit has already been reviewed")
+	}
+	if edit.EditorID == reviewerID {
+		return nil, errors.New("you cannot review your own suggested edit")
+	}
+
+	now := time.Now()
+	edit.Status = models.SuggestedEditRejected
+	edit.ReviewerID = &reviewerID
+	edit.ReviewNote = note
+	edit.ReviewedAt = &now
+	if err := s.editRepo.UpdateSuggestedEdit(edit); err != nil {
+		log.Printf("Failed to reject suggested edit %d: %v", id, err)
+		return nil, err
+	}
+
+	s.notify(edit.EditorID, "suggested-edit-rejected", fmt.Sprintf("Đề xuất chỉnh sửa %s của bạn đã bị từ chối", targetLabel(edit.TargetType)))
+	return s.editRepo.GetSuggestedEditByID(id)
+}
+
+// WithdrawSuggestedEdit cho phép người đề xuất rút lại đề xuất chưa được duyệt
+func (s *suggestedEditService) WithdrawSuggestedEdit(id, editorID uint) error {
+	edit, err := s.editRepo.GetSuggestedEditByID(id)
+	if err != nil {
+		return errors.New("suggested edit not found")
+	}
+	if edit.EditorID != editorID {
+		return errors.New("only the editor can withdraw this suggestion")
+	}
+	if edit.Status != models.SuggestedEditPending {
+		return errors.New("suggested edit has already been reviewed")
+	}
+	return s.editRepo.DeleteSuggestedEdit(id)
+}
+
+func (s *suggestedEditService) notify(userID uint, workflowID, message string) {
+	if err := s.novuClient.SendNotification(userID, workflowID, message); err != nil {
+		log.Printf("Gửi thông báo %s thất bại: %v", workflowID, err)
+	}
+}
+
+func targetLabel(targetType string) string {
+	if targetType == models.SuggestedEditTargetQuestion {
+		return "câu hỏi"
+	}
+	return "câu trả lời"
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"Forum_BE/repositories"
+	"Forum_BE/searchindex"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Chủ đề cần ít nhất ngần này câu hỏi đã duyệt mới được đưa vào mô hình
+	minTopicSamples = 3
+	// Mô hình cũ hơn thời gian này sẽ được huấn luyện lại ở nền
+	topicClassifierTTL = 6 * time.Hour
+	// Bỏ các gợi ý có độ tin cậy thấp hơn ngưỡng này
+	minSuggestConfidence = 0.05
+	// Chỉ tự gán chủ đề cho câu hỏi khi độ tin cậy đủ cao
+	autoAssignConfidence = 0.6
+	trainingBatchSize    = 1000
+)
+
+type TopicSuggestion struct {
+	TopicID    uint
+	Name       string
+	Confidence float64
+}
+
+// TopicClassifierService gợi ý chủ đề cho nội dung bằng Naive Bayes (multinomial)
+// huấn luyện trên các câu hỏi đã duyệt của từng chủ đề. Không bao giờ tạo chủ đề mới.
+type TopicClassifierService interface {
+	Suggest(title, description string, limit int) ([]TopicSuggestion, error)
+	Train() error
+}
+
+type topicModel struct {
+	topics     []uint
+	names      map[uint]string
+	logPrior   map[uint]float64
+	termCounts map[uint]map[string]float64
+	totalTerms map[uint]float64
+	vocabulary map[string]struct{}
+	trainedAt  time.Time
+}
+
+type topicClassifierService struct {
+	topicRepo repositories.TopicRepository
+
+	mu    sync.RWMutex
+	model *topicModel
+	// retraining đánh dấu đã có một lượt huấn luyện lại ở nền, để các request sau không xếp hàng huấn luyện thêm
+	retraining bool
+	training   sync.Mutex
+}
+
+func NewTopicClassifierService(topicRepo repositories.TopicRepository) TopicClassifierService {
+	return &topicClassifierService{topicRepo: topicRepo}
+}
+
+// Train huấn luyện lại toàn bộ mô hình từ DB rồi thay thế mô hình đang dùng
+func (s *topicClassifierService) Train() error {
+	s.training.Lock()
+	defer s.training.Unlock()
+	return s.train()
+}
+
+func (s *topicClassifierService) train() error {
+	docCounts := make(map[uint]int)
+	names := make(map[uint]string)
+	termCounts := make(map[uint]map[string]float64)
+	totalTerms := make(map[uint]float64)
+	vocabulary := make(map[string]struct{})
+
+	var afterID uint
+	for {
+		batch, err := s.topicRepo.ListTrainingSamples(afterID, trainingBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, sample := range batch {
+			afterID = sample.QuestionID
+			terms := documentTerms(sample.Title, sample.PlainContent)
+			if len(terms) == 0 {
+				continue
+			}
+			if termCounts[sample.TopicID] == nil {
+				termCounts[sample.TopicID] = make(map[string]float64)
+			}
+			for term, count := range terms {
+				termCounts[sample.TopicID][term] += count
+				totalTerms[sample.TopicID] += count
+				vocabulary[term] = struct{}{}
+			}
+			docCounts[sample.TopicID]++
+			names[sample.TopicID] = sample.TopicName
+		}
+		if len(batch) < trainingBatchSize {
+			break
+		}
+	}
+
+	model := &topicModel{
+		names:      make(map[uint]string),
+		logPrior:   make(map[uint]float64),
+		termCounts: make(map[uint]map[string]float64),
+		totalTerms: make(map[uint]float64),
+		vocabulary: vocabulary,
+		trainedAt:  time.Now(),
+	}
+	kept := 0
+	for _, count := range docCounts {
+		if count >= minTopicSamples {
+			kept += count
+		}
+	}
+	for topicID, count := range docCounts {
+		if count < minTopicSamples {
+			continue
+		}
+		model.topics = append(model.topics, topicID)
+		model.names[topicID] = names[topicID]
+		model.logPrior[topicID] = math.Log(float64(count) / float64(kept))
+		model.termCounts[topicID] = termCounts[topicID]
+		model.totalTerms[topicID] = totalTerms[topicID]
+	}
+
+	s.mu.Lock()
+	s.model = model
+	s.mu.Unlock()
+
+	log.Printf("Topic classifier trained on %d questions across %d topics", kept, len(model.topics))
+	return nil
+}
+
+// currentModel trả về mô hình hiện tại; lần đầu sẽ huấn luyện đồng bộ,
+// các lần sau nếu mô hình đã cũ thì huấn luyện lại ở nền (mỗi lúc chỉ một lượt).
+func (s *topicClassifierService) currentModel() (*topicModel, error) {
+	s.mu.RLock()
+	model := s.model
+	s.mu.RUnlock()
+
+	if model == nil {
+		s.training.Lock()
+		defer s.training.Unlock()
+		// Request khác có thể đã huấn luyện xong trong lúc chờ khoá
+		s.mu.RLock()
+		model = s.model
+		s.mu.RUnlock()
+		if model != nil {
+			return model, nil
+		}
+		if err := s.train(); err != nil {
+			return nil, err
+		}
+		s.mu.RLock()
+		model = s.model
+		s.mu.RUnlock()
+		return model, nil
+	}
+
+	if time.Since(model.trainedAt) > topicClassifierTTL {
+		s.mu.Lock()
+		start := !s.retraining
+		s.retraining = true
+		s.mu.Unlock()
+		if start {
+			go s.retrainStale()
+		}
+	}
+	return model, nil
+}
+
+// retrainStale huấn luyện lại ở nền, bỏ qua nếu cron vừa huấn luyện xong trong lúc chờ khoá
+func (s *topicClassifierService) retrainStale() {
+	defer func() {
+		s.mu.Lock()
+		s.retraining = false
+		s.mu.Unlock()
+	}()
+	s.training.Lock()
+	defer s.training.Unlock()
+
+	s.mu.RLock()
+	fresh := s.model != nil && time.Since(s.model.trainedAt) <= topicClassifierTTL
+	s.mu.RUnlock()
+	if fresh {
+		return
+	}
+	if err := s.train(); err != nil {
+		log.Printf("Failed to retrain topic classifier: %v", err)
+	}
+}
+
+func (s *topicClassifierService) Suggest(title, description string, limit int) ([]TopicSuggestion, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+	model, err := s.currentModel()
+	if err != nil {
+		return nil, err
+	}
+	if len(model.topics) == 0 {
+		return []TopicSuggestion{}, nil
+	}
+
+	// Chỉ dùng các term đã xuất hiện trong dữ liệu huấn luyện
+	terms := documentTerms(title, description)
+	known := make(map[string]float64)
+	for term, count := range terms {
+		if _, ok := model.vocabulary[term]; ok {
+			known[term] = count
+		}
+	}
+	if len(known) == 0 {
+		return []TopicSuggestion{}, nil
+	}
+
+	vocabSize := float64(len(model.vocabulary))
+	scores := make(map[uint]float64, len(model.topics))
+	maxScore := math.Inf(-1)
+	for _, topicID := range model.topics {
+		score := model.logPrior[topicID]
+		denominator := model.totalTerms[topicID] + vocabSize
+		for term, count := range known {
+			score += count * math.Log((model.termCounts[topicID][term]+1)/denominator)
+		}
+		scores[topicID] = score
+		if score > maxScore {
+			maxScore = score
+		}
+	}
+
+	// Chuẩn hoá log-likelihood thành xác suất hậu nghiệm (softmax)
+	var sum float64
+	for _, score := range scores {
+		sum += math.Exp(score - maxScore)
+	}
+	suggestions := make([]TopicSuggestion, 0, len(scores))
+	for topicID, score := range scores {
+		confidence := math.Exp(score-maxScore) / sum
+		if confidence < minSuggestConfidence {
+			continue
+		}
+		suggestions = append(suggestions, TopicSuggestion{
+			TopicID:    topicID,
+			Name:       model.names[topicID],
+			Confidence: math.Round(confidence*1000) / 1000,
+		})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].TopicID < suggestions[j].TopicID
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// documentTerms đếm term của tiêu đề (trọng số gấp đôi) và nội dung
+func documentTerms(title, body string) map[string]float64 {
+	terms := make(map[string]float64)
+	for _, term := range searchindex.Analyze(title) {
+		terms[term] += 2
+	}
+	for _, term := range searchindex.Analyze(body) {
+		terms[term]++
+	}
+	return terms
+}
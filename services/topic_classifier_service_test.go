@@ -0,0 +1,103 @@
+package services
+
+import (
+	"Forum_BE/repositories"
+	"testing"
+)
+
+// fakeTopicRepo chỉ cài ListTrainingSamples; các hàm khác của TopicRepository không được bộ phân loại dùng
+type fakeTopicRepo struct {
+	repositories.TopicRepository
+	samples []repositories.TopicTrainingSample
+}
+
+func (r *fakeTopicRepo) ListTrainingSamples(afterID uint, limit int) ([]repositories.TopicTrainingSample, error) {
+	var batch []repositories.TopicTrainingSample
+	for _, sample := range r.samples {
+		if sample.QuestionID > afterID && len(batch) < limit {
+			batch = append(batch, sample)
+		}
+	}
+	return batch, nil
+}
+
+func topicSamples() []repositories.TopicTrainingSample {
+	var samples []repositories.TopicTrainingSample
+	add := func(topicID uint, name string, titles ...string) {
+		for _, title := range titles {
+			samples = append(samples, repositories.TopicTrainingSample{
+				QuestionID: uint(len(samples) + 1),
+				TopicID:    topicID,
+				TopicName:  name,
+				Title:      title,
+			})
+		}
+	}
+	add(1, "Lập trình", "Lỗi goroutine trong Golang", "Cách dùng channel trong Golang", "Golang xử lý lỗi thế nào", "Viết test cho Golang")
+	add(2, "Nấu ăn", "Cách nấu phở bò", "Công thức nấu bún chả", "Nấu phở gà ngon", "Món bún bò Huế")
+	// Chủ đề chưa đủ minTopicSamples câu hỏi không được đưa vào mô hình
+	add(3, "Du lịch", "Du lịch Đà Lạt golang")
+	return samples
+}
+
+func TestTopicClassifierSuggest(t *testing.T) {
+	s := &topicClassifierService{topicRepo: &fakeTopicRepo{samples: topicSamples()}}
+	if err := s.Train(); err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	if len(s.model.topics) != 2 {
+		t.Fatalf("trained topics = %v, want 2 topics", s.model.topics)
+	}
+
+	tests := []struct {
+		name      string
+		title     string
+		body      string
+		wantTopic uint
+		wantNone  bool
+	}{
+		{"programming", "Golang channel bị deadlock", "", 1, false},
+		{"cooking", "Nấu phở thế nào", "", 2, false},
+		{"accent insensitive", "nau pho", "", 2, false},
+		{"body counts too", "Hỏi nhanh", "goroutine và channel", 1, false},
+		{"unknown words", "Thời tiết hôm nay", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions, err := s.Suggest(tt.title, tt.body, 3)
+			if err != nil {
+				t.Fatalf("Suggest: %v", err)
+			}
+			if tt.wantNone {
+				if len(suggestions) != 0 {
+					t.Errorf("suggestions = %+v, want none", suggestions)
+				}
+				return
+			}
+			if len(suggestions) == 0 || suggestions[0].TopicID != tt.wantTopic {
+				t.Fatalf("suggestions = %+v, want topic %d first", suggestions, tt.wantTopic)
+			}
+			if suggestions[0].Confidence < autoAssignConfidence {
+				t.Errorf("confidence = %v, want at least %v", suggestions[0].Confidence, autoAssignConfidence)
+			}
+			var sum float64
+			for _, suggestion := range suggestions {
+				sum += suggestion.Confidence
+			}
+			if sum > 1.001 {
+				t.Errorf("confidences sum to %v, want at most 1", sum)
+			}
+		})
+	}
+}
+
+func TestTopicClassifierSuggestLimit(t *testing.T) {
+	s := &topicClassifierService{topicRepo: &fakeTopicRepo{samples: topicSamples()}}
+	suggestions, err := s.Suggest("Golang nấu phở", "", 1)
+	if err != nil {
+		t.Fatalf("Suggest: %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Errorf("got %d suggestions, want 1", len(suggestions))
+	}
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"Forum_BE/models"
+	"Forum_BE/notification"
+	"Forum_BE/repositories"
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultVoteFraudWindowDays = 30
+	// Số lượt ủng hộ tối thiểu một người dành cho cùng một tác giả để bị coi là bỏ phiếu hàng loạt
+	defaultSerialVoteThreshold = 10
+	// Số lượt ủng hộ tối thiểu mỗi chiều giữa hai thành viên để tính là một cạnh của vòng bỏ phiếu
+	defaultVoteRingThreshold = 5
+)
+
+// Các vai trò nhận báo cáo gian lận phiếu bầu
+var voteFraudModeratorRoles = []models.Role{models.RoleRoot, models.RoleAdmin}
+
+// VoteFraudResult tóm tắt một lần quét gian lận phiếu bầu
+type VoteFraudResult struct {
+	SerialCases          int `json:"serial_cases"`
+	RingCases            int `json:"ring_cases"`
+	InvalidatedVotes     int `json:"invalidated_votes"`
+	InvalidatedReactions int `json:"invalidated_reactions"`
+}
+
+// VoteFraudService phát hiện bỏ phiếu hàng loạt (một người liên tục ủng hộ một tác giả) và vòng bỏ phiếu
+// (một nhóm tài khoản ủng hộ qua lại lẫn nhau) trên upvote và tương tác, huỷ các lượt ủng hộ vi phạm,
+// ghi báo cáo cho người kiểm duyệt và gửi thông báo kèm bằng chứng.
+type VoteFraudService interface {
+	Detect() (*VoteFraudResult, error)
+}
+
+type voteFraudService struct {
+	voteRepo        repositories.VoteRepository
+	reactionRepo    repositories.ReactionRepository
+	reportRepo      repositories.ReportRepository
+	userRepo        repositories.UserRepository
+	reactionCounter ReactionCounter
+	redisClient     *redis.Client
+	novuClient      *notification.NovuClient
+	window          time.Duration
+	serialThreshold int64
+	ringThreshold   int64
+}
+
+func NewVoteFraudService(voteRepo repositories.VoteRepository, reactionRepo repositories.ReactionRepository, reportRepo repositories.ReportRepository, userRepo repositories.UserRepository, reactionCounter ReactionCounter, redisClient *redis.Client, novuClient *notification.NovuClient) VoteFraudService {
+	return &voteFraudService{
+		voteRepo:        voteRepo,
+		reactionRepo:    reactionRepo,
+		reportRepo:      reportRepo,
+		userRepo:        userRepo,
+		reactionCounter: reactionCounter,
+		redisClient:     redisClient,
+		novuClient:      novuClient,
+		window:          time.Duration(envThreshold("VOTE_FRAUD_WINDOW_DAYS", defaultVoteFraudWindowDays)) * 24 * time.Hour,
+		serialThreshold: int64(envThreshold("VOTE_FRAUD_SERIAL_THRESHOLD", defaultSerialVoteThreshold)),
+		ringThreshold:   int64(envThreshold("VOTE_FRAUD_RING_THRESHOLD", defaultVoteRingThreshold)),
+	}
+}
+
+// voteEdgeKey là một chiều ủng hộ từ voter tới author
+type voteEdgeKey struct {
+	voter  uint
+	author uint
+}
+
+type voteEdgeCount struct {
+	votes     int64
+	reactions int64
+}
+
+func (c voteEdgeCount) total() int64 {
+	return c.votes + c.reactions
+}
+
+// voteFraudCase là một vụ việc cần xử lý: các chiều ủng hộ bị huỷ và bằng chứng kèm theo
+type voteFraudCase struct {
+	kind    string
+	members []uint
+	edges   []voteEdgeKey
+}
+
+func (s *voteFraudService) Detect() (*VoteFraudResult, error) {
+	since := time.Now().Add(-s.window)
+	edges, err := s.loadEdges(since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tổng lượt ủng hộ của mỗi người trong cửa sổ, dùng để xét mức độ tập trung
+	given := make(map[uint]int64)
+	for key, count := range edges {
+		given[key.voter] += count.total()
+	}
+
+	var cases []voteFraudCase
+	inRing := make(map[uint]int)
+	for i, members := range s.findRings(edges) {
+		c := voteFraudCase{kind: "ring", members: members}
+		for _, member := range members {
+			inRing[member] = i + 1
+		}
+		for key := range edges {
+			if inRing[key.voter] == i+1 && inRing[key.author] == i+1 {
+				c.edges = append(c.edges, key)
+			}
+		}
+		sortEdges(c.edges)
+		cases = append(cases, c)
+	}
+
+	// Bỏ phiếu hàng loạt: nhiều lượt cho cùng một tác giả và chiếm ít nhất một nửa số lượt ủng hộ của người đó
+	var serial []voteEdgeKey
+	for key, count := range edges {
+		if inRing[key.voter] != 0 && inRing[key.voter] == inRing[key.author] {
+			continue
+		}
+		if count.total() >= s.serialThreshold && count.total()*2 >= given[key.voter] {
+			serial = append(serial, key)
+		}
+	}
+	sortEdges(serial)
+	for _, key := range serial {
+		cases = append(cases, voteFraudCase{kind: "serial", members: []uint{key.voter, key.author}, edges: []voteEdgeKey{key}})
+	}
+
+	result := &VoteFraudResult{}
+	if len(cases) == 0 {
+		return result, nil
+	}
+	// Báo cáo vẫn được lưu vào hàng đợi khi chưa có người kiểm duyệt nào để thông báo
+	moderators, err := s.userRepo.ListUsersByRoles(voteFraudModeratorRoles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderators for vote fraud: %v", err)
+	}
+	for _, c := range cases {
+		votes, reactions, err := s.handleCase(c, edges, since, moderators)
+		if err != nil {
+			log.Printf("Failed to handle %s vote fraud among users %v: %v", c.kind, c.members, err)
+			continue
+		}
+		if c.kind == "ring" {
+			result.RingCases++
+		} else {
+			result.SerialCases++
+		}
+		result.InvalidatedVotes += votes
+		result.InvalidatedReactions += reactions
+	}
+	return result, nil
+}
+
+// loadEdges gộp upvote và tương tác thành các chiều ủng hộ giữa người dùng
+func (s *voteFraudService) loadEdges(since time.Time) (map[voteEdgeKey]voteEdgeCount, error) {
+	voteEdges, err := s.voteRepo.UpvoteEdges(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load upvote edges: %v", err)
+	}
+	reactionEdges, err := s.reactionRepo.ReactionEdges(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reaction edges: %v", err)
+	}
+	edges := make(map[voteEdgeKey]voteEdgeCount, len(voteEdges)+len(reactionEdges))
+	for _, edge := range voteEdges {
+		key := voteEdgeKey{voter: edge.VoterID, author: edge.AuthorID}
+		count := edges[key]
+		count.votes += edge.Count
+		edges[key] = count
+	}
+	for _, edge := range reactionEdges {
+		key := voteEdgeKey{voter: edge.VoterID, author: edge.AuthorID}
+		count := edges[key]
+		count.reactions += edge.Count
+		edges[key] = count
+	}
+	return edges, nil
+}
+
+// findRings trả về các thành phần liên thông mạnh (thuật toán Tarjan) có từ hai thành viên trở lên trên đồ thị
+// các chiều ủng hộ vượt ngưỡng; trong mỗi thành phần, mọi thành viên vừa ủng hộ vừa được ủng hộ trong nhóm
+func (s *voteFraudService) findRings(edges map[voteEdgeKey]voteEdgeCount) [][]uint {
+	graph := make(map[uint][]uint)
+	var nodes []uint
+	for key, count := range edges {
+		if count.total() < s.ringThreshold {
+			continue
+		}
+		if _, ok := graph[key.voter]; !ok {
+			nodes = append(nodes, key.voter)
+		}
+		graph[key.voter] = append(graph[key.voter], key.author)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+
+	index := make(map[uint]int)
+	low := make(map[uint]int)
+	onStack := make(map[uint]bool)
+	var stack []uint
+	var rings [][]uint
+	next := 1
+
+	var visit func(node uint)
+	visit = func(node uint) {
+		index[node], low[node] = next, next
+		next++
+		stack = append(stack, node)
+		onStack[node] = true
+		for _, target := range graph[node] {
+			if index[target] == 0 {
+				visit(target)
+				low[node] = min(low[node], low[target])
+			} else if onStack[target] {
+				low[node] = min(low[node], index[target])
+			}
+		}
+		if low[node] != index[node] {
+			return
+		}
+		var component []uint
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == node {
+				break
+			}
+		}
+		if len(component) >= 2 {
+			sort.Slice(component, func(i, j int) bool { return component[i] < component[j] })
+			rings = append(rings, component)
+		}
+	}
+	for _, node := range nodes {
+		if index[node] == 0 {
+			visit(node)
+		}
+	}
+	return rings
+}
+
+func sortEdges(edges []voteEdgeKey) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].voter != edges[j].voter {
+			return edges[i].voter < edges[j].voter
+		}
+		return edges[i].author < edges[j].author
+	})
+}
+
+// handleCase huỷ các lượt ủng hộ của vụ việc, ghi báo cáo và thông báo cho người kiểm duyệt
+func (s *voteFraudService) handleCase(c voteFraudCase, edges map[voteEdgeKey]voteEdgeCount, since time.Time, moderators []models.User) (int, int, error) {
+	now := time.Now()
+	names := make(map[uint]string, len(c.members))
+	for _, id := range c.members {
+		names[id] = fmt.Sprintf("#%d", id)
+		if user, err := s.userRepo.GetUserByID(id); err == nil {
+			names[id] = fmt.Sprintf("%s (#%d)", user.Username, id)
+		}
+	}
+
+	invalidatedVotes, invalidatedReactions := 0, 0
+	var evidence []string
+	for _, key := range c.edges {
+		voteIDs, err := s.voteRepo.UpvoteIDsBetween(key.voter, key.author, since)
+		if err != nil {
+			return 0, 0, err
+		}
+		if err := s.voteRepo.InvalidateVotes(voteIDs, now); err != nil {
+			return 0, 0, err
+		}
+		reactions, err := s.reactionRepo.ReactionsBetween(key.voter, key.author, since)
+		if err != nil {
+			return 0, 0, err
+		}
+		reactionIDs := make([]uint, 0, len(reactions))
+		for _, reaction := range reactions {
+			reactionIDs = append(reactionIDs, reaction.ID)
+		}
+		if err := s.reactionRepo.InvalidateReactions(reactionIDs); err != nil {
+			return 0, 0, err
+		}
+		for i := range reactions {
+			targetType, targetID := reactions[i].Target()
+			s.reactionCounter.Adjust(targetType, targetID, reactions[i].Type, -1)
+		}
+		invalidatedVotes += len(voteIDs)
+		invalidatedReactions += len(reactions)
+		evidence = append(evidence, fmt.Sprintf("%s → %s: %d upvote, %d tương tác (trên tổng %d lượt ủng hộ từ %s)",
+			names[key.voter], names[key.author], edges[key].votes, edges[key].reactions, edgeTotalFrom(edges, key.voter), since.Format("02/01/2006")))
+	}
+
+	memberNames := make([]string, 0, len(c.members))
+	for _, id := range c.members {
+		memberNames = append(memberNames, names[id])
+	}
+	reason := "Bỏ phiếu hàng loạt"
+	summary := fmt.Sprintf("%s liên tục ủng hộ nội dung của %s", names[c.members[0]], names[c.members[1]])
+	if c.kind == "ring" {
+		reason = "Vòng bỏ phiếu qua lại"
+		summary = fmt.Sprintf("Nhóm %s ủng hộ qua lại lẫn nhau", strings.Join(memberNames, ", "))
+	}
+	details := fmt.Sprintf("%s. Đã huỷ %d upvote và %d tương tác.\n%s", summary, invalidatedVotes, invalidatedReactions, strings.Join(evidence, "\n"))
+
+	report := &models.Report{
+		ID:             fmt.Sprintf("report-vote-%s-%d-%d", c.kind, now.UnixNano(), c.members[0]),
+		Reason:         reason,
+		Details:        details,
+		Source:         models.ReportSourceVoteFraud,
+		ContentType:    "user",
+		ContentID:      fmt.Sprintf("%d", c.members[0]),
+		ContentPreview: summary,
+	}
+	if err := s.reportRepo.CreateReport(report); err != nil {
+		return 0, 0, fmt.Errorf("failed to create vote fraud report: %v", err)
+	}
+	s.invalidateReportCache()
+
+	message := fmt.Sprintf("%s: %s. Đã huỷ %d upvote và %d tương tác. Bằng chứng: %s", reason, summary, invalidatedVotes, invalidatedReactions, strings.Join(evidence, "; "))
+	for _, moderator := range moderators {
+		if err := s.novuClient.SendNotification(moderator.ID, "vote-fraud-notification", message); err != nil {
+			log.Printf("Gửi thông báo gian lận phiếu bầu thất bại: %v", err)
+		}
+	}
+	log.Printf("Handled %s vote fraud among users %v: %d votes and %d reactions invalidated, report %s", c.kind, c.members, invalidatedVotes, invalidatedReactions, report.ID)
+	return invalidatedVotes, invalidatedReactions, nil
+}
+
+func edgeTotalFrom(edges map[voteEdgeKey]voteEdgeCount, voter uint) int64 {
+	var total int64
+	for key, count := range edges {
+		if key.voter == voter {
+			total += count.total()
+		}
+	}
+	return total
+}
+
+func (s *voteFraudService) invalidateReportCache() {
+	if s.redisClient == nil {
+		return
+	}
+	ctx := context.Background()
+	keys, err := s.redisClient.Keys(ctx, "reports:*").Result()
+	if err != nil || len(keys) == 0 {
+		return
+	}
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		log.Printf("Failed to delete report cache keys: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFindRings(t *testing.T) {
+	strong := voteEdgeCount{votes: 3, reactions: 2}
+	weak := voteEdgeCount{votes: 2, reactions: 2}
+
+	tests := []struct {
+		name  string
+		edges map[voteEdgeKey]voteEdgeCount
+		want  [][]uint
+	}{
+		{
+			name:  "no edges",
+			edges: map[voteEdgeKey]voteEdgeCount{},
+		},
+		{
+			name: "one way support is not a ring",
+			edges: map[voteEdgeKey]voteEdgeCount{
+				{1, 2}: strong,
+				{2, 3}: strong,
+			},
+		},
+		{
+			name: "mutual pair",
+			edges: map[voteEdgeKey]voteEdgeCount{
+				{1, 2}: strong,
+				{2, 1}: strong,
+			},
+			want: [][]uint{{1, 2}},
+		},
+		{
+			name: "edge below threshold breaks the ring",
+			edges: map[voteEdgeKey]voteEdgeCount{
+				{1, 2}: strong,
+				{2, 1}: weak,
+			},
+		},
+		{
+			name: "votes and reactions add up",
+			edges: map[voteEdgeKey]voteEdgeCount{
+				{1, 2}: {votes: 5},
+				{2, 1}: {reactions: 5},
+			},
+			want: [][]uint{{1, 2}},
+		},
+		{
+			name: "three member cycle with an outside voter",
+			edges: map[voteEdgeKey]voteEdgeCount{
+				{4, 1}: strong,
+				{1, 2}: strong,
+				{2, 3}: strong,
+				{3, 1}: strong,
+			},
+			want: [][]uint{{1, 2, 3}},
+		},
+		{
+			name: "separate rings",
+			edges: map[voteEdgeKey]voteEdgeCount{
+				{1, 2}:   strong,
+				{2, 1}:   strong,
+				{10, 11}: strong,
+				{11, 12}: strong,
+				{12, 10}: strong,
+				{2, 10}:  strong,
+			},
+			want: [][]uint{{1, 2}, {10, 11, 12}},
+		},
+	}
+	s := &voteFraudService{ringThreshold: 5}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.findRings(tt.edges)
+			sort.Slice(got, func(i, j int) bool { return got[i][0] < got[j][0] })
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findRings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -57,5 +57,11 @@ func GenerateCacheKey(prefix string, id uint, filters map[string]interface{}) st
 	if author, ok := filters["author"]; ok {
 		key += fmt.Sprintf("author:%v:", author)
 	}
+	if cursor, ok := filters["cursor"]; ok {
+		key += fmt.Sprintf("cursor:%v:", cursor)
+	}
+	if withTotal, ok := filters["with_total"]; ok {
+		key += fmt.Sprintf("with_total:%v:", withTotal)
+	}
 	return key
 }
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrCursorSecretMissing = errors.New("CURSOR_SECRET or JWT_SECRET must be set to sign pagination cursors")
+)
+
+// Cursor là vị trí keyset (giá trị cột sắp xếp, id) của phần tử biên của một trang.
+// Ref thay cho ID với các bảng có khoá chính dạng chuỗi; Position thay cho Time khi cột sắp xếp là số
+// (ví dụ thứ tự do người dùng sắp xếp).
+// Sort ghi lại chiều sắp xếp lúc phát hành để trang sau giữ đúng thứ tự;
+// Backward cho biết cần lấy các phần tử đứng trước vị trí này (trang trước).
+type Cursor struct {
+	Time     time.Time `json:"t"`
+	ID       uint      `json:"i,omitempty"`
+	Ref      string    `json:"r,omitempty"`
+	Position *int      `json:"p,omitempty"`
+	Sort     string    `json:"s"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+// String dùng làm một phần của cache key
+func (c Cursor) String() string {
+	value := fmt.Sprint(c.Time.UnixNano())
+	if c.Position != nil {
+		value = "p" + fmt.Sprint(*c.Position)
+	}
+	return fmt.Sprintf("%s.%d.%s.%s.%t", value, c.ID, c.Ref, c.Sort, c.Backward)
+}
+
+// Value là giá trị cột sắp xếp tại vị trí cursor
+func (c Cursor) Value() interface{} {
+	if c.Position != nil {
+		return *c.Position
+	}
+	return c.Time
+}
+
+// EncodeCursor trả về chuỗi cursor mờ: base64url(JSON) + "." + HMAC-SHA256 để client không sửa được.
+// Trả về chuỗi rỗng (không có trang tiếp) khi chưa cấu hình khoá ký.
+func EncodeCursor(c Cursor) string {
+	if cursorSecret() == "" {
+		return ""
+	}
+	payload, _ := json.Marshal(c)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signCursor(encoded)
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	if cursorSecret() == "" {
+		return nil, ErrInvalidCursor
+	}
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signCursor(encoded))) {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != "asc" && cursor.Sort != "desc" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// CheckCursorSecret được gọi lúc khởi động để không chạy server với khoá ký cursor rỗng
+func CheckCursorSecret() error {
+	if cursorSecret() == "" {
+		return ErrCursorSecretMissing
+	}
+	return nil
+}
+
+// Khoá ký cursor lấy từ CURSOR_SECRET, nếu không có thì dùng JWT_SECRET
+func cursorSecret() string {
+	if secret := os.Getenv("CURSOR_SECRET"); secret != "" {
+		return secret
+	}
+	return os.Getenv("JWT_SECRET")
+}
+
+func signCursor(encoded string) string {
+	mac := hmac.New(sha256.New, []byte(cursorSecret()))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	t.Setenv("CURSOR_SECRET", "test-secret")
+	position := 7
+	at := time.Date(2026, 3, 1, 10, 30, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{"time and id", Cursor{Time: at, ID: 42, Sort: "desc"}},
+		{"backward", Cursor{Time: at, ID: 42, Sort: "asc", Backward: true}},
+		{"string ref", Cursor{Time: at, Ref: "question:9", Sort: "asc"}},
+		{"position", Cursor{Position: &position, ID: 3, Sort: "asc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := EncodeCursor(tt.cursor)
+			if token == "" {
+				t.Fatal("EncodeCursor returned an empty token")
+			}
+			got, err := DecodeCursor(token)
+			if err != nil {
+				t.Fatalf("DecodeCursor: %v", err)
+			}
+			if got.String() != tt.cursor.String() {
+				t.Errorf("round trip = %s, want %s", got, tt.cursor)
+			}
+			if !got.Time.Equal(tt.cursor.Time) {
+				t.Errorf("Time = %v, want %v", got.Time, tt.cursor.Time)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	t.Setenv("CURSOR_SECRET", "test-secret")
+	valid := EncodeCursor(Cursor{Time: time.Unix(1700000000, 0), ID: 1, Sort: "desc"})
+	payload, signature, _ := strings.Cut(valid, ".")
+	badSort := EncodeCursor(Cursor{ID: 1, Sort: "sideways"})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", payload},
+		{"tampered payload", "x" + payload + "." + signature},
+		{"tampered signature", payload + "." + signature + "x"},
+		{"not base64", "!!!." + signCursor("!!!")},
+		{"not json", "bm90LWpzb24." + signCursor("bm90LWpzb24")},
+		{"invalid sort", badSort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.token); err != ErrInvalidCursor {
+				t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", tt.token, err)
+			}
+		})
+	}
+}
+
+func TestCursorSignedWithOtherSecret(t *testing.T) {
+	t.Setenv("CURSOR_SECRET", "first")
+	token := EncodeCursor(Cursor{ID: 1, Sort: "asc"})
+
+	t.Setenv("CURSOR_SECRET", "second")
+	if _, err := DecodeCursor(token); err != ErrInvalidCursor {
+		t.Errorf("DecodeCursor with rotated secret error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestCursorWithoutSecret(t *testing.T) {
+	t.Setenv("CURSOR_SECRET", "")
+	t.Setenv("JWT_SECRET", "")
+
+	if err := CheckCursorSecret(); err != ErrCursorSecretMissing {
+		t.Errorf("CheckCursorSecret error = %v, want ErrCursorSecretMissing", err)
+	}
+	if token := EncodeCursor(Cursor{ID: 1, Sort: "asc"}); token != "" {
+		t.Errorf("EncodeCursor without secret = %q, want empty", token)
+	}
+	if _, err := DecodeCursor("e30.abc"); err != ErrInvalidCursor {
+		t.Errorf("DecodeCursor without secret error = %v, want ErrInvalidCursor", err)
+	}
+
+	t.Setenv("JWT_SECRET", "fallback")
+	if err := CheckCursorSecret(); err != nil {
+		t.Errorf("CheckCursorSecret with JWT_SECRET error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Feed là dữ liệu chung để xuất ra Atom 1.0 hoặc RSS 2.0
+type Feed struct {
+	ID       string
+	Title    string
+	Subtitle string
+	Link     string // trang HTML tương ứng
+	SelfLink string // URL của chính feed
+	Updated  time.Time
+	Entries  []FeedEntry
+}
+
+type FeedEntry struct {
+	ID         string
+	Title      string
+	Link       string
+	Content    string // HTML đã được làm sạch
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// ETag đổi khi tập bài viết hoặc thời điểm cập nhật của bất kỳ bài nào thay đổi
+func (f *Feed) ETag() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s|%s|%d", f.ID, f.Title, f.Updated.UnixNano())
+	for _, entry := range f.Entries {
+		fmt.Fprintf(hash, "|%s:%d", entry.ID, entry.Updated.UnixNano())
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      atomText       `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomText       `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    atomText    `xml:"title"`
+	Subtitle *atomText   `xml:"subtitle,omitempty"`
+	Links    []atomLink  `xml:"link"`
+	Updated  string      `xml:"updated"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+// RenderAtom xuất feed theo chuẩn Atom 1.0; nội dung HTML được escape trong phần tử type="html"
+func RenderAtom(f *Feed) ([]byte, error) {
+	feed := atomFeed{
+		ID:      f.ID,
+		Title:   atomText{Type: "text", Body: f.Title},
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.SelfLink, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+	if f.Subtitle != "" {
+		feed.Subtitle = &atomText{Type: "text", Body: f.Subtitle}
+	}
+	for _, entry := range f.Entries {
+		item := atomEntry{
+			ID:        entry.ID,
+			Title:     atomText{Type: "text", Body: entry.Title},
+			Links:     []atomLink{{Href: entry.Link, Rel: "alternate", Type: "text/html"}},
+			Published: entry.Published.UTC().Format(time.RFC3339),
+			Updated:   entry.Updated.UTC().Format(time.RFC3339),
+			Content:   atomText{Type: "html", Body: entry.Content},
+		}
+		if entry.Author != "" {
+			item.Author = &atomPerson{Name: entry.Author}
+		}
+		for _, category := range entry.Categories {
+			item.Categories = append(item.Categories, atomCategory{Term: category})
+		}
+		feed.Entries = append(feed.Entries, item)
+	}
+	return marshalFeed(feed)
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Author      string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// RenderRSS xuất feed theo chuẩn RSS 2.0; tác giả dùng dc:creator vì <author> của RSS yêu cầu email
+func RenderRSS(f *Feed) ([]byte, error) {
+	description := f.Subtitle
+	if description == "" {
+		description = f.Title
+	}
+	feed := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   description,
+			AtomLink:      atomLink{Href: f.SelfLink, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, entry := range f.Entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			GUID:        rssGUID{IsPermaLink: false, Value: entry.ID},
+			PubDate:     entry.Published.UTC().Format(time.RFC1123Z),
+			Author:      entry.Author,
+			Categories:  entry.Categories,
+			Description: entry.Content,
+		})
+	}
+	return marshalFeed(feed)
+}
+
+func marshalFeed(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// SearchTerms tách chuỗi tìm kiếm thành các từ khoá (chữ thường, bỏ trùng)
+func SearchTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if field == "" || seen[field] {
+			continue
+		}
+		seen[field] = true
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+// Snippet cắt một đoạn quanh từ khoá đầu tiên tìm thấy và bọc các từ khoá bằng <mark>.
+// Kết quả đã được escape HTML nên có thể hiển thị trực tiếp.
+func Snippet(text, query string, radius int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	terms := SearchTerms(query)
+
+	start, end := 0, len(runes)
+	if pos := firstMatch(lower, terms); pos >= 0 {
+		start = pos - radius
+		if start < 0 {
+			start = 0
+		}
+	}
+	if start+2*radius < end {
+		end = start + 2*radius
+	}
+
+	snippet := highlightRunes(runes[start:end], lower[start:end], terms)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+func firstMatch(lower []rune, terms []string) int {
+	best := -1
+	s := string(lower)
+	for _, term := range terms {
+		idx := strings.Index(s, term)
+		if idx < 0 {
+			continue
+		}
+		pos := len([]rune(s[:idx]))
+		if best < 0 || pos < best {
+			best = pos
+		}
+	}
+	return best
+}
+
+func highlightRunes(runes, lower []rune, terms []string) string {
+	var b strings.Builder
+	i := 0
+	for i < len(runes) {
+		matched := 0
+		for _, term := range terms {
+			t := []rune(term)
+			if len(t) > matched && hasPrefixRunes(lower[i:], t) {
+				matched = len(t)
+			}
+		}
+		if matched > 0 {
+			b.WriteString("<mark>")
+			b.WriteString(html.EscapeString(string(runes[i : i+matched])))
+			b.WriteString("</mark>")
+			i += matched
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+	return b.String()
+}
+
+func hasPrefixRunes(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"bytes"
+	"log"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// Định dạng nội dung, trùng với models.ContentFormat
+const contentFormatMarkdown = "markdown"
+
+// markdownRenderer: CommonMark + GFM (bảng, gạch ngang, autolink, task list).
+// Khối code có chỉ định ngôn ngữ được render thành <code class="language-xxx">.
+// HTML thô trong markdown được giữ lại ở bước render và sẽ đi qua SanitizeHTML.
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// RenderMarkdown render markdown sang HTML đã được lọc
+func RenderMarkdown(source string) string {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(source), &buf); err != nil {
+		log.Printf("Failed to render markdown: %v", err)
+		return SanitizeHTML(source)
+	}
+	return SanitizeHTML(buf.String())
+}
+
+// RenderContent trả về HTML sẽ được lưu: markdown thì render từ source, còn lại thì lọc trực tiếp content
+func RenderContent(format, source, content string) string {
+	if format == contentFormatMarkdown {
+		return RenderMarkdown(source)
+	}
+	return SanitizeHTML(content)
+}
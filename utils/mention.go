@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Số lời nhắc tối đa được xử lý trong một nội dung
+const maxMentionsPerContent = 20
+
+// @username đứng đầu chuỗi hoặc sau ký tự không phải chữ/số, để không bắt nhầm email (a@b.com)
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@.])@([\p{L}\p{N}_.\-]{2,50})`)
+
+// ExtractMentions trả về các username được nhắc trong HTML (không trùng, giữ thứ tự xuất hiện).
+// Bỏ qua chữ trong code/pre/script/style để đoạn mã như @Override không bị tính là lời nhắc.
+func ExtractMentions(content string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	skipDepth := 0
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return usernames
+		case html.StartTagToken:
+			if name, _ := tokenizer.TagName(); isMentionSkipTag(string(name)) {
+				skipDepth++
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); isMentionSkipTag(string(name)) && skipDepth > 0 {
+				skipDepth--
+			}
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			for _, match := range mentionPattern.FindAllStringSubmatch(string(tokenizer.Text()), -1) {
+				username := strings.TrimRight(match[1], ".-")
+				key := strings.ToLower(username)
+				if len(username) < 2 || seen[key] {
+					continue
+				}
+				seen[key] = true
+				usernames = append(usernames, username)
+				if len(usernames) >= maxMentionsPerContent {
+					return usernames
+				}
+			}
+		}
+	}
+}
+
+func isMentionSkipTag(tag string) bool {
+	return tag == "code" || tag == "pre" || tag == "script" || tag == "style"
+}
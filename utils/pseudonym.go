@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// Pseudonym trả về bí danh ổn định của một người dùng trong một luồng thảo luận (ví dụ "question:12"):
+// cùng người trong cùng luồng luôn ra cùng bí danh, sang luồng khác thì khác để không lần ra được danh tính.
+// Khoá lấy từ ANONYMOUS_SECRET, nếu không có thì dùng JWT_SECRET.
+func Pseudonym(thread string, userID uint) string {
+	secret := os.Getenv("ANONYMOUS_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s|%d", thread, userID)
+	return "Người dùng ẩn danh #" + hex.EncodeToString(mac.Sum(nil))[:6]
+}
+
+// QuestionThread là khoá luồng dùng chung cho câu hỏi, các câu trả lời và bình luận bên dưới
+func QuestionThread(questionID uint) string {
+	return fmt.Sprintf("question:%d", questionID)
+}
+
+func PostThread(postID uint) string {
+	return fmt.Sprintf("post:%d", postID)
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPseudonym(t *testing.T) {
+	t.Setenv("ANONYMOUS_SECRET", "test-secret")
+
+	const prefix = "Người dùng ẩn danh #"
+	tests := []struct {
+		name             string
+		threadA, threadB string
+		userA, userB     uint
+		same             bool
+	}{
+		{"stable for the same user and thread", QuestionThread(1), QuestionThread(1), 7, 7, true},
+		{"differs between users", QuestionThread(1), QuestionThread(1), 7, 8, false},
+		{"differs between questions", QuestionThread(1), QuestionThread(2), 7, 7, false},
+		{"question and post threads are separate", QuestionThread(1), PostThread(1), 7, 7, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Pseudonym(tt.threadA, tt.userA), Pseudonym(tt.threadB, tt.userB)
+			if (a == b) != tt.same {
+				t.Errorf("Pseudonym %q vs %q, want same = %v", a, b, tt.same)
+			}
+			if !strings.HasPrefix(a, prefix) || len(strings.TrimPrefix(a, prefix)) != 6 {
+				t.Errorf("Pseudonym format = %q", a)
+			}
+		})
+	}
+}
+
+func TestPseudonymDependsOnSecret(t *testing.T) {
+	t.Setenv("ANONYMOUS_SECRET", "first")
+	first := Pseudonym(QuestionThread(1), 7)
+
+	t.Setenv("ANONYMOUS_SECRET", "second")
+	if Pseudonym(QuestionThread(1), 7) == first {
+		t.Error("Pseudonym did not change with ANONYMOUS_SECRET")
+	}
+
+	t.Setenv("ANONYMOUS_SECRET", "")
+	t.Setenv("JWT_SECRET", "first")
+	if Pseudonym(QuestionThread(1), 7) != first {
+		t.Error("Pseudonym does not fall back to JWT_SECRET")
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+)
+
+// Host ảnh mặc định được phép nhúng (ảnh tải lên qua Cloudinary); ghi đè bằng UPLOAD_IMAGE_HOSTS, phân tách bởi dấu phẩy
+const defaultUploadImageHosts = "res.cloudinary.com"
+
+var (
+	sanitizerOnce sync.Once
+	sanitizer     *bluemonday.Policy
+)
+
+// contentPolicy là danh sách cho phép dùng cho nội dung người dùng: định dạng cơ bản, bảng, khối code
+// và ảnh chỉ từ host tải lên của hệ thống. Mọi thuộc tính sự kiện, style, script đều bị loại bỏ.
+func contentPolicy() *bluemonday.Policy {
+	sanitizerOnce.Do(func() {
+		p := bluemonday.NewPolicy()
+		p.AllowStandardURLs()
+
+		p.AllowElements(
+			"p", "br", "hr", "div", "span",
+			"b", "strong", "i", "em", "u", "s", "strike", "del", "ins", "sub", "sup", "mark", "small",
+			"h1", "h2", "h3", "h4", "h5", "h6",
+			"blockquote", "ul", "ol", "li", "dl", "dt", "dd",
+			"pre", "code", "kbd", "samp",
+			"table", "thead", "tbody", "tfoot", "tr", "th", "td", "caption",
+			"figure", "figcaption",
+		)
+		p.AllowAttrs("href").OnElements("a")
+		p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+		p.AllowAttrs("colspan", "rowspan").Matching(bluemonday.Integer).OnElements("td", "th")
+		// Ngôn ngữ của khối code cho highlight phía client (ví dụ class="language-go")
+		p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("pre", "code")
+
+		p.AllowAttrs("src").Matching(uploadImagePattern()).OnElements("img")
+		p.AllowAttrs("alt", "title").OnElements("img")
+		p.AllowAttrs("width", "height").Matching(bluemonday.NumberOrPercent).OnElements("img")
+
+		sanitizer = p
+	})
+	return sanitizer
+}
+
+func uploadImagePattern() *regexp.Regexp {
+	hosts := os.Getenv("UPLOAD_IMAGE_HOSTS")
+	if hosts == "" {
+		hosts = defaultUploadImageHosts
+	}
+	var quoted []string
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			quoted = append(quoted, regexp.QuoteMeta(host))
+		}
+	}
+	return regexp.MustCompile(`^https://(` + strings.Join(quoted, "|") + `)/`)
+}
+
+// SanitizeHTML lọc HTML do người dùng gửi theo danh sách cho phép và gắn rel="nofollow ugc" cho mọi liên kết
+func SanitizeHTML(content string) string {
+	if content == "" {
+		return ""
+	}
+	return addLinkRel(contentPolicy().Sanitize(content))
+}
+
+// addLinkRel duyệt lại HTML đã lọc và đặt rel="nofollow ugc" cho các thẻ <a>
+func addLinkRel(content string) string {
+	var out bytes.Buffer
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+		if (tokenType == html.StartTagToken || tokenType == html.SelfClosingTagToken) && token.Data == "a" {
+			attrs := token.Attr[:0]
+			for _, attr := range token.Attr {
+				if attr.Key != "rel" {
+					attrs = append(attrs, attr)
+				}
+			}
+			token.Attr = append(attrs, html.Attribute{Key: "rel", Val: "nofollow ugc"})
+		}
+		out.WriteString(token.String())
+	}
+	return out.String()
+}
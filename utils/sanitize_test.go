@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name:  "keeps basic formatting",
+			input: "<p><strong>Xin</strong> <em>chào</em></p>",
+			want:  []string{"<p><strong>Xin</strong> <em>chào</em></p>"},
+		},
+		{
+			name:    "drops script",
+			input:   `<p>ok</p><script>alert(1)</script>`,
+			want:    []string{"<p>ok</p>"},
+			notWant: []string{"script", "alert"},
+		},
+		{
+			name:    "drops event handlers and style",
+			input:   `<p onclick="steal()" style="color:red">text</p>`,
+			want:    []string{"<p>text</p>"},
+			notWant: []string{"onclick", "style"},
+		},
+		{
+			name:    "drops javascript links",
+			input:   `<a href="javascript:alert(1)">x</a>`,
+			notWant: []string{"javascript"},
+		},
+		{
+			name:    "links get nofollow ugc and lose their own rel",
+			input:   `<a href="https://example.com" rel="author">x</a>`,
+			want:    []string{`href="https://example.com"`, `rel="nofollow ugc"`},
+			notWant: []string{"author"},
+		},
+		{
+			name:  "keeps images from the upload host",
+			input: `<img src="https://res.cloudinary.com/demo/image/upload/a.png" alt="a">`,
+			want:  []string{`src="https://res.cloudinary.com/demo/image/upload/a.png"`, `alt="a"`},
+		},
+		{
+			name:    "drops images from other hosts",
+			input:   `<img src="https://evil.example/a.png" alt="a">`,
+			notWant: []string{"evil.example", "src="},
+		},
+		{
+			name:  "keeps code language class",
+			input: `<pre><code class="language-go">x := 1</code></pre>`,
+			want:  []string{`<code class="language-go">`},
+		},
+		{
+			name:    "drops other classes",
+			input:   `<code class="hidden">x</code>`,
+			want:    []string{"<code>x</code>"},
+			notWant: []string{"hidden"},
+		},
+		{
+			name:    "drops non numeric table spans",
+			input:   `<table><tr><td colspan="2" rowspan="x">a</td></tr></table>`,
+			want:    []string{`colspan="2"`},
+			notWant: []string{"rowspan"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeHTML(tt.input)
+			if tt.input == "" && got != "" {
+				t.Fatalf("SanitizeHTML(%q) = %q, want empty", tt.input, got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("SanitizeHTML(%q) = %q, want it to contain %q", tt.input, got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("SanitizeHTML(%q) = %q, must not contain %q", tt.input, got, notWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapURL là một mục trong sitemap hoặc sitemap index
+type SitemapURL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type sitemapLoc struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	NS      string       `xml:"xmlns,attr"`
+	URLs    []sitemapLoc `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	NS       string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+// RenderSitemap xuất danh sách URL theo chuẩn sitemaps.org (tối đa 50.000 URL mỗi tệp)
+func RenderSitemap(urls []SitemapURL) ([]byte, error) {
+	set := sitemapURLSet{NS: sitemapNS}
+	for _, u := range urls {
+		set.URLs = append(set.URLs, toSitemapLoc(u))
+	}
+	return marshalFeed(set)
+}
+
+// RenderSitemapIndex xuất sitemap index trỏ tới các tệp sitemap con
+func RenderSitemapIndex(sitemaps []SitemapURL) ([]byte, error) {
+	index := sitemapIndex{NS: sitemapNS}
+	for _, u := range sitemaps {
+		index.Sitemaps = append(index.Sitemaps, toSitemapLoc(u))
+	}
+	return marshalFeed(index)
+}
+
+func toSitemapLoc(u SitemapURL) sitemapLoc {
+	loc := sitemapLoc{Loc: u.Loc}
+	if !u.LastMod.IsZero() {
+		loc.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+	}
+	return loc
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Độ dài tối đa của slug (không tính hậu tố -2, -3... khi trùng)
+const maxSlugLength = 80
+
+// Slugify chuyển tiêu đề thành slug ASCII chữ thường: bỏ dấu tiếng Việt (đ → d), thay ký tự khác chữ/số bằng "-"
+// và cắt ở ranh giới từ. Trả về chuỗi rỗng nếu tiêu đề không có chữ hoặc số nào.
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(title) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Dấu thanh và dấu mũ đã được tách khỏi chữ cái gốc bởi NFD
+			continue
+		case r == 'đ' || r == 'Đ':
+			r = 'd'
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			r = unicode.ToLower(r)
+		default:
+			dash = b.Len() > 0
+			continue
+		}
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteRune(r)
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > maxSlugLength/2 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimRight(slug, "-")
+	}
+	return slug
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"ascii", "Hello World", "hello-world"},
+		{"vietnamese diacritics", "Làm thế nào để học Go?", "lam-the-nao-de-hoc-go"},
+		{"d with stroke", "Đường đi của dữ liệu", "duong-di-cua-du-lieu"},
+		{"collapses separators", "  Go -- & -- Rust!!  ", "go-rust"},
+		{"keeps digits", "Top 10 câu hỏi 2026", "top-10-cau-hoi-2026"},
+		{"no letters or digits", "?!… —", ""},
+		{"drops non latin letters", "日本語 test", "test"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugifyTruncatesAtWordBoundary(t *testing.T) {
+	title := strings.Repeat("abcdefghi ", 12)
+	got := Slugify(title)
+	if len(got) > maxSlugLength {
+		t.Fatalf("len(Slugify) = %d, want at most %d", len(got), maxSlugLength)
+	}
+	if strings.HasSuffix(got, "-") {
+		t.Errorf("Slugify ends with a dash: %q", got)
+	}
+	for _, word := range strings.Split(got, "-") {
+		if word != "abcdefghi" {
+			t.Fatalf("Slugify cut a word in half: %q", got)
+		}
+	}
+
+	// Một từ dài không có ranh giới thì bị cắt đúng độ dài tối đa
+	long := Slugify(strings.Repeat("a", 200))
+	if len(long) != maxSlugLength {
+		t.Errorf("len(Slugify(long word)) = %d, want %d", len(long), maxSlugLength)
+	}
+}
@@ -1,13 +1,48 @@
 package utils
 
 import (
-	"regexp"
 	"strings"
+
+	"golang.org/x/net/html"
 )
 
+// Các thẻ khối: khi gặp sẽ chèn khoảng trắng để chữ ở hai khối liền nhau không bị dính vào nhau
+var blockElements = map[string]bool{
+	"p": true, "br": true, "hr": true, "div": true, "li": true, "ul": true, "ol": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "pre": true, "tr": true, "td": true, "th": true, "table": true,
+	"dt": true, "dd": true, "figure": true, "figcaption": true, "caption": true,
+}
+
+// StripHTML chuyển HTML thành văn bản thuần bằng tokenizer: giải mã entity,
+// bỏ nội dung script/style và gộp khoảng trắng
 func StripHTML(content string) string {
-	re := regexp.MustCompile(`<[^>]+>`)
-	clean := re.ReplaceAllString(content, "")
-	clean = strings.TrimSpace(strings.Join(strings.Fields(clean), " "))
-	return clean
+	var b strings.Builder
+	skipDepth := 0
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.TextToken:
+			if skipDepth == 0 {
+				b.Write(tokenizer.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			if tag == "script" || tag == "style" {
+				if tokenType == html.StartTagToken {
+					skipDepth++
+				} else if tokenType == html.EndTagToken && skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if blockElements[tag] {
+				b.WriteByte(' ')
+			}
+		}
+	}
 }